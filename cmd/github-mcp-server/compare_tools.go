@@ -0,0 +1,103 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/github/github-mcp-server/internal/toolsnaps"
+	"github.com/github/github-mcp-server/pkg/github"
+	"github.com/github/github-mcp-server/pkg/translations"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var compareToolsCmd = &cobra.Command{
+	Use:   "compare-tools",
+	Short: "Detect breaking changes between two versions of the tool schemas",
+	Long: `Compare two tool manifests and classify every schema difference as breaking or additive.
+
+A manifest is either a directory of tool snapshots (such as pkg/github/__toolsnaps__)
+or a JSON file containing an array of tools or a tools/list result. When --head is
+omitted, the tools compiled into this binary are used.
+
+Breaking changes are removed tools, removed or renamed properties, newly required
+properties, narrowed enums and changed types. Tools removed in favour of a deprecated
+alias are reported as non-breaking renames.
+
+The command exits with a non-zero status if any breaking change is found.
+
+Examples:
+  # Compare the current build against the committed snapshots
+  github-mcp-server compare-tools
+
+  # Compare snapshots from a previous release against the current build
+  git worktree add /tmp/prev v0.20.0
+  github-mcp-server compare-tools --base=/tmp/prev/pkg/github/__toolsnaps__
+
+  # Compare two tools/list results as JSON
+  github-mcp-server compare-tools --base=old.json --head=new.json --output=json`,
+	// Breaking changes are reported as an error; the usage text adds nothing in that case.
+	SilenceUsage: true,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		return runCompareTools()
+	},
+}
+
+func init() {
+	compareToolsCmd.Flags().String("base", "pkg/github/__toolsnaps__", "Baseline manifest: a snapshot directory or JSON file")
+	compareToolsCmd.Flags().String("head", "", "Manifest to check: a snapshot directory or JSON file (defaults to the current build)")
+	compareToolsCmd.Flags().StringP("output", "o", "text", "Output format: text or json")
+	_ = viper.BindPFlag("compare-tools-base", compareToolsCmd.Flags().Lookup("base"))
+	_ = viper.BindPFlag("compare-tools-head", compareToolsCmd.Flags().Lookup("head"))
+	_ = viper.BindPFlag("compare-tools-output", compareToolsCmd.Flags().Lookup("output"))
+
+	rootCmd.AddCommand(compareToolsCmd)
+}
+
+func runCompareTools() error {
+	base, err := toolsnaps.LoadManifest(viper.GetString("compare-tools-base"))
+	if err != nil {
+		return err
+	}
+
+	var head toolsnaps.Manifest
+	if headPath := viper.GetString("compare-tools-head"); headPath != "" {
+		head, err = toolsnaps.LoadManifest(headPath)
+		if err != nil {
+			return err
+		}
+	} else {
+		head = toolsnaps.Manifest{}
+		for _, tool := range github.AllTools(translations.NullTranslationHelper) {
+			if err := head.Add(tool.Tool); err != nil {
+				return fmt.Errorf("failed to add tool %s to manifest: %w", tool.Tool.Name, err)
+			}
+		}
+	}
+
+	report := toolsnaps.CompareManifests(base, head, github.DeprecatedToolAliasTargets())
+
+	switch outputFormat := viper.GetString("compare-tools-output"); outputFormat {
+	case "json":
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			return fmt.Errorf("failed to encode report: %w", err)
+		}
+	case "text":
+		if len(report.Changes) == 0 {
+			fmt.Println("No tool schema changes found.")
+		}
+		for _, change := range report.Changes {
+			fmt.Println(change.String())
+		}
+	default:
+		return fmt.Errorf("unknown output format: %s", outputFormat)
+	}
+
+	if breaking := report.Breaking(); len(breaking) > 0 {
+		return fmt.Errorf("found %d breaking tool schema change(s)", len(breaking))
+	}
+	return nil
+}
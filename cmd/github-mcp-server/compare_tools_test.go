@@ -0,0 +1,21 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCompareToolsAgainstSnapshots runs compare-tools as documented, against the committed
+// snapshots, so that a stale or missing snapshot fails here rather than for the next person
+// checking a release.
+func TestCompareToolsAgainstSnapshots(t *testing.T) {
+	viper.Set("compare-tools-base", filepath.Join("..", "..", "pkg", "github", "__toolsnaps__"))
+	viper.Set("compare-tools-head", "")
+	viper.Set("compare-tools-output", "text")
+	t.Cleanup(viper.Reset)
+
+	require.NoError(t, runCompareTools())
+}
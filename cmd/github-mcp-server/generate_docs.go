@@ -448,12 +448,12 @@ func generateDeprecatedAliasesTable() string {
 	var buf strings.Builder
 
 	// Add table header
-	buf.WriteString("| Old Name | New Name |\n")
-	buf.WriteString("|----------|----------|\n")
+	buf.WriteString("| Old Name | New Name | Sunset |\n")
+	buf.WriteString("|----------|----------|--------|\n")
 
 	aliases := github.DeprecatedToolAliases
 	if len(aliases) == 0 {
-		buf.WriteString("| *(none currently)* | |")
+		buf.WriteString("| *(none currently)* | | |")
 	} else {
 		// Sort keys for deterministic output
 		var oldNames []string
@@ -463,8 +463,12 @@ func generateDeprecatedAliasesTable() string {
 		sort.Strings(oldNames)
 
 		for i, oldName := range oldNames {
-			newName := aliases[oldName]
-			fmt.Fprintf(&buf, "| `%s` | `%s` |", oldName, newName)
+			newName := aliases[oldName].Tool
+			sunset := aliases[oldName].Sunset
+			if sunset == "" {
+				sunset = "not scheduled"
+			}
+			fmt.Fprintf(&buf, "| `%s` | `%s` | %s |", oldName, newName, sunset)
 			if i < len(oldNames)-1 {
 				buf.WriteString("\n")
 			}
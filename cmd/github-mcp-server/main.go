@@ -8,8 +8,14 @@ import (
 	"time"
 
 	"github.com/github/github-mcp-server/internal/ghmcp"
+	"github.com/github/github-mcp-server/pkg/audit"
 	"github.com/github/github-mcp-server/pkg/github"
 	ghhttp "github.com/github/github-mcp-server/pkg/http"
+	"github.com/github/github-mcp-server/pkg/http/eventstore"
+	"github.com/github/github-mcp-server/pkg/http/middleware"
+	"github.com/github/github-mcp-server/pkg/lockdown"
+	"github.com/github/github-mcp-server/pkg/sanitize"
+	"github.com/github/github-mcp-server/pkg/tokensource"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
@@ -33,8 +39,19 @@ var (
 		Short: "Start stdio server",
 		Long:  `Start a server that communicates via standard input/output streams using JSON-RPC messages.`,
 		RunE: func(_ *cobra.Command, _ []string) error {
+			app, err := appConfig()
+			if err != nil {
+				return err
+			}
+			tokenSource, err := tokenSource()
+			if err != nil {
+				return err
+			}
+			if app != nil && tokenSource != nil {
+				return errors.New("GitHub App authentication can't be combined with another token source")
+			}
 			token := viper.GetString("personal_access_token")
-			if token == "" {
+			if token == "" && app == nil && tokenSource == nil {
 				return errors.New("GITHUB_PERSONAL_ACCESS_TOKEN not set")
 			}
 
@@ -70,10 +87,20 @@ var (
 			}
 
 			ttl := viper.GetDuration("repo-access-cache-ttl")
+			lockdownPolicy, err := loadLockdownPolicy()
+			if err != nil {
+				return err
+			}
+			injectionDetector, err := loadInjectionDetector()
+			if err != nil {
+				return err
+			}
 			stdioServerConfig := ghmcp.StdioServerConfig{
 				Version:              version,
 				Host:                 viper.GetString("host"),
 				Token:                token,
+				App:                  app,
+				TokenSource:          tokenSource,
 				EnabledToolsets:      enabledToolsets,
 				EnabledTools:         enabledTools,
 				EnabledFeatures:      enabledFeatures,
@@ -85,7 +112,25 @@ var (
 				ContentWindowSize:    viper.GetInt("content-window-size"),
 				LockdownMode:         viper.GetBool("lockdown-mode"),
 				InsidersMode:         viper.GetBool("insiders"),
+				StrictToolAliases:    viper.GetBool("strict-tool-aliases"),
+				PromptsDir:           viper.GetString("prompts-dir"),
 				RepoAccessCacheTTL:   &ttl,
+				RepoAccessCacheSize:  viper.GetInt("repo-access-cache-size"),
+				RepoAccessCacheDir:   viper.GetString("repo-access-cache-dir"),
+				LockdownPolicy:       lockdownPolicy,
+				LockdownPlaceholders: viper.GetBool("lockdown-placeholders"),
+				InjectionDetector:    injectionDetector,
+
+				ResourcePollInterval:     viper.GetDuration("resource-poll-interval"),
+				MaxResourceSubscriptions: viper.GetInt("max-resource-subscriptions"),
+				MetricsAddr:              viper.GetString("metrics-addr"),
+				OTLPEndpoint:             otlpEndpoint(),
+				Audit:                    auditOptions(),
+				APICacheBytes:            int64(viper.GetInt("api-cache-size")) << 20,
+				APICacheDir:              viper.GetString("api-cache-dir"),
+				RateLimitMaxWait:         viper.GetDuration("rate-limit-max-wait"),
+				RateLimitMeta:            viper.GetBool("rate-limit-meta"),
+				DisableInjectionMarkers:  viper.GetBool("disable-injection-markers"),
 			}
 			return ghmcp.RunStdioServer(stdioServerConfig)
 		},
@@ -97,19 +142,63 @@ var (
 		Long:  `Start an HTTP server that listens for MCP requests over HTTP.`,
 		RunE: func(_ *cobra.Command, _ []string) error {
 			ttl := viper.GetDuration("repo-access-cache-ttl")
+			lockdownPolicy, err := loadLockdownPolicy()
+			if err != nil {
+				return err
+			}
+			injectionDetector, err := loadInjectionDetector()
+			if err != nil {
+				return err
+			}
+			rateLimits, err := middleware.ParseRateLimits(viper.GetString("tool-rate-limits"), viper.GetString("tool-concurrency-limits"))
+			if err != nil {
+				return fmt.Errorf("invalid tool call limits: %w", err)
+			}
 			httpConfig := ghhttp.ServerConfig{
-				Version:              version,
-				Host:                 viper.GetString("host"),
-				Port:                 viper.GetInt("port"),
-				BaseURL:              viper.GetString("base-url"),
-				ResourcePath:         viper.GetString("base-path"),
-				ExportTranslations:   viper.GetBool("export-translations"),
-				EnableCommandLogging: viper.GetBool("enable-command-logging"),
-				LogFilePath:          viper.GetString("log-file"),
-				ContentWindowSize:    viper.GetInt("content-window-size"),
-				LockdownMode:         viper.GetBool("lockdown-mode"),
-				RepoAccessCacheTTL:   &ttl,
-				ScopeChallenge:       viper.GetBool("scope-challenge"),
+				Version:                 version,
+				Host:                    viper.GetString("host"),
+				Port:                    viper.GetInt("port"),
+				BaseURL:                 viper.GetString("base-url"),
+				ResourcePath:            viper.GetString("base-path"),
+				ExportTranslations:      viper.GetBool("export-translations"),
+				EnableCommandLogging:    viper.GetBool("enable-command-logging"),
+				LogFilePath:             viper.GetString("log-file"),
+				ContentWindowSize:       viper.GetInt("content-window-size"),
+				LockdownMode:            viper.GetBool("lockdown-mode"),
+				StrictToolAliases:       viper.GetBool("strict-tool-aliases"),
+				PromptsDir:              viper.GetString("prompts-dir"),
+				RepoAccessCacheTTL:      &ttl,
+				RepoAccessCacheSize:     viper.GetInt("repo-access-cache-size"),
+				RepoAccessCacheDir:      viper.GetString("repo-access-cache-dir"),
+				LockdownPolicy:          lockdownPolicy,
+				LockdownPlaceholders:    viper.GetBool("lockdown-placeholders"),
+				InjectionDetector:       injectionDetector,
+				DisableInjectionMarkers: viper.GetBool("disable-injection-markers"),
+				ScopeChallenge:          viper.GetBool("scope-challenge"),
+				OAuthClientID:           viper.GetString("oauth-client-id"),
+				OAuthClientSecret:       viper.GetString("oauth_client_secret"),
+				OAuthUpstreamURL:        viper.GetString("oauth-upstream-url"),
+				OAuthRedirectURIs:       viper.GetStringSlice("oauth-redirect-uris"),
+				TLSCertFile:             viper.GetString("tls-cert"),
+				TLSKeyFile:              viper.GetString("tls-key"),
+				TLSClientCAFile:         viper.GetString("tls-client-ca"),
+				MetricsAddr:             viper.GetString("metrics-addr"),
+				OTLPEndpoint:            otlpEndpoint(),
+				ShutdownDelay:           viper.GetDuration("shutdown-delay"),
+				StatefulSessions:        viper.GetBool("stateful-sessions"),
+				SessionIdleTimeout:      viper.GetDuration("session-idle-timeout"),
+				MaxSessions:             viper.GetInt("max-sessions"),
+				SessionEventDir:         viper.GetString("session-event-dir"),
+				SessionEventBufferBytes: viper.GetInt("session-event-buffer-bytes"),
+				RateLimits:              rateLimits,
+				Audit:                   auditOptions(),
+				APICacheBytes:           int64(viper.GetInt("api-cache-size")) << 20,
+				APICacheDir:             viper.GetString("api-cache-dir"),
+				RateLimitMaxWait:        viper.GetDuration("rate-limit-max-wait"),
+				RateLimitMeta:           viper.GetBool("rate-limit-meta"),
+
+				ResourcePollInterval:     viper.GetDuration("resource-poll-interval"),
+				MaxResourceSubscriptions: viper.GetInt("max-resource-subscriptions"),
 			}
 
 			return ghhttp.RunHTTPServer(httpConfig)
@@ -135,14 +224,58 @@ func init() {
 	rootCmd.PersistentFlags().String("gh-host", "", "Specify the GitHub hostname (for GitHub Enterprise etc.)")
 	rootCmd.PersistentFlags().Int("content-window-size", 5000, "Specify the content window size")
 	rootCmd.PersistentFlags().Bool("lockdown-mode", false, "Enable lockdown mode")
+	rootCmd.PersistentFlags().String("lockdown-policy", "", "YAML file of additional bots, organizations, teams and author associations trusted in lockdown mode")
+	rootCmd.PersistentFlags().Bool("lockdown-placeholders", false, "List a placeholder for every item lockdown mode hides from a tool result, instead of only counting them")
+	rootCmd.PersistentFlags().String("injection-rules", "", "YAML file of prompt injection rules and threshold, merged into the built-in rules")
+	rootCmd.PersistentFlags().Bool("disable-injection-markers", false, "Leave user-authored text that looks like a prompt injection unmarked, without warning about it in results")
 	rootCmd.PersistentFlags().Bool("insiders", false, "Enable insiders features")
+	rootCmd.PersistentFlags().Bool("strict-tool-aliases", false, "Reject calls to deprecated tool aliases instead of routing them to the replacement tool")
+	rootCmd.PersistentFlags().String("prompts-dir", "", "Directory of YAML or Markdown prompt definitions to serve alongside the built-in prompts")
 	rootCmd.PersistentFlags().Duration("repo-access-cache-ttl", 5*time.Minute, "Override the repo access cache TTL (e.g. 1m, 0s to disable)")
+	rootCmd.PersistentFlags().Int("repo-access-cache-size", lockdown.DefaultRepoAccessCacheSize, "Maximum number of repositories kept in the in-memory repo access cache")
+	rootCmd.PersistentFlags().String("repo-access-cache-dir", "", "Keep the repo access cache in this directory instead of in memory, so that it can be shared by replicas of the HTTP server")
+	rootCmd.PersistentFlags().String("otlp-endpoint", "", "OpenTelemetry collector base URL for OTLP/HTTP trace export (e.g. http://localhost:4318); defaults to OTEL_EXPORTER_OTLP_ENDPOINT")
+	rootCmd.PersistentFlags().String("metrics-addr", "", "Address for a separate Prometheus metrics listener (e.g. 127.0.0.1:9090); metrics are disabled when unset")
+	rootCmd.PersistentFlags().String("audit-log", "", "Path to a JSON Lines audit log of tool calls")
+	rootCmd.PersistentFlags().Int("audit-log-max-size", 100, "Size in megabytes at which the audit log is rotated")
+	rootCmd.PersistentFlags().Int("audit-log-max-backups", 5, "Number of rotated audit log files to keep")
+	rootCmd.PersistentFlags().String("audit-webhook-url", "", "URL to post audit events to as JSON Lines; deliveries are signed with GITHUB_AUDIT_WEBHOOK_SECRET when set")
+	rootCmd.PersistentFlags().StringSlice("audit-redact", nil, "Additional tool argument names to redact from audit events, matched case-insensitively with wildcards (e.g. \"query,*_sha\")")
+	rootCmd.PersistentFlags().Int("api-cache-size", 0, "Size in megabytes of a cache of GitHub API responses, revalidated with conditional requests; off when 0. The cache holds response bodies, including private repository data, in memory or in --api-cache-dir")
+	rootCmd.PersistentFlags().String("api-cache-dir", "", "Keep cached GitHub API responses in this directory so that they survive restarts, instead of in memory")
+	rootCmd.PersistentFlags().Bool("rate-limit-meta", false, "Attach the remaining GitHub API rate limits to every tool result in _meta")
+	rootCmd.PersistentFlags().Duration("resource-poll-interval", github.DefaultResourcePollInterval, "Interval at which subscribed resources are polled for changes (0s disables resource subscriptions; the http command supports them with --stateful-sessions only)")
+	rootCmd.PersistentFlags().Int("max-resource-subscriptions", 50, "Maximum number of resources a session may subscribe to (0 for no limit)")
+	rootCmd.PersistentFlags().Duration("rate-limit-max-wait", 0, "Longest total time a request waits for GitHub rate limits to reset before retrying it, e.g. 10s (retries are off when unset)")
+
+	// Stdio-specific flags
+	stdioCmd.Flags().String("token-file", "", "Read the GitHub token from this file, re-reading it when it changes")
+	stdioCmd.Flags().String("token-command", "", "Get the GitHub token from the output of this shell command (e.g. \"gh auth token\"), run with sh -c (cmd /C on Windows) and again when the token is rejected")
+	stdioCmd.Flags().Bool("git-credential", false, "Get the GitHub token from the git credential helper configured for the GitHub host")
+	stdioCmd.Flags().String("app-id", "", "Authenticate as this GitHub App (ID or client ID) instead of with a personal access token")
+	stdioCmd.Flags().String("app-private-key-file", "", "Path to the PEM private key of the GitHub App")
+	stdioCmd.Flags().Int64("app-installation-id", 0, "ID of the GitHub App installation to authenticate as")
+	stdioCmd.Flags().String("app-installation-owner", "", "Organization, user or owner/repo whose GitHub App installation to authenticate as, when the installation ID is not known")
 
 	// HTTP-specific flags
 	httpCmd.Flags().Int("port", 8082, "HTTP server port")
 	httpCmd.Flags().String("base-url", "", "Base URL where this server is publicly accessible (for OAuth resource metadata)")
 	httpCmd.Flags().String("base-path", "", "Externally visible base path for the HTTP server (for OAuth resource metadata)")
 	httpCmd.Flags().Bool("scope-challenge", false, "Enable OAuth scope challenge responses")
+	httpCmd.Flags().String("oauth-client-id", "", "Client ID of a GitHub OAuth App or GitHub App; serves an OAuth authorization server with dynamic client registration that proxies to it (secret in GITHUB_OAUTH_CLIENT_SECRET, requires --base-url)")
+	httpCmd.Flags().String("oauth-upstream-url", "", "OAuth base URL of GitHub for --oauth-client-id (default https://github.com/login/oauth; use https://HOSTNAME/login/oauth for GitHub Enterprise Server)")
+	httpCmd.Flags().StringSlice("oauth-redirect-uris", nil, "Redirect URIs clients of --oauth-client-id may register besides loopback addresses, matched exactly (e.g. \"https://vscode.dev/redirect\")")
+	httpCmd.Flags().String("tls-cert", "", "Path to a PEM TLS certificate; serves HTTPS when set together with --tls-key")
+	httpCmd.Flags().String("tls-key", "", "Path to the PEM private key for --tls-cert")
+	httpCmd.Flags().Bool("stateful-sessions", false, "Keep an MCP session per client so that interrupted streams can be resumed with Last-Event-ID")
+	httpCmd.Flags().Duration("session-idle-timeout", 30*time.Minute, "Close stateful sessions that receive no requests for this long (0 keeps them open)")
+	httpCmd.Flags().Int("max-sessions", 1000, "Maximum number of concurrent stateful sessions (0 means no limit)")
+	httpCmd.Flags().String("session-event-dir", "", "Keep events of stateful sessions in files under this directory instead of in memory")
+	httpCmd.Flags().Int("session-event-buffer-bytes", eventstore.DefaultMemoryBytes, "Maximum bytes of events kept in memory across all stateful sessions")
+	httpCmd.Flags().String("tool-rate-limits", "", "Per-client tool call rate limits by tool class, e.g. \"default=600/m,search=30/m,write=60/m\"")
+	httpCmd.Flags().String("tool-concurrency-limits", "", "Per-client limits on concurrent tool calls by tool class, e.g. \"default=8,search=2,write=4\"")
+	httpCmd.Flags().Duration("shutdown-delay", 0, "Time to keep serving after /readyz starts failing on shutdown, before in-flight requests are drained")
+	httpCmd.Flags().String("tls-client-ca", "", "Path to a PEM CA bundle; when set, clients of the MCP endpoints must present a certificate signed by one of these CAs")
 
 	// Bind flag to viper
 	_ = viper.BindPFlag("toolsets", rootCmd.PersistentFlags().Lookup("toolsets"))
@@ -156,12 +289,54 @@ func init() {
 	_ = viper.BindPFlag("host", rootCmd.PersistentFlags().Lookup("gh-host"))
 	_ = viper.BindPFlag("content-window-size", rootCmd.PersistentFlags().Lookup("content-window-size"))
 	_ = viper.BindPFlag("lockdown-mode", rootCmd.PersistentFlags().Lookup("lockdown-mode"))
+	_ = viper.BindPFlag("lockdown-policy", rootCmd.PersistentFlags().Lookup("lockdown-policy"))
+	_ = viper.BindPFlag("lockdown-placeholders", rootCmd.PersistentFlags().Lookup("lockdown-placeholders"))
+	_ = viper.BindPFlag("injection-rules", rootCmd.PersistentFlags().Lookup("injection-rules"))
+	_ = viper.BindPFlag("disable-injection-markers", rootCmd.PersistentFlags().Lookup("disable-injection-markers"))
 	_ = viper.BindPFlag("insiders", rootCmd.PersistentFlags().Lookup("insiders"))
+	_ = viper.BindPFlag("strict-tool-aliases", rootCmd.PersistentFlags().Lookup("strict-tool-aliases"))
+	_ = viper.BindPFlag("prompts-dir", rootCmd.PersistentFlags().Lookup("prompts-dir"))
 	_ = viper.BindPFlag("repo-access-cache-ttl", rootCmd.PersistentFlags().Lookup("repo-access-cache-ttl"))
+	_ = viper.BindPFlag("repo-access-cache-size", rootCmd.PersistentFlags().Lookup("repo-access-cache-size"))
+	_ = viper.BindPFlag("repo-access-cache-dir", rootCmd.PersistentFlags().Lookup("repo-access-cache-dir"))
+	_ = viper.BindPFlag("metrics-addr", rootCmd.PersistentFlags().Lookup("metrics-addr"))
+	_ = viper.BindPFlag("otlp-endpoint", rootCmd.PersistentFlags().Lookup("otlp-endpoint"))
+	_ = viper.BindPFlag("audit-log", rootCmd.PersistentFlags().Lookup("audit-log"))
+	_ = viper.BindPFlag("audit-log-max-size", rootCmd.PersistentFlags().Lookup("audit-log-max-size"))
+	_ = viper.BindPFlag("audit-log-max-backups", rootCmd.PersistentFlags().Lookup("audit-log-max-backups"))
+	_ = viper.BindPFlag("audit-webhook-url", rootCmd.PersistentFlags().Lookup("audit-webhook-url"))
+	_ = viper.BindPFlag("audit-redact", rootCmd.PersistentFlags().Lookup("audit-redact"))
+	_ = viper.BindPFlag("api-cache-size", rootCmd.PersistentFlags().Lookup("api-cache-size"))
+	_ = viper.BindPFlag("api-cache-dir", rootCmd.PersistentFlags().Lookup("api-cache-dir"))
+	_ = viper.BindPFlag("rate-limit-max-wait", rootCmd.PersistentFlags().Lookup("rate-limit-max-wait"))
+	_ = viper.BindPFlag("rate-limit-meta", rootCmd.PersistentFlags().Lookup("rate-limit-meta"))
+	_ = viper.BindPFlag("resource-poll-interval", rootCmd.PersistentFlags().Lookup("resource-poll-interval"))
+	_ = viper.BindPFlag("max-resource-subscriptions", rootCmd.PersistentFlags().Lookup("max-resource-subscriptions"))
+	_ = viper.BindPFlag("token-file", stdioCmd.Flags().Lookup("token-file"))
+	_ = viper.BindPFlag("token-command", stdioCmd.Flags().Lookup("token-command"))
+	_ = viper.BindPFlag("git-credential", stdioCmd.Flags().Lookup("git-credential"))
+	_ = viper.BindPFlag("app-id", stdioCmd.Flags().Lookup("app-id"))
+	_ = viper.BindPFlag("app-private-key-file", stdioCmd.Flags().Lookup("app-private-key-file"))
+	_ = viper.BindPFlag("app-installation-id", stdioCmd.Flags().Lookup("app-installation-id"))
+	_ = viper.BindPFlag("app-installation-owner", stdioCmd.Flags().Lookup("app-installation-owner"))
 	_ = viper.BindPFlag("port", httpCmd.Flags().Lookup("port"))
 	_ = viper.BindPFlag("base-url", httpCmd.Flags().Lookup("base-url"))
 	_ = viper.BindPFlag("base-path", httpCmd.Flags().Lookup("base-path"))
 	_ = viper.BindPFlag("scope-challenge", httpCmd.Flags().Lookup("scope-challenge"))
+	_ = viper.BindPFlag("oauth-client-id", httpCmd.Flags().Lookup("oauth-client-id"))
+	_ = viper.BindPFlag("oauth-upstream-url", httpCmd.Flags().Lookup("oauth-upstream-url"))
+	_ = viper.BindPFlag("oauth-redirect-uris", httpCmd.Flags().Lookup("oauth-redirect-uris"))
+	_ = viper.BindPFlag("tls-cert", httpCmd.Flags().Lookup("tls-cert"))
+	_ = viper.BindPFlag("tls-key", httpCmd.Flags().Lookup("tls-key"))
+	_ = viper.BindPFlag("tls-client-ca", httpCmd.Flags().Lookup("tls-client-ca"))
+	_ = viper.BindPFlag("shutdown-delay", httpCmd.Flags().Lookup("shutdown-delay"))
+	_ = viper.BindPFlag("tool-rate-limits", httpCmd.Flags().Lookup("tool-rate-limits"))
+	_ = viper.BindPFlag("tool-concurrency-limits", httpCmd.Flags().Lookup("tool-concurrency-limits"))
+	_ = viper.BindPFlag("stateful-sessions", httpCmd.Flags().Lookup("stateful-sessions"))
+	_ = viper.BindPFlag("session-idle-timeout", httpCmd.Flags().Lookup("session-idle-timeout"))
+	_ = viper.BindPFlag("max-sessions", httpCmd.Flags().Lookup("max-sessions"))
+	_ = viper.BindPFlag("session-event-dir", httpCmd.Flags().Lookup("session-event-dir"))
+	_ = viper.BindPFlag("session-event-buffer-bytes", httpCmd.Flags().Lookup("session-event-buffer-bytes"))
 	// Add subcommands
 	rootCmd.AddCommand(stdioCmd)
 	rootCmd.AddCommand(httpCmd)
@@ -174,6 +349,103 @@ func initConfig() {
 	viper.AutomaticEnv()
 }
 
+// auditOptions returns the audit log configuration from the --audit-* flags.
+func auditOptions() audit.Options {
+	return audit.Options{
+		FilePath: viper.GetString("audit-log"),
+		File: audit.FileSinkOptions{
+			MaxBytes:   int64(viper.GetInt("audit-log-max-size")) << 20,
+			MaxBackups: viper.GetInt("audit-log-max-backups"),
+		},
+		WebhookURL:    viper.GetString("audit-webhook-url"),
+		WebhookSecret: viper.GetString("audit_webhook_secret"),
+		Redactions:    viper.GetStringSlice("audit-redact"),
+	}
+}
+
+// otlpEndpoint returns the --otlp-endpoint flag, falling back to the standard
+// OpenTelemetry environment variable.
+func otlpEndpoint() string {
+	if endpoint := viper.GetString("otlp-endpoint"); endpoint != "" {
+		return endpoint
+	}
+	return os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
+}
+
+// tokenSource returns the configured token provider, or nil when the token is given
+// directly.
+func tokenSource() (tokensource.TokenSource, error) {
+	var sources []tokensource.TokenSource
+	if path := viper.GetString("token-file"); path != "" {
+		sources = append(sources, tokensource.File(path))
+	}
+	if command := viper.GetString("token-command"); strings.TrimSpace(command) != "" {
+		sources = append(sources, tokensource.ShellCommand(command))
+	}
+	if viper.GetBool("git-credential") {
+		sources = append(sources, tokensource.GitCredential(viper.GetString("host")))
+	}
+	switch len(sources) {
+	case 0:
+		return nil, nil
+	case 1:
+		return sources[0], nil
+	default:
+		return nil, errors.New("only one of GITHUB_TOKEN_FILE, GITHUB_TOKEN_COMMAND and GITHUB_GIT_CREDENTIAL may be set")
+	}
+}
+
+// appConfig returns the GitHub App authentication configuration, or nil when no app is
+// configured.
+func appConfig() (*tokensource.AppConfig, error) {
+	appID := viper.GetString("app-id")
+	if appID == "" {
+		return nil, nil
+	}
+	keyFile := viper.GetString("app-private-key-file")
+	if keyFile == "" {
+		return nil, errors.New("GITHUB_APP_PRIVATE_KEY_FILE not set")
+	}
+	key, err := os.ReadFile(keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read GitHub App private key: %w", err)
+	}
+	installationID := viper.GetInt64("app-installation-id")
+	owner := viper.GetString("app-installation-owner")
+	if installationID == 0 && owner == "" {
+		return nil, errors.New("GITHUB_APP_INSTALLATION_ID or GITHUB_APP_INSTALLATION_OWNER must be set")
+	}
+	return &tokensource.AppConfig{
+		AppID:          appID,
+		PrivateKey:     key,
+		InstallationID: installationID,
+		Owner:          owner,
+	}, nil
+}
+
+// loadLockdownPolicy loads the lockdown trust policy file, if one is configured.
+func loadLockdownPolicy() (*lockdown.Policy, error) {
+	path := viper.GetString("lockdown-policy")
+	if path == "" {
+		return nil, nil
+	}
+	return lockdown.LoadPolicy(path)
+}
+
+// loadInjectionDetector builds the prompt injection detector from the rules file, if one is
+// configured, or the built-in rules.
+func loadInjectionDetector() (*sanitize.InjectionDetector, error) {
+	path := viper.GetString("injection-rules")
+	if path == "" {
+		return sanitize.DefaultInjectionDetector(), nil
+	}
+	rules, err := sanitize.LoadInjectionRules(path)
+	if err != nil {
+		return nil, err
+	}
+	return sanitize.NewInjectionDetector(rules)
+}
+
 func main() {
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintf(os.Stderr, "%v\n", err)
@@ -12,6 +12,7 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/github/github-mcp-server/pkg/audit"
 	"github.com/github/github-mcp-server/pkg/errors"
 	"github.com/github/github-mcp-server/pkg/git/gitops/shell"
 	"github.com/github/github-mcp-server/pkg/github"
@@ -19,13 +20,18 @@ import (
 	"github.com/github/github-mcp-server/pkg/inventory"
 	"github.com/github/github-mcp-server/pkg/lockdown"
 	mcplog "github.com/github/github-mcp-server/pkg/log"
+	"github.com/github/github-mcp-server/pkg/metrics"
 	"github.com/github/github-mcp-server/pkg/raw"
+	"github.com/github/github-mcp-server/pkg/sanitize"
 	"github.com/github/github-mcp-server/pkg/scopes"
+	"github.com/github/github-mcp-server/pkg/tokensource"
+	"github.com/github/github-mcp-server/pkg/tracing"
 	"github.com/github/github-mcp-server/pkg/translations"
 	"github.com/github/github-mcp-server/pkg/utils"
 	gogithub "github.com/google/go-github/v82/github"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/shurcooL/githubv4"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // githubClients holds all the GitHub API clients created for a server instance.
@@ -59,8 +65,33 @@ func createGitHubClients(cfg github.MCPServerConfig, apiHost utils.APIHostResolv
 		return nil, fmt.Errorf("failed to get Raw URL: %w", err)
 	}
 
-	// Construct REST client
-	restClient := gogithub.NewClient(nil).WithAuthToken(cfg.Token)
+	// Record API usage underneath authentication when metrics or tracing are enabled
+	var baseTransport http.RoundTripper = http.DefaultTransport
+	if cfg.Metrics != nil {
+		baseTransport = &transport.MetricsTransport{Transport: baseTransport, Metrics: cfg.Metrics}
+	}
+	if cfg.TracerProvider != nil {
+		baseTransport = transport.NewTracingTransport(baseTransport, cfg.TracerProvider)
+	}
+	// The cache sees the Authorization header, which scopes its entries to the token
+	if cfg.APICache != nil {
+		baseTransport = &transport.CachingTransport{Transport: baseTransport, Store: cfg.APICache}
+	}
+	// Retries are recorded by metrics and tracing as separate requests
+	if cfg.RateLimitMaxWait > 0 {
+		baseTransport = &transport.RetryTransport{Transport: baseTransport, MaxWait: cfg.RateLimitMaxWait}
+	}
+	if cfg.RateLimitMeta {
+		baseTransport = &transport.RateLimitTransport{Transport: baseTransport}
+	}
+
+	// Construct REST client. Tokens from a token source are set per request, as they change
+	restClient := gogithub.NewClient(&http.Client{Transport: baseTransport}).WithAuthToken(cfg.Token)
+	if cfg.TokenSource != nil {
+		restClient = gogithub.NewClient(&http.Client{
+			Transport: &transport.BearerAuthTransport{Transport: baseTransport, Source: cfg.TokenSource},
+		})
+	}
 	restClient.UserAgent = fmt.Sprintf("github-mcp-server/%s", cfg.Version)
 	restClient.BaseURL = restURL
 	restClient.UploadURL = uploadURL
@@ -70,9 +101,10 @@ func createGitHubClients(cfg github.MCPServerConfig, apiHost utils.APIHostResolv
 	gqlHTTPClient := &http.Client{
 		Transport: &transport.BearerAuthTransport{
 			Transport: &transport.GraphQLFeaturesTransport{
-				Transport: http.DefaultTransport,
+				Transport: baseTransport,
 			},
-			Token: cfg.Token,
+			Token:  cfg.Token,
+			Source: cfg.TokenSource,
 		},
 	}
 
@@ -90,7 +122,16 @@ func createGitHubClients(cfg github.MCPServerConfig, apiHost utils.APIHostResolv
 		if cfg.RepoAccessTTL != nil {
 			opts = append(opts, lockdown.WithTTL(*cfg.RepoAccessTTL))
 		}
-		repoAccessCache = lockdown.GetInstance(gqlClient, opts...)
+		if cfg.RepoAccessStore != nil {
+			opts = append(opts, lockdown.WithStore(cfg.RepoAccessStore))
+		}
+		if cfg.LockdownPolicy != nil {
+			opts = append(opts, lockdown.WithPolicy(cfg.LockdownPolicy))
+		}
+		repoAccessCache = lockdown.NewRepoAccessCache(gqlClient, opts...)
+		if cfg.Metrics != nil {
+			cfg.Metrics.RegisterLockdownCache(repoAccessCache.Stats)
+		}
 	}
 
 	return &githubClients{
@@ -147,7 +188,8 @@ func NewStdioMCPServer(ctx context.Context, cfg github.MCPServerConfig) (*mcp.Se
 	)
 	// Build and register the tool/resource/prompt inventory
 	inventoryBuilder := github.NewInventory(cfg.Translator).
-		WithDeprecatedAliases(github.DeprecatedToolAliases).
+		AddPrompts(cfg.Prompts...).
+		WithDeprecatedAliases(github.DeprecatedToolAliasTargets()).
 		WithReadOnly(cfg.ReadOnly).
 		WithToolsets(github.ResolvedEnabledToolsets(cfg.DynamicToolsets, cfg.EnabledToolsets, cfg.EnabledTools)).
 		WithTools(github.CleanTools(cfg.EnabledTools)).
@@ -159,6 +201,9 @@ func NewStdioMCPServer(ctx context.Context, cfg github.MCPServerConfig) (*mcp.Se
 	if cfg.TokenScopes != nil {
 		inventoryBuilder = inventoryBuilder.WithFilter(github.CreateToolScopeFilter(cfg.TokenScopes))
 	}
+	if cfg.TokenPermissions != nil {
+		inventoryBuilder = inventoryBuilder.WithFilter(github.CreateToolPermissionFilter(cfg.TokenPermissions))
+	}
 
 	inventory, err := inventoryBuilder.Build()
 	if err != nil {
@@ -192,6 +237,14 @@ type StdioServerConfig struct {
 	// GitHub Token to authenticate with the GitHub API
 	Token string
 
+	// App authenticates as a GitHub App installation instead of with Token when set.
+	// Its BaseURL defaults to the REST API of Host.
+	App *tokensource.AppConfig
+
+	// TokenSource provides tokens instead of Token when set, such as from a file or a
+	// credential command
+	TokenSource tokensource.TokenSource
+
 	// EnabledToolsets is a list of toolsets to enable
 	// See: https://github.com/github/github-mcp-server?tab=readme-ov-file#tool-configuration
 	EnabledToolsets []string
@@ -230,8 +283,67 @@ type StdioServerConfig struct {
 	// InsidersMode indicates if we should enable experimental features
 	InsidersMode bool
 
+	// StrictToolAliases rejects calls made through deprecated tool aliases
+	StrictToolAliases bool
+
+	// PromptsDir is a directory of user-defined prompt files to load
+	PromptsDir string
+
 	// RepoAccessCacheTTL overrides the default TTL for repository access cache entries.
 	RepoAccessCacheTTL *time.Duration
+
+	// RepoAccessCacheSize bounds the repositories kept by the in-memory repository access
+	// cache. Defaults to lockdown.DefaultRepoAccessCacheSize.
+	RepoAccessCacheSize int
+
+	// RepoAccessCacheDir keeps the repository access cache in files under this directory
+	// instead of in memory
+	RepoAccessCacheDir string
+
+	// LockdownPolicy extends the content trusted in lockdown mode
+	LockdownPolicy *lockdown.Policy
+
+	// LockdownPlaceholders lists a placeholder for every item lockdown mode hides from a
+	// tool result
+	LockdownPlaceholders bool
+
+	// InjectionDetector marks user-authored text that looks like a prompt injection in
+	// tool results. Defaults to the built-in rules.
+	InjectionDetector *sanitize.InjectionDetector
+
+	// DisableInjectionMarkers leaves user-authored text that looks like a prompt injection
+	// unmarked in tool results and resources.
+	DisableInjectionMarkers bool
+
+	// ResourcePollInterval enables resource subscriptions, polling subscribed resources
+	// for changes at this interval. Zero disables subscriptions.
+	ResourcePollInterval time.Duration
+
+	// MaxResourceSubscriptions caps the resources a session may subscribe to (0 for no limit)
+	MaxResourceSubscriptions int
+
+	// MetricsAddr serves Prometheus metrics at /metrics on this address when set
+	MetricsAddr string
+
+	// OTLPEndpoint enables tracing, exporting spans to the OpenTelemetry collector at this base URL
+	OTLPEndpoint string
+
+	// Audit configures the audit log of tool calls, which is disabled when it has neither
+	// a file path nor a webhook URL
+	Audit audit.Options
+
+	// APICacheBytes bounds the cache of GitHub REST responses, which is disabled when zero
+	APICacheBytes int64
+
+	// APICacheDir keeps cached GitHub responses in this directory instead of in memory
+	APICacheDir string
+
+	// RateLimitMaxWait is the longest a GitHub request waits in total for rate limits to
+	// reset before it is retried. Zero, the default, disables retries.
+	RateLimitMaxWait time.Duration
+
+	// RateLimitMeta attaches the remaining GitHub rate limits to tool results in _meta
+	RateLimitMeta bool
 }
 
 // RunStdioServer is not concurrent safe.
@@ -258,26 +370,139 @@ func RunStdioServer(cfg StdioServerConfig) error {
 	logger := slog.New(slogHandler)
 	logger.Info("starting server", "version", cfg.Version, "host", cfg.Host, "dynamicToolsets", cfg.DynamicToolsets, "readOnly", cfg.ReadOnly, "lockdownEnabled", cfg.LockdownMode)
 
+	// Resolve the token source, fetching the first token now so that misconfiguration is
+	// reported at startup rather than on the first tool call
+	token := cfg.Token
+	tokenSource := cfg.TokenSource
+	if cfg.App != nil {
+		appCfg := *cfg.App
+		if appCfg.BaseURL == nil {
+			apiHost, err := utils.NewAPIHost(cfg.Host)
+			if err != nil {
+				return fmt.Errorf("failed to parse API host: %w", err)
+			}
+			if appCfg.BaseURL, err = apiHost.BaseRESTURL(ctx); err != nil {
+				return fmt.Errorf("failed to get base REST URL: %w", err)
+			}
+		}
+		app, err := tokensource.NewAppInstallation(appCfg)
+		if err != nil {
+			return fmt.Errorf("failed to configure GitHub App authentication: %w", err)
+		}
+		tokenSource = app
+		logger.Info("authenticating as GitHub App installation", "appID", appCfg.AppID)
+	}
+	if tokenSource != nil {
+		var err error
+		if token, err = tokenSource.Token(ctx); err != nil {
+			return fmt.Errorf("failed to get GitHub token: %w", err)
+		}
+	}
+
 	// Fetch token scopes for scope-based tool filtering (PAT tokens only)
 	// Only classic PATs (ghp_ prefix) return OAuth scopes via X-OAuth-Scopes header.
-	// Fine-grained PATs and other token types don't support this, so we skip filtering.
+	// Fine-grained PATs don't support this and are filtered by their permissions instead.
 	var tokenScopes []string
-	if strings.HasPrefix(cfg.Token, "ghp_") {
-		fetchedScopes, err := fetchTokenScopesForHost(ctx, cfg.Token, cfg.Host)
+	if strings.HasPrefix(token, "ghp_") {
+		fetchedScopes, err := fetchTokenScopesForHost(ctx, token, cfg.Host)
 		if err != nil {
 			logger.Warn("failed to fetch token scopes, continuing without scope filtering", "error", err)
 		} else {
 			tokenScopes = fetchedScopes
 			logger.Info("token scopes fetched for filtering", "scopes", tokenScopes)
 		}
-	} else {
-		logger.Debug("skipping scope filtering for non-PAT token")
+	}
+
+	// Fine-grained PATs have permissions instead of scopes, which are found by probing
+	var tokenPermissions scopes.PermissionSet
+	if strings.HasPrefix(token, "github_pat_") {
+		wanted := scopes.PermissionsForTools(github.AllTools(t))
+		probed, err := probeTokenPermissionsForHost(ctx, token, cfg.Host, wanted)
+		if err != nil {
+			logger.Warn("failed to probe token permissions, continuing without permission filtering", "error", err)
+		} else {
+			tokenPermissions = probed
+			logger.Info("token permissions probed for filtering", "permissions", probed)
+		}
+	}
+	if tokenScopes == nil && tokenPermissions == nil {
+		logger.Debug("skipping scope filtering for token without known scopes or permissions")
+	}
+
+	var userPrompts []inventory.ServerPrompt
+	if cfg.PromptsDir != "" {
+		loaded, err := github.LoadPromptLibrary(cfg.PromptsDir)
+		if err != nil {
+			return fmt.Errorf("failed to load prompt library: %w", err)
+		}
+		userPrompts = loaded
+		logger.Info("loaded prompt library", "dir", cfg.PromptsDir, "prompts", len(userPrompts))
+	}
+
+	var serverMetrics *metrics.Metrics
+	if cfg.MetricsAddr != "" {
+		serverMetrics = metrics.New()
+		go func() {
+			if err := metrics.ListenAndServe(ctx, cfg.MetricsAddr, serverMetrics.Handler()); err != nil {
+				logger.Error("metrics server failed", "addr", cfg.MetricsAddr, "error", err)
+			}
+		}()
+		logger.Info("metrics server listening", "addr", cfg.MetricsAddr)
+	}
+
+	var tracerProvider trace.TracerProvider
+	if cfg.OTLPEndpoint != "" {
+		provider, err := tracing.NewProvider(ctx, tracing.ProviderOptions{
+			Endpoint:       cfg.OTLPEndpoint,
+			ServiceVersion: cfg.Version,
+			Logger:         logger.With("component", "tracing"),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to configure tracing: %w", err)
+		}
+		defer func() {
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := provider.Shutdown(shutdownCtx); err != nil {
+				logger.Warn("failed to export remaining spans", "error", err)
+			}
+		}()
+		tracerProvider = provider
+		logger.Info("exporting traces", "endpoint", cfg.OTLPEndpoint)
+	}
+
+	cfg.Audit.Logger = logger.With("component", "audit")
+	auditor, err := audit.Open(cfg.Audit)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	if auditor != nil {
+		defer func() {
+			if err := auditor.Close(); err != nil {
+				logger.Warn("failed to close audit log", "error", err)
+			}
+		}()
+		logger.Info("auditing tool calls", "file", cfg.Audit.FilePath, "webhook", cfg.Audit.WebhookURL)
+	}
+
+	apiCache, err := transport.NewCacheStore(cfg.APICacheDir, cfg.APICacheBytes)
+	if err != nil {
+		return fmt.Errorf("failed to open API cache: %w", err)
+	}
+
+	var repoAccessStore lockdown.RepoAccessStore
+	if cfg.LockdownMode {
+		repoAccessStore, err = lockdown.NewRepoAccessStore(cfg.RepoAccessCacheDir, cfg.RepoAccessCacheSize)
+		if err != nil {
+			return fmt.Errorf("failed to open repo access cache: %w", err)
+		}
 	}
 
 	ghServer, err := NewStdioMCPServer(ctx, github.MCPServerConfig{
 		Version:           cfg.Version,
 		Host:              cfg.Host,
 		Token:             cfg.Token,
+		TokenSource:       tokenSource,
 		EnabledToolsets:   cfg.EnabledToolsets,
 		EnabledTools:      cfg.EnabledTools,
 		EnabledFeatures:   cfg.EnabledFeatures,
@@ -287,9 +512,26 @@ func RunStdioServer(cfg StdioServerConfig) error {
 		ContentWindowSize: cfg.ContentWindowSize,
 		LockdownMode:      cfg.LockdownMode,
 		InsidersMode:      cfg.InsidersMode,
+		StrictToolAliases: cfg.StrictToolAliases,
 		Logger:            logger,
 		RepoAccessTTL:     cfg.RepoAccessCacheTTL,
+		RepoAccessStore:   repoAccessStore,
+		LockdownPolicy:    cfg.LockdownPolicy,
 		TokenScopes:       tokenScopes,
+		TokenPermissions:  tokenPermissions,
+		Prompts:           userPrompts,
+
+		ResourcePollInterval:     cfg.ResourcePollInterval,
+		MaxResourceSubscriptions: cfg.MaxResourceSubscriptions,
+		Metrics:                  serverMetrics,
+		TracerProvider:           tracerProvider,
+		Audit:                    auditor,
+		APICache:                 apiCache,
+		RateLimitMaxWait:         cfg.RateLimitMaxWait,
+		RateLimitMeta:            cfg.RateLimitMeta,
+		LockdownPlaceholders:     cfg.LockdownPlaceholders,
+		InjectionDetector:        cfg.InjectionDetector,
+		DisableInjectionMarkers:  cfg.DisableInjectionMarkers,
 	})
 	if err != nil {
 		return fmt.Errorf("failed to create MCP server: %w", err)
@@ -316,6 +558,10 @@ func RunStdioServer(cfg StdioServerConfig) error {
 
 		// enable GitHub errors in the context
 		ctx := errors.ContextWithGitHubErrors(ctx)
+		if serverMetrics != nil {
+			serverMetrics.SessionStarted()
+			defer serverMetrics.SessionEnded()
+		}
 		errC <- ghServer.Run(ctx, &mcp.IOTransport{Reader: in, Writer: out})
 	}()
 
@@ -397,3 +643,15 @@ func fetchTokenScopesForHost(ctx context.Context, token, host string) ([]string,
 
 	return fetcher.FetchTokenScopes(ctx, token)
 }
+
+// probeTokenPermissionsForHost determines which of the wanted fine-grained permissions a
+// token has on the given GitHub host.
+func probeTokenPermissionsForHost(ctx context.Context, token, host string, wanted []scopes.Permission) (scopes.PermissionSet, error) {
+	apiHost, err := utils.NewAPIHost(host)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse API host: %w", err)
+	}
+
+	prober := scopes.NewPermissionProber(apiHost, scopes.FetcherOptions{})
+	return prober.ProbePermissions(ctx, token, wanted)
+}
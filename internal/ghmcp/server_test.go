@@ -1 +1,63 @@
 package ghmcp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/github/github-mcp-server/pkg/github"
+	"github.com/github/github-mcp-server/pkg/tokensource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testAPIHost resolves every API to a local server.
+type testAPIHost struct{ baseURL string }
+
+func (h testAPIHost) url(path string) (*url.URL, error) { return url.Parse(h.baseURL + path) }
+
+func (h testAPIHost) BaseRESTURL(context.Context) (*url.URL, error) { return h.url("/api/v3/") }
+func (h testAPIHost) GraphqlURL(context.Context) (*url.URL, error)  { return h.url("/api/graphql") }
+func (h testAPIHost) UploadURL(context.Context) (*url.URL, error)   { return h.url("/api/uploads/") }
+func (h testAPIHost) RawURL(context.Context) (*url.URL, error)      { return h.url("/raw/") }
+
+func TestCreateGitHubClientsWithTokenSource(t *testing.T) {
+	var mu sync.Mutex
+	auth := make(map[string]string)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		auth[r.URL.Path] = r.Header.Get("Authorization")
+		mu.Unlock()
+		if strings.HasSuffix(r.URL.Path, "/graphql") {
+			_, _ = w.Write([]byte(`{"data":{"viewer":{"login":"app[bot]"}}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"login":"app[bot]"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	clients, err := createGitHubClients(github.MCPServerConfig{
+		Version:     "test",
+		Token:       "ghp_unused",
+		TokenSource: tokensource.Static("ghs_installation"),
+	}, testAPIHost{baseURL: server.URL})
+	require.NoError(t, err)
+
+	_, _, err = clients.rest.Users.Get(t.Context(), "")
+	require.NoError(t, err)
+
+	req, err := http.NewRequestWithContext(t.Context(), http.MethodPost, server.URL+"/api/graphql", strings.NewReader(`{"query":"{viewer{login}}"}`))
+	require.NoError(t, err)
+	resp, err := clients.gqlHTTP.Do(req)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+
+	assert.Equal(t, map[string]string{
+		"/api/v3/user": "Bearer ghs_installation",
+		"/api/graphql": "Bearer ghs_installation",
+	}, auth, "REST and GraphQL requests use the token source instead of the token")
+}
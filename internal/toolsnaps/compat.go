@@ -0,0 +1,441 @@
+package toolsnaps
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+// ChangeKind identifies the kind of difference found between two versions of a tool schema.
+type ChangeKind string
+
+const (
+	ChangeToolRemoved           ChangeKind = "tool_removed"
+	ChangeToolAdded             ChangeKind = "tool_added"
+	ChangeToolAliased           ChangeKind = "tool_aliased"
+	ChangePropertyRemoved       ChangeKind = "property_removed"
+	ChangePropertyRenamed       ChangeKind = "property_renamed"
+	ChangePropertyAdded         ChangeKind = "property_added"
+	ChangePropertyRequired      ChangeKind = "property_required"
+	ChangePropertyOptional      ChangeKind = "property_optional"
+	ChangeEnumNarrowed          ChangeKind = "enum_narrowed"
+	ChangeEnumWidened           ChangeKind = "enum_widened"
+	ChangeTypeChanged           ChangeKind = "type_changed"
+	ChangeTypeWidened           ChangeKind = "type_widened"
+	ChangeRequiredPropertyAdded ChangeKind = "required_property_added"
+)
+
+// breakingKinds are the change kinds that can cause a previously valid tool call to fail.
+var breakingKinds = map[ChangeKind]bool{
+	ChangeToolRemoved:           true,
+	ChangePropertyRemoved:       true,
+	ChangePropertyRenamed:       true,
+	ChangePropertyRequired:      true,
+	ChangeEnumNarrowed:          true,
+	ChangeTypeChanged:           true,
+	ChangeRequiredPropertyAdded: true,
+}
+
+// Change describes a single difference between two versions of a tool's input schema.
+type Change struct {
+	Tool     string     `json:"tool"`
+	Kind     ChangeKind `json:"kind"`
+	Path     string     `json:"path,omitempty"`
+	Detail   string     `json:"detail"`
+	Breaking bool       `json:"breaking"`
+
+	// Guess marks a change inferred from the schemas rather than known for certain, such
+	// as a removed property paired with an added one as a rename.
+	Guess bool `json:"guess,omitempty"`
+}
+
+func (c Change) String() string {
+	severity := "additive"
+	if c.Breaking {
+		severity = "BREAKING"
+	}
+	if c.Path == "" {
+		return fmt.Sprintf("[%s] %s: %s (%s)", severity, c.Tool, c.Detail, c.Kind)
+	}
+	return fmt.Sprintf("[%s] %s.%s: %s (%s)", severity, c.Tool, c.Path, c.Detail, c.Kind)
+}
+
+// Report is the ordered list of changes found when comparing two manifests.
+type Report struct {
+	Changes []Change `json:"changes"`
+}
+
+// Breaking returns only the changes that can break existing clients.
+func (r Report) Breaking() []Change {
+	var breaking []Change
+	for _, c := range r.Changes {
+		if c.Breaking {
+			breaking = append(breaking, c)
+		}
+	}
+	return breaking
+}
+
+// HasBreaking reports whether any change in the report is breaking.
+func (r Report) HasBreaking() bool {
+	return len(r.Breaking()) > 0
+}
+
+// Manifest maps tool names to their input schemas.
+type Manifest map[string]*jsonschema.Schema
+
+// manifestTool is the subset of a serialized tool that is relevant for compatibility checks.
+type manifestTool struct {
+	Name        string             `json:"name"`
+	InputSchema *jsonschema.Schema `json:"inputSchema"`
+}
+
+// Add marshals a tool (typically an mcp.Tool) and records its input schema in the manifest.
+// If a tool with the same name is already present the first one wins, mirroring how
+// duplicate names resolve during registration.
+func (m Manifest) Add(tool any) error {
+	data, err := json.Marshal(tool)
+	if err != nil {
+		return fmt.Errorf("failed to marshal tool: %w", err)
+	}
+	return m.addJSON(data)
+}
+
+func (m Manifest) addJSON(data []byte) error {
+	var mt manifestTool
+	if err := json.Unmarshal(data, &mt); err != nil {
+		return fmt.Errorf("failed to parse tool JSON: %w", err)
+	}
+	if mt.Name == "" {
+		return fmt.Errorf("tool has no name")
+	}
+	if _, exists := m[mt.Name]; exists {
+		return nil
+	}
+	if mt.InputSchema == nil {
+		mt.InputSchema = &jsonschema.Schema{Type: "object"}
+	}
+	m[mt.Name] = mt.InputSchema
+	return nil
+}
+
+// LoadManifest loads a manifest from either a directory of .snap files or a JSON file.
+func LoadManifest(path string) (Manifest, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat manifest %s: %w", path, err)
+	}
+	if info.IsDir() {
+		return LoadManifestDir(path)
+	}
+	return LoadManifestFile(path)
+}
+
+// LoadManifestDir loads a manifest from a directory of tool snapshots, such as __toolsnaps__.
+func LoadManifestDir(dir string) (Manifest, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.snap"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list snapshots in %s: %w", dir, err)
+	}
+
+	m := Manifest{}
+	for _, p := range paths {
+		data, err := os.ReadFile(p) //nolint:gosec // snapshot paths are supplied by the caller
+		if err != nil {
+			return nil, fmt.Errorf("failed to read snapshot %s: %w", p, err)
+		}
+		if err := m.addJSON(data); err != nil {
+			return nil, fmt.Errorf("invalid snapshot %s: %w", p, err)
+		}
+	}
+	return m, nil
+}
+
+// LoadManifestFile loads a manifest from a JSON file containing either an array of tools
+// or a tools/list result of the form {"tools": [...]}.
+func LoadManifestFile(path string) (Manifest, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // manifest paths are supplied by the caller
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest %s: %w", path, err)
+	}
+
+	var tools []json.RawMessage
+	if err := json.Unmarshal(data, &tools); err != nil {
+		var list struct {
+			Tools []json.RawMessage `json:"tools"`
+		}
+		if err := json.Unmarshal(data, &list); err != nil {
+			return nil, fmt.Errorf("failed to parse manifest %s: %w", path, err)
+		}
+		tools = list.Tools
+	}
+
+	m := Manifest{}
+	for i, raw := range tools {
+		if err := m.addJSON(raw); err != nil {
+			return nil, fmt.Errorf("invalid tool at index %d in %s: %w", i, path, err)
+		}
+	}
+	return m, nil
+}
+
+// CompareManifests classifies every difference between base and head.
+// aliases maps deprecated tool names to their replacements; a tool that was removed
+// but is still resolvable through an alias is reported as a non-breaking rename.
+func CompareManifests(base, head Manifest, aliases map[string]string) Report {
+	var changes []Change
+
+	for name, baseSchema := range base {
+		headSchema, ok := head[name]
+		if !ok {
+			if target, aliased := aliases[name]; aliased {
+				if _, exists := head[target]; exists {
+					changes = append(changes, newChange(name, ChangeToolAliased, "", fmt.Sprintf("tool removed; calls resolve to %q via deprecated alias", target)))
+					continue
+				}
+			}
+			changes = append(changes, newChange(name, ChangeToolRemoved, "", "tool removed"))
+			continue
+		}
+		changes = append(changes, compareSchemas(name, "", baseSchema, headSchema)...)
+	}
+
+	for name := range head {
+		if _, ok := base[name]; !ok {
+			changes = append(changes, newChange(name, ChangeToolAdded, "", "tool added"))
+		}
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		if changes[i].Tool != changes[j].Tool {
+			return changes[i].Tool < changes[j].Tool
+		}
+		if changes[i].Path != changes[j].Path {
+			return changes[i].Path < changes[j].Path
+		}
+		return changes[i].Kind < changes[j].Kind
+	})
+
+	return Report{Changes: changes}
+}
+
+func newChange(tool string, kind ChangeKind, path, detail string) Change {
+	return Change{
+		Tool:     tool,
+		Kind:     kind,
+		Path:     path,
+		Detail:   detail,
+		Breaking: breakingKinds[kind],
+	}
+}
+
+func joinPath(parent, child string) string {
+	if parent == "" {
+		return child
+	}
+	return parent + "." + child
+}
+
+// compareSchemas compares two schemas located at path within the named tool.
+func compareSchemas(tool, path string, base, head *jsonschema.Schema) []Change {
+	if base == nil || head == nil {
+		return nil
+	}
+
+	var changes []Change
+
+	baseTypes, headTypes := schemaTypes(base), schemaTypes(head)
+	switch {
+	case typesEqual(baseTypes, headTypes):
+	case typesCovered(baseTypes, headTypes):
+		changes = append(changes, newChange(tool, ChangeTypeWidened, path, fmt.Sprintf("type widened from %s to %s", formatTypes(baseTypes), formatTypes(headTypes))))
+	default:
+		changes = append(changes, newChange(tool, ChangeTypeChanged, path, fmt.Sprintf("type changed from %s to %s", formatTypes(baseTypes), formatTypes(headTypes))))
+		// Nested comparisons are meaningless once the type itself has changed.
+		return changes
+	}
+
+	changes = append(changes, compareEnums(tool, path, base.Enum, head.Enum)...)
+	changes = append(changes, compareProperties(tool, path, base, head)...)
+
+	if base.Items != nil && head.Items != nil {
+		changes = append(changes, compareSchemas(tool, path+"[]", base.Items, head.Items)...)
+	}
+
+	return changes
+}
+
+func compareEnums(tool, path string, base, head []any) []Change {
+	switch {
+	case len(base) == 0 && len(head) == 0:
+		return nil
+	case len(base) == 0:
+		return []Change{newChange(tool, ChangeEnumNarrowed, path, fmt.Sprintf("values restricted to %s", formatValues(head)))}
+	case len(head) == 0:
+		return []Change{newChange(tool, ChangeEnumWidened, path, "enum restriction removed")}
+	}
+
+	removed := valuesMissing(base, head)
+	added := valuesMissing(head, base)
+
+	var changes []Change
+	if len(removed) > 0 {
+		changes = append(changes, newChange(tool, ChangeEnumNarrowed, path, fmt.Sprintf("enum values removed: %s", formatValues(removed))))
+	}
+	if len(added) > 0 {
+		changes = append(changes, newChange(tool, ChangeEnumWidened, path, fmt.Sprintf("enum values added: %s", formatValues(added))))
+	}
+	return changes
+}
+
+func compareProperties(tool, path string, base, head *jsonschema.Schema) []Change {
+	var changes []Change
+
+	var removed, added []string
+	for name, baseProp := range base.Properties {
+		headProp, ok := head.Properties[name]
+		if !ok {
+			removed = append(removed, name)
+			continue
+		}
+		changes = append(changes, compareSchemas(tool, joinPath(path, name), baseProp, headProp)...)
+	}
+	for name := range head.Properties {
+		if _, ok := base.Properties[name]; !ok {
+			added = append(added, name)
+		}
+	}
+	sort.Strings(removed)
+	sort.Strings(added)
+
+	renames := detectRenames(removed, added, base.Properties, head.Properties)
+	for _, name := range removed {
+		if target, ok := renames[name]; ok {
+			change := newChange(tool, ChangePropertyRenamed, joinPath(path, name), fmt.Sprintf("property removed; possibly renamed to %q, which was added with the same type", target))
+			change.Guess = true
+			changes = append(changes, change)
+			continue
+		}
+		changes = append(changes, newChange(tool, ChangePropertyRemoved, joinPath(path, name), "property removed"))
+	}
+
+	renamedTo := make(map[string]bool, len(renames))
+	for _, target := range renames {
+		renamedTo[target] = true
+	}
+	for _, name := range added {
+		if renamedTo[name] {
+			continue
+		}
+		if slices.Contains(head.Required, name) {
+			changes = append(changes, newChange(tool, ChangeRequiredPropertyAdded, joinPath(path, name), "required property added"))
+			continue
+		}
+		changes = append(changes, newChange(tool, ChangePropertyAdded, joinPath(path, name), "optional property added"))
+	}
+
+	for _, name := range head.Required {
+		if _, existed := base.Properties[name]; existed && !slices.Contains(base.Required, name) {
+			changes = append(changes, newChange(tool, ChangePropertyRequired, joinPath(path, name), "property is now required"))
+		}
+	}
+	for _, name := range base.Required {
+		if _, exists := head.Properties[name]; exists && !slices.Contains(head.Required, name) {
+			changes = append(changes, newChange(tool, ChangePropertyOptional, joinPath(path, name), "property is no longer required"))
+		}
+	}
+
+	return changes
+}
+
+// detectRenames guesses that a property was renamed when it is the only one removed from an
+// object of a tool's schema, and the only one added to it has the same type. Schemas don't
+// record renames, so anything less clear-cut is reported as a removal and an addition.
+func detectRenames(removed, added []string, baseProps, headProps map[string]*jsonschema.Schema) map[string]string {
+	if len(removed) != 1 || len(added) != 1 || !sameShape(baseProps[removed[0]], headProps[added[0]]) {
+		return nil
+	}
+	return map[string]string{removed[0]: added[0]}
+}
+
+func sameShape(a, b *jsonschema.Schema) bool {
+	if a == nil || b == nil {
+		return false
+	}
+	if !typesEqual(schemaTypes(a), schemaTypes(b)) {
+		return false
+	}
+	return len(valuesMissing(a.Enum, b.Enum)) == 0 && len(valuesMissing(b.Enum, a.Enum)) == 0
+}
+
+func schemaTypes(s *jsonschema.Schema) []string {
+	if s.Type != "" {
+		return []string{s.Type}
+	}
+	types := slices.Clone(s.Types)
+	sort.Strings(types)
+	return types
+}
+
+func typesEqual(a, b []string) bool {
+	return slices.Equal(a, b)
+}
+
+// typesCovered reports whether every value accepted by base types is accepted by head types.
+// An empty type list accepts anything.
+func typesCovered(base, head []string) bool {
+	if len(head) == 0 {
+		return true
+	}
+	if len(base) == 0 {
+		return false
+	}
+	for _, t := range base {
+		if slices.Contains(head, t) {
+			continue
+		}
+		if t == "integer" && slices.Contains(head, "number") {
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+func formatTypes(types []string) string {
+	if len(types) == 0 {
+		return "any"
+	}
+	return strings.Join(types, "|")
+}
+
+func valuesMissing(from, in []any) []any {
+	var missing []any
+	for _, v := range from {
+		found := false
+		for _, w := range in {
+			if fmt.Sprint(v) == fmt.Sprint(w) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, v)
+		}
+	}
+	return missing
+}
+
+func formatValues(values []any) string {
+	parts := make([]string, 0, len(values))
+	for _, v := range values {
+		parts = append(parts, fmt.Sprintf("%v", v))
+	}
+	return strings.Join(parts, ", ")
+}
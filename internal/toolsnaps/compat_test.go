@@ -0,0 +1,269 @@
+package toolsnaps
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func objectSchema(required []string, props map[string]*jsonschema.Schema) *jsonschema.Schema {
+	return &jsonschema.Schema{Type: "object", Properties: props, Required: required}
+}
+
+func TestCompareManifests(t *testing.T) {
+	baseline := Manifest{
+		"list_things": objectSchema([]string{"owner"}, map[string]*jsonschema.Schema{
+			"owner": {Type: "string"},
+			"state": {Type: "string", Enum: []any{"open", "closed"}},
+			"page":  {Type: "integer"},
+		}),
+	}
+
+	tests := []struct {
+		name         string
+		base         Manifest
+		head         Manifest
+		aliases      map[string]string
+		expectedKind []ChangeKind
+		expectedPath []string
+		breaking     bool
+	}{
+		{
+			name:     "identical manifests",
+			base:     baseline,
+			head:     baseline,
+			breaking: false,
+		},
+		{
+			name:         "tool removed",
+			base:         baseline,
+			head:         Manifest{},
+			expectedKind: []ChangeKind{ChangeToolRemoved},
+			expectedPath: []string{""},
+			breaking:     true,
+		},
+		{
+			name:         "tool removed but covered by alias",
+			base:         baseline,
+			head:         Manifest{"things_list": baseline["list_things"]},
+			aliases:      map[string]string{"list_things": "things_list"},
+			expectedKind: []ChangeKind{ChangeToolAliased, ChangeToolAdded},
+			expectedPath: []string{"", ""},
+			breaking:     false,
+		},
+		{
+			name: "optional property added",
+			base: baseline,
+			head: Manifest{"list_things": objectSchema([]string{"owner"}, map[string]*jsonschema.Schema{
+				"owner":   {Type: "string"},
+				"state":   {Type: "string", Enum: []any{"open", "closed"}},
+				"page":    {Type: "integer"},
+				"perPage": {Type: "number"},
+			})},
+			expectedKind: []ChangeKind{ChangePropertyAdded},
+			expectedPath: []string{"perPage"},
+			breaking:     false,
+		},
+		{
+			name: "required property added",
+			base: baseline,
+			head: Manifest{"list_things": objectSchema([]string{"owner", "repo"}, map[string]*jsonschema.Schema{
+				"owner": {Type: "string"},
+				"repo":  {Type: "string"},
+				"state": {Type: "string", Enum: []any{"open", "closed"}},
+				"page":  {Type: "integer"},
+			})},
+			expectedKind: []ChangeKind{ChangeRequiredPropertyAdded},
+			expectedPath: []string{"repo"},
+			breaking:     true,
+		},
+		{
+			name: "property removed",
+			base: baseline,
+			head: Manifest{"list_things": objectSchema([]string{"owner"}, map[string]*jsonschema.Schema{
+				"owner": {Type: "string"},
+				"state": {Type: "string", Enum: []any{"open", "closed"}},
+			})},
+			expectedKind: []ChangeKind{ChangePropertyRemoved},
+			expectedPath: []string{"page"},
+			breaking:     true,
+		},
+		{
+			name: "property renamed",
+			base: baseline,
+			head: Manifest{"list_things": objectSchema([]string{"owner"}, map[string]*jsonschema.Schema{
+				"owner":      {Type: "string"},
+				"state":      {Type: "string", Enum: []any{"open", "closed"}},
+				"pageNumber": {Type: "integer"},
+			})},
+			expectedKind: []ChangeKind{ChangePropertyRenamed},
+			expectedPath: []string{"page"},
+			breaking:     true,
+		},
+		{
+			name: "several properties removed and added are not paired",
+			base: baseline,
+			head: Manifest{"list_things": objectSchema([]string{"owner"}, map[string]*jsonschema.Schema{
+				"owner":   {Type: "string"},
+				"status":  {Type: "string", Enum: []any{"open", "closed"}},
+				"perPage": {Type: "integer"},
+			})},
+			expectedKind: []ChangeKind{ChangePropertyRemoved, ChangePropertyAdded, ChangePropertyRemoved, ChangePropertyAdded},
+			expectedPath: []string{"page", "perPage", "state", "status"},
+			breaking:     true,
+		},
+		{
+			name: "property removed and one of another type added",
+			base: baseline,
+			head: Manifest{"list_things": objectSchema([]string{"owner"}, map[string]*jsonschema.Schema{
+				"owner":  {Type: "string"},
+				"state":  {Type: "string", Enum: []any{"open", "closed"}},
+				"cursor": {Type: "string"},
+			})},
+			expectedKind: []ChangeKind{ChangePropertyAdded, ChangePropertyRemoved},
+			expectedPath: []string{"cursor", "page"},
+			breaking:     true,
+		},
+		{
+			name: "property becomes required",
+			base: baseline,
+			head: Manifest{"list_things": objectSchema([]string{"owner", "page"}, map[string]*jsonschema.Schema{
+				"owner": {Type: "string"},
+				"state": {Type: "string", Enum: []any{"open", "closed"}},
+				"page":  {Type: "integer"},
+			})},
+			expectedKind: []ChangeKind{ChangePropertyRequired},
+			expectedPath: []string{"page"},
+			breaking:     true,
+		},
+		{
+			name: "property becomes optional",
+			base: baseline,
+			head: Manifest{"list_things": objectSchema(nil, map[string]*jsonschema.Schema{
+				"owner": {Type: "string"},
+				"state": {Type: "string", Enum: []any{"open", "closed"}},
+				"page":  {Type: "integer"},
+			})},
+			expectedKind: []ChangeKind{ChangePropertyOptional},
+			expectedPath: []string{"owner"},
+			breaking:     false,
+		},
+		{
+			name: "enum narrowed",
+			base: baseline,
+			head: Manifest{"list_things": objectSchema([]string{"owner"}, map[string]*jsonschema.Schema{
+				"owner": {Type: "string"},
+				"state": {Type: "string", Enum: []any{"open"}},
+				"page":  {Type: "integer"},
+			})},
+			expectedKind: []ChangeKind{ChangeEnumNarrowed},
+			expectedPath: []string{"state"},
+			breaking:     true,
+		},
+		{
+			name: "enum widened",
+			base: baseline,
+			head: Manifest{"list_things": objectSchema([]string{"owner"}, map[string]*jsonschema.Schema{
+				"owner": {Type: "string"},
+				"state": {Type: "string", Enum: []any{"open", "closed", "all"}},
+				"page":  {Type: "integer"},
+			})},
+			expectedKind: []ChangeKind{ChangeEnumWidened},
+			expectedPath: []string{"state"},
+			breaking:     false,
+		},
+		{
+			name: "type changed",
+			base: baseline,
+			head: Manifest{"list_things": objectSchema([]string{"owner"}, map[string]*jsonschema.Schema{
+				"owner": {Type: "string"},
+				"state": {Type: "string", Enum: []any{"open", "closed"}},
+				"page":  {Type: "string"},
+			})},
+			expectedKind: []ChangeKind{ChangeTypeChanged},
+			expectedPath: []string{"page"},
+			breaking:     true,
+		},
+		{
+			name: "integer widened to number",
+			base: baseline,
+			head: Manifest{"list_things": objectSchema([]string{"owner"}, map[string]*jsonschema.Schema{
+				"owner": {Type: "string"},
+				"state": {Type: "string", Enum: []any{"open", "closed"}},
+				"page":  {Type: "number"},
+			})},
+			expectedKind: []ChangeKind{ChangeTypeWidened},
+			expectedPath: []string{"page"},
+			breaking:     false,
+		},
+		{
+			name: "nested array item property removed",
+			base: Manifest{"push_files": objectSchema(nil, map[string]*jsonschema.Schema{
+				"files": {Type: "array", Items: objectSchema(nil, map[string]*jsonschema.Schema{
+					"path":    {Type: "string"},
+					"content": {Type: "string"},
+				})},
+			})},
+			head: Manifest{"push_files": objectSchema(nil, map[string]*jsonschema.Schema{
+				"files": {Type: "array", Items: objectSchema(nil, map[string]*jsonschema.Schema{
+					"path": {Type: "string"},
+				})},
+			})},
+			expectedKind: []ChangeKind{ChangePropertyRemoved},
+			expectedPath: []string{"files[].content"},
+			breaking:     true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			report := CompareManifests(tc.base, tc.head, tc.aliases)
+
+			var kinds []ChangeKind
+			var paths []string
+			for _, c := range report.Changes {
+				kinds = append(kinds, c.Kind)
+				paths = append(paths, c.Path)
+				assert.Equal(t, c.Kind == ChangePropertyRenamed, c.Guess, "only renames are guessed")
+			}
+			assert.ElementsMatch(t, tc.expectedKind, kinds)
+			assert.ElementsMatch(t, tc.expectedPath, paths)
+			assert.Equal(t, tc.breaking, report.HasBreaking())
+		})
+	}
+}
+
+func TestLoadManifest(t *testing.T) {
+	dir := t.TempDir()
+
+	// Given a snapshot directory and an equivalent tools/list result
+	snapDir := filepath.Join(dir, "__toolsnaps__")
+	require.NoError(t, os.MkdirAll(snapDir, 0700))
+	require.NoError(t, os.WriteFile(filepath.Join(snapDir, "get_thing.snap"), []byte(`{"name":"get_thing","inputSchema":{"type":"object","properties":{"id":{"type":"integer"}},"required":["id"]}}`), 0600))
+	listPath := filepath.Join(dir, "tools.json")
+	require.NoError(t, os.WriteFile(listPath, []byte(`{"tools":[{"name":"get_thing","inputSchema":{"type":"object","properties":{"id":{"type":"integer"}},"required":["id"]}}]}`), 0600))
+
+	// When both are loaded
+	fromDir, err := LoadManifest(snapDir)
+	require.NoError(t, err)
+	fromFile, err := LoadManifest(listPath)
+	require.NoError(t, err)
+
+	// Then they describe the same tool and compare cleanly
+	require.Contains(t, fromDir, "get_thing")
+	assert.Equal(t, []string{"id"}, fromDir["get_thing"].Required)
+	assert.Empty(t, CompareManifests(fromDir, fromFile, nil).Changes)
+}
+
+func TestManifestAdd(t *testing.T) {
+	m := Manifest{}
+	require.NoError(t, m.Add(map[string]any{"name": "first", "inputSchema": map[string]any{"type": "object"}}))
+	require.NoError(t, m.Add(map[string]any{"name": "first", "inputSchema": map[string]any{"type": "string"}}))
+	assert.Equal(t, "object", m["first"].Type, "expected first registration to win")
+
+	assert.Error(t, m.Add(map[string]any{"inputSchema": map[string]any{"type": "object"}}))
+}
@@ -0,0 +1,180 @@
+// Package audit records tool invocations for compliance: who called which tool on what,
+// when, and with what outcome. Events are written as JSON Lines to size-rotated files or
+// posted to a webhook, with sensitive arguments redacted.
+package audit
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"slices"
+	"time"
+)
+
+// Access tells apart tools that only read from GitHub from those that modify it.
+type Access string
+
+const (
+	AccessRead  Access = "read"
+	AccessWrite Access = "write"
+	// AccessUnknown is recorded for calls to tools the server does not have.
+	AccessUnknown Access = "unknown"
+)
+
+// OutcomeSuccess is the outcome of tool calls that succeeded. Failed calls record an
+// error class instead, such as "github_not_found".
+const OutcomeSuccess = "success"
+
+// Event is the audit record of a single tool call.
+type Event struct {
+	Time time.Time `json:"time"`
+	// Actor is the GitHub identity of the token the tool was called with.
+	Actor Actor `json:"actor"`
+	// Client is the MCP client that made the call.
+	Client Client `json:"client"`
+	// SessionID is the MCP session ID, when the transport has sessions.
+	SessionID string `json:"session_id,omitempty"`
+	// Tool is the name of the tool that ran, after deprecated aliases are resolved.
+	Tool string `json:"tool"`
+	// RequestedTool is the name the client called, when it was a deprecated alias.
+	RequestedTool string `json:"requested_tool,omitempty"`
+	// Owner and Repo are the repository the call targeted, if any.
+	Owner  string `json:"owner,omitempty"`
+	Repo   string `json:"repo,omitempty"`
+	Access Access `json:"access"`
+	// Outcome is OutcomeSuccess or the error class of a failed call.
+	Outcome    string  `json:"outcome"`
+	DurationMS float64 `json:"duration_ms"`
+	// Arguments are the tool arguments, redacted by the logger before they are written.
+	Arguments map[string]any `json:"arguments,omitempty"`
+}
+
+// Actor is the GitHub identity behind a tool call.
+type Actor struct {
+	// Login is empty when the token has no user, as for GitHub App installation tokens,
+	// or the identity could not be resolved.
+	Login string `json:"login,omitempty"`
+	ID    int64  `json:"id,omitempty"`
+	// TokenType is the kind of token, such as "pat" or "oauth", when known.
+	TokenType string `json:"token_type,omitempty"`
+}
+
+// Client identifies the MCP client behind a tool call.
+type Client struct {
+	Name    string `json:"name,omitempty"`
+	Version string `json:"version,omitempty"`
+	// Certificate is the subject common name of the mutual TLS client certificate.
+	Certificate string `json:"certificate,omitempty"`
+}
+
+// Sink receives audit events.
+type Sink interface {
+	Write(event *Event) error
+	Close() error
+}
+
+// LoggerOptions configures a Logger.
+type LoggerOptions struct {
+	// Redactor redacts tool arguments. Defaults to a redactor with DefaultRedactions.
+	Redactor *Redactor
+
+	// Logger reports events that could not be written.
+	Logger *slog.Logger
+}
+
+// Logger redacts audit events and writes them to its sinks.
+type Logger struct {
+	sinks    []Sink
+	redactor *Redactor
+	logger   *slog.Logger
+}
+
+// NewLogger creates a logger that writes to every one of sinks.
+func NewLogger(opts LoggerOptions, sinks ...Sink) *Logger {
+	if opts.Redactor == nil {
+		opts.Redactor = NewRedactor(DefaultRedactions)
+	}
+	if opts.Logger == nil {
+		opts.Logger = slog.New(slog.DiscardHandler)
+	}
+	return &Logger{sinks: sinks, redactor: opts.Redactor, logger: opts.Logger}
+}
+
+// Record redacts the arguments of event and writes it to every sink. Failures are logged
+// rather than returned, so that auditing never fails a tool call.
+func (l *Logger) Record(event *Event) {
+	event.Arguments = l.redactor.Redact(event.Arguments)
+	for _, sink := range l.sinks {
+		if err := sink.Write(event); err != nil {
+			l.logger.Warn("failed to write audit event", "tool", event.Tool, "error", err)
+		}
+	}
+}
+
+// Close closes every sink, flushing buffered events.
+func (l *Logger) Close() error {
+	var errs []error
+	for _, sink := range l.sinks {
+		errs = append(errs, sink.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// Options configures the sinks of a logger created with Open.
+type Options struct {
+	// FilePath writes events to this file, rotated as configured by FileSinkOptions.
+	FilePath string
+	File     FileSinkOptions
+
+	// WebhookURL posts events to this URL, signed with WebhookSecret if it is set.
+	WebhookURL    string
+	WebhookSecret string
+
+	// Redactions are argument name patterns redacted in addition to DefaultRedactions.
+	Redactions []string
+
+	// Logger reports events that could not be written or delivered.
+	Logger *slog.Logger
+}
+
+// Open creates a logger with a file sink, a webhook sink, or both. It returns nil when
+// neither is configured.
+func Open(opts Options) (*Logger, error) {
+	if opts.FilePath == "" && opts.WebhookURL == "" {
+		return nil, nil
+	}
+	if err := ValidatePatterns(opts.Redactions); err != nil {
+		return nil, err
+	}
+	if opts.Logger == nil {
+		opts.Logger = slog.New(slog.DiscardHandler)
+	}
+
+	var sinks []Sink
+	if opts.FilePath != "" {
+		sink, err := NewFileSink(opts.FilePath, opts.File)
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, sink)
+	}
+	if opts.WebhookURL != "" {
+		u, err := url.Parse(opts.WebhookURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			for _, sink := range sinks {
+				_ = sink.Close()
+			}
+			return nil, fmt.Errorf("invalid audit webhook URL %q", opts.WebhookURL)
+		}
+		sinks = append(sinks, NewWebhookSink(opts.WebhookURL, WebhookSinkOptions{
+			Secret: opts.WebhookSecret,
+			Logger: opts.Logger,
+		}))
+	}
+
+	return NewLogger(LoggerOptions{
+		Redactor: NewRedactor(append(slices.Clone(DefaultRedactions), opts.Redactions...)),
+		Logger:   opts.Logger,
+	}, sinks...), nil
+}
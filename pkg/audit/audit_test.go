@@ -0,0 +1,204 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingSink struct {
+	events []*Event
+	err    error
+}
+
+func (s *recordingSink) Write(event *Event) error {
+	s.events = append(s.events, event)
+	return s.err
+}
+
+func (s *recordingSink) Close() error { return nil }
+
+func TestRedactor(t *testing.T) {
+	t.Parallel()
+
+	r := NewRedactor(append(DefaultRedactions, "Query"))
+	long := strings.Repeat("a", maxValueLength+10)
+	redacted := r.Redact(map[string]any{
+		"owner":        "octo",
+		"body":         "secret plans",
+		"GITHUB_TOKEN": "ghp_x",
+		"query":        "is:open",
+		"title":        long,
+		"page":         float64(2),
+		"files": []any{
+			map[string]any{"path": "a.txt", "content": "x"},
+		},
+		"reviewers": []any{
+			map[string]any{"login": "hubot", "api_secret": "s"},
+		},
+	})
+
+	assert.Equal(t, "octo", redacted["owner"])
+	assert.Equal(t, Redacted, redacted["body"])
+	assert.Equal(t, Redacted, redacted["GITHUB_TOKEN"], "patterns are case-insensitive")
+	assert.Equal(t, Redacted, redacted["query"], "extra patterns are applied")
+	assert.Equal(t, Redacted, redacted["files"])
+	assert.Equal(t, float64(2), redacted["page"])
+	assert.Equal(t, long[:maxValueLength]+"... (266 bytes)", redacted["title"])
+	assert.Equal(t, []any{map[string]any{"login": "hubot", "api_secret": Redacted}}, redacted["reviewers"], "nested arguments are redacted")
+
+	assert.Nil(t, r.Redact(nil))
+}
+
+func TestValidatePatterns(t *testing.T) {
+	t.Parallel()
+
+	assert.NoError(t, ValidatePatterns([]string{"*token*", "body"}))
+	assert.Error(t, ValidatePatterns([]string{"[unclosed"}))
+}
+
+func TestLoggerRedactsAndWritesToEverySink(t *testing.T) {
+	t.Parallel()
+
+	first := &recordingSink{err: io.ErrShortWrite}
+	second := &recordingSink{}
+	logger := NewLogger(LoggerOptions{}, first, second)
+
+	logger.Record(&Event{Tool: "create_issue", Arguments: map[string]any{"body": "hi", "title": "t"}})
+
+	require.Len(t, second.events, 1, "a failing sink does not stop the others")
+	assert.Equal(t, Redacted, second.events[0].Arguments["body"])
+	assert.Equal(t, "t", second.events[0].Arguments["title"])
+	require.NoError(t, logger.Close())
+}
+
+func readLines(t *testing.T, path string) []Event {
+	t.Helper()
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	var events []Event
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var event Event
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &event))
+		events = append(events, event)
+	}
+	require.NoError(t, scanner.Err())
+	return events
+}
+
+func TestFileSinkRotates(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	line, err := json.Marshal(&Event{Tool: "t1"})
+	require.NoError(t, err)
+
+	// Room for two events per file, each followed by a newline
+	sink, err := NewFileSink(path, FileSinkOptions{MaxBytes: int64(2 * (len(line) + 1)), MaxBackups: 2})
+	require.NoError(t, err)
+	for _, tool := range []string{"t1", "t2", "t3", "t4", "t5", "t6", "t7"} {
+		require.NoError(t, sink.Write(&Event{Tool: tool}))
+	}
+	require.NoError(t, sink.Close())
+
+	tools := func(path string) []string {
+		var names []string
+		for _, event := range readLines(t, path) {
+			names = append(names, event.Tool)
+		}
+		return names
+	}
+	assert.Equal(t, []string{"t7"}, tools(path))
+	assert.Equal(t, []string{"t5", "t6"}, tools(path+".1"))
+	assert.Equal(t, []string{"t3", "t4"}, tools(path+".2"))
+	assert.NoFileExists(t, path+".3", "older backups are removed")
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	assert.Error(t, sink.Write(&Event{Tool: "t8"}), "writes fail after close")
+}
+
+func TestFileSinkAppendsToExistingFile(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	for _, tool := range []string{"first", "second"} {
+		sink, err := NewFileSink(path, FileSinkOptions{})
+		require.NoError(t, err)
+		require.NoError(t, sink.Write(&Event{Tool: tool}))
+		require.NoError(t, sink.Close())
+	}
+	assert.Len(t, readLines(t, path), 2)
+}
+
+func TestWebhookSinkDeliversSignedBatches(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	var bodies []string
+	failNext := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		defer mu.Unlock()
+		if failNext {
+			failNext = false
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		assert.Equal(t, "application/x-ndjson", r.Header.Get("Content-Type"))
+		assert.Equal(t, Sign("s3cret", body), r.Header.Get(SignatureHeader))
+		bodies = append(bodies, string(body))
+	}))
+	t.Cleanup(server.Close)
+
+	sink := NewWebhookSink(server.URL, WebhookSinkOptions{Secret: "s3cret", BatchSize: 2, FlushInterval: time.Hour})
+	for _, tool := range []string{"a", "b", "c"} {
+		require.NoError(t, sink.Write(&Event{Tool: tool}))
+	}
+	require.NoError(t, sink.Close())
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, bodies, 2, "a full batch is sent, retried once, and the rest flushed on close")
+	assert.Equal(t, 2, strings.Count(bodies[0], "\n"))
+	assert.Contains(t, bodies[1], `"tool":"c"`)
+}
+
+func TestWebhookSinkDropsWhenBufferIsFull(t *testing.T) {
+	t.Parallel()
+
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) {
+		<-release
+	}))
+	t.Cleanup(server.Close)
+
+	sink := NewWebhookSink(server.URL, WebhookSinkOptions{BatchSize: 1, BufferSize: 1, FlushInterval: time.Hour})
+	var err error
+	for range 10 {
+		if err = sink.Write(&Event{Tool: "a"}); err != nil {
+			break
+		}
+	}
+	assert.ErrorIs(t, err, ErrWebhookBufferFull)
+
+	close(release)
+	require.NoError(t, sink.Close())
+}
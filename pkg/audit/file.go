@@ -0,0 +1,124 @@
+package audit
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// DefaultMaxFileBytes is the size at which audit log files are rotated by default.
+const DefaultMaxFileBytes = 100 << 20
+
+// FileSinkOptions configures a FileSink.
+type FileSinkOptions struct {
+	// MaxBytes is the size beyond which the file is rotated. Defaults to
+	// DefaultMaxFileBytes.
+	MaxBytes int64
+
+	// MaxBackups is the number of rotated files kept, named after the file with a ".1",
+	// ".2", ... suffix from newest to oldest. Zero keeps no rotated files.
+	MaxBackups int
+}
+
+// FileSink appends events to a file as JSON Lines, rotating it when it grows too large.
+type FileSink struct {
+	path string
+	opts FileSinkOptions
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+// NewFileSink opens path for appending, creating it readable only by its owner if it
+// doesn't exist.
+func NewFileSink(path string, opts FileSinkOptions) (*FileSink, error) {
+	if opts.MaxBytes <= 0 {
+		opts.MaxBytes = DefaultMaxFileBytes
+	}
+	s := &FileSink{path: path, opts: opts}
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Write appends event to the file.
+func (s *FileSink) Write(event *Event) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file == nil {
+		return errors.New("audit log is closed")
+	}
+	if s.size > 0 && s.size+int64(len(line)) > s.opts.MaxBytes {
+		if err := s.rotate(); err != nil {
+			return fmt.Errorf("failed to rotate audit log: %w", err)
+		}
+	}
+	n, err := s.file.Write(line)
+	s.size += int64(n)
+	return err
+}
+
+// Close closes the file.
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
+
+func (s *FileSink) open() error {
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	s.file = file
+	s.size = info.Size()
+	return nil
+}
+
+// rotate shifts the backups along, moves the current file to the first backup and starts
+// a new one. It must be called with s.mu held.
+func (s *FileSink) rotate() error {
+	if err := s.file.Close(); err != nil {
+		return err
+	}
+	s.file = nil
+
+	if s.opts.MaxBackups > 0 {
+		_ = os.Remove(s.backupPath(s.opts.MaxBackups))
+		for i := s.opts.MaxBackups - 1; i >= 1; i-- {
+			if err := os.Rename(s.backupPath(i), s.backupPath(i+1)); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return err
+			}
+		}
+		if err := os.Rename(s.path, s.backupPath(1)); err != nil {
+			return err
+		}
+	} else if err := os.Remove(s.path); err != nil {
+		return err
+	}
+	return s.open()
+}
+
+func (s *FileSink) backupPath(n int) string {
+	return fmt.Sprintf("%s.%d", s.path, n)
+}
@@ -0,0 +1,100 @@
+package audit
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Redacted replaces the values of redacted arguments.
+const Redacted = "[REDACTED]"
+
+// maxValueLength is the length beyond which string arguments are truncated, so that file
+// contents and issue bodies do not bloat the audit log.
+const maxValueLength = 256
+
+// DefaultRedactions are the argument name patterns redacted by default: credentials, and
+// free-form content that may contain them.
+var DefaultRedactions = []string{
+	"*token*",
+	"*secret*",
+	"*password*",
+	"*private_key*",
+	"body",
+	"content",
+	"files",
+}
+
+// Redactor replaces the values of arguments whose names match its patterns.
+type Redactor struct {
+	patterns []string
+}
+
+// NewRedactor creates a redactor for patterns, which are matched case-insensitively
+// against argument names at any depth with path.Match syntax, such as "*token*".
+func NewRedactor(patterns []string) *Redactor {
+	r := &Redactor{}
+	for _, pattern := range patterns {
+		if pattern = strings.ToLower(strings.TrimSpace(pattern)); pattern != "" {
+			r.patterns = append(r.patterns, pattern)
+		}
+	}
+	return r
+}
+
+// ValidatePatterns reports the first malformed pattern.
+func ValidatePatterns(patterns []string) error {
+	for _, pattern := range patterns {
+		if _, err := path.Match(strings.ToLower(strings.TrimSpace(pattern)), ""); err != nil {
+			return fmt.Errorf("invalid redaction pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// Redact returns a copy of args with matching arguments redacted and long strings
+// truncated.
+func (r *Redactor) Redact(args map[string]any) map[string]any {
+	if args == nil {
+		return nil
+	}
+	redacted := make(map[string]any, len(args))
+	for name, value := range args {
+		if r.matches(name) {
+			redacted[name] = Redacted
+			continue
+		}
+		redacted[name] = r.redactValue(value)
+	}
+	return redacted
+}
+
+func (r *Redactor) redactValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		return r.Redact(v)
+	case []any:
+		items := make([]any, len(v))
+		for i, item := range v {
+			items[i] = r.redactValue(item)
+		}
+		return items
+	case string:
+		if len(v) > maxValueLength {
+			return fmt.Sprintf("%s... (%d bytes)", v[:maxValueLength], len(v))
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+func (r *Redactor) matches(name string) bool {
+	name = strings.ToLower(name)
+	for _, pattern := range r.patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
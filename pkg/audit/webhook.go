@@ -0,0 +1,179 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrWebhookBufferFull is returned when events arrive faster than the webhook accepts them.
+var ErrWebhookBufferFull = errors.New("audit webhook buffer is full")
+
+// SignatureHeader carries the HMAC-SHA256 signature of webhook deliveries, in the same
+// "sha256=<hex>" format as GitHub webhooks.
+const SignatureHeader = "X-Hub-Signature-256"
+
+// WebhookSinkOptions configures a WebhookSink.
+type WebhookSinkOptions struct {
+	// Secret signs every delivery with SignatureHeader when set.
+	Secret string
+
+	// BatchSize is the maximum number of events per delivery. Defaults to 100.
+	BatchSize int
+
+	// FlushInterval is how long events wait for a batch to fill. Defaults to 5 seconds.
+	FlushInterval time.Duration
+
+	// BufferSize is the number of events held while deliveries are in progress; events
+	// beyond it are dropped. Defaults to 10000.
+	BufferSize int
+
+	// HTTPClient delivers the events. Defaults to a client with a timeout.
+	HTTPClient *http.Client
+
+	// Logger reports failed deliveries.
+	Logger *slog.Logger
+}
+
+// WebhookSink posts events to a URL in batches, as a JSON Lines body. Deliveries happen in
+// the background so that a slow webhook does not delay tool calls. A delivery that fails
+// is retried once before its events are dropped.
+type WebhookSink struct {
+	url  string
+	opts WebhookSinkOptions
+
+	events    chan []byte
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewWebhookSink starts delivering events to url.
+func NewWebhookSink(url string, opts WebhookSinkOptions) *WebhookSink {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 100
+	}
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = 5 * time.Second
+	}
+	if opts.BufferSize <= 0 {
+		opts.BufferSize = 10000
+	}
+	if opts.HTTPClient == nil {
+		opts.HTTPClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	if opts.Logger == nil {
+		opts.Logger = slog.New(slog.DiscardHandler)
+	}
+
+	s := &WebhookSink{
+		url:    url,
+		opts:   opts,
+		events: make(chan []byte, opts.BufferSize),
+		done:   make(chan struct{}),
+	}
+	go s.run()
+	return s
+}
+
+// Write queues event for delivery.
+func (s *WebhookSink) Write(event *Event) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	select {
+	case s.events <- append(line, '\n'):
+		return nil
+	default:
+		return ErrWebhookBufferFull
+	}
+}
+
+// Close delivers the queued events and stops the sink. Events written after Close panic.
+func (s *WebhookSink) Close() error {
+	s.closeOnce.Do(func() { close(s.events) })
+	<-s.done
+	return nil
+}
+
+func (s *WebhookSink) run() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.opts.FlushInterval)
+	defer ticker.Stop()
+
+	var batch bytes.Buffer
+	count := 0
+	flush := func() {
+		if count == 0 {
+			return
+		}
+		s.deliver(batch.Bytes(), count)
+		batch.Reset()
+		count = 0
+	}
+
+	for {
+		select {
+		case line, ok := <-s.events:
+			if !ok {
+				flush()
+				return
+			}
+			batch.Write(line)
+			count++
+			if count >= s.opts.BatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+func (s *WebhookSink) deliver(body []byte, count int) {
+	var err error
+	for range 2 {
+		if err = s.post(body); err == nil {
+			return
+		}
+	}
+	s.opts.Logger.Warn("failed to deliver audit events", "url", s.url, "events", count, "error", err)
+}
+
+func (s *WebhookSink) post(body []byte) error {
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	if s.opts.Secret != "" {
+		req.Header.Set(SignatureHeader, Sign(s.opts.Secret, body))
+	}
+
+	resp, err := s.opts.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Sign returns the SignatureHeader value for body, for verifying deliveries.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,34 @@
+package context
+
+import "context"
+
+type clientIdentityCtxKey struct{}
+
+// ClientIdentity describes the client certificate presented over mutual TLS.
+type ClientIdentity struct {
+	// CommonName is the subject common name of the client certificate
+	CommonName string
+	// Organization is the subject organization of the client certificate
+	Organization []string
+	// DNSNames, EmailAddresses and URIs are the subject alternative names of the client certificate
+	DNSNames       []string
+	EmailAddresses []string
+	URIs           []string
+	// SerialNumber is the certificate serial number in hexadecimal
+	SerialNumber string
+	// Fingerprint is the hex-encoded SHA-256 digest of the certificate
+	Fingerprint string
+}
+
+// WithClientIdentity adds the mutual TLS client identity to the context
+func WithClientIdentity(ctx context.Context, identity *ClientIdentity) context.Context {
+	return context.WithValue(ctx, clientIdentityCtxKey{}, identity)
+}
+
+// GetClientIdentity retrieves the mutual TLS client identity from the context
+func GetClientIdentity(ctx context.Context) (*ClientIdentity, bool) {
+	if identity, ok := ctx.Value(clientIdentityCtxKey{}).(*ClientIdentity); ok {
+		return identity, true
+	}
+	return nil, false
+}
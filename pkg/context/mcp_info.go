@@ -1,6 +1,9 @@
 package context
 
-import "context"
+import (
+	"context"
+	"encoding/json"
+)
 
 type mcpMethodInfoCtx string
 
@@ -12,6 +15,8 @@ var mcpMethodInfoCtxKey mcpMethodInfoCtx = "mcpmethodinfo"
 //   - Avoiding duplicate JSON parsing in middlewares (secret-scanning, scope-challenge)
 //   - Performance optimization for per-request server creation
 type MCPMethodInfo struct {
+	// ID is the raw JSON-RPC request ID, used by middleware that answers the request itself
+	ID json.RawMessage
 	// Method is the MCP method being called (e.g., "tools/call", "tools/list", "initialize")
 	Method string
 	// ItemName is the name of the specific item being accessed (tool name, resource URI, prompt name)
@@ -0,0 +1,91 @@
+package context
+
+import (
+	"context"
+	"maps"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// rateLimitNotifierCtxKey is a context key for the rate limit wait notifier
+type rateLimitNotifierCtxKey struct{}
+
+// RateLimitNotifier is called before a GitHub API request waits for a rate limit to
+// reset and is retried. Attempt counts the retries, starting at 1.
+type RateLimitNotifier func(ctx context.Context, wait time.Duration, attempt int)
+
+// WithRateLimitNotifier adds a rate limit wait notifier to the context
+func WithRateLimitNotifier(ctx context.Context, notify RateLimitNotifier) context.Context {
+	return context.WithValue(ctx, rateLimitNotifierCtxKey{}, notify)
+}
+
+// GetRateLimitNotifier retrieves the rate limit wait notifier from the context
+func GetRateLimitNotifier(ctx context.Context) RateLimitNotifier {
+	if notify, ok := ctx.Value(rateLimitNotifierCtxKey{}).(RateLimitNotifier); ok {
+		return notify
+	}
+	return nil
+}
+
+// rateLimitsCtxKey is a context key for the rate limits reported during a request
+type rateLimitsCtxKey struct{}
+
+// RateLimit is the state of a GitHub rate limit, as reported in response headers.
+type RateLimit struct {
+	Limit     int
+	Remaining int
+	Used      int
+	Reset     time.Time
+}
+
+// RateLimits collects the latest rate limit GitHub reported for each resource, such as
+// "core", "search" or "graphql", over the API requests made for an MCP request.
+type RateLimits struct {
+	mu        sync.Mutex
+	resources map[string]RateLimit
+}
+
+// Observe records the rate limit reported by the X-RateLimit-* headers of a response.
+// Responses without them are ignored.
+func (r *RateLimits) Observe(header http.Header) {
+	resource := header.Get("X-RateLimit-Resource")
+	limit, limitErr := strconv.Atoi(header.Get("X-RateLimit-Limit"))
+	remaining, remainingErr := strconv.Atoi(header.Get("X-RateLimit-Remaining"))
+	if resource == "" || limitErr != nil || remainingErr != nil {
+		return
+	}
+	rate := RateLimit{Limit: limit, Remaining: remaining}
+	rate.Used, _ = strconv.Atoi(header.Get("X-RateLimit-Used"))
+	if reset, err := strconv.ParseInt(header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		rate.Reset = time.Unix(reset, 0).UTC()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.resources == nil {
+		r.resources = make(map[string]RateLimit)
+	}
+	r.resources[resource] = rate
+}
+
+// Resources returns the rate limits observed so far by resource.
+func (r *RateLimits) Resources() map[string]RateLimit {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return maps.Clone(r.resources)
+}
+
+// WithRateLimits adds a rate limit collector to the context
+func WithRateLimits(ctx context.Context, limits *RateLimits) context.Context {
+	return context.WithValue(ctx, rateLimitsCtxKey{}, limits)
+}
+
+// GetRateLimits retrieves the rate limit collector from the context
+func GetRateLimits(ctx context.Context) *RateLimits {
+	if limits, ok := ctx.Value(rateLimitsCtxKey{}).(*RateLimits); ok {
+		return limits
+	}
+	return nil
+}
@@ -378,10 +378,16 @@ Use this tool to list workflows in a repository, or list workflow runs, jobs, an
 			case actionsMethodListWorkflows:
 				return listWorkflows(ctx, client, owner, repo, pagination)
 			case actionsMethodListWorkflowRuns:
-				return listWorkflowRuns(ctx, client, args, owner, repo, resourceID, pagination)
+				return listWorkflowRuns(ctx, client, deps, args, owner, repo, resourceID, pagination)
 			case actionsMethodListWorkflowJobs:
+				if result, err := restrictWorkflowRun(ctx, client, deps, owner, repo, resourceIDInt); result != nil || err != nil {
+					return result, nil, err
+				}
 				return listWorkflowJobs(ctx, client, args, owner, repo, resourceIDInt, pagination)
 			case actionsMethodListWorkflowArtifacts:
+				if result, err := restrictWorkflowRun(ctx, client, deps, owner, repo, resourceIDInt); result != nil || err != nil {
+					return result, nil, err
+				}
 				return listWorkflowArtifacts(ctx, client, owner, repo, resourceIDInt, pagination)
 			default:
 				return utils.NewToolResultError(fmt.Sprintf("unknown method: %s", method)), nil, nil
@@ -482,14 +488,23 @@ Use this tool to get details about individual workflows, workflow runs, jobs, an
 			case actionsMethodGetWorkflow:
 				return getWorkflow(ctx, client, owner, repo, resourceID)
 			case actionsMethodGetWorkflowRun:
-				return getWorkflowRun(ctx, client, owner, repo, resourceIDInt)
+				return getWorkflowRun(ctx, client, deps, owner, repo, resourceIDInt)
 			case actionsMethodGetWorkflowJob:
+				if result, err := restrictWorkflowJob(ctx, client, deps, owner, repo, resourceIDInt); result != nil || err != nil {
+					return result, nil, err
+				}
 				return getWorkflowJob(ctx, client, owner, repo, resourceIDInt)
 			case actionsMethodDownloadWorkflowArtifact:
+				if result, err := restrictWorkflowArtifact(ctx, client, deps, owner, repo, resourceIDInt); result != nil || err != nil {
+					return result, nil, err
+				}
 				return downloadWorkflowArtifact(ctx, client, owner, repo, resourceIDInt)
 			case actionsMethodGetWorkflowRunUsage:
 				return getWorkflowRunUsage(ctx, client, owner, repo, resourceIDInt)
 			case actionsMethodGetWorkflowRunLogsURL:
+				if result, err := restrictWorkflowRun(ctx, client, deps, owner, repo, resourceIDInt); result != nil || err != nil {
+					return result, nil, err
+				}
 				return getWorkflowRunLogsURL(ctx, client, owner, repo, resourceIDInt)
 			default:
 				return utils.NewToolResultError(fmt.Sprintf("unknown method: %s", method)), nil, nil
@@ -722,9 +737,15 @@ For single job logs, provide job_id. For all failed jobs in a run, provide run_i
 
 			if failedOnly && runID > 0 {
 				// Handle failed-only mode: get logs for all failed jobs in the workflow run
+				if result, err := restrictWorkflowRun(ctx, client, deps, owner, repo, int64(runID)); result != nil || err != nil {
+					return result, nil, err
+				}
 				return handleFailedJobLogs(ctx, client, owner, repo, int64(runID), returnContent, tailLines, deps.GetContentWindowSize())
 			} else if jobID > 0 {
 				// Handle single job mode
+				if result, err := restrictWorkflowJob(ctx, client, deps, owner, repo, int64(jobID)); result != nil || err != nil {
+					return result, nil, err
+				}
 				return handleSingleJobLogs(ctx, client, owner, repo, int64(jobID), returnContent, tailLines, deps.GetContentWindowSize())
 			}
 
@@ -736,6 +757,74 @@ For single job logs, provide job_id. For all failed jobs in a run, provide run_i
 
 // Helper functions for consolidated actions tools
 
+// workflowRunAuthor returns the author of workflow runs of a repository: the user whose push,
+// pull request or other event started the run. A run of a pull request from a fork runs the
+// workflows and code of that pull request, so its jobs, logs and artifacts are theirs too.
+// Re-runs keep the original author.
+func workflowRunAuthor(owner, repo string) func(*github.WorkflowRun) contentAuthor {
+	return func(run *github.WorkflowRun) contentAuthor {
+		return contentAuthor{Kind: "workflow run", Login: run.GetActor().GetLogin(), Owner: owner, Repo: repo}
+	}
+}
+
+// sanitizeWorkflowRun sanitizes the title, head branch and head commit message of a
+// workflow run.
+func sanitizeWorkflowRun(ctx context.Context, run *github.WorkflowRun) {
+	sanitizeText(ctx, run.DisplayTitle, run.HeadBranch)
+	if run.HeadCommit != nil {
+		sanitizeText(ctx, run.HeadCommit.Message)
+	}
+}
+
+// restrictWorkflowRun returns an error result in lockdown mode if the author of a workflow
+// run is not trusted. Jobs, logs and artifacts are returned as is otherwise, since
+// sanitizing would alter them.
+func restrictWorkflowRun(ctx context.Context, client *github.Client, deps ToolDependencies, owner, repo string, runID int64) (*mcp.CallToolResult, error) {
+	if !deps.GetFlags(ctx).LockdownMode {
+		return nil, nil
+	}
+	run, resp, err := client.Actions.GetWorkflowRunByID(ctx, owner, repo, runID)
+	if err != nil {
+		return ghErrors.NewGitHubAPIErrorResponse(ctx, "failed to get workflow run", resp, err), nil
+	}
+	_ = resp.Body.Close()
+
+	safe, err := checkUserContent(ctx, deps, workflowRunAuthor(owner, repo)(run))
+	if err != nil {
+		return utils.NewToolResultError(err.Error()), nil
+	}
+	if !safe {
+		return utils.NewToolResultError("access to workflow run is restricted by lockdown mode"), nil
+	}
+	return nil, nil
+}
+
+// restrictWorkflowJob is restrictWorkflowRun for the run of a job.
+func restrictWorkflowJob(ctx context.Context, client *github.Client, deps ToolDependencies, owner, repo string, jobID int64) (*mcp.CallToolResult, error) {
+	if !deps.GetFlags(ctx).LockdownMode {
+		return nil, nil
+	}
+	job, resp, err := client.Actions.GetWorkflowJobByID(ctx, owner, repo, jobID)
+	if err != nil {
+		return ghErrors.NewGitHubAPIErrorResponse(ctx, "failed to get workflow job", resp, err), nil
+	}
+	_ = resp.Body.Close()
+	return restrictWorkflowRun(ctx, client, deps, owner, repo, job.GetRunID())
+}
+
+// restrictWorkflowArtifact is restrictWorkflowRun for the run that produced an artifact.
+func restrictWorkflowArtifact(ctx context.Context, client *github.Client, deps ToolDependencies, owner, repo string, artifactID int64) (*mcp.CallToolResult, error) {
+	if !deps.GetFlags(ctx).LockdownMode {
+		return nil, nil
+	}
+	artifact, resp, err := client.Actions.GetArtifact(ctx, owner, repo, artifactID)
+	if err != nil {
+		return ghErrors.NewGitHubAPIErrorResponse(ctx, "failed to get artifact", resp, err), nil
+	}
+	_ = resp.Body.Close()
+	return restrictWorkflowRun(ctx, client, deps, owner, repo, artifact.GetWorkflowRun().GetID())
+}
+
 func getWorkflow(ctx context.Context, client *github.Client, owner, repo, resourceID string) (*mcp.CallToolResult, any, error) {
 	var workflow *github.Workflow
 	var resp *github.Response
@@ -760,12 +849,22 @@ func getWorkflow(ctx context.Context, client *github.Client, owner, repo, resour
 	return utils.NewToolResultText(string(r)), nil, nil
 }
 
-func getWorkflowRun(ctx context.Context, client *github.Client, owner, repo string, resourceID int64) (*mcp.CallToolResult, any, error) {
+func getWorkflowRun(ctx context.Context, client *github.Client, deps ToolDependencies, owner, repo string, resourceID int64) (*mcp.CallToolResult, any, error) {
 	workflowRun, resp, err := client.Actions.GetWorkflowRunByID(ctx, owner, repo, resourceID)
 	if err != nil {
 		return ghErrors.NewGitHubAPIErrorResponse(ctx, "failed to get workflow run", resp, err), nil, nil
 	}
 	defer func() { _ = resp.Body.Close() }()
+
+	safe, err := checkUserContent(ctx, deps, workflowRunAuthor(owner, repo)(workflowRun))
+	if err != nil {
+		return utils.NewToolResultError(err.Error()), nil, nil
+	}
+	if !safe {
+		return utils.NewToolResultError("access to workflow run is restricted by lockdown mode"), nil, nil
+	}
+	sanitizeWorkflowRun(ctx, workflowRun)
+
 	r, err := json.Marshal(workflowRun)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to marshal workflow run: %w", err)
@@ -806,7 +905,7 @@ func listWorkflows(ctx context.Context, client *github.Client, owner, repo strin
 	return utils.NewToolResultText(string(r)), nil, nil
 }
 
-func listWorkflowRuns(ctx context.Context, client *github.Client, args map[string]any, owner, repo, resourceID string, pagination PaginationParams) (*mcp.CallToolResult, any, error) {
+func listWorkflowRuns(ctx context.Context, client *github.Client, deps ToolDependencies, args map[string]any, owner, repo, resourceID string, pagination PaginationParams) (*mcp.CallToolResult, any, error) {
 	filterArgs, err := OptionalParam[map[string]any](args, "workflow_runs_filter")
 	if err != nil {
 		return utils.NewToolResultError(err.Error()), nil, nil
@@ -848,6 +947,12 @@ func listWorkflowRuns(ctx context.Context, client *github.Client, args map[strin
 	}
 
 	defer func() { _ = resp.Body.Close() }()
+
+	workflowRuns.WorkflowRuns, err = filterUserContent(ctx, deps, workflowRuns.WorkflowRuns, workflowRunAuthor(owner, repo), sanitizeWorkflowRun)
+	if err != nil {
+		return utils.NewToolResultError(err.Error()), nil, nil
+	}
+
 	r, err := json.Marshal(workflowRuns)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to marshal workflow runs: %w", err)
@@ -0,0 +1,189 @@
+package github
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/github/github-mcp-server/pkg/audit"
+	ghcontext "github.com/github/github-mcp-server/pkg/context"
+	gherrors "github.com/github/github-mcp-server/pkg/errors"
+	"github.com/github/github-mcp-server/pkg/inventory"
+	"github.com/github/github-mcp-server/pkg/metrics"
+	"github.com/github/github-mcp-server/pkg/utils"
+	"github.com/google/go-github/v82/github"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// auditIdentityTTL is how long the GitHub identity of a token is cached for audit events.
+const auditIdentityTTL = time.Hour
+
+// auditTokenTypes names the kinds of token recorded in audit events.
+var auditTokenTypes = map[utils.TokenType]string{
+	utils.TokenTypePersonalAccessToken:            "personal_access_token",
+	utils.TokenTypeFineGrainedPersonalAccessToken: "fine_grained_personal_access_token",
+	utils.TokenTypeOAuthAccessToken:               "oauth",
+	utils.TokenTypeUserToServerGitHubAppToken:     "github_app_user",
+	utils.TokenTypeServerToServerGitHubAppToken:   "github_app_installation",
+}
+
+// AuditMiddleware records an audit event for every tool call. The identity of the token is
+// looked up with the get_me API once per token and cached. Like MetricsMiddleware, it must
+// be added after addGitHubAPIErrorToContext so that it can classify failures.
+func AuditMiddleware(auditor *audit.Logger, inv *inventory.Inventory, getClient GetClientFn) mcp.Middleware {
+	dynamicTools := make(map[string]bool)
+	for _, tool := range DynamicTools(inv) {
+		dynamicTools[tool.Tool.Name] = true
+	}
+	identities := newAuditIdentities(getClient)
+
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			callReq, ok := req.(*mcp.CallToolRequest)
+			if method != "tools/call" || !ok || callReq.Params == nil {
+				return next(ctx, method, req)
+			}
+
+			requested := callReq.Params.Name
+			ctx = gherrors.ContextWithGitHubErrors(ctx)
+			start := time.Now()
+			result, err := next(ctx, method, req)
+			duration := time.Since(start)
+			outcome := toolCallErrorClass(ctx, result, err)
+
+			event := &audit.Event{
+				Time:       start.UTC(),
+				Actor:      identities.lookup(ctx),
+				Client:     auditClient(ctx, req),
+				Tool:       callReq.Params.Name,
+				Access:     audit.AccessUnknown,
+				Outcome:    outcome,
+				DurationMS: float64(duration.Microseconds()) / 1000,
+			}
+			if event.Outcome == metrics.ErrorClassNone {
+				event.Outcome = audit.OutcomeSuccess
+			}
+			if event.Tool != requested {
+				event.RequestedTool = requested
+			}
+			if session, ok := req.GetSession().(*mcp.ServerSession); ok && session != nil {
+				event.SessionID = session.ID()
+			}
+			if tool, _, findErr := inv.FindToolByName(event.Tool); findErr == nil {
+				event.Access = audit.AccessWrite
+				if tool.IsReadOnly() {
+					event.Access = audit.AccessRead
+				}
+			} else if dynamicTools[event.Tool] {
+				event.Access = audit.AccessRead
+			}
+
+			var args map[string]any
+			if json.Unmarshal(callReq.Params.Arguments, &args) == nil {
+				event.Owner, _ = args["owner"].(string)
+				event.Repo, _ = args["repo"].(string)
+				event.Arguments = args
+			}
+
+			auditor.Record(event)
+			return result, err
+		}
+	}
+}
+
+// auditClient describes the MCP client from its initialize request and, over mutual TLS,
+// its certificate.
+func auditClient(ctx context.Context, req mcp.Request) audit.Client {
+	var client audit.Client
+	if session, ok := req.GetSession().(*mcp.ServerSession); ok && session != nil {
+		if params := session.InitializeParams(); params != nil && params.ClientInfo != nil {
+			client.Name = params.ClientInfo.Name
+			client.Version = params.ClientInfo.Version
+		}
+	}
+	if identity, ok := ghcontext.GetClientIdentity(ctx); ok {
+		client.Certificate = identity.CommonName
+	}
+	return client
+}
+
+// auditIdentities caches the GitHub identity of tokens, keyed by a hash of the token so
+// that tokens are not kept in memory longer than the request that carried them.
+type auditIdentities struct {
+	getClient GetClientFn
+
+	mu      sync.Mutex
+	entries map[[sha256.Size]byte]auditIdentity
+}
+
+type auditIdentity struct {
+	actor     audit.Actor
+	expiresAt time.Time
+}
+
+func newAuditIdentities(getClient GetClientFn) *auditIdentities {
+	return &auditIdentities{getClient: getClient, entries: make(map[[sha256.Size]byte]auditIdentity)}
+}
+
+// lookup returns the identity of the token of the request. In stdio mode there is no token
+// in the context, so the server's own token is looked up once.
+func (c *auditIdentities) lookup(ctx context.Context) audit.Actor {
+	var token, tokenType string
+	if info, ok := ghcontext.GetTokenInfo(ctx); ok {
+		token, tokenType = info.Token, auditTokenTypes[info.TokenType]
+	}
+	key := sha256.Sum256([]byte(token))
+
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	c.mu.Unlock()
+	if ok && time.Now().Before(entry.expiresAt) {
+		return entry.actor
+	}
+
+	actor := audit.Actor{TokenType: tokenType}
+	client, err := c.getClient(ctx)
+	if err != nil {
+		return actor
+	}
+	user, _, err := client.Users.Get(ctx, "")
+	switch {
+	case err == nil:
+		actor.Login = user.GetLogin()
+		actor.ID = user.GetID()
+	case !definiteIdentityFailure(err):
+		// Transient failures are retried on the next call
+		return actor
+	}
+
+	c.mu.Lock()
+	now := time.Now()
+	for k, e := range c.entries {
+		if now.After(e.expiresAt) {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = auditIdentity{actor: actor, expiresAt: now.Add(auditIdentityTTL)}
+	c.mu.Unlock()
+	return actor
+}
+
+// definiteIdentityFailure reports whether looking up the user of a token failed in a way
+// that won't change on retry, like the 401 or 403 of GitHub App installation tokens, which
+// have no user. Such failures are cached like identities.
+func definiteIdentityFailure(err error) bool {
+	var errResp *github.ErrorResponse
+	if !errors.As(err, &errResp) || errResp.Response == nil {
+		return false
+	}
+	switch errResp.Response.StatusCode {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return true
+	default:
+		return false
+	}
+}
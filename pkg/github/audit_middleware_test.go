@@ -0,0 +1,229 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+
+	"github.com/github/github-mcp-server/pkg/audit"
+	ghcontext "github.com/github/github-mcp-server/pkg/context"
+	gherrors "github.com/github/github-mcp-server/pkg/errors"
+	"github.com/github/github-mcp-server/pkg/inventory"
+	"github.com/github/github-mcp-server/pkg/translations"
+	"github.com/github/github-mcp-server/pkg/utils"
+	gogithub "github.com/google/go-github/v82/github"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingAuditSink struct {
+	events []*audit.Event
+}
+
+func (s *recordingAuditSink) Write(event *audit.Event) error {
+	s.events = append(s.events, event)
+	return nil
+}
+
+func (s *recordingAuditSink) Close() error { return nil }
+
+func TestAuditMiddleware(t *testing.T) {
+	t.Parallel()
+
+	inv, err := inventory.NewBuilder().
+		SetTools([]inventory.ServerTool{
+			GetMe(translations.NullTranslationHelper),
+			IssueWrite(translations.NullTranslationHelper),
+		}).
+		WithDeprecatedAliases(map[string]string{"old_me": "get_me"}).
+		WithToolsets([]string{"all"}).
+		Build()
+	require.NoError(t, err)
+
+	tests := []struct {
+		name            string
+		toolName        string
+		args            map[string]any
+		handler         mcp.MethodHandler
+		expectedTool    string
+		expectedAlias   string
+		expectedAccess  audit.Access
+		expectedOutcome string
+	}{
+		{
+			name:     "successful write",
+			toolName: "issue_write",
+			args:     map[string]any{"owner": "octo", "repo": "hello", "title": "Bug", "body": "secret"},
+			handler: func(context.Context, string, mcp.Request) (mcp.Result, error) {
+				return utils.NewToolResultText("ok"), nil
+			},
+			expectedTool:    "issue_write",
+			expectedAccess:  audit.AccessWrite,
+			expectedOutcome: audit.OutcomeSuccess,
+		},
+		{
+			name:     "failed GitHub request",
+			toolName: "get_me",
+			handler: func(ctx context.Context, _ string, _ mcp.Request) (mcp.Result, error) {
+				return gherrors.NewGitHubAPIErrorResponse(ctx, "failed", githubResponse(http.StatusNotFound, nil), errors.New("not found")), nil
+			},
+			expectedTool:    "get_me",
+			expectedAccess:  audit.AccessRead,
+			expectedOutcome: "github_not_found",
+		},
+		{
+			name:     "deprecated alias",
+			toolName: "old_me",
+			handler: func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+				return DeprecatedToolAliasMiddleware(inv, false, nil)(func(context.Context, string, mcp.Request) (mcp.Result, error) {
+					return utils.NewToolResultText("ok"), nil
+				})(ctx, method, req)
+			},
+			expectedTool:    "get_me",
+			expectedAlias:   "old_me",
+			expectedAccess:  audit.AccessRead,
+			expectedOutcome: audit.OutcomeSuccess,
+		},
+		{
+			name:     "unknown tool",
+			toolName: "made_up_tool",
+			handler: func(context.Context, string, mcp.Request) (mcp.Result, error) {
+				return nil, errors.New("unknown tool")
+			},
+			expectedTool:    "made_up_tool",
+			expectedAccess:  audit.AccessUnknown,
+			expectedOutcome: "protocol_error",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			client := gogithub.NewClient(MockHTTPClientWithHandlers(map[string]http.HandlerFunc{
+				GetUser: mockResponse(t, http.StatusOK, &gogithub.User{Login: gogithub.Ptr("octocat"), ID: gogithub.Ptr(int64(1))}),
+			}))
+			getClient := func(context.Context) (*gogithub.Client, error) { return client, nil }
+
+			sink := &recordingAuditSink{}
+			handler := AuditMiddleware(audit.NewLogger(audit.LoggerOptions{}, sink), inv, getClient)(tc.handler)
+
+			args, err := json.Marshal(tc.args)
+			require.NoError(t, err)
+			ctx := ghcontext.WithTokenInfo(context.Background(), &ghcontext.TokenInfo{Token: "gho_x", TokenType: utils.TokenTypeOAuthAccessToken})
+			_, _ = handler(ctx, "tools/call", &mcp.CallToolRequest{
+				Params: &mcp.CallToolParamsRaw{Name: tc.toolName, Arguments: args},
+			})
+
+			require.Len(t, sink.events, 1)
+			event := sink.events[0]
+			assert.Equal(t, tc.expectedTool, event.Tool)
+			assert.Equal(t, tc.expectedAlias, event.RequestedTool)
+			assert.Equal(t, tc.expectedAccess, event.Access)
+			assert.Equal(t, tc.expectedOutcome, event.Outcome)
+			assert.Equal(t, audit.Actor{Login: "octocat", ID: 1, TokenType: "oauth"}, event.Actor)
+			assert.False(t, event.Time.IsZero())
+			if tc.args != nil {
+				assert.Equal(t, "octo", event.Owner)
+				assert.Equal(t, "hello", event.Repo)
+				assert.Equal(t, "Bug", event.Arguments["title"])
+				assert.Equal(t, audit.Redacted, event.Arguments["body"])
+			}
+		})
+	}
+}
+
+func TestAuditMiddlewareCachesIdentityPerToken(t *testing.T) {
+	t.Parallel()
+
+	inv, err := inventory.NewBuilder().
+		SetTools([]inventory.ServerTool{GetMe(translations.NullTranslationHelper)}).
+		WithToolsets([]string{"all"}).
+		Build()
+	require.NoError(t, err)
+
+	var lookups atomic.Int32
+	client := gogithub.NewClient(MockHTTPClientWithHandlers(map[string]http.HandlerFunc{
+		GetUser: func(w http.ResponseWriter, _ *http.Request) {
+			// Installation tokens have no user
+			lookups.Add(1)
+			w.WriteHeader(http.StatusForbidden)
+		},
+	}))
+	getClient := func(context.Context) (*gogithub.Client, error) { return client, nil }
+
+	sink := &recordingAuditSink{}
+	handler := AuditMiddleware(audit.NewLogger(audit.LoggerOptions{}, sink), inv, getClient)(
+		func(context.Context, string, mcp.Request) (mcp.Result, error) {
+			return utils.NewToolResultText("ok"), nil
+		})
+
+	for _, token := range []string{"ghs_a", "ghs_a", "ghs_b"} {
+		ctx := ghcontext.WithTokenInfo(context.Background(), &ghcontext.TokenInfo{Token: token, TokenType: utils.TokenTypeServerToServerGitHubAppToken})
+		_, _ = handler(ctx, "tools/call", &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{Name: "get_me"}})
+	}
+
+	assert.Equal(t, int32(2), lookups.Load(), "the identity is looked up once per token, even when it is forbidden")
+	require.Len(t, sink.events, 3)
+	assert.Equal(t, audit.Actor{TokenType: "github_app_installation"}, sink.events[0].Actor)
+}
+
+func TestAuditMiddlewareRetriesTransientIdentityFailures(t *testing.T) {
+	t.Parallel()
+
+	inv, err := inventory.NewBuilder().
+		SetTools([]inventory.ServerTool{GetMe(translations.NullTranslationHelper)}).
+		WithToolsets([]string{"all"}).
+		Build()
+	require.NoError(t, err)
+
+	var lookups atomic.Int32
+	client := gogithub.NewClient(MockHTTPClientWithHandlers(map[string]http.HandlerFunc{
+		GetUser: func(w http.ResponseWriter, _ *http.Request) {
+			if lookups.Add(1) == 1 {
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"login":"octocat","id":1}`))
+		},
+	}))
+	getClient := func(context.Context) (*gogithub.Client, error) { return client, nil }
+
+	sink := &recordingAuditSink{}
+	handler := AuditMiddleware(audit.NewLogger(audit.LoggerOptions{}, sink), inv, getClient)(
+		func(context.Context, string, mcp.Request) (mcp.Result, error) {
+			return utils.NewToolResultText("ok"), nil
+		})
+
+	for range 3 {
+		ctx := ghcontext.WithTokenInfo(context.Background(), &ghcontext.TokenInfo{Token: "gho_a", TokenType: utils.TokenTypeOAuthAccessToken})
+		_, _ = handler(ctx, "tools/call", &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{Name: "get_me"}})
+	}
+
+	assert.Equal(t, int32(2), lookups.Load(), "transient failures are retried, successes are cached")
+	require.Len(t, sink.events, 3)
+	assert.Equal(t, audit.Actor{TokenType: "oauth"}, sink.events[0].Actor)
+	assert.Equal(t, audit.Actor{Login: "octocat", ID: 1, TokenType: "oauth"}, sink.events[1].Actor)
+	assert.Equal(t, sink.events[1].Actor, sink.events[2].Actor)
+}
+
+func TestAuditMiddlewareIgnoresOtherMethods(t *testing.T) {
+	t.Parallel()
+
+	sink := &recordingAuditSink{}
+	inv, err := inventory.NewBuilder().Build()
+	require.NoError(t, err)
+	handler := AuditMiddleware(audit.NewLogger(audit.LoggerOptions{}, sink), inv, nil)(
+		func(context.Context, string, mcp.Request) (mcp.Result, error) {
+			return &mcp.ListToolsResult{}, nil
+		})
+
+	_, err = handler(context.Background(), "tools/list", &mcp.ListToolsRequest{})
+	require.NoError(t, err)
+	assert.Empty(t, sink.events)
+}
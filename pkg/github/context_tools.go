@@ -10,6 +10,7 @@ import (
 	"github.com/github/github-mcp-server/pkg/scopes"
 	"github.com/github/github-mcp-server/pkg/translations"
 	"github.com/github/github-mcp-server/pkg/utils"
+	"github.com/google/go-github/v82/github"
 	"github.com/google/jsonschema-go/jsonschema"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/shurcooL/githubv4"
@@ -287,3 +288,72 @@ func GetTeamMembers(t translations.TranslationHelperFunc) inventory.ServerTool {
 		},
 	)
 }
+
+// RateLimitBucket is the state of one GitHub rate limit.
+type RateLimitBucket struct {
+	Limit     int       `json:"limit"`
+	Remaining int       `json:"remaining"`
+	Used      int       `json:"used"`
+	ResetAt   time.Time `json:"reset_at"`
+}
+
+func convertToRateLimitBucket(rate *github.Rate) *RateLimitBucket {
+	if rate == nil {
+		return nil
+	}
+	return &RateLimitBucket{
+		Limit:     rate.Limit,
+		Remaining: rate.Remaining,
+		Used:      rate.Used,
+		ResetAt:   rate.Reset.Time,
+	}
+}
+
+// GetRateLimit creates a tool to get the rate limits of the authenticated token.
+func GetRateLimit(t translations.TranslationHelperFunc) inventory.ServerTool {
+	return NewTool(
+		ToolsetMetadataContext,
+		mcp.Tool{
+			Name:        "get_rate_limit",
+			Description: t("TOOL_GET_RATE_LIMIT_DESCRIPTION", "Get the remaining GitHub API rate limits of the current credentials for the core REST API, search, code search and GraphQL, and when each resets. Use this before long series of calls, such as paging through search results, to budget them. Checking does not count against the limits."),
+			Annotations: &mcp.ToolAnnotations{
+				Title:        t("TOOL_GET_RATE_LIMIT_TITLE", "Get API rate limits"),
+				ReadOnlyHint: true,
+			},
+			// Use json.RawMessage to ensure "properties" is included even when empty.
+			// OpenAI strict mode requires the properties field to be present.
+			InputSchema: json.RawMessage(`{"type":"object","properties":{}}`),
+		},
+		nil,
+		func(ctx context.Context, deps ToolDependencies, _ *mcp.CallToolRequest, _ map[string]any) (*mcp.CallToolResult, any, error) {
+			client, err := deps.GetClient(ctx)
+			if err != nil {
+				return utils.NewToolResultErrorFromErr("failed to get GitHub client", err), nil, nil
+			}
+
+			limits, res, err := client.RateLimit.Get(ctx)
+			if err != nil {
+				return ghErrors.NewGitHubAPIErrorResponse(ctx,
+					"failed to get rate limits",
+					res,
+					err,
+				), nil, nil
+			}
+
+			buckets := make(map[string]*RateLimitBucket)
+			for name, rate := range map[string]*github.Rate{
+				"core":        limits.GetCore(),
+				"search":      limits.GetSearch(),
+				"graphql":     limits.GetGraphQL(),
+				"code_search": limits.GetCodeSearch(),
+			} {
+				// GitHub Enterprise Server leaves out the limits it doesn't enforce
+				if bucket := convertToRateLimitBucket(rate); bucket != nil {
+					buckets[name] = bucket
+				}
+			}
+
+			return MarshalledTextResult(buckets), nil, nil
+		},
+	)
+}
@@ -513,3 +513,85 @@ func Test_GetTeamMembers(t *testing.T) {
 		})
 	}
 }
+
+func Test_GetRateLimit(t *testing.T) {
+	t.Parallel()
+
+	serverTool := GetRateLimit(translations.NullTranslationHelper)
+	tool := serverTool.Tool
+	require.NoError(t, toolsnaps.Test(tool.Name, tool))
+
+	assert.Equal(t, "get_rate_limit", tool.Name)
+	assert.True(t, tool.Annotations.ReadOnlyHint, "get_rate_limit tool should be read-only")
+
+	reset := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	rate := func(limit, remaining int) map[string]any {
+		return map[string]any{"limit": limit, "remaining": remaining, "used": limit - remaining, "reset": reset.Unix()}
+	}
+
+	tests := []struct {
+		name               string
+		handler            http.HandlerFunc
+		expectToolError    bool
+		expectedToolErrMsg string
+		expectedBuckets    map[string]RateLimitBucket
+	}{
+		{
+			name: "successful get rate limits",
+			handler: mockResponse(t, http.StatusOK, map[string]any{
+				"resources": map[string]any{
+					"core":                 rate(5000, 4990),
+					"search":               rate(30, 28),
+					"graphql":              rate(5000, 5000),
+					"code_search":          rate(10, 9),
+					"integration_manifest": rate(5000, 5000),
+				},
+			}),
+			expectedBuckets: map[string]RateLimitBucket{
+				"core":        {Limit: 5000, Remaining: 4990, Used: 10, ResetAt: reset},
+				"search":      {Limit: 30, Remaining: 28, Used: 2, ResetAt: reset},
+				"graphql":     {Limit: 5000, Remaining: 5000, Used: 0, ResetAt: reset},
+				"code_search": {Limit: 10, Remaining: 9, Used: 1, ResetAt: reset},
+			},
+		},
+		{
+			name: "limits not enforced by the server are left out",
+			handler: mockResponse(t, http.StatusOK, map[string]any{
+				"resources": map[string]any{"core": rate(5000, 4990)},
+			}),
+			expectedBuckets: map[string]RateLimitBucket{
+				"core": {Limit: 5000, Remaining: 4990, Used: 10, ResetAt: reset},
+			},
+		},
+		{
+			name:               "get rate limits fails",
+			handler:            badRequestHandler("expected test failure"),
+			expectToolError:    true,
+			expectedToolErrMsg: "expected test failure",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			deps := BaseDeps{Client: github.NewClient(MockHTTPClientWithHandlers(map[string]http.HandlerFunc{
+				GetRateLimits: tc.handler,
+			}))}
+			handler := serverTool.Handler(deps)
+
+			request := createMCPRequest(map[string]any{})
+			result, err := handler(ContextWithDeps(context.Background(), deps), &request)
+			require.NoError(t, err)
+
+			if tc.expectToolError {
+				require.True(t, result.IsError, "expected tool call result to be an error")
+				assert.Contains(t, getErrorResult(t, result).Text, tc.expectedToolErrMsg)
+				return
+			}
+
+			require.False(t, result.IsError)
+			var buckets map[string]RateLimitBucket
+			require.NoError(t, json.Unmarshal([]byte(getTextResult(t, result).Text), &buckets))
+			assert.Equal(t, tc.expectedBuckets, buckets)
+		})
+	}
+}
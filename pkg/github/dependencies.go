@@ -266,10 +266,14 @@ type RequestDeps struct {
 	apiHosts          utils.APIHostResolver
 	version           string
 	lockdownMode      bool
-	RepoAccessOpts    []lockdown.RepoAccessOption
+	RepoAccessCache   *lockdown.RepoAccessCache
 	T                 translations.TranslationHelperFunc
 	ContentWindowSize int
 
+	// Transport is the base transport of GitHub API clients, beneath authentication, for
+	// example to record metrics or traces. Defaults to http.DefaultTransport.
+	Transport http.RoundTripper
+
 	// Feature flag checker for runtime checks
 	featureChecker inventory.FeatureFlagChecker
 }
@@ -279,7 +283,7 @@ func NewRequestDeps(
 	apiHosts utils.APIHostResolver,
 	version string,
 	lockdownMode bool,
-	repoAccessOpts []lockdown.RepoAccessOption,
+	repoAccessCache *lockdown.RepoAccessCache,
 	t translations.TranslationHelperFunc,
 	contentWindowSize int,
 	featureChecker inventory.FeatureFlagChecker,
@@ -288,7 +292,7 @@ func NewRequestDeps(
 		apiHosts:          apiHosts,
 		version:           version,
 		lockdownMode:      lockdownMode,
-		RepoAccessOpts:    repoAccessOpts,
+		RepoAccessCache:   repoAccessCache,
 		T:                 t,
 		ContentWindowSize: contentWindowSize,
 		featureChecker:    featureChecker,
@@ -314,7 +318,7 @@ func (d *RequestDeps) GetClient(ctx context.Context) (*gogithub.Client, error) {
 	}
 
 	// Construct REST client
-	restClient := gogithub.NewClient(nil).WithAuthToken(token)
+	restClient := gogithub.NewClient(&http.Client{Transport: d.baseTransport()}).WithAuthToken(token)
 	restClient.UserAgent = fmt.Sprintf("github-mcp-server/%s", d.version)
 	restClient.BaseURL = baseRestURL
 	restClient.UploadURL = uploadURL
@@ -337,7 +341,7 @@ func (d *RequestDeps) GetGQLClient(ctx context.Context) (*githubv4.Client, error
 	gqlHTTPClient := &http.Client{
 		Transport: &transport.BearerAuthTransport{
 			Transport: &transport.GraphQLFeaturesTransport{
-				Transport: http.DefaultTransport,
+				Transport: d.baseTransport(),
 			},
 			Token: token,
 		},
@@ -352,6 +356,13 @@ func (d *RequestDeps) GetGQLClient(ctx context.Context) (*githubv4.Client, error
 	return gqlClient, nil
 }
 
+func (d *RequestDeps) baseTransport() http.RoundTripper {
+	if d.Transport == nil {
+		return http.DefaultTransport
+	}
+	return d.Transport
+}
+
 // GetRawClient implements ToolDependencies.
 func (d *RequestDeps) GetRawClient(ctx context.Context) (*raw.Client, error) {
 	client, err := d.GetClient(ctx)
@@ -380,9 +391,8 @@ func (d *RequestDeps) GetRepoAccessCache(ctx context.Context) (*lockdown.RepoAcc
 		return nil, err
 	}
 
-	// Create repo access cache
-	instance := lockdown.GetInstance(gqlClient, d.RepoAccessOpts...)
-	return instance, nil
+	// The store is shared by requests, but access is checked with each request's token
+	return d.RepoAccessCache.WithClient(gqlClient), nil
 }
 
 // GetT implements ToolDependencies.
@@ -1,42 +1,128 @@
 // deprecated_tool_aliases.go
 package github
 
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/github/github-mcp-server/pkg/inventory"
+	"github.com/github/github-mcp-server/pkg/utils"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// DeprecatedToolAlias is the replacement of a renamed tool.
+type DeprecatedToolAlias struct {
+	// Tool is the canonical name that calls to the alias resolve to.
+	Tool string
+
+	// Sunset is the release in which the alias stops resolving, such as "v1.0.0". It is
+	// left empty until a removal is scheduled.
+	Sunset string
+}
+
 // DeprecatedToolAliases maps old tool names to their new canonical names.
 // When tools are renamed, add an entry here to maintain backward compatibility.
 // Users referencing the old name will receive the new tool with a deprecation warning.
 //
 // Example:
 //
-//	"get_issue": "issue_read",
-//	"create_pr": "pull_request_create",
-var DeprecatedToolAliases = map[string]string{
+//	"get_issue": {Tool: "issue_read", Sunset: "v1.0.0"},
+//	"create_pr": {Tool: "pull_request_create"},
+var DeprecatedToolAliases = map[string]DeprecatedToolAlias{
 	// Add entries as tools are renamed
 	// Actions tools consolidated
-	"list_workflows":                 "actions_list",
-	"list_workflow_runs":             "actions_list",
-	"list_workflow_jobs":             "actions_list",
-	"list_workflow_run_artifacts":    "actions_list",
-	"get_workflow":                   "actions_get",
-	"get_workflow_run":               "actions_get",
-	"get_workflow_job":               "actions_get",
-	"get_workflow_run_usage":         "actions_get",
-	"get_workflow_run_logs":          "actions_get",
-	"get_workflow_job_logs":          "actions_get",
-	"download_workflow_run_artifact": "actions_get",
-	"run_workflow":                   "actions_run_trigger",
-	"rerun_workflow_run":             "actions_run_trigger",
-	"rerun_failed_jobs":              "actions_run_trigger",
-	"cancel_workflow_run":            "actions_run_trigger",
-	"delete_workflow_run_logs":       "actions_run_trigger",
+	"list_workflows":                 {Tool: "actions_list"},
+	"list_workflow_runs":             {Tool: "actions_list"},
+	"list_workflow_jobs":             {Tool: "actions_list"},
+	"list_workflow_run_artifacts":    {Tool: "actions_list"},
+	"get_workflow":                   {Tool: "actions_get"},
+	"get_workflow_run":               {Tool: "actions_get"},
+	"get_workflow_job":               {Tool: "actions_get"},
+	"get_workflow_run_usage":         {Tool: "actions_get"},
+	"get_workflow_run_logs":          {Tool: "actions_get"},
+	"get_workflow_job_logs":          {Tool: "actions_get"},
+	"download_workflow_run_artifact": {Tool: "actions_get"},
+	"run_workflow":                   {Tool: "actions_run_trigger"},
+	"rerun_workflow_run":             {Tool: "actions_run_trigger"},
+	"rerun_failed_jobs":              {Tool: "actions_run_trigger"},
+	"cancel_workflow_run":            {Tool: "actions_run_trigger"},
+	"delete_workflow_run_logs":       {Tool: "actions_run_trigger"},
 
 	// Projects tools consolidated
-	"list_projects":       "projects_list",
-	"list_project_fields": "projects_list",
-	"list_project_items":  "projects_list",
-	"get_project":         "projects_get",
-	"get_project_field":   "projects_get",
-	"get_project_item":    "projects_get",
-	"add_project_item":    "projects_write",
-	"update_project_item": "projects_write",
-	"delete_project_item": "projects_write",
+	"list_projects":       {Tool: "projects_list"},
+	"list_project_fields": {Tool: "projects_list"},
+	"list_project_items":  {Tool: "projects_list"},
+	"get_project":         {Tool: "projects_get"},
+	"get_project_field":   {Tool: "projects_get"},
+	"get_project_item":    {Tool: "projects_get"},
+	"add_project_item":    {Tool: "projects_write"},
+	"update_project_item": {Tool: "projects_write"},
+	"delete_project_item": {Tool: "projects_write"},
+}
+
+// DeprecatedToolAliasTargets returns the canonical tool name of every deprecated alias.
+func DeprecatedToolAliasTargets() map[string]string {
+	targets := make(map[string]string, len(DeprecatedToolAliases))
+	for alias, entry := range DeprecatedToolAliases {
+		targets[alias] = entry.Tool
+	}
+	return targets
+}
+
+// DeprecatedToolAliasNotice returns the message shown to clients that call a tool
+// through a deprecated alias.
+func DeprecatedToolAliasNotice(alias, canonical string) string {
+	if sunset := DeprecatedToolAliases[alias].Sunset; sunset != "" {
+		return fmt.Sprintf("Tool %q is deprecated and will be removed in %s. Use %q instead.", alias, sunset, canonical)
+	}
+	return fmt.Sprintf("Tool %q is deprecated. Use %q instead.", alias, canonical)
+}
+
+// DeprecatedToolAliasMiddleware routes tools/call requests made with a deprecated
+// alias to the canonical tool and appends a deprecation notice to the result.
+// In strict mode the call is rejected instead, pointing the caller at the replacement.
+func DeprecatedToolAliasMiddleware(inv *inventory.Inventory, strict bool, logger *slog.Logger) mcp.Middleware {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			if method != "tools/call" {
+				return next(ctx, method, req)
+			}
+			callReq, ok := req.(*mcp.CallToolRequest)
+			if !ok || callReq.Params == nil {
+				return next(ctx, method, req)
+			}
+
+			alias := callReq.Params.Name
+			canonical, isAlias := inv.ResolveDeprecatedAlias(alias)
+			if !isAlias {
+				return next(ctx, method, req)
+			}
+
+			notice := DeprecatedToolAliasNotice(alias, canonical)
+			logger.Warn("deprecated tool alias called",
+				"alias", alias,
+				"tool", canonical,
+				"sunset", DeprecatedToolAliases[alias].Sunset,
+				"strict", strict,
+			)
+
+			if strict {
+				return utils.NewToolResultError(notice), nil
+			}
+
+			callReq.Params.Name = canonical
+			result, err := next(ctx, method, req)
+			if err != nil {
+				return result, err
+			}
+			if toolResult, ok := result.(*mcp.CallToolResult); ok && toolResult != nil {
+				toolResult.Content = append(toolResult.Content, &mcp.TextContent{Text: notice})
+			}
+			return result, nil
+		}
+	}
 }
@@ -0,0 +1,107 @@
+package github
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/github/github-mcp-server/pkg/inventory"
+	"github.com/github/github-mcp-server/pkg/translations"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestDeprecatedToolAliasEntries checks that every alias resolves to a tool that exists, and
+// that its sunset, when one is scheduled, is a release version.
+func TestDeprecatedToolAliasEntries(t *testing.T) {
+	tools := make(map[string]bool)
+	for _, tool := range AllTools(translations.NullTranslationHelper) {
+		tools[tool.Tool.Name] = true
+	}
+	release := regexp.MustCompile(`^v\d+\.\d+\.\d+$`)
+
+	require.NotEmpty(t, DeprecatedToolAliases)
+	for alias, entry := range DeprecatedToolAliases {
+		assert.False(t, tools[alias], "alias %q is the name of a current tool", alias)
+		assert.True(t, tools[entry.Tool], "alias %q resolves to unknown tool %q", alias, entry.Tool)
+		if entry.Sunset != "" {
+			assert.Regexp(t, release, entry.Sunset, "alias %q has an invalid sunset", alias)
+		}
+	}
+}
+
+func TestDeprecatedToolAliasNotice(t *testing.T) {
+	DeprecatedToolAliases["old_me"] = DeprecatedToolAlias{Tool: "get_me", Sunset: "v9.0.0"}
+	t.Cleanup(func() { delete(DeprecatedToolAliases, "old_me") })
+
+	assert.Equal(t, `Tool "old_me" is deprecated and will be removed in v9.0.0. Use "get_me" instead.`, DeprecatedToolAliasNotice("old_me", "get_me"))
+	assert.Equal(t, `Tool "list_workflows" is deprecated. Use "actions_list" instead.`, DeprecatedToolAliasNotice("list_workflows", "actions_list"))
+	assert.Equal(t, `Tool "unknown_alias" is deprecated. Use "get_me" instead.`, DeprecatedToolAliasNotice("unknown_alias", "get_me"))
+}
+
+func TestDeprecatedToolAliasMiddleware(t *testing.T) {
+	inv, err := inventory.NewBuilder().
+		SetTools([]inventory.ServerTool{GetMe(translations.NullTranslationHelper)}).
+		WithDeprecatedAliases(map[string]string{"old_me": "get_me"}).
+		WithToolsets([]string{"all"}).
+		Build()
+	require.NoError(t, err)
+
+	tests := []struct {
+		name           string
+		toolName       string
+		strict         bool
+		expectedCalled string
+		expectError    bool
+		expectNotice   bool
+	}{
+		{
+			name:           "canonical name passes through",
+			toolName:       "get_me",
+			expectedCalled: "get_me",
+		},
+		{
+			name:           "alias is routed with notice",
+			toolName:       "old_me",
+			expectedCalled: "get_me",
+			expectNotice:   true,
+		},
+		{
+			name:         "alias is rejected in strict mode",
+			toolName:     "old_me",
+			strict:       true,
+			expectError:  true,
+			expectNotice: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var called string
+			next := func(_ context.Context, _ string, req mcp.Request) (mcp.Result, error) {
+				called = req.(*mcp.CallToolRequest).Params.Name
+				return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "ok"}}}, nil
+			}
+
+			handler := DeprecatedToolAliasMiddleware(inv, tc.strict, nil)(next)
+			result, err := handler(context.Background(), "tools/call", &mcp.CallToolRequest{
+				Params: &mcp.CallToolParamsRaw{Name: tc.toolName},
+			})
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.expectedCalled, called)
+
+			toolResult, ok := result.(*mcp.CallToolResult)
+			require.True(t, ok)
+			assert.Equal(t, tc.expectError, toolResult.IsError)
+
+			last := toolResult.Content[len(toolResult.Content)-1].(*mcp.TextContent).Text
+			if tc.expectNotice {
+				assert.Equal(t, DeprecatedToolAliasNotice("old_me", "get_me"), last)
+			} else {
+				assert.Equal(t, "ok", last)
+			}
+		})
+	}
+}
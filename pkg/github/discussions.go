@@ -57,7 +57,8 @@ type NodeFragment struct {
 	Author         struct {
 		Login githubv4.String
 	}
-	Category struct {
+	AuthorAssociation githubv4.CommentAuthorAssociation
+	Category          struct {
 		Name githubv4.String
 	} `graphql:"category"`
 	URL githubv4.String `graphql:"url"`
@@ -104,6 +105,7 @@ func fragmentToDiscussion(fragment NodeFragment) *github.Discussion {
 		User: &github.User{
 			Login: github.Ptr(string(fragment.Author.Login)),
 		},
+		AuthorAssociation: github.Ptr(string(fragment.AuthorAssociation)),
 		DiscussionCategory: &github.DiscussionCategory{
 			Name: github.Ptr(string(fragment.Category.Name)),
 		},
@@ -255,6 +257,15 @@ func ListDiscussions(t translations.TranslationHelperFunc) inventory.ServerTool
 				totalCount = fragment.TotalCount
 			}
 
+			discussions, err = filterUserContent(ctx, deps, discussions, func(d *github.Discussion) contentAuthor {
+				return contentAuthor{Kind: "discussion", Login: d.GetUser().GetLogin(), Association: d.GetAuthorAssociation(), Owner: owner, Repo: repo}
+			}, func(ctx context.Context, d *github.Discussion) {
+				sanitizeText(ctx, d.Title)
+			})
+			if err != nil {
+				return utils.NewToolResultError(err.Error()), nil, nil
+			}
+
 			// Create response with pagination info
 			response := map[string]any{
 				"discussions": discussions,
@@ -331,8 +342,12 @@ func GetDiscussion(t translations.TranslationHelperFunc) inventory.ServerTool {
 						Closed         githubv4.Boolean
 						IsAnswered     githubv4.Boolean
 						AnswerChosenAt *githubv4.DateTime
-						URL            githubv4.String `graphql:"url"`
-						Category       struct {
+						Author         struct {
+							Login githubv4.String
+						}
+						AuthorAssociation githubv4.CommentAuthorAssociation
+						URL               githubv4.String `graphql:"url"`
+						Category          struct {
 							Name githubv4.String
 						} `graphql:"category"`
 					} `graphql:"discussion(number: $discussionNumber)"`
@@ -348,15 +363,28 @@ func GetDiscussion(t translations.TranslationHelperFunc) inventory.ServerTool {
 			}
 			d := q.Repository.Discussion
 
+			safe, err := checkUserContent(ctx, deps, contentAuthor{Kind: "discussion", Login: string(d.Author.Login), Association: string(d.AuthorAssociation), Owner: params.Owner, Repo: params.Repo})
+			if err != nil {
+				return utils.NewToolResultError(err.Error()), nil, nil
+			}
+			if !safe {
+				return utils.NewToolResultError("access to discussion is restricted by lockdown mode"), nil, nil
+			}
+			title, body := string(d.Title), string(d.Body)
+			sanitizeText(ctx, &title, &body)
+
 			// Build response as map to include fields not present in go-github's Discussion struct.
 			// The go-github library's Discussion type lacks isAnswered and answerChosenAt fields,
 			// so we use map[string]interface{} for the response (consistent with other functions
 			// like ListDiscussions and GetDiscussionComments).
 			response := map[string]any{
-				"number":     int(d.Number),
-				"title":      string(d.Title),
-				"body":       string(d.Body),
-				"url":        string(d.URL),
+				"number": int(d.Number),
+				"title":  title,
+				"body":   body,
+				"url":    string(d.URL),
+				"user": map[string]any{
+					"login": string(d.Author.Login),
+				},
 				"closed":     bool(d.Closed),
 				"isAnswered": bool(d.IsAnswered),
 				"createdAt":  d.CreatedAt.Time,
@@ -452,7 +480,11 @@ func GetDiscussionComments(t translations.TranslationHelperFunc) inventory.Serve
 					Discussion struct {
 						Comments struct {
 							Nodes []struct {
-								Body githubv4.String
+								Body   githubv4.String
+								Author struct {
+									Login githubv4.String
+								}
+								AuthorAssociation githubv4.CommentAuthorAssociation
 							}
 							PageInfo struct {
 								HasNextPage     githubv4.Boolean
@@ -482,7 +514,20 @@ func GetDiscussionComments(t translations.TranslationHelperFunc) inventory.Serve
 
 			var comments []*github.IssueComment
 			for _, c := range q.Repository.Discussion.Comments.Nodes {
-				comments = append(comments, &github.IssueComment{Body: github.Ptr(string(c.Body))})
+				comments = append(comments, &github.IssueComment{
+					Body:              github.Ptr(string(c.Body)),
+					User:              &github.User{Login: github.Ptr(string(c.Author.Login))},
+					AuthorAssociation: github.Ptr(string(c.AuthorAssociation)),
+				})
+			}
+
+			comments, err = filterUserContent(ctx, deps, comments, func(c *github.IssueComment) contentAuthor {
+				return contentAuthor{Kind: "comment", Login: c.GetUser().GetLogin(), Association: c.GetAuthorAssociation(), Owner: params.Owner, Repo: params.Repo}
+			}, func(ctx context.Context, c *github.IssueComment) {
+				sanitizeText(ctx, c.Body)
+			})
+			if err != nil {
+				return utils.NewToolResultError(err.Error()), nil, nil
 			}
 
 			// Create response with pagination info
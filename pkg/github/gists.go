@@ -94,6 +94,11 @@ func ListGists(t translations.TranslationHelperFunc) inventory.ServerTool {
 				return ghErrors.NewGitHubAPIStatusErrorResponse(ctx, "failed to list gists", resp, body), nil, nil
 			}
 
+			gists, err = filterUserContent(ctx, deps, gists, gistAuthor, sanitizeGist)
+			if err != nil {
+				return utils.NewToolResultError(err.Error()), nil, nil
+			}
+
 			r, err := json.Marshal(gists)
 			if err != nil {
 				return utils.NewToolResultErrorFromErr("failed to marshal response", err), nil, nil
@@ -104,6 +109,17 @@ func ListGists(t translations.TranslationHelperFunc) inventory.ServerTool {
 	)
 }
 
+// gistAuthor returns the owner of a gist. Gists belong to no repository, so lockdown mode
+// only trusts those of the viewer and trusted bots.
+func gistAuthor(gist *github.Gist) contentAuthor {
+	return contentAuthor{Kind: "gist", Login: gist.GetOwner().GetLogin()}
+}
+
+// sanitizeGist sanitizes the description of a gist, leaving the content of its files as is.
+func sanitizeGist(ctx context.Context, gist *github.Gist) {
+	sanitizeText(ctx, gist.Description)
+}
+
 // GetGist creates a tool to get the content of a gist
 func GetGist(t translations.TranslationHelperFunc) inventory.ServerTool {
 	return NewTool(
@@ -152,6 +168,15 @@ func GetGist(t translations.TranslationHelperFunc) inventory.ServerTool {
 				return ghErrors.NewGitHubAPIStatusErrorResponse(ctx, "failed to get gist", resp, body), nil, nil
 			}
 
+			safe, err := checkUserContent(ctx, deps, gistAuthor(gist))
+			if err != nil {
+				return utils.NewToolResultError(err.Error()), nil, nil
+			}
+			if !safe {
+				return utils.NewToolResultError("access to gist is restricted by lockdown mode"), nil, nil
+			}
+			sanitizeGist(ctx, gist)
+
 			r, err := json.Marshal(gist)
 			if err != nil {
 				return utils.NewToolResultErrorFromErr("failed to marshal response", err), nil, nil
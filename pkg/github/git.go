@@ -105,6 +105,10 @@ func GetRepositoryTree(t translations.TranslationHelperFunc) inventory.ServerToo
 				return utils.NewToolResultError("failed to get GitHub client"), nil, nil
 			}
 
+			if result, err := restrictRefContent(ctx, client, deps, owner, repo, treeSHA); result != nil || err != nil {
+				return result, nil, err
+			}
+
 			// If no tree_sha is provided, use the repository's default branch
 			if treeSHA == "" {
 				repoInfo, repoResp, err := client.Repositories.Get(ctx, owner, repo)
@@ -33,6 +33,7 @@ const (
 	GetReposTagsByOwnerByRepo            = "GET /repos/{owner}/{repo}/tags"
 	GetReposCommitsByOwnerByRepo         = "GET /repos/{owner}/{repo}/commits"
 	GetReposCommitsByOwnerByRepoByRef    = "GET /repos/{owner}/{repo}/commits/{ref}"
+	GetReposCompareByOwnerByRepoByBase   = "GET /repos/{owner}/{repo}/compare/{basehead}"
 	GetReposContentsByOwnerByRepoByPath  = "GET /repos/{owner}/{repo}/contents/{path}"
 	PutReposContentsByOwnerByRepoByPath  = "PUT /repos/{owner}/{repo}/contents/{path}"
 	PostReposForksByOwnerByRepo          = "POST /repos/{owner}/{repo}/forks"
@@ -133,6 +134,7 @@ const (
 	PostReposActionsRunsRerunByOwnerByRepoByRunID                = "POST /repos/{owner}/{repo}/actions/runs/{run_id}/rerun"
 	PostReposActionsRunsRerunFailedJobsByOwnerByRepoByRunID      = "POST /repos/{owner}/{repo}/actions/runs/{run_id}/rerun-failed-jobs"
 	PostReposActionsRunsCancelByOwnerByRepoByRunID               = "POST /repos/{owner}/{repo}/actions/runs/{run_id}/cancel"
+	GetReposActionsJobsByOwnerByRepoByJobID                      = "GET /repos/{owner}/{repo}/actions/jobs/{job_id}"
 	GetReposActionsJobsLogsByOwnerByRepoByJobID                  = "GET /repos/{owner}/{repo}/actions/jobs/{job_id}/logs"
 	DeleteReposActionsRunsLogsByOwnerByRepoByRunID               = "DELETE /repos/{owner}/{repo}/actions/runs/{run_id}/logs"
 
@@ -173,6 +175,9 @@ const (
 
 	// Organization issue types endpoints
 	GetOrgsIssueTypesByOrg = "GET /orgs/{org}/issue-types"
+
+	// Rate limit endpoints
+	GetRateLimits = "GET /rate_limit"
 )
 
 type expectations struct {
@@ -0,0 +1,326 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/github/github-mcp-server/pkg/inventory"
+	"github.com/github/github-mcp-server/pkg/octicons"
+	"github.com/github/github-mcp-server/pkg/translations"
+	"github.com/google/go-github/v82/github"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/yosida95/uritemplate/v3"
+)
+
+var (
+	issueResourceURITemplate           = uritemplate.MustNew("issue://{owner}/{repo}/{number}")
+	pullRequestResourceURITemplate     = uritemplate.MustNew("pr://{owner}/{repo}/{number}")
+	pullRequestDiffResourceURITemplate = uritemplate.MustNew("pr://{owner}/{repo}/{number}/diff")
+)
+
+// maxResourceCommentPages bounds how many pages of comments are rendered into a resource.
+const maxResourceCommentPages = 10
+
+var (
+	errIssueRestrictedByLockdownMode       = errors.New("access to issue details is restricted by lockdown mode")
+	errPullRequestRestrictedByLockdownMode = errors.New("access to pull request is restricted by lockdown mode")
+)
+
+// GetIssueResource defines the resource template for reading an issue and its comments as Markdown.
+func GetIssueResource(t translations.TranslationHelperFunc) inventory.ServerResourceTemplate {
+	return inventory.NewServerResourceTemplate(
+		ToolsetMetadataIssues,
+		mcp.ResourceTemplate{
+			Name:        "issue",
+			URITemplate: issueResourceURITemplate.Raw(),
+			Description: t("RESOURCE_ISSUE_DESCRIPTION", "Issue with its comments, rendered as Markdown"),
+			MIMEType:    "text/markdown",
+			Icons:       octicons.Icons("issue-opened"),
+		},
+		func(_ any) mcp.ResourceHandler {
+			return IssueResourceHandler(issueResourceURITemplate)
+		},
+	)
+}
+
+// GetPullRequestResource defines the resource template for reading a pull request and its comments as Markdown.
+func GetPullRequestResource(t translations.TranslationHelperFunc) inventory.ServerResourceTemplate {
+	return inventory.NewServerResourceTemplate(
+		ToolsetMetadataPullRequests,
+		mcp.ResourceTemplate{
+			Name:        "pull_request",
+			URITemplate: pullRequestResourceURITemplate.Raw(),
+			Description: t("RESOURCE_PULL_REQUEST_DESCRIPTION", "Pull request with its comments, rendered as Markdown"),
+			MIMEType:    "text/markdown",
+			Icons:       octicons.Icons("git-pull-request"),
+		},
+		func(_ any) mcp.ResourceHandler {
+			return PullRequestResourceHandler(pullRequestResourceURITemplate)
+		},
+	)
+}
+
+// GetPullRequestDiffResource defines the resource template for reading the diff of a pull request.
+func GetPullRequestDiffResource(t translations.TranslationHelperFunc) inventory.ServerResourceTemplate {
+	return inventory.NewServerResourceTemplate(
+		ToolsetMetadataPullRequests,
+		mcp.ResourceTemplate{
+			Name:        "pull_request_diff",
+			URITemplate: pullRequestDiffResourceURITemplate.Raw(),
+			Description: t("RESOURCE_PULL_REQUEST_DIFF_DESCRIPTION", "Unified diff of a pull request"),
+			MIMEType:    "text/x-diff",
+			Icons:       octicons.Icons("git-pull-request"),
+		},
+		func(_ any) mcp.ResourceHandler {
+			return PullRequestDiffResourceHandler(pullRequestDiffResourceURITemplate)
+		},
+	)
+}
+
+// IssueResourceHandler returns a handler that renders an issue and its comments as Markdown.
+// Lockdown mode and sanitization are applied the same way as in the issue_read tool.
+func IssueResourceHandler(resourceURITemplate *uritemplate.Template) mcp.ResourceHandler {
+	return func(ctx context.Context, request *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+		deps := MustDepsFromContext(ctx)
+		owner, repo, number, err := matchNumberedResourceURI(resourceURITemplate, request.Params.URI)
+		if err != nil {
+			return nil, err
+		}
+
+		client, err := deps.GetClient(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get GitHub client: %w", err)
+		}
+
+		issue, resp, err := client.Issues.Get(ctx, owner, repo, number)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get issue: %w", err)
+		}
+		_ = resp.Body.Close()
+
+		safe, err := checkUserContent(ctx, deps, issueAuthor(owner, repo)(issue))
+		if err != nil {
+			return nil, err
+		}
+		if !safe {
+			return nil, errIssueRestrictedByLockdownMode
+		}
+
+		comments, err := listResourceComments(ctx, client, deps, owner, repo, number)
+		if err != nil {
+			return nil, err
+		}
+
+		var b strings.Builder
+		writeResourceHeader(ctx, &b, issue.GetTitle(), number, issue.GetState(), issue.GetUser().GetLogin(), issue.GetCreatedAt().Time)
+		if labels := issueLabelNames(issue.Labels); len(labels) > 0 {
+			fmt.Fprintf(&b, "**Labels:** %s\n", strings.Join(labels, ", "))
+		}
+		writeResourceBody(ctx, &b, issue.GetBody(), comments)
+
+		return markdownResourceResult(request.Params.URI, b.String()), nil
+	}
+}
+
+// PullRequestResourceHandler returns a handler that renders a pull request and its comments as Markdown.
+// Lockdown mode and sanitization are applied the same way as in the pull_request_read tool.
+func PullRequestResourceHandler(resourceURITemplate *uritemplate.Template) mcp.ResourceHandler {
+	return func(ctx context.Context, request *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+		deps := MustDepsFromContext(ctx)
+		owner, repo, number, err := matchNumberedResourceURI(resourceURITemplate, request.Params.URI)
+		if err != nil {
+			return nil, err
+		}
+
+		client, err := deps.GetClient(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get GitHub client: %w", err)
+		}
+
+		pr, resp, err := client.PullRequests.Get(ctx, owner, repo, number)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get pull request: %w", err)
+		}
+		_ = resp.Body.Close()
+
+		safe, err := checkUserContent(ctx, deps, pullRequestAuthor(owner, repo)(pr))
+		if err != nil {
+			return nil, err
+		}
+		if !safe {
+			return nil, errPullRequestRestrictedByLockdownMode
+		}
+
+		// Conversation comments on a pull request are issue comments
+		comments, err := listResourceComments(ctx, client, deps, owner, repo, number)
+		if err != nil {
+			return nil, err
+		}
+
+		state := pr.GetState()
+		if pr.GetMerged() {
+			state = "merged"
+		} else if pr.GetDraft() {
+			state = "draft"
+		}
+
+		var b strings.Builder
+		writeResourceHeader(ctx, &b, pr.GetTitle(), number, state, pr.GetUser().GetLogin(), pr.GetCreatedAt().Time)
+		fmt.Fprintf(&b, "**Branches:** %s ← %s\n", pr.GetBase().GetRef(), pr.GetHead().GetRef())
+		fmt.Fprintf(&b, "**Changes:** %d files, +%d −%d\n", pr.GetChangedFiles(), pr.GetAdditions(), pr.GetDeletions())
+		if labels := issueLabelNames(pr.Labels); len(labels) > 0 {
+			fmt.Fprintf(&b, "**Labels:** %s\n", strings.Join(labels, ", "))
+		}
+		writeResourceBody(ctx, &b, pr.GetBody(), comments)
+
+		return markdownResourceResult(request.Params.URI, b.String()), nil
+	}
+}
+
+// PullRequestDiffResourceHandler returns a handler that serves the unified diff of a pull request.
+// In lockdown mode the diff is only served if the pull request author is trusted.
+func PullRequestDiffResourceHandler(resourceURITemplate *uritemplate.Template) mcp.ResourceHandler {
+	return func(ctx context.Context, request *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+		deps := MustDepsFromContext(ctx)
+		owner, repo, number, err := matchNumberedResourceURI(resourceURITemplate, request.Params.URI)
+		if err != nil {
+			return nil, err
+		}
+
+		client, err := deps.GetClient(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get GitHub client: %w", err)
+		}
+
+		if deps.GetFlags(ctx).LockdownMode {
+			pr, resp, err := client.PullRequests.Get(ctx, owner, repo, number)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get pull request: %w", err)
+			}
+			_ = resp.Body.Close()
+
+			safe, err := checkUserContent(ctx, deps, pullRequestAuthor(owner, repo)(pr))
+			if err != nil {
+				return nil, err
+			}
+			if !safe {
+				return nil, errPullRequestRestrictedByLockdownMode
+			}
+		}
+
+		diff, resp, err := client.PullRequests.GetRaw(ctx, owner, repo, number, github.RawOptions{Type: github.Diff})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get pull request diff: %w", err)
+		}
+		_ = resp.Body.Close()
+
+		return &mcp.ReadResourceResult{
+			Contents: []*mcp.ResourceContents{
+				{
+					URI:      request.Params.URI,
+					MIMEType: "text/x-diff",
+					Text:     diff,
+				},
+			},
+		}, nil
+	}
+}
+
+// matchNumberedResourceURI extracts the owner, repo and number from an issue:// or pr:// URI.
+func matchNumberedResourceURI(resourceURITemplate *uritemplate.Template, uri string) (string, string, int, error) {
+	uriValues := resourceURITemplate.Match(uri)
+	if uriValues == nil {
+		return "", "", 0, fmt.Errorf("failed to match URI: %s", uri)
+	}
+
+	owner := uriValues.Get("owner").String()
+	if owner == "" {
+		return "", "", 0, errors.New("owner is required")
+	}
+	repo := uriValues.Get("repo").String()
+	if repo == "" {
+		return "", "", 0, errors.New("repo is required")
+	}
+	number, err := strconv.Atoi(uriValues.Get("number").String())
+	if err != nil || number <= 0 {
+		return "", "", 0, fmt.Errorf("invalid number: %q", uriValues.Get("number").String())
+	}
+	return owner, repo, number, nil
+}
+
+// listResourceComments fetches the comments on an issue or pull request, dropping comments
+// from untrusted authors in lockdown mode.
+func listResourceComments(ctx context.Context, client *github.Client, deps ToolDependencies, owner, repo string, number int) ([]*github.IssueComment, error) {
+	opts := &github.IssueListCommentsOptions{ListOptions: github.ListOptions{PerPage: 100}}
+	var comments []*github.IssueComment
+	for range maxResourceCommentPages {
+		page, resp, err := client.Issues.ListComments(ctx, owner, repo, number, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get comments: %w", err)
+		}
+		_ = resp.Body.Close()
+
+		page, err = filterUserContent(ctx, deps, page, issueCommentAuthor(owner, repo), nil)
+		if err != nil {
+			return nil, err
+		}
+		comments = append(comments, page...)
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	return comments, nil
+}
+
+func writeResourceHeader(ctx context.Context, b *strings.Builder, title string, number int, state, author string, created time.Time) {
+	sanitizeText(ctx, &title)
+	fmt.Fprintf(b, "# %s (#%d)\n\n", title, number)
+	fmt.Fprintf(b, "**State:** %s\n", state)
+	fmt.Fprintf(b, "**Author:** @%s\n", author)
+	if !created.IsZero() {
+		fmt.Fprintf(b, "**Created:** %s\n", created.UTC().Format(time.RFC3339))
+	}
+}
+
+func writeResourceBody(ctx context.Context, b *strings.Builder, body string, comments []*github.IssueComment) {
+	sanitizeText(ctx, &body)
+	if body = strings.TrimSpace(body); body != "" {
+		fmt.Fprintf(b, "\n%s\n", body)
+	}
+	if len(comments) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "\n## Comments\n")
+	for _, comment := range comments {
+		commentBody := comment.GetBody()
+		sanitizeText(ctx, &commentBody)
+		fmt.Fprintf(b, "\n### @%s commented on %s\n\n", comment.GetUser().GetLogin(), comment.GetCreatedAt().UTC().Format(time.RFC3339))
+		fmt.Fprintf(b, "%s\n", strings.TrimSpace(commentBody))
+	}
+}
+
+func issueLabelNames(labels []*github.Label) []string {
+	names := make([]string, 0, len(labels))
+	for _, label := range labels {
+		names = append(names, label.GetName())
+	}
+	return names
+}
+
+func markdownResourceResult(uri, text string) *mcp.ReadResourceResult {
+	return &mcp.ReadResourceResult{
+		Contents: []*mcp.ResourceContents{
+			{
+				URI:      uri,
+				MIMEType: "text/markdown",
+				Text:     text,
+			},
+		},
+	}
+}
@@ -14,7 +14,6 @@ import (
 	ghErrors "github.com/github/github-mcp-server/pkg/errors"
 	"github.com/github/github-mcp-server/pkg/inventory"
 	"github.com/github/github-mcp-server/pkg/octicons"
-	"github.com/github/github-mcp-server/pkg/sanitize"
 	"github.com/github/github-mcp-server/pkg/scopes"
 	"github.com/github/github-mcp-server/pkg/translations"
 	"github.com/github/github-mcp-server/pkg/utils"
@@ -117,9 +116,10 @@ type IssueFragment struct {
 	Author struct {
 		Login githubv4.String
 	}
-	CreatedAt githubv4.DateTime
-	UpdatedAt githubv4.DateTime
-	Labels    struct {
+	AuthorAssociation githubv4.CommentAuthorAssociation
+	CreatedAt         githubv4.DateTime
+	UpdatedAt         githubv4.DateTime
+	Labels            struct {
 		Nodes []struct {
 			Name        githubv4.String
 			ID          githubv4.String
@@ -218,17 +218,18 @@ func fragmentToIssue(fragment IssueFragment) *github.Issue {
 
 	return &github.Issue{
 		Number:    github.Ptr(int(fragment.Number)),
-		Title:     github.Ptr(sanitize.Sanitize(string(fragment.Title))),
+		Title:     github.Ptr(string(fragment.Title)),
 		CreatedAt: &github.Timestamp{Time: fragment.CreatedAt.Time},
 		UpdatedAt: &github.Timestamp{Time: fragment.UpdatedAt.Time},
 		User: &github.User{
 			Login: github.Ptr(string(fragment.Author.Login)),
 		},
-		State:    github.Ptr(string(fragment.State)),
-		ID:       github.Ptr(fragment.DatabaseID),
-		Body:     github.Ptr(sanitize.Sanitize(string(fragment.Body))),
-		Labels:   foundLabels,
-		Comments: github.Ptr(int(fragment.Comments.TotalCount)),
+		AuthorAssociation: github.Ptr(string(fragment.AuthorAssociation)),
+		State:             github.Ptr(string(fragment.State)),
+		ID:                github.Ptr(fragment.DatabaseID),
+		Body:              github.Ptr(string(fragment.Body)),
+		Labels:            foundLabels,
+		Comments:          github.Ptr(int(fragment.Comments.TotalCount)),
 	}
 }
 
@@ -330,13 +331,31 @@ Options are:
 		})
 }
 
-func GetIssue(ctx context.Context, client *github.Client, deps ToolDependencies, owner string, repo string, issueNumber int) (*mcp.CallToolResult, error) {
-	cache, err := deps.GetRepoAccessCache(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get repo access cache: %w", err)
+// issueAuthor returns the author of issues of a repository.
+func issueAuthor(owner, repo string) func(*github.Issue) contentAuthor {
+	return func(issue *github.Issue) contentAuthor {
+		return contentAuthor{Kind: "issue", Login: issue.GetUser().GetLogin(), Association: issue.GetAuthorAssociation(), Owner: owner, Repo: repo}
+	}
+}
+
+// sanitizeIssue sanitizes the title and body of an issue or pull request.
+func sanitizeIssue(ctx context.Context, issue *github.Issue) {
+	sanitizeText(ctx, issue.Title, issue.Body)
+}
+
+// issueCommentAuthor returns the author of issue comments of a repository.
+func issueCommentAuthor(owner, repo string) func(*github.IssueComment) contentAuthor {
+	return func(comment *github.IssueComment) contentAuthor {
+		return contentAuthor{Kind: "comment", Login: comment.GetUser().GetLogin(), Association: comment.GetAuthorAssociation(), Owner: owner, Repo: repo}
 	}
-	flags := deps.GetFlags(ctx)
+}
+
+// sanitizeIssueComment sanitizes the body of an issue comment.
+func sanitizeIssueComment(ctx context.Context, comment *github.IssueComment) {
+	sanitizeText(ctx, comment.Body)
+}
 
+func GetIssue(ctx context.Context, client *github.Client, deps ToolDependencies, owner string, repo string, issueNumber int) (*mcp.CallToolResult, error) {
 	issue, resp, err := client.Issues.Get(ctx, owner, repo, issueNumber)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get issue: %w", err)
@@ -351,31 +370,14 @@ func GetIssue(ctx context.Context, client *github.Client, deps ToolDependencies,
 		return ghErrors.NewGitHubAPIStatusErrorResponse(ctx, "failed to get issue", resp, body), nil
 	}
 
-	if flags.LockdownMode {
-		if cache == nil {
-			return nil, fmt.Errorf("lockdown cache is not configured")
-		}
-		login := issue.GetUser().GetLogin()
-		if login != "" {
-			isSafeContent, err := cache.IsSafeContent(ctx, login, owner, repo)
-			if err != nil {
-				return utils.NewToolResultError(fmt.Sprintf("failed to check lockdown mode: %v", err)), nil
-			}
-			if !isSafeContent {
-				return utils.NewToolResultError("access to issue details is restricted by lockdown mode"), nil
-			}
-		}
+	safe, err := checkUserContent(ctx, deps, issueAuthor(owner, repo)(issue))
+	if err != nil {
+		return utils.NewToolResultError(err.Error()), nil
 	}
-
-	// Sanitize title/body on response
-	if issue != nil {
-		if issue.Title != nil {
-			issue.Title = github.Ptr(sanitize.Sanitize(*issue.Title))
-		}
-		if issue.Body != nil {
-			issue.Body = github.Ptr(sanitize.Sanitize(*issue.Body))
-		}
+	if !safe {
+		return utils.NewToolResultError("access to issue details is restricted by lockdown mode"), nil
 	}
+	sanitizeIssue(ctx, issue)
 
 	minimalIssue := convertToMinimalIssue(issue)
 
@@ -383,12 +385,6 @@ func GetIssue(ctx context.Context, client *github.Client, deps ToolDependencies,
 }
 
 func GetIssueComments(ctx context.Context, client *github.Client, deps ToolDependencies, owner string, repo string, issueNumber int, pagination PaginationParams) (*mcp.CallToolResult, error) {
-	cache, err := deps.GetRepoAccessCache(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get repo access cache: %w", err)
-	}
-	flags := deps.GetFlags(ctx)
-
 	opts := &github.IssueListCommentsOptions{
 		ListOptions: github.ListOptions{
 			Page:    pagination.Page,
@@ -409,29 +405,10 @@ func GetIssueComments(ctx context.Context, client *github.Client, deps ToolDepen
 		}
 		return ghErrors.NewGitHubAPIStatusErrorResponse(ctx, "failed to get issue comments", resp, body), nil
 	}
-	if flags.LockdownMode {
-		if cache == nil {
-			return nil, fmt.Errorf("lockdown cache is not configured")
-		}
-		filteredComments := make([]*github.IssueComment, 0, len(comments))
-		for _, comment := range comments {
-			user := comment.User
-			if user == nil {
-				continue
-			}
-			login := user.GetLogin()
-			if login == "" {
-				continue
-			}
-			isSafeContent, err := cache.IsSafeContent(ctx, login, owner, repo)
-			if err != nil {
-				return utils.NewToolResultError(fmt.Sprintf("failed to check lockdown mode: %v", err)), nil
-			}
-			if isSafeContent {
-				filteredComments = append(filteredComments, comment)
-			}
-		}
-		comments = filteredComments
+
+	comments, err = filterUserContent(ctx, deps, comments, issueCommentAuthor(owner, repo), sanitizeIssueComment)
+	if err != nil {
+		return utils.NewToolResultError(err.Error()), nil
 	}
 
 	r, err := json.Marshal(comments)
@@ -443,12 +420,6 @@ func GetIssueComments(ctx context.Context, client *github.Client, deps ToolDepen
 }
 
 func GetSubIssues(ctx context.Context, client *github.Client, deps ToolDependencies, owner string, repo string, issueNumber int, pagination PaginationParams) (*mcp.CallToolResult, error) {
-	cache, err := deps.GetRepoAccessCache(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get repo access cache: %w", err)
-	}
-	featureFlags := deps.GetFlags(ctx)
-
 	opts := &github.IssueListOptions{
 		ListOptions: github.ListOptions{
 			Page:    pagination.Page,
@@ -475,29 +446,13 @@ func GetSubIssues(ctx context.Context, client *github.Client, deps ToolDependenc
 		return ghErrors.NewGitHubAPIStatusErrorResponse(ctx, "failed to list sub-issues", resp, body), nil
 	}
 
-	if featureFlags.LockdownMode {
-		if cache == nil {
-			return nil, fmt.Errorf("lockdown cache is not configured")
-		}
-		filteredSubIssues := make([]*github.SubIssue, 0, len(subIssues))
-		for _, subIssue := range subIssues {
-			user := subIssue.User
-			if user == nil {
-				continue
-			}
-			login := user.GetLogin()
-			if login == "" {
-				continue
-			}
-			isSafeContent, err := cache.IsSafeContent(ctx, login, owner, repo)
-			if err != nil {
-				return utils.NewToolResultError(fmt.Sprintf("failed to check lockdown mode: %v", err)), nil
-			}
-			if isSafeContent {
-				filteredSubIssues = append(filteredSubIssues, subIssue)
-			}
-		}
-		subIssues = filteredSubIssues
+	subIssues, err = filterUserContent(ctx, deps, subIssues, func(subIssue *github.SubIssue) contentAuthor {
+		return issueAuthor(owner, repo)((*github.Issue)(subIssue))
+	}, func(ctx context.Context, subIssue *github.SubIssue) {
+		sanitizeIssue(ctx, (*github.Issue)(subIssue))
+	})
+	if err != nil {
+		return utils.NewToolResultError(err.Error()), nil
 	}
 
 	r, err := json.Marshal(subIssues)
@@ -1116,7 +1071,7 @@ func SearchIssues(t translations.TranslationHelperFunc) inventory.ServerTool {
 		},
 		[]scopes.Scope{scopes.Repo},
 		func(ctx context.Context, deps ToolDependencies, _ *mcp.CallToolRequest, args map[string]any) (*mcp.CallToolResult, any, error) {
-			result, err := searchHandler(ctx, deps.GetClient, args, "issue", "failed to search issues")
+			result, err := searchHandler(ctx, deps, args, "issue", "failed to search issues")
 			return result, nil, err
 		})
 }
@@ -1737,6 +1692,11 @@ func ListIssues(t translations.TranslationHelperFunc) inventory.ServerTool {
 				totalCount = fragment.TotalCount
 			}
 
+			issues, err = filterUserContent(ctx, deps, issues, issueAuthor(owner, repo), sanitizeIssue)
+			if err != nil {
+				return utils.NewToolResultError(err.Error()), nil, nil
+			}
+
 			// Create response with issues
 			response := map[string]any{
 				"issues": issues,
@@ -66,33 +66,46 @@ func (rt *repoAccessMockTransport) RoundTrip(req *http.Request) (*http.Response,
 
 	owner := toString(payload.Variables["owner"])
 	repo := toString(payload.Variables["name"])
-	username := toString(payload.Variables["username"])
 
-	value, ok := rt.responses[repoAccessKey{owner: owner, repo: repo, username: username}]
-	if !ok {
-		value = repoAccessValue{isPrivate: false, permission: "WRITE"}
+	// Single user lookups search collaborators for $username, batched lookups alias one
+	// search per user as u0, u1, ...
+	fields := map[string]string{"collaborators": "username"}
+	if _, ok := payload.Variables["username"]; !ok {
+		fields = map[string]string{}
+		for name := range payload.Variables {
+			if strings.HasPrefix(name, "u") && name != "username" {
+				fields[name] = name
+			}
+		}
 	}
 
-	edges := []any{}
-	if value.permission != "" {
-		edges = append(edges, map[string]any{
-			"permission": value.permission,
-			"node": map[string]any{
-				"login": username,
-			},
-		})
-	}
+	repository := map[string]any{}
+	for field, variable := range fields {
+		username := toString(payload.Variables[variable])
+		value, ok := rt.responses[repoAccessKey{owner: owner, repo: repo, username: username}]
+		if !ok {
+			value = repoAccessValue{isPrivate: false, permission: "WRITE"}
+		}
 
-	responseBody, err := json.Marshal(map[string]any{
-		"data": map[string]any{
-			"repository": map[string]any{
-				"isPrivate": value.isPrivate,
-				"collaborators": map[string]any{
-					"edges": edges,
+		edges := []any{}
+		if value.permission != "" {
+			edges = append(edges, map[string]any{
+				"permission": value.permission,
+				"node": map[string]any{
+					"login": username,
 				},
-			},
-		},
-	})
+			})
+		}
+		repository["isPrivate"] = value.isPrivate
+		repository[field] = map[string]any{"edges": edges}
+	}
+
+	// Checks of content outside of a repository only query the viewer
+	data := map[string]any{"viewer": map[string]any{"login": "viewer"}}
+	if owner != "" {
+		data["repository"] = repository
+	}
+	responseBody, err := json.Marshal(map[string]any{"data": data})
 	if err != nil {
 		return nil, err
 	}
@@ -213,6 +226,9 @@ func Test_GetIssue(t *testing.T) {
 			gqlHTTPClient: githubv4mock.NewMockedHTTPClient(
 				githubv4mock.NewQueryMatcher(
 					struct {
+						Viewer struct {
+							Login githubv4.String
+						}
 						Repository struct {
 							IsPrivate     githubv4.Boolean
 							Collaborators struct {
@@ -231,6 +247,9 @@ func Test_GetIssue(t *testing.T) {
 						"username": githubv4.String("testuser2"),
 					},
 					githubv4mock.DataResponse(map[string]any{
+						"viewer": map[string]any{
+							"login": "viewer",
+						},
 						"repository": map[string]any{
 							"isPrivate": true,
 							"collaborators": map[string]any{
@@ -257,6 +276,9 @@ func Test_GetIssue(t *testing.T) {
 			gqlHTTPClient: githubv4mock.NewMockedHTTPClient(
 				githubv4mock.NewQueryMatcher(
 					struct {
+						Viewer struct {
+							Login githubv4.String
+						}
 						Repository struct {
 							IsPrivate     githubv4.Boolean
 							Collaborators struct {
@@ -275,6 +297,9 @@ func Test_GetIssue(t *testing.T) {
 						"username": githubv4.String("testuser"),
 					},
 					githubv4mock.DataResponse(map[string]any{
+						"viewer": map[string]any{
+							"login": "viewer",
+						},
 						"repository": map[string]any{
 							"isPrivate": false,
 							"collaborators": map[string]any{
@@ -1555,8 +1580,8 @@ func Test_ListIssues(t *testing.T) {
 	}
 
 	// Define the actual query strings that match the implementation
-	qBasicNoLabels := "query($after:String$direction:OrderDirection!$first:Int!$orderBy:IssueOrderField!$owner:String!$repo:String!$states:[IssueState!]!){repository(owner: $owner, name: $repo){issues(first: $first, after: $after, states: $states, orderBy: {field: $orderBy, direction: $direction}){nodes{number,title,body,state,databaseId,author{login},createdAt,updatedAt,labels(first: 100){nodes{name,id,description}},comments{totalCount}},pageInfo{hasNextPage,hasPreviousPage,startCursor,endCursor},totalCount}}}"
-	qWithLabels := "query($after:String$direction:OrderDirection!$first:Int!$labels:[String!]!$orderBy:IssueOrderField!$owner:String!$repo:String!$states:[IssueState!]!){repository(owner: $owner, name: $repo){issues(first: $first, after: $after, labels: $labels, states: $states, orderBy: {field: $orderBy, direction: $direction}){nodes{number,title,body,state,databaseId,author{login},createdAt,updatedAt,labels(first: 100){nodes{name,id,description}},comments{totalCount}},pageInfo{hasNextPage,hasPreviousPage,startCursor,endCursor},totalCount}}}"
+	qBasicNoLabels := "query($after:String$direction:OrderDirection!$first:Int!$orderBy:IssueOrderField!$owner:String!$repo:String!$states:[IssueState!]!){repository(owner: $owner, name: $repo){issues(first: $first, after: $after, states: $states, orderBy: {field: $orderBy, direction: $direction}){nodes{number,title,body,state,databaseId,author{login},authorAssociation,createdAt,updatedAt,labels(first: 100){nodes{name,id,description}},comments{totalCount}},pageInfo{hasNextPage,hasPreviousPage,startCursor,endCursor},totalCount}}}"
+	qWithLabels := "query($after:String$direction:OrderDirection!$first:Int!$labels:[String!]!$orderBy:IssueOrderField!$owner:String!$repo:String!$states:[IssueState!]!){repository(owner: $owner, name: $repo){issues(first: $first, after: $after, labels: $labels, states: $states, orderBy: {field: $orderBy, direction: $direction}){nodes{number,title,body,state,databaseId,author{login},authorAssociation,createdAt,updatedAt,labels(first: 100){nodes{name,id,description}},comments{totalCount}},pageInfo{hasNextPage,hasPreviousPage,startCursor,endCursor},totalCount}}}"
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
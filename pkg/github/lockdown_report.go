@@ -0,0 +1,176 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// LockdownMetaKey is the _meta key under which LockdownReportMiddleware reports the content
+// lockdown mode hid from tool results and resources.
+const LockdownMetaKey = "github.com/lockdown"
+
+// Reasons lockdown mode hides content for.
+const (
+	// LockdownReasonNoPushAccess hides content in a public repository whose author has no
+	// push access to it, and is not otherwise trusted.
+	LockdownReasonNoPushAccess = "no_push_access"
+
+	// LockdownReasonUntrustedAuthor hides content outside of a repository, such as a gist,
+	// whose author is neither the viewer nor a trusted bot.
+	LockdownReasonUntrustedAuthor = "untrusted_author"
+
+	// LockdownReasonUnknownAuthor hides content whose author isn't known, such as commits
+	// by an email address not linked to an account.
+	LockdownReasonUnknownAuthor = "unknown_author"
+)
+
+// lockdownReasons lists the reasons in the order they are reported.
+var lockdownReasons = []struct {
+	reason      string
+	description string
+}{
+	{LockdownReasonNoPushAccess, "no push access"},
+	{LockdownReasonUntrustedAuthor, "untrusted author"},
+	{LockdownReasonUnknownAuthor, "unknown author"},
+}
+
+// LockdownReport counts the items lockdown mode hid from a tool result.
+type LockdownReport struct {
+	// Hidden is the number of items hidden.
+	Hidden int `json:"hidden"`
+
+	// Reasons counts the hidden items by reason, such as LockdownReasonNoPushAccess.
+	Reasons map[string]int `json:"reasons"`
+
+	// Placeholders describes each hidden item, such as "comment by @octocat hidden by
+	// lockdown mode (no push access)", when placeholders are enabled.
+	Placeholders []string `json:"placeholders,omitempty"`
+}
+
+// lockdownRecorder collects the content lockdown mode hides during a tool call or resource
+// read.
+type lockdownRecorder struct {
+	placeholders bool
+
+	mu     sync.Mutex
+	report LockdownReport
+}
+
+func (r *lockdownRecorder) record(author contentAuthor, reason string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.report.Hidden++
+	if r.report.Reasons == nil {
+		r.report.Reasons = make(map[string]int)
+	}
+	r.report.Reasons[reason]++
+	if r.placeholders {
+		r.report.Placeholders = append(r.report.Placeholders, lockdownPlaceholder(author, reason))
+	}
+}
+
+// lockdownPlaceholder describes a hidden item.
+func lockdownPlaceholder(author contentAuthor, reason string) string {
+	kind := author.Kind
+	if kind == "" {
+		kind = "item"
+	}
+	by := "an unknown author"
+	if author.Login != "" {
+		by = "@" + author.Login
+	}
+	for _, r := range lockdownReasons {
+		if r.reason == reason {
+			reason = r.description
+		}
+	}
+	return fmt.Sprintf("%s by %s hidden by lockdown mode (%s)", kind, by, reason)
+}
+
+// lockdownRecorderCtxKey is a context key for the lockdown recorder of a request
+type lockdownRecorderCtxKey struct{}
+
+func withLockdownRecorder(ctx context.Context, recorder *lockdownRecorder) context.Context {
+	return context.WithValue(ctx, lockdownRecorderCtxKey{}, recorder)
+}
+
+func getLockdownRecorder(ctx context.Context) *lockdownRecorder {
+	if recorder, ok := ctx.Value(lockdownRecorderCtxKey{}).(*lockdownRecorder); ok {
+		return recorder
+	}
+	return nil
+}
+
+// LockdownReportMiddleware reports the items lockdown mode hid from the result of a tool
+// call or resource read, so that agents don't mistake a filtered list for a complete one. The
+// report is attached to the result's _meta, and summarized in a text note appended to its
+// content, which lists a placeholder for every hidden item if placeholders is set. Results
+// from which nothing was hidden are left unchanged.
+func LockdownReportMiddleware(placeholders bool) mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			if method != "tools/call" && method != "resources/read" {
+				return next(ctx, method, req)
+			}
+
+			recorder := &lockdownRecorder{placeholders: placeholders}
+			result, err := next(withLockdownRecorder(ctx, recorder), method, req)
+			report := recorder.report
+			if err != nil || report.Hidden == 0 {
+				return result, err
+			}
+
+			switch r := result.(type) {
+			case *mcp.CallToolResult:
+				if r == nil || r.IsError {
+					return result, nil
+				}
+				if r.Meta == nil {
+					r.Meta = mcp.Meta{}
+				}
+				r.Meta[LockdownMetaKey] = report
+				r.Content = append(r.Content, &mcp.TextContent{Text: lockdownNote(report)})
+			case *mcp.ReadResourceResult:
+				if r == nil {
+					return result, nil
+				}
+				if r.Meta == nil {
+					r.Meta = mcp.Meta{}
+				}
+				r.Meta[LockdownMetaKey] = report
+				var uri string
+				if params, ok := req.GetParams().(*mcp.ReadResourceParams); ok && params != nil {
+					uri = params.URI
+				}
+				r.Contents = append(r.Contents, &mcp.ResourceContents{URI: uri, MIMEType: "text/plain", Text: lockdownNote(report)})
+			}
+			return result, nil
+		}
+	}
+}
+
+// lockdownNote summarizes a report for the agent, such as "Lockdown mode hid 3 items from
+// this result (no push access: 2, unknown author: 1)."
+func lockdownNote(report LockdownReport) string {
+	var counts []string
+	for _, r := range lockdownReasons {
+		if n := report.Reasons[r.reason]; n > 0 {
+			counts = append(counts, fmt.Sprintf("%s: %d", r.description, n))
+		}
+	}
+	items := "items"
+	if report.Hidden == 1 {
+		items = "item"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Lockdown mode hid %d %s from this result (%s).", report.Hidden, items, strings.Join(counts, ", "))
+	for _, placeholder := range report.Placeholders {
+		fmt.Fprintf(&b, "\n- %s", placeholder)
+	}
+	return b.String()
+}
@@ -0,0 +1,111 @@
+package github
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	gherrors "github.com/github/github-mcp-server/pkg/errors"
+	"github.com/github/github-mcp-server/pkg/inventory"
+	"github.com/github/github-mcp-server/pkg/metrics"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Error classes recorded for failed tool calls, in addition to metrics.ErrorClassNone.
+const (
+	// errorClassProtocol is a call rejected by the MCP server, such as an unknown tool or
+	// invalid arguments.
+	errorClassProtocol = "protocol_error"
+	// errorClassTool is a tool error that did not come from a GitHub API call.
+	errorClassTool = "tool_error"
+	// errorClassGraphQL is a failed GitHub GraphQL query or mutation.
+	errorClassGraphQL = "github_graphql"
+)
+
+// unknownToolName is the tool label for calls to tools the server does not have, so that
+// arbitrary client input cannot create new metric series.
+const unknownToolName = "unknown"
+
+// MetricsMiddleware records every MCP request and the outcome and latency of tool calls.
+// It must be added after addGitHubAPIErrorToContext so that it runs first and can read
+// the GitHub errors recorded by the tool.
+func MetricsMiddleware(m *metrics.Metrics, inv *inventory.Inventory) mcp.Middleware {
+	dynamicTools := make(map[string]bool)
+	for _, tool := range DynamicTools(inv) {
+		dynamicTools[tool.Tool.Name] = true
+	}
+
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			m.ObserveMCPRequest(method)
+
+			callReq, ok := req.(*mcp.CallToolRequest)
+			if method != "tools/call" || !ok || callReq.Params == nil {
+				return next(ctx, method, req)
+			}
+
+			ctx = gherrors.ContextWithGitHubErrors(ctx)
+			start := time.Now()
+			result, err := next(ctx, method, req)
+
+			// Read the name after the call so that deprecated aliases are recorded under
+			// the tool they resolved to
+			tool := callReq.Params.Name
+			if _, _, findErr := inv.FindToolByName(tool); findErr != nil && !dynamicTools[tool] {
+				tool = unknownToolName
+			}
+			m.ObserveToolCall(tool, toolCallErrorClass(ctx, result, err), time.Since(start))
+			return result, err
+		}
+	}
+}
+
+// toolCallErrorClass classifies the outcome of a tool call. Failed GitHub REST and raw
+// content requests are classified by response status, e.g. "github_rate_limited".
+func toolCallErrorClass(ctx context.Context, result mcp.Result, err error) string {
+	if err != nil {
+		return errorClassProtocol
+	}
+	toolResult, ok := result.(*mcp.CallToolResult)
+	if !ok || toolResult == nil || !toolResult.IsError {
+		return metrics.ErrorClassNone
+	}
+
+	if apiErrs, _ := gherrors.GetGitHubAPIErrors(ctx); len(apiErrs) > 0 {
+		var resp *http.Response
+		if last := apiErrs[len(apiErrs)-1]; last.Response != nil {
+			resp = last.Response.Response
+		}
+		return "github_" + responseErrorClass(resp)
+	}
+	if rawErrs, _ := gherrors.GetGitHubRawAPIErrors(ctx); len(rawErrs) > 0 {
+		return "github_" + responseErrorClass(rawErrs[len(rawErrs)-1].Response)
+	}
+	if gqlErrs, _ := gherrors.GetGitHubGraphQLErrors(ctx); len(gqlErrs) > 0 {
+		return errorClassGraphQL
+	}
+	return errorClassTool
+}
+
+func responseErrorClass(resp *http.Response) string {
+	if resp == nil {
+		return "request_failed"
+	}
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests,
+		resp.StatusCode == http.StatusForbidden && resp.Header.Get("X-RateLimit-Remaining") == "0":
+		return "rate_limited"
+	case resp.StatusCode == http.StatusUnauthorized:
+		return "unauthorized"
+	case resp.StatusCode == http.StatusForbidden:
+		return "forbidden"
+	case resp.StatusCode == http.StatusNotFound:
+		return "not_found"
+	case resp.StatusCode == http.StatusUnprocessableEntity:
+		return "validation_failed"
+	case resp.StatusCode >= http.StatusInternalServerError:
+		return "server_error"
+	default:
+		return "client_error"
+	}
+}
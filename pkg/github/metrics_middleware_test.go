@@ -0,0 +1,158 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	gherrors "github.com/github/github-mcp-server/pkg/errors"
+	"github.com/github/github-mcp-server/pkg/inventory"
+	"github.com/github/github-mcp-server/pkg/metrics"
+	"github.com/github/github-mcp-server/pkg/translations"
+	"github.com/github/github-mcp-server/pkg/utils"
+	gogithub "github.com/google/go-github/v82/github"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func githubResponse(status int, header http.Header) *gogithub.Response {
+	if header == nil {
+		header = http.Header{}
+	}
+	return &gogithub.Response{Response: &http.Response{StatusCode: status, Header: header}}
+}
+
+func TestMetricsMiddleware(t *testing.T) {
+	inv, err := inventory.NewBuilder().
+		SetTools([]inventory.ServerTool{GetMe(translations.NullTranslationHelper)}).
+		WithDeprecatedAliases(map[string]string{"old_me": "get_me"}).
+		WithToolsets([]string{"all"}).
+		Build()
+	require.NoError(t, err)
+
+	tests := []struct {
+		name          string
+		toolName      string
+		handler       mcp.MethodHandler
+		expectedTool  string
+		expectedClass string
+	}{
+		{
+			name:     "successful call",
+			toolName: "get_me",
+			handler: func(context.Context, string, mcp.Request) (mcp.Result, error) {
+				return utils.NewToolResultText("ok"), nil
+			},
+			expectedTool:  "get_me",
+			expectedClass: metrics.ErrorClassNone,
+		},
+		{
+			name:     "tool error without GitHub error",
+			toolName: "get_me",
+			handler: func(context.Context, string, mcp.Request) (mcp.Result, error) {
+				return utils.NewToolResultError("missing required parameter"), nil
+			},
+			expectedTool:  "get_me",
+			expectedClass: "tool_error",
+		},
+		{
+			name:     "GitHub API rate limit",
+			toolName: "get_me",
+			handler: func(ctx context.Context, _ string, _ mcp.Request) (mcp.Result, error) {
+				resp := githubResponse(http.StatusForbidden, http.Header{"X-Ratelimit-Remaining": []string{"0"}})
+				return gherrors.NewGitHubAPIErrorResponse(ctx, "failed to get user", resp, errors.New("rate limited")), nil
+			},
+			expectedTool:  "get_me",
+			expectedClass: "github_rate_limited",
+		},
+		{
+			name:     "GitHub GraphQL error",
+			toolName: "get_me",
+			handler: func(ctx context.Context, _ string, _ mcp.Request) (mcp.Result, error) {
+				return gherrors.NewGitHubGraphQLErrorResponse(ctx, "failed to query", errors.New("boom")), nil
+			},
+			expectedTool:  "get_me",
+			expectedClass: "github_graphql",
+		},
+		{
+			name:     "alias is recorded under the tool it resolved to",
+			toolName: "old_me",
+			handler: func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+				return DeprecatedToolAliasMiddleware(inv, false, nil)(func(context.Context, string, mcp.Request) (mcp.Result, error) {
+					return utils.NewToolResultText("ok"), nil
+				})(ctx, method, req)
+			},
+			expectedTool:  "get_me",
+			expectedClass: metrics.ErrorClassNone,
+		},
+		{
+			name:     "unknown tool",
+			toolName: "made_up_tool",
+			handler: func(context.Context, string, mcp.Request) (mcp.Result, error) {
+				return nil, errors.New("unknown tool")
+			},
+			expectedTool:  "unknown",
+			expectedClass: "protocol_error",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			m := metrics.New()
+			handler := MetricsMiddleware(m, inv)(tc.handler)
+			_, _ = handler(context.Background(), "tools/call", &mcp.CallToolRequest{
+				Params: &mcp.CallToolParamsRaw{Name: tc.toolName},
+			})
+
+			out := httptest.NewRecorder()
+			m.Handler().ServeHTTP(out, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+			assert.Contains(t, out.Body.String(), `github_mcp_requests_total{method="tools/call"} 1`)
+			assert.Contains(t, out.Body.String(),
+				`github_mcp_tool_calls_total{error_class="`+tc.expectedClass+`",tool="`+tc.expectedTool+`"} 1`)
+			assert.Contains(t, out.Body.String(), `github_mcp_tool_call_duration_seconds_count{tool="`+tc.expectedTool+`"} 1`)
+		})
+	}
+}
+
+func TestMetricsMiddlewareIgnoresOtherMethods(t *testing.T) {
+	inv, err := inventory.NewBuilder().Build()
+	require.NoError(t, err)
+
+	m := metrics.New()
+	handler := MetricsMiddleware(m, inv)(func(context.Context, string, mcp.Request) (mcp.Result, error) {
+		return &mcp.ListToolsResult{}, nil
+	})
+	_, err = handler(context.Background(), "tools/list", &mcp.ListToolsRequest{})
+	require.NoError(t, err)
+
+	out := httptest.NewRecorder()
+	m.Handler().ServeHTTP(out, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Contains(t, out.Body.String(), `github_mcp_requests_total{method="tools/list"} 1`)
+	assert.NotContains(t, out.Body.String(), "github_mcp_tool_calls_total{")
+}
+
+func Test_responseErrorClass(t *testing.T) {
+	tests := []struct {
+		name     string
+		resp     *http.Response
+		expected string
+	}{
+		{name: "no response", expected: "request_failed"},
+		{name: "unauthorized", resp: githubResponse(http.StatusUnauthorized, nil).Response, expected: "unauthorized"},
+		{name: "forbidden", resp: githubResponse(http.StatusForbidden, nil).Response, expected: "forbidden"},
+		{name: "secondary rate limit", resp: githubResponse(http.StatusTooManyRequests, nil).Response, expected: "rate_limited"},
+		{name: "not found", resp: githubResponse(http.StatusNotFound, nil).Response, expected: "not_found"},
+		{name: "validation", resp: githubResponse(http.StatusUnprocessableEntity, nil).Response, expected: "validation_failed"},
+		{name: "conflict", resp: githubResponse(http.StatusConflict, nil).Response, expected: "client_error"},
+		{name: "server error", resp: githubResponse(http.StatusBadGateway, nil).Response, expected: "server_error"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, responseErrorClass(tc.resp))
+		})
+	}
+}
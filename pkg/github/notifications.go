@@ -151,6 +151,11 @@ func ListNotifications(t translations.TranslationHelperFunc) inventory.ServerToo
 				return ghErrors.NewGitHubAPIStatusErrorResponse(ctx, "failed to get notifications", resp, body), nil, nil
 			}
 
+			notifications, err = filterUserContent(ctx, deps, notifications, notificationAuthor, sanitizeNotification)
+			if err != nil {
+				return utils.NewToolResultError(err.Error()), nil, nil
+			}
+
 			// Marshal response to JSON
 			r, err := json.Marshal(notifications)
 			if err != nil {
@@ -162,6 +167,19 @@ func ListNotifications(t translations.TranslationHelperFunc) inventory.ServerToo
 	)
 }
 
+// notificationAuthor returns the author of a notification. Notifications don't say who
+// wrote their subject, so lockdown mode only keeps those of private repositories.
+func notificationAuthor(notification *github.Notification) contentAuthor {
+	return contentAuthor{Kind: "notification", Private: notification.GetRepository().GetPrivate()}
+}
+
+// sanitizeNotification sanitizes the title of the subject of a notification.
+func sanitizeNotification(ctx context.Context, notification *github.Notification) {
+	if notification.Subject != nil {
+		sanitizeText(ctx, notification.Subject.Title)
+	}
+}
+
 // DismissNotification creates a tool to mark a notification as read/done.
 func DismissNotification(t translations.TranslationHelperFunc) inventory.ServerTool {
 	return NewTool(
@@ -388,6 +406,15 @@ func GetNotificationDetails(t translations.TranslationHelperFunc) inventory.Serv
 				return ghErrors.NewGitHubAPIStatusErrorResponse(ctx, "failed to get notification details", resp, body), nil, nil
 			}
 
+			safe, err := checkUserContent(ctx, deps, notificationAuthor(thread))
+			if err != nil {
+				return utils.NewToolResultError(err.Error()), nil, nil
+			}
+			if !safe {
+				return utils.NewToolResultError("access to notification is restricted by lockdown mode"), nil, nil
+			}
+			sanitizeNotification(ctx, thread)
+
 			r, err := json.Marshal(thread)
 			if err != nil {
 				return utils.NewToolResultErrorFromErr("failed to marshal response", err), nil, nil
@@ -155,7 +155,7 @@ Use this tool to list projects for a user or organization, or list project field
 						return utils.NewToolResultError(err.Error()), nil, nil
 					}
 				}
-				return listProjectItems(ctx, client, args, owner, ownerType)
+				return listProjectItems(ctx, client, deps, args, owner, ownerType)
 			default:
 				return utils.NewToolResultError(fmt.Sprintf("unknown method: %s", method)), nil, nil
 			}
@@ -274,7 +274,7 @@ Use this tool to get details about individual projects, project fields, and proj
 				if err != nil {
 					return utils.NewToolResultError(err.Error()), nil, nil
 				}
-				return getProjectItem(ctx, client, owner, ownerType, projectNumber, itemID, fields)
+				return getProjectItem(ctx, client, deps, owner, ownerType, projectNumber, itemID, fields)
 			default:
 				return utils.NewToolResultError(fmt.Sprintf("unknown method: %s", method)), nil, nil
 			}
@@ -453,6 +453,69 @@ func ProjectsWrite(t translations.TranslationHelperFunc) inventory.ServerTool {
 	return tool
 }
 
+// projectItemAuthor returns the author of the issue, pull request or draft issue of a project
+// item. Issues and pull requests are checked against their own repository, while draft
+// issues only exist in the project, so they are only trusted from the viewer and trusted bots.
+func projectItemAuthor(item *github.ProjectV2Item) contentAuthor {
+	author := contentAuthor{Kind: "project item", Login: item.GetCreator().GetLogin()}
+	if item.Content == nil {
+		return author
+	}
+	switch {
+	case item.Content.Issue != nil:
+		issue := item.Content.Issue
+		author.Login = issue.GetUser().GetLogin()
+		author.Association = issue.GetAuthorAssociation()
+		author.Owner, author.Repo = repoFromAPIURL(issue.GetRepositoryURL())
+		if author.Repo == "" {
+			author.Owner, author.Repo = repoFromAPIURL(issue.GetURL())
+		}
+	case item.Content.PullRequest != nil:
+		pr := item.Content.PullRequest
+		author.Login = pr.GetUser().GetLogin()
+		author.Association = pr.GetAuthorAssociation()
+		author.Owner, author.Repo = repoFromAPIURL(pr.GetURL())
+	case item.Content.DraftIssue != nil:
+		if login := item.Content.DraftIssue.GetUser().GetLogin(); login != "" {
+			author.Login = login
+		}
+	}
+	return author
+}
+
+// sanitizeProjectItem sanitizes the title and body of the content of a project item, and the
+// text of its field values.
+func sanitizeProjectItem(ctx context.Context, item *github.ProjectV2Item) {
+	if item.Content != nil {
+		switch {
+		case item.Content.Issue != nil:
+			sanitizeIssue(ctx, item.Content.Issue)
+		case item.Content.PullRequest != nil:
+			sanitizePullRequest(ctx, item.Content.PullRequest)
+		case item.Content.DraftIssue != nil:
+			sanitizeText(ctx, item.Content.DraftIssue.Title, item.Content.DraftIssue.Body)
+		}
+	}
+	for _, field := range item.Fields {
+		switch value := field.Value.(type) {
+		case string:
+			sanitizeText(ctx, &value)
+			field.Value = value
+		case map[string]any:
+			// Such as the title field, which reflects the title of the item
+			if text, ok := value["text"].(string); ok {
+				sanitizeText(ctx, &text)
+				value["text"] = text
+			}
+		}
+	}
+}
+
+// sanitizeProject sanitizes the title and descriptions of a project.
+func sanitizeProject(ctx context.Context, project *MinimalProject) {
+	sanitizeText(ctx, project.Title, project.Description, project.ShortDescription)
+}
+
 // Helper functions for consolidated projects tools
 
 func listProjects(ctx context.Context, client *github.Client, args map[string]any, owner, ownerType string) (*mcp.CallToolResult, any, error) {
@@ -510,6 +573,7 @@ func listProjects(ctx context.Context, client *github.Client, args map[string]an
 
 		for _, project := range projects {
 			mp := convertToMinimalProject(project)
+			sanitizeProject(ctx, mp)
 			mp.OwnerType = ownerType
 			minimalProjects = append(minimalProjects, *mp)
 		}
@@ -541,6 +605,7 @@ func listProjectsFromBothOwnerTypes(ctx context.Context, client *github.Client,
 	if userErr == nil && userResp.StatusCode == http.StatusOK {
 		for _, project := range userProjects {
 			mp := convertToMinimalProject(project)
+			sanitizeProject(ctx, mp)
 			mp.OwnerType = "user"
 			minimalProjects = append(minimalProjects, *mp)
 		}
@@ -552,6 +617,7 @@ func listProjectsFromBothOwnerTypes(ctx context.Context, client *github.Client,
 	if orgErr == nil && orgResp.StatusCode == http.StatusOK {
 		for _, project := range orgProjects {
 			mp := convertToMinimalProject(project)
+			sanitizeProject(ctx, mp)
 			mp.OwnerType = "org"
 			minimalProjects = append(minimalProjects, *mp)
 		}
@@ -628,7 +694,7 @@ func listProjectFields(ctx context.Context, client *github.Client, args map[stri
 	return utils.NewToolResultText(string(r)), nil, nil
 }
 
-func listProjectItems(ctx context.Context, client *github.Client, args map[string]any, owner, ownerType string) (*mcp.CallToolResult, any, error) {
+func listProjectItems(ctx context.Context, client *github.Client, deps ToolDependencies, args map[string]any, owner, ownerType string) (*mcp.CallToolResult, any, error) {
 	projectNumber, err := RequiredInt(args, "project_number")
 	if err != nil {
 		return utils.NewToolResultError(err.Error()), nil, nil
@@ -680,6 +746,11 @@ func listProjectItems(ctx context.Context, client *github.Client, args map[strin
 	}
 	defer func() { _ = resp.Body.Close() }()
 
+	projectItems, err = filterUserContent(ctx, deps, projectItems, projectItemAuthor, sanitizeProjectItem)
+	if err != nil {
+		return utils.NewToolResultError(err.Error()), nil, nil
+	}
+
 	response := map[string]any{
 		"items":    projectItems,
 		"pageInfo": buildPageInfo(resp),
@@ -721,6 +792,7 @@ func getProject(ctx context.Context, client *github.Client, owner, ownerType str
 	}
 
 	minimalProject := convertToMinimalProject(project)
+	sanitizeProject(ctx, minimalProject)
 	r, err := json.Marshal(minimalProject)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to marshal response: %w", err)
@@ -764,7 +836,7 @@ func getProjectField(ctx context.Context, client *github.Client, owner, ownerTyp
 	return utils.NewToolResultText(string(r)), nil, nil
 }
 
-func getProjectItem(ctx context.Context, client *github.Client, owner, ownerType string, projectNumber int, itemID int64, fields []int64) (*mcp.CallToolResult, any, error) {
+func getProjectItem(ctx context.Context, client *github.Client, deps ToolDependencies, owner, ownerType string, projectNumber int, itemID int64, fields []int64) (*mcp.CallToolResult, any, error) {
 	var resp *github.Response
 	var projectItem *github.ProjectV2Item
 	var opts *github.GetProjectItemOptions
@@ -799,6 +871,15 @@ func getProjectItem(ctx context.Context, client *github.Client, owner, ownerType
 		return ghErrors.NewGitHubAPIStatusErrorResponse(ctx, "failed to get project item", resp, body), nil, nil
 	}
 
+	safe, err := checkUserContent(ctx, deps, projectItemAuthor(projectItem))
+	if err != nil {
+		return utils.NewToolResultError(err.Error()), nil, nil
+	}
+	if !safe {
+		return utils.NewToolResultError("access to project item is restricted by lockdown mode"), nil, nil
+	}
+	sanitizeProjectItem(ctx, projectItem)
+
 	r, err := json.Marshal(projectItem)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to marshal response: %w", err)
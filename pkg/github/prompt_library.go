@@ -0,0 +1,135 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/github/github-mcp-server/pkg/inventory"
+	"github.com/github/github-mcp-server/pkg/prompts"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// LoadPromptLibrary loads user-defined prompts from the YAML and Markdown files in dir.
+// Each prompt is attached to the toolset named in its definition, or to the context
+// toolset when none is given, so it is enabled and filtered like the built-in prompts.
+func LoadPromptLibrary(dir string) ([]inventory.ServerPrompt, error) {
+	definitions, err := prompts.LoadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	// Build() can only fail if WithTools specifies invalid tools - not used here
+	r, _ := NewInventory(stubTranslator).Build()
+	toolsets := make(map[string]inventory.ToolsetMetadata)
+	for _, ts := range r.AvailableToolsets() {
+		toolsets[string(ts.ID)] = ts
+	}
+
+	builtin := make(map[string]bool)
+	for _, p := range AllPrompts(stubTranslator) {
+		builtin[p.Prompt.Name] = true
+	}
+
+	result := make([]inventory.ServerPrompt, 0, len(definitions))
+	for _, def := range definitions {
+		if builtin[def.Name] {
+			return nil, fmt.Errorf("prompt %q in %s conflicts with a built-in prompt", def.Name, def.Source)
+		}
+
+		toolsetID := def.Toolset
+		if toolsetID == "" {
+			toolsetID = string(ToolsetMetadataContext.ID)
+		}
+		toolset, ok := toolsets[toolsetID]
+		if !ok {
+			return nil, fmt.Errorf("prompt %q in %s references unknown toolset %q", def.Name, def.Source, toolsetID)
+		}
+
+		for _, arg := range def.Arguments {
+			if arg.Complete == "" {
+				continue
+			}
+			if _, ok := RepositoryResourceArgumentResolvers[arg.Complete]; !ok {
+				return nil, fmt.Errorf("prompt %q in %s: argument %q uses unknown completion source %q", def.Name, def.Source, arg.Name, arg.Complete)
+			}
+		}
+
+		prompt := inventory.NewServerPrompt(toolset, def.Prompt(), userPromptHandler(def))
+		prompt.CompletionHandler = userPromptCompletionHandler(def)
+		result = append(result, prompt)
+	}
+	return result, nil
+}
+
+func userPromptHandler(def prompts.Definition) mcp.PromptHandler {
+	return func(_ context.Context, request *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		messages, err := def.Render(request.Params.Arguments)
+		if err != nil {
+			return nil, err
+		}
+		return &mcp.GetPromptResult{
+			Description: def.Description,
+			Messages:    messages,
+		}, nil
+	}
+}
+
+// userPromptCompletionHandler completes prompt arguments from their allowed values, or
+// from the repository completion sources for arguments that declare one.
+func userPromptCompletionHandler(def prompts.Definition) inventory.PromptCompletionHandler {
+	return func(ctx context.Context, req *mcp.CompleteRequest) (*mcp.CompleteResult, error) {
+		argName := req.Params.Argument.Name
+		argValue := req.Params.Argument.Value
+
+		arg, ok := def.Argument(argName)
+		if !ok {
+			return nil, fmt.Errorf("prompt %q has no argument %q", def.Name, argName)
+		}
+
+		values := []string{}
+		switch {
+		case len(arg.Values) > 0:
+			values = append(values, def.CompleteValues(argName, argValue)...)
+		case arg.Complete != "":
+			deps, ok := DepsFromContext(ctx)
+			if !ok {
+				return nil, errors.New("no GitHub client available for completion")
+			}
+			client, err := deps.GetClient(ctx)
+			if err != nil {
+				return nil, err
+			}
+
+			// Completion sources look up related values by their own names, so map
+			// the prompt's argument names onto the sources they complete from.
+			resolved := map[string]string{}
+			if req.Params.Context != nil {
+				for name, value := range req.Params.Context.Arguments {
+					resolved[name] = value
+					if other, ok := def.Argument(name); ok && other.Complete != "" {
+						resolved[other.Complete] = value
+					}
+				}
+			}
+
+			completed, err := RepositoryResourceArgumentResolvers[arg.Complete](ctx, client, resolved, argValue)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, completed...)
+		}
+
+		if len(values) > 100 {
+			values = slices.Clip(values[:100])
+		}
+		return &mcp.CompleteResult{
+			Completion: mcp.CompletionResultDetails{
+				Values:  values,
+				Total:   len(values),
+				HasMore: false,
+			},
+		}, nil
+	}
+}
@@ -0,0 +1,162 @@
+package github
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/github/github-mcp-server/pkg/inventory"
+	gogithub "github.com/google/go-github/v82/github"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const securityReviewPrompt = `
+name: security_review
+description: Review a repository's security posture
+toolset: code_security
+arguments:
+  - name: org
+    required: true
+    complete: owner
+  - name: severity
+    values: [critical, high, medium, low]
+messages:
+  - role: user
+    content: Review open alerts in {{.org}}{{if .severity}} with severity {{.severity}}{{end}}.
+`
+
+func writePromptFile(t *testing.T, dir, name, contents string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(contents), 0600))
+}
+
+func TestLoadPromptLibrary(t *testing.T) {
+	dir := t.TempDir()
+	writePromptFile(t, dir, "security.yaml", securityReviewPrompt)
+	writePromptFile(t, dir, "standup.md", "---\nname: standup\ndescription: Summarize my recent activity\n---\nSummarize what I worked on yesterday.\n")
+
+	prompts, err := LoadPromptLibrary(dir)
+	require.NoError(t, err)
+	require.Len(t, prompts, 2)
+
+	assert.Equal(t, "security_review", prompts[0].Prompt.Name)
+	assert.Equal(t, ToolsetMetadataCodeSecurity.ID, prompts[0].Toolset.ID)
+	assert.NotNil(t, prompts[0].CompletionHandler)
+
+	// Prompts without a toolset are attached to the context toolset
+	assert.Equal(t, "standup", prompts[1].Prompt.Name)
+	assert.Equal(t, ToolsetMetadataContext.ID, prompts[1].Toolset.ID)
+
+	result, err := prompts[0].Handler(context.Background(), &mcp.GetPromptRequest{
+		Params: &mcp.GetPromptParams{
+			Name:      "security_review",
+			Arguments: map[string]string{"org": "octo-org", "severity": "high"},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, result.Messages, 1)
+	assert.Equal(t, "Review open alerts in octo-org with severity high.", result.Messages[0].Content.(*mcp.TextContent).Text)
+
+	_, err = prompts[0].Handler(context.Background(), &mcp.GetPromptRequest{
+		Params: &mcp.GetPromptParams{
+			Name:      "security_review",
+			Arguments: map[string]string{"severity": "urgent"},
+		},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `missing required argument "org"`)
+	assert.Contains(t, err.Error(), `argument "severity" must be one of`)
+}
+
+func TestLoadPromptLibrary_Errors(t *testing.T) {
+	tests := []struct {
+		name        string
+		contents    string
+		expectedErr string
+	}{
+		{
+			name:        "unknown toolset",
+			contents:    "name: p\ndescription: d\ntoolset: nope\nmessages: [{role: user, content: hi}]",
+			expectedErr: `unknown toolset "nope"`,
+		},
+		{
+			name:        "conflicts with built-in prompt",
+			contents:    "name: issue_to_fix_workflow\ndescription: d\nmessages: [{role: user, content: hi}]",
+			expectedErr: "conflicts with a built-in prompt",
+		},
+		{
+			name:        "unknown completion source",
+			contents:    "name: p\ndescription: d\narguments: [{name: a, complete: planets}]\nmessages: [{role: user, content: hi}]",
+			expectedErr: `unknown completion source "planets"`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writePromptFile(t, dir, "prompt.yaml", tc.contents)
+
+			_, err := LoadPromptLibrary(dir)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.expectedErr)
+		})
+	}
+}
+
+func TestUserPromptCompletions(t *testing.T) {
+	dir := t.TempDir()
+	writePromptFile(t, dir, "security.yaml", securityReviewPrompt)
+
+	prompts, err := LoadPromptLibrary(dir)
+	require.NoError(t, err)
+
+	inv, err := inventory.NewBuilder().
+		SetPrompts(prompts).
+		WithToolsets([]string{"all"}).
+		Build()
+	require.NoError(t, err)
+
+	mockedClient := MockHTTPClientWithHandlers(map[string]http.HandlerFunc{
+		GetUser:          mockResponse(t, http.StatusOK, &gogithub.User{Login: gogithub.Ptr("octocat")}),
+		"GET /user/orgs": mockResponse(t, http.StatusOK, []*gogithub.Organization{{Login: gogithub.Ptr("octo-org")}}),
+		GetSearchUsers:   mockResponse(t, http.StatusOK, &gogithub.UsersSearchResult{Users: []*gogithub.User{{Login: gogithub.Ptr("octonaut")}}}),
+	})
+	deps := stubDeps{clientFn: stubClientFnFromHTTP(mockedClient)}
+	handler := CompletionsHandler(deps.GetClient, inv)
+
+	complete := func(ctx context.Context, arg, value string) (*mcp.CompleteResult, error) {
+		return handler(ctx, &mcp.CompleteRequest{
+			Params: &mcp.CompleteParams{
+				Ref:      &mcp.CompleteReference{Type: "ref/prompt", Name: "security_review"},
+				Argument: mcp.CompleteParamsArgument{Name: arg, Value: value},
+			},
+		})
+	}
+
+	// Static values are completed by prefix
+	result, err := complete(context.Background(), "severity", "c")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"critical"}, result.Completion.Values)
+
+	// Dynamic sources use the GitHub client from the injected dependencies
+	result, err = complete(ContextWithDeps(context.Background(), deps), "org", "octo")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"octocat", "octo-org", "octonaut"}, result.Completion.Values)
+
+	_, err = complete(context.Background(), "unknown", "")
+	require.Error(t, err)
+
+	// Prompts without completions, or that don't exist, yield no result
+	result, err = handler(context.Background(), &mcp.CompleteRequest{
+		Params: &mcp.CompleteParams{
+			Ref:      &mcp.CompleteReference{Type: "ref/prompt", Name: "missing"},
+			Argument: mcp.CompleteParamsArgument{Name: "org"},
+		},
+	})
+	require.NoError(t, err)
+	assert.Nil(t, result)
+}
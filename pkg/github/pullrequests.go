@@ -17,7 +17,6 @@ import (
 	ghErrors "github.com/github/github-mcp-server/pkg/errors"
 	"github.com/github/github-mcp-server/pkg/inventory"
 	"github.com/github/github-mcp-server/pkg/octicons"
-	"github.com/github/github-mcp-server/pkg/sanitize"
 	"github.com/github/github-mcp-server/pkg/scopes"
 	"github.com/github/github-mcp-server/pkg/translations"
 	"github.com/github/github-mcp-server/pkg/utils"
@@ -104,12 +103,18 @@ Possible options:
 				result, err := GetPullRequest(ctx, client, deps, owner, repo, pullNumber)
 				return result, nil, err
 			case "get_diff":
+				if result, err := restrictPullRequestChanges(ctx, client, deps, owner, repo, pullNumber); result != nil || err != nil {
+					return result, nil, err
+				}
 				result, err := GetPullRequestDiff(ctx, client, owner, repo, pullNumber)
 				return result, nil, err
 			case "get_status":
 				result, err := GetPullRequestStatus(ctx, client, owner, repo, pullNumber)
 				return result, nil, err
 			case "get_files":
+				if result, err := restrictPullRequestChanges(ctx, client, deps, owner, repo, pullNumber); result != nil || err != nil {
+					return result, nil, err
+				}
 				result, err := GetPullRequestFiles(ctx, client, owner, repo, pullNumber, pagination)
 				return result, nil, err
 			case "get_review_comments":
@@ -135,13 +140,19 @@ Possible options:
 		})
 }
 
-func GetPullRequest(ctx context.Context, client *github.Client, deps ToolDependencies, owner, repo string, pullNumber int) (*mcp.CallToolResult, error) {
-	cache, err := deps.GetRepoAccessCache(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get repo access cache: %w", err)
+// pullRequestAuthor returns the author of pull requests of a repository.
+func pullRequestAuthor(owner, repo string) func(*github.PullRequest) contentAuthor {
+	return func(pr *github.PullRequest) contentAuthor {
+		return contentAuthor{Kind: "pull request", Login: pr.GetUser().GetLogin(), Association: pr.GetAuthorAssociation(), Owner: owner, Repo: repo}
 	}
-	ff := deps.GetFlags(ctx)
+}
 
+// sanitizePullRequest sanitizes the title and body of a pull request.
+func sanitizePullRequest(ctx context.Context, pr *github.PullRequest) {
+	sanitizeText(ctx, pr.Title, pr.Body)
+}
+
+func GetPullRequest(ctx context.Context, client *github.Client, deps ToolDependencies, owner, repo string, pullNumber int) (*mcp.CallToolResult, error) {
 	pr, resp, err := client.PullRequests.Get(ctx, owner, repo, pullNumber)
 	if err != nil {
 		return ghErrors.NewGitHubAPIErrorResponse(ctx,
@@ -160,38 +171,47 @@ func GetPullRequest(ctx context.Context, client *github.Client, deps ToolDepende
 		return ghErrors.NewGitHubAPIStatusErrorResponse(ctx, "failed to get pull request", resp, body), nil
 	}
 
-	// sanitize title/body on response
-	if pr != nil {
-		if pr.Title != nil {
-			pr.Title = github.Ptr(sanitize.Sanitize(*pr.Title))
-		}
-		if pr.Body != nil {
-			pr.Body = github.Ptr(sanitize.Sanitize(*pr.Body))
-		}
+	safe, err := checkUserContent(ctx, deps, pullRequestAuthor(owner, repo)(pr))
+	if err != nil {
+		return utils.NewToolResultError(err.Error()), nil
 	}
-
-	if ff.LockdownMode {
-		if cache == nil {
-			return nil, fmt.Errorf("lockdown cache is not configured")
-		}
-		login := pr.GetUser().GetLogin()
-		if login != "" {
-			isSafeContent, err := cache.IsSafeContent(ctx, login, owner, repo)
-			if err != nil {
-				return nil, fmt.Errorf("failed to check content removal: %w", err)
-			}
-
-			if !isSafeContent {
-				return utils.NewToolResultError("access to pull request is restricted by lockdown mode"), nil
-			}
-		}
+	if !safe {
+		return utils.NewToolResultError("access to pull request is restricted by lockdown mode"), nil
 	}
+	sanitizePullRequest(ctx, pr)
 
 	minimalPR := convertToMinimalPullRequest(pr)
 
 	return MarshalledTextResult(minimalPR), nil
 }
 
+// restrictPullRequestChanges returns an error result in lockdown mode if the author of a pull
+// request is not trusted, as its changes are theirs too. Changes are returned as is otherwise,
+// since sanitizing would alter them.
+func restrictPullRequestChanges(ctx context.Context, client *github.Client, deps ToolDependencies, owner, repo string, pullNumber int) (*mcp.CallToolResult, error) {
+	if !deps.GetFlags(ctx).LockdownMode {
+		return nil, nil
+	}
+	pr, resp, err := client.PullRequests.Get(ctx, owner, repo, pullNumber)
+	if err != nil {
+		return ghErrors.NewGitHubAPIErrorResponse(ctx,
+			"failed to get pull request",
+			resp,
+			err,
+		), nil
+	}
+	_ = resp.Body.Close()
+
+	safe, err := checkUserContent(ctx, deps, pullRequestAuthor(owner, repo)(pr))
+	if err != nil {
+		return utils.NewToolResultError(err.Error()), nil
+	}
+	if !safe {
+		return utils.NewToolResultError("access to pull request is restricted by lockdown mode"), nil
+	}
+	return nil, nil
+}
+
 func GetPullRequestDiff(ctx context.Context, client *github.Client, owner, repo string, pullNumber int) (*mcp.CallToolResult, error) {
 	raw, resp, err := client.PullRequests.GetRaw(
 		ctx,
@@ -331,9 +351,10 @@ type reviewCommentNode struct {
 	Author struct {
 		Login githubv4.String
 	}
-	CreatedAt githubv4.DateTime
-	UpdatedAt githubv4.DateTime
-	URL       githubv4.URI
+	AuthorAssociation githubv4.CommentAuthorAssociation
+	CreatedAt         githubv4.DateTime
+	UpdatedAt         githubv4.DateTime
+	URL               githubv4.URI
 }
 
 type pageInfoFragment struct {
@@ -344,12 +365,6 @@ type pageInfoFragment struct {
 }
 
 func GetPullRequestReviewComments(ctx context.Context, gqlClient *githubv4.Client, deps ToolDependencies, owner, repo string, pullNumber int, pagination CursorPaginationParams) (*mcp.CallToolResult, error) {
-	cache, err := deps.GetRepoAccessCache(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get repo access cache: %w", err)
-	}
-	ff := deps.GetFlags(ctx)
-
 	// Convert pagination parameters to GraphQL format
 	gqlParams, err := pagination.ToGraphQLParams()
 	if err != nil {
@@ -381,30 +396,37 @@ func GetPullRequestReviewComments(ctx context.Context, gqlClient *githubv4.Clien
 		), nil
 	}
 
-	// Lockdown mode filtering
-	if ff.LockdownMode {
-		if cache == nil {
-			return nil, fmt.Errorf("lockdown cache is not configured")
+	// Filter the comments of all threads at once, so that their authors are resolved together
+	threads := query.Repository.PullRequest.ReviewThreads.Nodes
+	var comments []*reviewCommentNode
+	for i := range threads {
+		for j := range threads[i].Comments.Nodes {
+			comments = append(comments, &threads[i].Comments.Nodes[j])
 		}
-
-		// Iterate through threads and filter comments
-		for i := range query.Repository.PullRequest.ReviewThreads.Nodes {
-			thread := &query.Repository.PullRequest.ReviewThreads.Nodes[i]
-			filteredComments := make([]reviewCommentNode, 0, len(thread.Comments.Nodes))
-
-			for _, comment := range thread.Comments.Nodes {
-				login := string(comment.Author.Login)
-				if login != "" {
-					isSafeContent, err := cache.IsSafeContent(ctx, login, owner, repo)
-					if err != nil {
-						return nil, fmt.Errorf("failed to check lockdown mode: %w", err)
-					}
-					if isSafeContent {
-						filteredComments = append(filteredComments, comment)
-					}
-				}
+	}
+	comments, err = filterUserContent(ctx, deps, comments, func(comment *reviewCommentNode) contentAuthor {
+		return contentAuthor{Kind: "review comment", Login: string(comment.Author.Login), Association: string(comment.AuthorAssociation), Owner: owner, Repo: repo}
+	}, func(ctx context.Context, comment *reviewCommentNode) {
+		body := string(comment.Body)
+		sanitizeText(ctx, &body)
+		comment.Body = githubv4.String(body)
+	})
+	if err != nil {
+		return utils.NewToolResultError(err.Error()), nil
+	}
+	kept := make(map[*reviewCommentNode]bool, len(comments))
+	for _, comment := range comments {
+		kept[comment] = true
+	}
+	for i := range threads {
+		thread := &threads[i]
+		filteredComments := make([]reviewCommentNode, 0, len(thread.Comments.Nodes))
+		for j := range thread.Comments.Nodes {
+			if kept[&thread.Comments.Nodes[j]] {
+				filteredComments = append(filteredComments, thread.Comments.Nodes[j])
 			}
-
+		}
+		if len(filteredComments) < len(thread.Comments.Nodes) {
 			thread.Comments.Nodes = filteredComments
 			thread.Comments.TotalCount = githubv4.Int(int32(len(filteredComments))) //nolint:gosec // comment count is bounded by API limits
 		}
@@ -431,12 +453,6 @@ func GetPullRequestReviewComments(ctx context.Context, gqlClient *githubv4.Clien
 }
 
 func GetPullRequestReviews(ctx context.Context, client *github.Client, deps ToolDependencies, owner, repo string, pullNumber int) (*mcp.CallToolResult, error) {
-	cache, err := deps.GetRepoAccessCache(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get repo access cache: %w", err)
-	}
-	ff := deps.GetFlags(ctx)
-
 	reviews, resp, err := client.PullRequests.ListReviews(ctx, owner, repo, pullNumber, nil)
 	if err != nil {
 		return ghErrors.NewGitHubAPIErrorResponse(ctx,
@@ -455,24 +471,13 @@ func GetPullRequestReviews(ctx context.Context, client *github.Client, deps Tool
 		return ghErrors.NewGitHubAPIStatusErrorResponse(ctx, "failed to get pull request reviews", resp, body), nil
 	}
 
-	if ff.LockdownMode {
-		if cache == nil {
-			return nil, fmt.Errorf("lockdown cache is not configured")
-		}
-		filteredReviews := make([]*github.PullRequestReview, 0, len(reviews))
-		for _, review := range reviews {
-			login := review.GetUser().GetLogin()
-			if login != "" {
-				isSafeContent, err := cache.IsSafeContent(ctx, login, owner, repo)
-				if err != nil {
-					return nil, fmt.Errorf("failed to check lockdown mode: %w", err)
-				}
-				if isSafeContent {
-					filteredReviews = append(filteredReviews, review)
-				}
-				reviews = filteredReviews
-			}
-		}
+	reviews, err = filterUserContent(ctx, deps, reviews, func(review *github.PullRequestReview) contentAuthor {
+		return contentAuthor{Kind: "review", Login: review.GetUser().GetLogin(), Association: review.GetAuthorAssociation(), Owner: owner, Repo: repo}
+	}, func(ctx context.Context, review *github.PullRequestReview) {
+		sanitizeText(ctx, review.Body)
+	})
+	if err != nil {
+		return utils.NewToolResultError(err.Error()), nil
 	}
 
 	r, err := json.Marshal(reviews)
@@ -1290,17 +1295,9 @@ func ListPullRequests(t translations.TranslationHelperFunc) inventory.ServerTool
 				return ghErrors.NewGitHubAPIStatusErrorResponse(ctx, "failed to list pull requests", resp, bodyBytes), nil, nil
 			}
 
-			// sanitize title/body on each PR
-			for _, pr := range prs {
-				if pr == nil {
-					continue
-				}
-				if pr.Title != nil {
-					pr.Title = github.Ptr(sanitize.Sanitize(*pr.Title))
-				}
-				if pr.Body != nil {
-					pr.Body = github.Ptr(sanitize.Sanitize(*pr.Body))
-				}
+			prs, err = filterUserContent(ctx, deps, prs, pullRequestAuthor(owner, repo), sanitizePullRequest)
+			if err != nil {
+				return utils.NewToolResultError(err.Error()), nil, nil
 			}
 
 			r, err := json.Marshal(prs)
@@ -1478,7 +1475,7 @@ func SearchPullRequests(t translations.TranslationHelperFunc) inventory.ServerTo
 		},
 		[]scopes.Scope{scopes.Repo},
 		func(ctx context.Context, deps ToolDependencies, _ *mcp.CallToolRequest, args map[string]any) (*mcp.CallToolResult, any, error) {
-			result, err := searchHandler(ctx, deps.GetClient, args, "pr", "failed to search pull requests")
+			result, err := searchHandler(ctx, deps, args, "pr", "failed to search pull requests")
 			return result, nil, err
 		})
 }
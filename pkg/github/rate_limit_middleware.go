@@ -0,0 +1,51 @@
+package github
+
+import (
+	"context"
+
+	ghcontext "github.com/github/github-mcp-server/pkg/context"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// RateLimitMetaKey is the _meta key under which RateLimitMetaMiddleware attaches rate
+// limits to tool results.
+const RateLimitMetaKey = "github.com/rate_limit"
+
+// RateLimitMetaMiddleware attaches the GitHub rate limits reported by the API responses of
+// a tool call to its result, keyed by resource such as "core" or "search", so that agents
+// can see how much budget is left. The API clients must record rate limits with
+// transport.RateLimitTransport. Results of tool calls that made no API request are left
+// unchanged.
+func RateLimitMetaMiddleware(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		if method != "tools/call" {
+			return next(ctx, method, req)
+		}
+
+		limits := &ghcontext.RateLimits{}
+		result, err := next(ghcontext.WithRateLimits(ctx, limits), method, req)
+		toolResult, ok := result.(*mcp.CallToolResult)
+		if err != nil || !ok || toolResult == nil {
+			return result, err
+		}
+		resources := limits.Resources()
+		if len(resources) == 0 {
+			return result, err
+		}
+
+		buckets := make(map[string]RateLimitBucket, len(resources))
+		for resource, rate := range resources {
+			buckets[resource] = RateLimitBucket{
+				Limit:     rate.Limit,
+				Remaining: rate.Remaining,
+				Used:      rate.Used,
+				ResetAt:   rate.Reset,
+			}
+		}
+		if toolResult.Meta == nil {
+			toolResult.Meta = mcp.Meta{}
+		}
+		toolResult.Meta[RateLimitMetaKey] = buckets
+		return toolResult, nil
+	}
+}
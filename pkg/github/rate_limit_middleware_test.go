@@ -0,0 +1,77 @@
+package github
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	ghcontext "github.com/github/github-mcp-server/pkg/context"
+	"github.com/github/github-mcp-server/pkg/utils"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimitMetaMiddleware(t *testing.T) {
+	t.Parallel()
+
+	rateLimitHeader := func(resource, remaining string) http.Header {
+		return http.Header{
+			"X-Ratelimit-Resource":  {resource},
+			"X-Ratelimit-Limit":     {"5000"},
+			"X-Ratelimit-Remaining": {remaining},
+			"X-Ratelimit-Used":      {"10"},
+			"X-Ratelimit-Reset":     {"1792324800"},
+		}
+	}
+
+	tests := []struct {
+		name         string
+		method       string
+		responses    []http.Header
+		expectedMeta map[string]RateLimitBucket
+	}{
+		{
+			name:      "latest limit of each resource",
+			method:    "tools/call",
+			responses: []http.Header{rateLimitHeader("core", "4991"), rateLimitHeader("search", "25"), rateLimitHeader("core", "4990")},
+			expectedMeta: map[string]RateLimitBucket{
+				"core":   {Limit: 5000, Remaining: 4990, Used: 10, ResetAt: time.Unix(1792324800, 0).UTC()},
+				"search": {Limit: 5000, Remaining: 25, Used: 10, ResetAt: time.Unix(1792324800, 0).UTC()},
+			},
+		},
+		{
+			name:      "no rate limit headers",
+			method:    "tools/call",
+			responses: []http.Header{{"Content-Type": {"text/plain"}}},
+		},
+		{
+			name:   "other methods",
+			method: "resources/read",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			handler := RateLimitMetaMiddleware(func(ctx context.Context, _ string, _ mcp.Request) (mcp.Result, error) {
+				if limits := ghcontext.GetRateLimits(ctx); limits != nil {
+					for _, header := range tc.responses {
+						limits.Observe(header)
+					}
+				}
+				return utils.NewToolResultText("ok"), nil
+			})
+
+			result, err := handler(context.Background(), tc.method, &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{Name: "search_code"}})
+			require.NoError(t, err)
+			toolResult := result.(*mcp.CallToolResult)
+			if tc.expectedMeta == nil {
+				assert.Nil(t, toolResult.Meta)
+				return
+			}
+			assert.Equal(t, tc.expectedMeta, toolResult.Meta[RateLimitMetaKey])
+		})
+	}
+}
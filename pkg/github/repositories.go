@@ -8,6 +8,8 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"regexp"
+	"strconv"
 	"strings"
 
 	ghErrors "github.com/github/github-mcp-server/pkg/errors"
@@ -105,6 +107,15 @@ func GetCommit(t translations.TranslationHelperFunc) inventory.ServerTool {
 				return ghErrors.NewGitHubAPIStatusErrorResponse(ctx, "failed to get commit", resp, body), nil, nil
 			}
 
+			safe, err := checkUserContent(ctx, deps, commitAuthor(owner, repo)(commit))
+			if err != nil {
+				return utils.NewToolResultError(err.Error()), nil, nil
+			}
+			if !safe {
+				return utils.NewToolResultError("access to commit is restricted by lockdown mode"), nil, nil
+			}
+			sanitizeCommit(ctx, commit)
+
 			// Convert to minimal commit
 			minimalCommit := convertToMinimalCommit(commit, includeDiff)
 
@@ -118,6 +129,114 @@ func GetCommit(t translations.TranslationHelperFunc) inventory.ServerTool {
 	)
 }
 
+// commitAuthor returns the author of commits of a repository. Commits by an email address
+// not linked to an account have no known author.
+func commitAuthor(owner, repo string) func(*github.RepositoryCommit) contentAuthor {
+	return func(commit *github.RepositoryCommit) contentAuthor {
+		return contentAuthor{Kind: "commit", Login: commit.GetAuthor().GetLogin(), Owner: owner, Repo: repo}
+	}
+}
+
+// sanitizeCommit sanitizes the message of a commit, leaving its diff as is.
+func sanitizeCommit(ctx context.Context, commit *github.RepositoryCommit) {
+	if commit.Commit != nil {
+		sanitizeText(ctx, commit.Commit.Message)
+	}
+}
+
+// repositoryAuthor returns the owner of a repository, whose admins write its description and
+// topics. Unlike the content of a repository, they are not written for the repositories the
+// viewer works on, so lockdown mode only trusts those of private repositories, of
+// repositories the viewer can push to, and of the viewer's own.
+func repositoryAuthor(repo *github.Repository) contentAuthor {
+	return contentAuthor{
+		Kind:    "repository",
+		Login:   repo.GetOwner().GetLogin(),
+		Private: repo.GetPrivate() || repo.GetPermissions().GetPush(),
+	}
+}
+
+// sanitizeRepository sanitizes the description and topics of a repository.
+func sanitizeRepository(ctx context.Context, repo *github.Repository) {
+	if repo == nil {
+		return
+	}
+	sanitizeText(ctx, repo.Description)
+	for i := range repo.Topics {
+		sanitizeText(ctx, &repo.Topics[i])
+	}
+}
+
+func starredRepositoryAuthor(starred *github.StarredRepository) contentAuthor {
+	return repositoryAuthor(starred.GetRepository())
+}
+
+func sanitizeStarredRepository(ctx context.Context, starred *github.StarredRepository) {
+	sanitizeRepository(ctx, starred.GetRepository())
+}
+
+// pullRequestRefPattern matches the head and merge refs of pull requests, such as
+// refs/pull/1/head.
+var pullRequestRefPattern = regexp.MustCompile(`^(?:refs/)?pull/(\d+)/`)
+
+// restrictRefContent returns an error result in lockdown mode if the contents of a ref may
+// have been pushed by an untrusted author: the refs of a pull request by one, or a commit
+// by one that isn't on the default branch, such as a commit of a pull request from a fork.
+// Branches and tags can only be pushed by users with push access, so they are trusted.
+// Contents are returned as is otherwise, since sanitizing would alter them.
+func restrictRefContent(ctx context.Context, client *github.Client, deps ToolDependencies, owner, repo, ref string) (*mcp.CallToolResult, error) {
+	if !deps.GetFlags(ctx).LockdownMode {
+		return nil, nil
+	}
+	if match := pullRequestRefPattern.FindStringSubmatch(ref); match != nil {
+		pullNumber, err := strconv.Atoi(match[1])
+		if err != nil {
+			return utils.NewToolResultError(fmt.Sprintf("invalid pull request ref %q", ref)), nil
+		}
+		return restrictPullRequestChanges(ctx, client, deps, owner, repo, pullNumber)
+	}
+	if !looksLikeSHA(ref) {
+		return nil, nil
+	}
+
+	repoInfo, resp, err := client.Repositories.Get(ctx, owner, repo)
+	if err != nil {
+		return ghErrors.NewGitHubAPIErrorResponse(ctx, "failed to get repository info", resp, err), nil
+	}
+	_ = resp.Body.Close()
+	if repoInfo.GetPrivate() {
+		return nil, nil
+	}
+
+	comparison, resp, err := client.Repositories.CompareCommits(ctx, owner, repo, ref, repoInfo.GetDefaultBranch(), &github.ListOptions{PerPage: 1})
+	if err != nil {
+		if resp != nil && (resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusUnprocessableEntity) {
+			// Not a commit, such as the SHA of a tree, whose commit can't be told
+			return utils.NewToolResultError("access to contents by a SHA other than a commit SHA is restricted by lockdown mode"), nil
+		}
+		return ghErrors.NewGitHubAPIErrorResponse(ctx, "failed to compare commit with the default branch", resp, err), nil
+	}
+	_ = resp.Body.Close()
+	if status := comparison.GetStatus(); status == "ahead" || status == "identical" {
+		return nil, nil
+	}
+
+	commit, resp, err := client.Repositories.GetCommit(ctx, owner, repo, ref, &github.ListOptions{PerPage: 1})
+	if err != nil {
+		return ghErrors.NewGitHubAPIErrorResponse(ctx, "failed to get commit", resp, err), nil
+	}
+	_ = resp.Body.Close()
+
+	safe, err := checkUserContent(ctx, deps, commitAuthor(owner, repo)(commit))
+	if err != nil {
+		return utils.NewToolResultError(err.Error()), nil
+	}
+	if !safe {
+		return utils.NewToolResultError("access to commit is restricted by lockdown mode"), nil
+	}
+	return nil, nil
+}
+
 // ListCommits creates a tool to get commits of a branch in a repository.
 func ListCommits(t translations.TranslationHelperFunc) inventory.ServerTool {
 	return NewTool(
@@ -210,6 +329,11 @@ func ListCommits(t translations.TranslationHelperFunc) inventory.ServerTool {
 				return ghErrors.NewGitHubAPIStatusErrorResponse(ctx, "failed to list commits", resp, body), nil, nil
 			}
 
+			commits, err = filterUserContent(ctx, deps, commits, commitAuthor(owner, repo), sanitizeCommit)
+			if err != nil {
+				return utils.NewToolResultError(err.Error()), nil, nil
+			}
+
 			// Convert to minimal commits
 			minimalCommits := make([]MinimalCommit, len(commits))
 			for i, commit := range commits {
@@ -690,6 +814,14 @@ func GetFileContents(t translations.TranslationHelperFunc) inventory.ServerTool
 				return utils.NewToolResultError("failed to get GitHub client"), nil, nil
 			}
 
+			checkedRef := ref
+			if sha != "" {
+				checkedRef = sha
+			}
+			if result, err := restrictRefContent(ctx, client, deps, owner, repo, checkedRef); result != nil || err != nil {
+				return result, nil, err
+			}
+
 			rawOpts, fallbackUsed, err := resolveGitReference(ctx, client, owner, repo, ref, sha)
 			if err != nil {
 				return utils.NewToolResultError(fmt.Sprintf("failed to resolve git reference: %s", err)), nil, nil
@@ -1604,6 +1736,18 @@ func GetTag(t translations.TranslationHelperFunc) inventory.ServerTool {
 	)
 }
 
+// releaseAuthor returns the author of releases of a repository.
+func releaseAuthor(owner, repo string) func(*github.RepositoryRelease) contentAuthor {
+	return func(release *github.RepositoryRelease) contentAuthor {
+		return contentAuthor{Kind: "release", Login: release.GetAuthor().GetLogin(), Owner: owner, Repo: repo}
+	}
+}
+
+// sanitizeRelease sanitizes the name and notes of a release.
+func sanitizeRelease(ctx context.Context, release *github.RepositoryRelease) {
+	sanitizeText(ctx, release.Name, release.Body)
+}
+
 // ListReleases creates a tool to list releases in a GitHub repository.
 func ListReleases(t translations.TranslationHelperFunc) inventory.ServerTool {
 	return NewTool(
@@ -1669,6 +1813,11 @@ func ListReleases(t translations.TranslationHelperFunc) inventory.ServerTool {
 				return ghErrors.NewGitHubAPIStatusErrorResponse(ctx, "failed to list releases", resp, body), nil, nil
 			}
 
+			releases, err = filterUserContent(ctx, deps, releases, releaseAuthor(owner, repo), sanitizeRelease)
+			if err != nil {
+				return utils.NewToolResultError(err.Error()), nil, nil
+			}
+
 			r, err := json.Marshal(releases)
 			if err != nil {
 				return nil, nil, fmt.Errorf("failed to marshal response: %w", err)
@@ -1735,6 +1884,15 @@ func GetLatestRelease(t translations.TranslationHelperFunc) inventory.ServerTool
 				return ghErrors.NewGitHubAPIStatusErrorResponse(ctx, "failed to get latest release", resp, body), nil, nil
 			}
 
+			safe, err := checkUserContent(ctx, deps, releaseAuthor(owner, repo)(release))
+			if err != nil {
+				return utils.NewToolResultError(err.Error()), nil, nil
+			}
+			if !safe {
+				return utils.NewToolResultError("access to release is restricted by lockdown mode"), nil, nil
+			}
+			sanitizeRelease(ctx, release)
+
 			r, err := json.Marshal(release)
 			if err != nil {
 				return nil, nil, fmt.Errorf("failed to marshal response: %w", err)
@@ -1812,6 +1970,15 @@ func GetReleaseByTag(t translations.TranslationHelperFunc) inventory.ServerTool
 				return ghErrors.NewGitHubAPIStatusErrorResponse(ctx, "failed to get release by tag", resp, body), nil, nil
 			}
 
+			safe, err := checkUserContent(ctx, deps, releaseAuthor(owner, repo)(release))
+			if err != nil {
+				return utils.NewToolResultError(err.Error()), nil, nil
+			}
+			if !safe {
+				return utils.NewToolResultError("access to release is restricted by lockdown mode"), nil, nil
+			}
+			sanitizeRelease(ctx, release)
+
 			r, err := json.Marshal(release)
 			if err != nil {
 				return nil, nil, fmt.Errorf("failed to marshal response: %w", err)
@@ -1917,6 +2084,11 @@ func ListStarredRepositories(t translations.TranslationHelperFunc) inventory.Ser
 				return ghErrors.NewGitHubAPIStatusErrorResponse(ctx, "failed to list starred repositories", resp, body), nil, nil
 			}
 
+			repos, err = filterUserContent(ctx, deps, repos, starredRepositoryAuthor, sanitizeStarredRepository)
+			if err != nil {
+				return utils.NewToolResultError(err.Error()), nil, nil
+			}
+
 			// Convert to minimal format
 			minimalRepos := make([]MinimalRepository, 0, len(repos))
 			for _, starredRepo := range repos {
@@ -21,6 +21,7 @@ var RepositoryResourceArgumentResolvers = map[string]CompleteHandler{
 	"sha":      completeSHA,
 	"tag":      completeTag,
 	"prNumber": completePRNumber,
+	"number":   completeIssueNumber,
 	"path":     completePath,
 }
 
@@ -52,6 +53,10 @@ func RepositoryResourceCompletionHandler(getClient GetClientFn) func(ctx context
 		if !ok {
 			return nil, errors.New("no resolver for argument: " + argName)
 		}
+		// pr:// templates share the number argument with issue:// but only complete pull requests
+		if argName == "number" && strings.HasPrefix(req.Params.Ref.URI, "pr://") {
+			resolver = completePRNumber
+		}
 
 		values, err := resolver(ctx, client, resolved, argValue)
 		if err != nil {
@@ -231,6 +236,30 @@ func completePRNumber(ctx context.Context, client *github.Client, resolved map[s
 	return values, nil
 }
 
+func completeIssueNumber(ctx context.Context, client *github.Client, resolved map[string]string, argValue string) ([]string, error) {
+	var values []string
+	owner := resolved["owner"]
+	repo := resolved["repo"]
+	if owner == "" || repo == "" {
+		return values, errors.New("owner or repo not specified")
+	}
+
+	issues, _, err := client.Search.Issues(ctx, fmt.Sprintf("repo:%s/%s is:open is:issue", owner, repo), &github.SearchOptions{ListOptions: github.ListOptions{PerPage: 100}})
+	if err != nil {
+		return values, err
+	}
+	for _, issue := range issues.Issues {
+		num := fmt.Sprintf("%d", issue.GetNumber())
+		if argValue == "" || strings.HasPrefix(num, argValue) {
+			values = append(values, num)
+		}
+	}
+	if len(values) > 100 {
+		values = values[:100]
+	}
+	return values, nil
+}
+
 func completePath(ctx context.Context, client *github.Client, resolved map[string]string, argValue string) ([]string, error) {
 	owner := resolved["owner"]
 	repo := resolved["repo"]
@@ -185,6 +185,20 @@ func TestCompletePRNumber_MissingDependencies(t *testing.T) {
 	assert.Nil(t, result) // Returns nil slice when dependencies are missing
 }
 
+func TestCompleteIssueNumber_MissingDependencies(t *testing.T) {
+	ctx := t.Context()
+
+	resolved := map[string]string{"repo": "testrepo"}
+	result, err := completeIssueNumber(ctx, nil, resolved, "1")
+	require.Error(t, err)
+	assert.Nil(t, result)
+
+	resolved = map[string]string{"owner": "testowner"}
+	result, err = completeIssueNumber(ctx, nil, resolved, "1")
+	require.Error(t, err)
+	assert.Nil(t, result)
+}
+
 func TestCompletePath_MissingDependencies(t *testing.T) {
 	ctx := t.Context()
 
@@ -230,7 +244,7 @@ func TestCompletePath_RefSelection(t *testing.T) {
 func TestRepositoryResourceArgumentResolvers_Existence(t *testing.T) {
 	// Test that all expected resolvers are present
 	expectedResolvers := []string{
-		"owner", "repo", "branch", "sha", "tag", "prNumber", "path",
+		"owner", "repo", "branch", "sha", "tag", "prNumber", "number", "path",
 	}
 
 	for _, resolver := range expectedResolvers {
@@ -0,0 +1,340 @@
+package github
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	ghcontext "github.com/github/github-mcp-server/pkg/context"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/yosida95/uritemplate/v3"
+)
+
+// DefaultResourcePollInterval is how often subscribed resources are checked for changes
+// when no interval is configured.
+const DefaultResourcePollInterval = time.Minute
+
+// ResourceSubscriptionOptions configures resource subscriptions.
+type ResourceSubscriptionOptions struct {
+	// PollInterval is how often each subscribed resource is checked for changes.
+	PollInterval time.Duration
+	// MaxPerSession caps the number of resources a single session may subscribe to.
+	// Zero means no limit.
+	MaxPerSession int
+	// Logger is used to report polling failures.
+	Logger *slog.Logger
+}
+
+// ResourceSubscriptions implements resources/subscribe for repository content, issue and
+// pull request resources. Each subscribed URI is polled with conditional requests, so an
+// unchanged resource costs a 304 response that does not count against the rate limit, and
+// subscribers are sent notifications/resources/updated when its ETag changes.
+//
+// A session may only subscribe to a resource it can read with its own token, and pollers
+// are shared only by sessions subscribing with the same token, so that no poller spends
+// the token of a session that has left.
+type ResourceSubscriptions struct {
+	ctx       context.Context
+	getClient GetClientFn
+	opts      ResourceSubscriptionOptions
+	notify    func(ctx context.Context, uri string)
+
+	mu       sync.Mutex
+	pollers  map[resourcePollerKey]*resourcePoller
+	sessions map[*mcp.ServerSession]map[string]resourcePollerKey
+}
+
+// resourcePollerKey identifies the poller of a URI for the sessions sharing a token.
+type resourcePollerKey struct {
+	uri   string
+	token [sha256.Size]byte
+}
+
+type resourcePoller struct {
+	cancel      context.CancelFunc
+	subscribers int
+}
+
+// NewResourceSubscriptions creates a subscription manager. Pollers run until ctx is done or
+// their last subscriber leaves. Call Attach to deliver notifications through a server.
+func NewResourceSubscriptions(ctx context.Context, getClient GetClientFn, opts ResourceSubscriptionOptions) *ResourceSubscriptions {
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = DefaultResourcePollInterval
+	}
+	if opts.Logger == nil {
+		opts.Logger = slog.Default()
+	}
+	return &ResourceSubscriptions{
+		ctx:       ctx,
+		getClient: getClient,
+		opts:      opts,
+		notify:    func(context.Context, string) {},
+		pollers:   make(map[resourcePollerKey]*resourcePoller),
+		sessions:  make(map[*mcp.ServerSession]map[string]resourcePollerKey),
+	}
+}
+
+// Attach sends resource update notifications to the subscribers of server.
+func (s *ResourceSubscriptions) Attach(server *mcp.Server) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.notify = func(ctx context.Context, uri string) {
+		_ = server.ResourceUpdated(ctx, &mcp.ResourceUpdatedNotificationParams{URI: uri})
+	}
+}
+
+// Subscribe is the mcp.ServerOptions.SubscribeHandler. The resource is fetched with the
+// session's token first, so that sessions can't follow resources they can't read.
+func (s *ResourceSubscriptions) Subscribe(ctx context.Context, req *mcp.SubscribeRequest) error {
+	uri := req.Params.URI
+	apiPath, err := resourcePollPath(uri)
+	if err != nil {
+		return err
+	}
+	if subscribed, err := s.checkSubscription(req.Session, uri); subscribed || err != nil {
+		return err
+	}
+
+	// Not holding s.mu, so that a slow request doesn't stall other sessions
+	if _, err := s.fetchETag(ctx, apiPath, ""); err != nil {
+		return fmt.Errorf("cannot subscribe to %s: %w", uri, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Another request of the session may have subscribed in the meantime
+	if subscribed, err := s.checkSubscriptionLocked(req.Session, uri); subscribed || err != nil {
+		return err
+	}
+	subscribed := s.sessions[req.Session]
+	if subscribed == nil {
+		subscribed = make(map[string]resourcePollerKey)
+		s.sessions[req.Session] = subscribed
+		if req.Session != nil {
+			go s.releaseOnClose(req.Session)
+		}
+	}
+	key := resourcePollerKey{uri: uri, token: tokenHash(ctx)}
+	subscribed[uri] = key
+
+	poller, ok := s.pollers[key]
+	if !ok {
+		// The poller calls GitHub with the values of the subscribe request, such as the
+		// token of an HTTP session, but lives until s.ctx is done or it is released
+		pollCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		stop := context.AfterFunc(s.ctx, cancel)
+		poller = &resourcePoller{cancel: func() {
+			stop()
+			cancel()
+		}}
+		s.pollers[key] = poller
+		go s.poll(pollCtx, uri, apiPath)
+	}
+	poller.subscribers++
+	return nil
+}
+
+// checkSubscription reports whether session is already subscribed to uri, or an error if
+// it may not subscribe to another resource.
+func (s *ResourceSubscriptions) checkSubscription(session *mcp.ServerSession, uri string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.checkSubscriptionLocked(session, uri)
+}
+
+// checkSubscriptionLocked is checkSubscription with s.mu held.
+func (s *ResourceSubscriptions) checkSubscriptionLocked(session *mcp.ServerSession, uri string) (bool, error) {
+	subscribed := s.sessions[session]
+	if _, ok := subscribed[uri]; ok {
+		return true, nil
+	}
+	if s.opts.MaxPerSession > 0 && len(subscribed) >= s.opts.MaxPerSession {
+		return false, fmt.Errorf("subscription limit reached: a session may subscribe to at most %d resources", s.opts.MaxPerSession)
+	}
+	return false, nil
+}
+
+// Unsubscribe is the mcp.ServerOptions.UnsubscribeHandler.
+func (s *ResourceSubscriptions) Unsubscribe(_ context.Context, req *mcp.UnsubscribeRequest) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	subscribed := s.sessions[req.Session]
+	key, ok := subscribed[req.Params.URI]
+	if !ok {
+		return nil
+	}
+	delete(subscribed, req.Params.URI)
+	if len(subscribed) == 0 {
+		delete(s.sessions, req.Session)
+	}
+	s.release(key)
+	return nil
+}
+
+// releaseOnClose drops all subscriptions held by a session once it disconnects.
+func (s *ResourceSubscriptions) releaseOnClose(session *mcp.ServerSession) {
+	_ = session.Wait()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, key := range s.sessions[session] {
+		s.release(key)
+	}
+	delete(s.sessions, session)
+}
+
+// release removes a subscriber from a poller, stopping it if none remain. s.mu must be held.
+func (s *ResourceSubscriptions) release(key resourcePollerKey) {
+	poller, ok := s.pollers[key]
+	if !ok {
+		return
+	}
+	poller.subscribers--
+	if poller.subscribers <= 0 {
+		poller.cancel()
+		delete(s.pollers, key)
+	}
+}
+
+// tokenHash identifies the token GitHub is called with in ctx. Without one, as in stdio
+// mode, all sessions share the server's credentials.
+func tokenHash(ctx context.Context) [sha256.Size]byte {
+	var token string
+	if tokenInfo, ok := ghcontext.GetTokenInfo(ctx); ok && tokenInfo != nil {
+		token = tokenInfo.Token
+	}
+	return sha256.Sum256([]byte(token))
+}
+
+func (s *ResourceSubscriptions) poll(ctx context.Context, uri, apiPath string) {
+	ticker := time.NewTicker(s.opts.PollInterval)
+	defer ticker.Stop()
+
+	var etag string
+	for {
+		current, err := s.fetchETag(ctx, apiPath, etag)
+		switch {
+		case ctx.Err() != nil:
+			return
+		case err != nil:
+			s.opts.Logger.Warn("failed to poll subscribed resource", "uri", uri, "error", err)
+		default:
+			// The first successful poll only establishes the baseline
+			if etag != "" && current != etag {
+				s.mu.Lock()
+				notify := s.notify
+				s.mu.Unlock()
+				notify(ctx, uri)
+			}
+			etag = current
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// fetchETag returns the current ETag of the API resource at apiPath. If it still matches
+// etag, GitHub answers 304 Not Modified and etag is returned unchanged.
+func (s *ResourceSubscriptions) fetchETag(ctx context.Context, apiPath, etag string) (string, error) {
+	client, err := s.getClient(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to get GitHub client: %w", err)
+	}
+
+	req, err := client.NewRequest(http.MethodGet, apiPath, nil)
+	if err != nil {
+		return "", err
+	}
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+
+	resp, err := client.Client().Do(req.WithContext(ctx))
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	switch resp.StatusCode {
+	case http.StatusNotModified:
+		return etag, nil
+	case http.StatusOK:
+		return resp.Header.Get("ETag"), nil
+	default:
+		return "", fmt.Errorf("unexpected status polling %s: %s", apiPath, resp.Status)
+	}
+}
+
+// resourcePollPath maps a subscribable resource URI to the REST API path whose ETag changes
+// along with the resource.
+func resourcePollPath(uri string) (string, error) {
+	matchers := []struct {
+		template *uritemplate.Template
+		path     func(owner, repo string, values uritemplate.Values) string
+	}{
+		{issueResourceURITemplate, func(owner, repo string, v uritemplate.Values) string {
+			// New comments bump the issue's updated_at, and with it the ETag
+			return fmt.Sprintf("repos/%s/%s/issues/%s", owner, repo, url.PathEscape(v.Get("number").String()))
+		}},
+		{pullRequestResourceURITemplate, pullRequestPollPath("number")},
+		{pullRequestDiffResourceURITemplate, pullRequestPollPath("number")},
+		{repositoryResourcePrContentURITemplate, pullRequestPollPath("prNumber")},
+		{repositoryResourceContentURITemplate, contentsPollPath("", "")},
+		{repositoryResourceBranchContentURITemplate, contentsPollPath("branch", "refs/heads/")},
+		{repositoryResourceTagContentURITemplate, contentsPollPath("tag", "refs/tags/")},
+		{repositoryResourceCommitContentURITemplate, contentsPollPath("sha", "")},
+	}
+
+	for _, m := range matchers {
+		values := m.template.Match(uri)
+		if values == nil {
+			continue
+		}
+		owner := values.Get("owner").String()
+		repo := values.Get("repo").String()
+		if owner == "" || repo == "" {
+			return "", fmt.Errorf("owner and repo are required: %s", uri)
+		}
+		return m.path(url.PathEscape(owner), url.PathEscape(repo), values), nil
+	}
+	return "", fmt.Errorf("resource does not support subscriptions: %s", uri)
+}
+
+func pullRequestPollPath(numberVar string) func(owner, repo string, values uritemplate.Values) string {
+	return func(owner, repo string, values uritemplate.Values) string {
+		return fmt.Sprintf("repos/%s/%s/pulls/%s", owner, repo, url.PathEscape(values.Get(numberVar).String()))
+	}
+}
+
+func contentsPollPath(refVar, refPrefix string) func(owner, repo string, values uritemplate.Values) string {
+	return func(owner, repo string, values uritemplate.Values) string {
+		pathValue := values.Get("path")
+		segments := pathValue.List()
+		if len(segments) == 0 && pathValue.String() != "" {
+			segments = strings.Split(pathValue.String(), "/")
+		}
+		for i, segment := range segments {
+			segments[i] = url.PathEscape(segment)
+		}
+
+		apiPath := fmt.Sprintf("repos/%s/%s/contents/%s", owner, repo, strings.Join(segments, "/"))
+		if refVar != "" {
+			apiPath += "?ref=" + url.QueryEscape(refPrefix+values.Get(refVar).String())
+		}
+		return apiPath
+	}
+}
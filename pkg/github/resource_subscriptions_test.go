@@ -0,0 +1,272 @@
+package github
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	ghcontext "github.com/github/github-mcp-server/pkg/context"
+	gogithub "github.com/google/go-github/v82/github"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_resourcePollPath(t *testing.T) {
+	tests := []struct {
+		uri          string
+		expectedPath string
+		expectError  string
+	}{
+		{uri: "issue://owner/repo/42", expectedPath: "repos/owner/repo/issues/42"},
+		{uri: "pr://owner/repo/7", expectedPath: "repos/owner/repo/pulls/7"},
+		{uri: "pr://owner/repo/7/diff", expectedPath: "repos/owner/repo/pulls/7"},
+		{uri: "repo://owner/repo/refs/pull/7/head/contents/main.go", expectedPath: "repos/owner/repo/pulls/7"},
+		{uri: "repo://owner/repo/contents/docs/README.md", expectedPath: "repos/owner/repo/contents/docs/README.md"},
+		{uri: "repo://owner/repo/refs/heads/main/contents/go.mod", expectedPath: "repos/owner/repo/contents/go.mod?ref=refs%2Fheads%2Fmain"},
+		{uri: "repo://owner/repo/refs/tags/v1.0.0/contents/go.mod", expectedPath: "repos/owner/repo/contents/go.mod?ref=refs%2Ftags%2Fv1.0.0"},
+		{uri: "repo://owner/repo/sha/abc123/contents/go.mod", expectedPath: "repos/owner/repo/contents/go.mod?ref=abc123"},
+		{uri: "issue:///repo/42", expectError: "owner and repo are required"},
+		{uri: "ui://get_me/app.html", expectError: "resource does not support subscriptions"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.uri, func(t *testing.T) {
+			path, err := resourcePollPath(tc.uri)
+			if tc.expectError != "" {
+				require.ErrorContains(t, err, tc.expectError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedPath, path)
+		})
+	}
+}
+
+// etagBackend serves an issue whose ETag can be changed by the test, answering
+// conditional requests with 304 Not Modified while it is unchanged.
+type etagBackend struct {
+	mu    sync.Mutex
+	etag  string
+	polls int
+}
+
+func (b *etagBackend) setETag(etag string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.etag = etag
+}
+
+func (b *etagBackend) handler(w http.ResponseWriter, r *http.Request) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.polls++
+	if r.Header.Get("If-None-Match") == b.etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("ETag", b.etag)
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte(`{"number": 42}`))
+}
+
+func TestResourceSubscriptions_Limits(t *testing.T) {
+	backend := &etagBackend{etag: `"a"`}
+	deps := stubDeps{clientFn: stubClientFnFromHTTP(MockHTTPClientWithHandlers(map[string]http.HandlerFunc{
+		GetReposIssuesByOwnerByRepoByIssueNumber: backend.handler,
+		GetReposPullsByOwnerByRepoByPullNumber:   backend.handler,
+	}))}
+
+	subs := NewResourceSubscriptions(t.Context(), deps.GetClient, ResourceSubscriptionOptions{
+		PollInterval:  time.Hour,
+		MaxPerSession: 2,
+	})
+
+	subscribe := func(uri string) error {
+		return subs.Subscribe(t.Context(), &mcp.SubscribeRequest{Params: &mcp.SubscribeParams{URI: uri}})
+	}
+	unsubscribe := func(uri string) error {
+		return subs.Unsubscribe(t.Context(), &mcp.UnsubscribeRequest{Params: &mcp.UnsubscribeParams{URI: uri}})
+	}
+
+	require.NoError(t, subscribe("issue://owner/repo/42"))
+	// Subscribing twice to the same resource does not count against the limit
+	require.NoError(t, subscribe("issue://owner/repo/42"))
+	require.NoError(t, subscribe("pr://owner/repo/7"))
+	require.ErrorContains(t, subscribe("pr://owner/repo/8"), "subscription limit reached")
+	require.ErrorContains(t, subscribe("ui://get_me/app.html"), "does not support subscriptions")
+
+	subs.mu.Lock()
+	assert.Len(t, subs.pollers, 2)
+	subs.mu.Unlock()
+
+	require.NoError(t, unsubscribe("issue://owner/repo/42"))
+	require.NoError(t, unsubscribe("issue://owner/repo/42"))
+	require.NoError(t, subscribe("pr://owner/repo/8"))
+
+	subs.mu.Lock()
+	assert.Len(t, subs.pollers, 2)
+	assert.NotContains(t, subs.pollers, resourcePollerKey{uri: "issue://owner/repo/42", token: tokenHash(t.Context())})
+	subs.mu.Unlock()
+}
+
+func TestResourceSubscriptions_NotifiesSubscribers(t *testing.T) {
+	backend := &etagBackend{etag: `"a"`}
+	deps := stubDeps{clientFn: stubClientFnFromHTTP(MockHTTPClientWithHandlers(map[string]http.HandlerFunc{
+		GetReposIssuesByOwnerByRepoByIssueNumber: backend.handler,
+	}))}
+
+	subs := NewResourceSubscriptions(t.Context(), deps.GetClient, ResourceSubscriptionOptions{
+		PollInterval: 10 * time.Millisecond,
+	})
+	server := mcp.NewServer(&mcp.Implementation{Name: "test"}, &mcp.ServerOptions{
+		SubscribeHandler:   subs.Subscribe,
+		UnsubscribeHandler: subs.Unsubscribe,
+	})
+	issueResource := GetIssueResource(stubTranslation)
+	server.AddResourceTemplate(&issueResource.Template, IssueResourceHandler(issueResourceURITemplate))
+	subs.Attach(server)
+
+	updated := make(chan string, 10)
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client"}, &mcp.ClientOptions{
+		ResourceUpdatedHandler: func(_ context.Context, req *mcp.ResourceUpdatedNotificationRequest) {
+			updated <- req.Params.URI
+		},
+	})
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := server.Connect(t.Context(), serverTransport, nil)
+	require.NoError(t, err)
+	clientSession, err := client.Connect(t.Context(), clientTransport, nil)
+	require.NoError(t, err)
+
+	require.NotNil(t, clientSession.InitializeResult().Capabilities.Resources)
+	assert.True(t, clientSession.InitializeResult().Capabilities.Resources.Subscribe)
+	require.NoError(t, clientSession.Subscribe(t.Context(), &mcp.SubscribeParams{URI: "issue://owner/repo/42"}))
+
+	// Wait for the baseline poll, then change the resource
+	require.Eventually(t, func() bool {
+		backend.mu.Lock()
+		defer backend.mu.Unlock()
+		return backend.polls >= 2
+	}, time.Second, 5*time.Millisecond)
+	select {
+	case uri := <-updated:
+		t.Fatalf("unexpected update for unchanged resource %s", uri)
+	default:
+	}
+
+	backend.setETag(`"b"`)
+	select {
+	case uri := <-updated:
+		assert.Equal(t, "issue://owner/repo/42", uri)
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for resource update notification")
+	}
+
+	// Disconnecting the session stops its pollers
+	require.NoError(t, clientSession.Close())
+	_ = serverSession.Wait()
+	require.Eventually(t, func() bool {
+		subs.mu.Lock()
+		defer subs.mu.Unlock()
+		return len(subs.pollers) == 0 && len(subs.sessions) == 0
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestResourceSubscriptions_RequireReadAccess(t *testing.T) {
+	var mu sync.Mutex
+	var polledWith []string
+	httpClient := MockHTTPClientWithHandlers(map[string]http.HandlerFunc{
+		GetReposIssuesByOwnerByRepoByIssueNumber: func(w http.ResponseWriter, r *http.Request) {
+			authorization := r.Header.Get("Authorization")
+			mu.Lock()
+			polledWith = append(polledWith, authorization)
+			mu.Unlock()
+			if authorization == "Bearer ghp_bob" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Header().Set("ETag", `"a"`)
+			_, _ = w.Write([]byte(`{"number": 42}`))
+		},
+	})
+	getClient := func(ctx context.Context) (*gogithub.Client, error) {
+		tokenInfo, _ := ghcontext.GetTokenInfo(ctx)
+		return gogithub.NewClient(httpClient).WithAuthToken(tokenInfo.Token), nil
+	}
+
+	subs := NewResourceSubscriptions(t.Context(), getClient, ResourceSubscriptionOptions{
+		PollInterval: 10 * time.Millisecond,
+	})
+	server := mcp.NewServer(&mcp.Implementation{Name: "test"}, &mcp.ServerOptions{
+		SubscribeHandler:   subs.Subscribe,
+		UnsubscribeHandler: subs.Unsubscribe,
+	})
+	subs.Attach(server)
+
+	// Each session calls GitHub with the token it was connected with, as in HTTP mode
+	var tokensMu sync.Mutex
+	tokens := make(map[*mcp.ServerSession]string)
+	server.AddReceivingMiddleware(func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			session, _ := req.GetSession().(*mcp.ServerSession)
+			tokensMu.Lock()
+			token := tokens[session]
+			tokensMu.Unlock()
+			return next(ghcontext.WithTokenInfo(ctx, &ghcontext.TokenInfo{Token: token}), method, req)
+		}
+	})
+	connect := func(token string) *mcp.ClientSession {
+		serverTransport, clientTransport := mcp.NewInMemoryTransports()
+		serverSession, err := server.Connect(t.Context(), serverTransport, nil)
+		require.NoError(t, err)
+		tokensMu.Lock()
+		tokens[serverSession] = token
+		tokensMu.Unlock()
+		clientSession, err := mcp.NewClient(&mcp.Implementation{Name: "test-client"}, nil).Connect(t.Context(), clientTransport, nil)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = clientSession.Close() })
+		return clientSession
+	}
+	subscribe := func(session *mcp.ClientSession) error {
+		return session.Subscribe(t.Context(), &mcp.SubscribeParams{URI: "issue://owner/repo/42"})
+	}
+	pollers := func() int {
+		subs.mu.Lock()
+		defer subs.mu.Unlock()
+		return len(subs.pollers)
+	}
+
+	alice := connect("ghp_alice")
+	require.NoError(t, subscribe(alice))
+
+	// A session that can't read the resource is refused, instead of sharing another's poller
+	require.ErrorContains(t, subscribe(connect("ghp_bob")), "cannot subscribe to issue://owner/repo/42")
+	assert.Equal(t, 1, pollers())
+
+	// Sessions with the same token share a poller, sessions with another token get their own
+	alice2 := connect("ghp_alice")
+	require.NoError(t, subscribe(alice2))
+	assert.Equal(t, 1, pollers())
+	require.NoError(t, subscribe(connect("ghp_carol")))
+	assert.Equal(t, 2, pollers())
+
+	// Once alice's sessions are gone, her token is no longer spent
+	require.NoError(t, alice.Close())
+	require.NoError(t, alice2.Close())
+	require.Eventually(t, func() bool { return pollers() == 1 }, time.Second, 5*time.Millisecond)
+	mu.Lock()
+	polledWith = nil
+	mu.Unlock()
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(polledWith) >= 2
+	}, time.Second, 5*time.Millisecond)
+	mu.Lock()
+	assert.NotContains(t, polledWith, "Bearer ghp_alice")
+	mu.Unlock()
+}
@@ -15,5 +15,10 @@ func AllResources(t translations.TranslationHelperFunc) []inventory.ServerResour
 		GetRepositoryResourceCommitContent(t),
 		GetRepositoryResourceTagContent(t),
 		GetRepositoryResourcePrContent(t),
+
+		// Issue and pull request resources
+		GetIssueResource(t),
+		GetPullRequestResource(t),
+		GetPullRequestDiffResource(t),
 	}
 }
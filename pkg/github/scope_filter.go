@@ -62,3 +62,15 @@ func CreateToolScopeFilter(tokenScopes []string) inventory.ToolFilter {
 		return scopes.HasRequiredScopes(tokenScopes, tool.AcceptedScopes), nil
 	}
 }
+
+// CreateToolPermissionFilter creates an inventory.ToolFilter that filters tools based on
+// the fine-grained permissions of a token, as determined by scopes.PermissionProber.
+//
+// Fine-grained personal access tokens have no OAuth scopes, so CreateToolScopeFilter can't
+// filter for them. Instead, tools are hidden when a permission they need is known to be
+// missing. Tools whose permissions are unknown, or could not be probed, remain visible.
+func CreateToolPermissionFilter(permissions scopes.PermissionSet) inventory.ToolFilter {
+	return func(_ context.Context, tool *inventory.ServerTool) (bool, error) {
+		return permissions.Allows(scopes.ToolPermissions(tool)...), nil
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"testing"
 
 	"github.com/github/github-mcp-server/pkg/inventory"
+	"github.com/github/github-mcp-server/pkg/scopes"
+	"github.com/github/github-mcp-server/pkg/translations"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -189,3 +191,32 @@ func TestCreateToolScopeFilter_Integration(t *testing.T) {
 	assert.Contains(t, toolNames, "repo_tool")
 	assert.NotContains(t, toolNames, "gist_tool")
 }
+
+func TestCreateToolPermissionFilter(t *testing.T) {
+	// A token that can read but not write issues, and whose pull request access is unknown
+	filter := CreateToolPermissionFilter(scopes.PermissionSet{
+		scopes.IssuesRead:         true,
+		scopes.IssuesWrite:        false,
+		scopes.NotificationsRead:  false,
+		scopes.NotificationsWrite: false,
+	})
+
+	inv, err := NewInventory(translations.NullTranslationHelper).
+		WithToolsets([]string{"issues", "pull_requests", "notifications", "context"}).
+		WithFilter(filter).
+		Build()
+	require.NoError(t, err)
+
+	toolNames := make(map[string]bool)
+	for _, tool := range inv.AvailableTools(context.Background()) {
+		toolNames[tool.Tool.Name] = true
+	}
+
+	assert.True(t, toolNames["issue_read"], "tools needing granted permissions are visible")
+	assert.True(t, toolNames["list_issues"])
+	assert.False(t, toolNames["issue_write"], "tools needing missing permissions are hidden")
+	assert.False(t, toolNames["add_issue_comment"])
+	assert.False(t, toolNames["list_notifications"])
+	assert.True(t, toolNames["create_pull_request"], "tools needing unknown permissions are visible")
+	assert.True(t, toolNames["get_me"], "tools needing no permissions are visible")
+}
@@ -110,6 +110,11 @@ func SearchRepositories(t translations.TranslationHelperFunc) inventory.ServerTo
 				return ghErrors.NewGitHubAPIStatusErrorResponse(ctx, "failed to search repositories", resp, body), nil, nil
 			}
 
+			result.Repositories, err = filterUserContent(ctx, deps, result.Repositories, repositoryAuthor, sanitizeRepository)
+			if err != nil {
+				return utils.NewToolResultError(err.Error()), nil, nil
+			}
+
 			// Return either minimal or full response based on parameter
 			var r []byte
 			if minimalOutput {
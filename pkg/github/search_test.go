@@ -228,6 +228,38 @@ func Test_SearchRepositories_FullOutput(t *testing.T) {
 	assert.Equal(t, *mockSearchResult.Repositories[0].Name, *returnedResult.Repositories[0].Name)
 }
 
+func Test_SearchRepositories_SanitizesMetadata(t *testing.T) {
+	mockedClient := MockHTTPClientWithHandlers(map[string]http.HandlerFunc{
+		GetSearchRepositories: mockResponse(t, http.StatusOK, &github.RepositoriesSearchResult{
+			Total: github.Ptr(1),
+			Repositories: []*github.Repository{
+				{
+					ID:          github.Ptr(int64(12345)),
+					Description: github.Ptr("A CLI<!-- hidden instructions -->"),
+					Topics:      []string{"cli<!-- hidden topic -->"},
+				},
+			},
+		}),
+	})
+
+	deps := BaseDeps{
+		Client: github.NewClient(mockedClient),
+	}
+	serverTool := SearchRepositories(translations.NullTranslationHelper)
+	handler := serverTool.Handler(deps)
+
+	request := createMCPRequest(map[string]any{"query": "cli"})
+	result, err := handler(ContextWithDeps(context.Background(), deps), &request)
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	var returnedResult MinimalSearchRepositoriesResult
+	require.NoError(t, json.Unmarshal([]byte(getTextResult(t, result).Text), &returnedResult))
+	require.Len(t, returnedResult.Items, 1)
+	assert.Equal(t, "A CLI", returnedResult.Items[0].Description)
+	assert.Equal(t, []string{"cli"}, returnedResult.Items[0].Topics)
+}
+
 func Test_SearchCode(t *testing.T) {
 	// Verify tool definition once
 	serverTool := SearchCode(translations.NullTranslationHelper)
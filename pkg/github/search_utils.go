@@ -39,7 +39,7 @@ func hasTypeFilter(query string) bool {
 
 func searchHandler(
 	ctx context.Context,
-	getClient GetClientFn,
+	deps ToolDependencies,
 	args map[string]any,
 	searchType string,
 	errorPrefix string,
@@ -90,7 +90,7 @@ func searchHandler(
 		},
 	}
 
-	client, err := getClient(ctx)
+	client, err := deps.GetClient(ctx)
 	if err != nil {
 		return utils.NewToolResultErrorFromErr(errorPrefix+": failed to get GitHub client", err), nil
 	}
@@ -108,6 +108,11 @@ func searchHandler(
 		return ghErrors.NewGitHubAPIStatusErrorResponse(ctx, errorPrefix, resp, body), nil
 	}
 
+	result.Issues, err = filterUserContent(ctx, deps, result.Issues, searchIssueAuthor, sanitizeIssue)
+	if err != nil {
+		return utils.NewToolResultError(err.Error()), nil
+	}
+
 	r, err := json.Marshal(result)
 	if err != nil {
 		return utils.NewToolResultErrorFromErr(errorPrefix+": failed to marshal response", err), nil
@@ -115,3 +120,14 @@ func searchHandler(
 
 	return utils.NewToolResultText(string(r)), nil
 }
+
+// searchIssueAuthor returns the author of an issue or pull request found by a search, whose
+// repository is only given by its API URL.
+func searchIssueAuthor(issue *github.Issue) contentAuthor {
+	owner, repo := repoFromAPIURL(issue.GetRepositoryURL())
+	kind := "issue"
+	if issue.IsPullRequest() {
+		kind = "pull request"
+	}
+	return contentAuthor{Kind: kind, Login: issue.GetUser().GetLogin(), Association: issue.GetAuthorAssociation(), Owner: owner, Repo: repo}
+}
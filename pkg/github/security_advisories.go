@@ -208,6 +208,28 @@ func ListGlobalSecurityAdvisories(t translations.TranslationHelperFunc) inventor
 	)
 }
 
+// repositoryAdvisoryAuthor returns the author of repository security advisories: their
+// publisher once published, or whoever drafted or reported them otherwise. Advisories listed
+// across repositories, with owner and repo empty, are checked against their own repository.
+func repositoryAdvisoryAuthor(owner, repo string) func(*github.SecurityAdvisory) contentAuthor {
+	return func(advisory *github.SecurityAdvisory) contentAuthor {
+		author := contentAuthor{Kind: "security advisory", Login: advisory.GetAuthor().GetLogin(), Owner: owner, Repo: repo}
+		if login := advisory.GetPublisher().GetLogin(); login != "" {
+			author.Login = login
+		}
+		if author.Repo == "" {
+			author.Owner, author.Repo = repoFromAPIURL(advisory.GetURL())
+		}
+		return author
+	}
+}
+
+// sanitizeRepositoryAdvisory sanitizes the summary and description of a repository security
+// advisory.
+func sanitizeRepositoryAdvisory(ctx context.Context, advisory *github.SecurityAdvisory) {
+	sanitizeText(ctx, advisory.Summary, advisory.Description)
+}
+
 func ListRepositorySecurityAdvisories(t translations.TranslationHelperFunc) inventory.ServerTool {
 	return NewTool(
 		ToolsetMetadataSecurityAdvisories,
@@ -302,6 +324,11 @@ func ListRepositorySecurityAdvisories(t translations.TranslationHelperFunc) inve
 				return ghErrors.NewGitHubAPIStatusErrorResponse(ctx, "failed to list repository advisories", resp, body), nil, nil
 			}
 
+			advisories, err = filterUserContent(ctx, deps, advisories, repositoryAdvisoryAuthor(owner, repo), sanitizeRepositoryAdvisory)
+			if err != nil {
+				return utils.NewToolResultError(err.Error()), nil, nil
+			}
+
 			r, err := json.Marshal(advisories)
 			if err != nil {
 				return nil, nil, fmt.Errorf("failed to marshal advisories: %w", err)
@@ -454,6 +481,11 @@ func ListOrgRepositorySecurityAdvisories(t translations.TranslationHelperFunc) i
 				return ghErrors.NewGitHubAPIStatusErrorResponse(ctx, "failed to list organization repository advisories", resp, body), nil, nil
 			}
 
+			advisories, err = filterUserContent(ctx, deps, advisories, repositoryAdvisoryAuthor("", ""), sanitizeRepositoryAdvisory)
+			if err != nil {
+				return utils.NewToolResultError(err.Error()), nil, nil
+			}
+
 			r, err := json.Marshal(advisories)
 			if err != nil {
 				return nil, nil, fmt.Errorf("failed to marshal advisories: %w", err)
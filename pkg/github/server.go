@@ -6,15 +6,26 @@ import (
 	"fmt"
 	"log/slog"
 	"strings"
+	"sync/atomic"
 	"time"
 
+	"github.com/github/github-mcp-server/pkg/audit"
+	ghcontext "github.com/github/github-mcp-server/pkg/context"
 	gherrors "github.com/github/github-mcp-server/pkg/errors"
 	"github.com/github/github-mcp-server/pkg/git"
+	"github.com/github/github-mcp-server/pkg/http/transport"
 	"github.com/github/github-mcp-server/pkg/inventory"
+	"github.com/github/github-mcp-server/pkg/lockdown"
+	"github.com/github/github-mcp-server/pkg/metrics"
 	"github.com/github/github-mcp-server/pkg/octicons"
+	"github.com/github/github-mcp-server/pkg/sanitize"
+	"github.com/github/github-mcp-server/pkg/scopes"
+	"github.com/github/github-mcp-server/pkg/tokensource"
+	"github.com/github/github-mcp-server/pkg/tracing"
 	"github.com/github/github-mcp-server/pkg/translations"
 	"github.com/github/github-mcp-server/pkg/utils"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type MCPServerConfig struct {
@@ -27,6 +38,10 @@ type MCPServerConfig struct {
 	// GitHub Token to authenticate with the GitHub API
 	Token string
 
+	// TokenSource provides tokens that change over time, such as GitHub App installation
+	// tokens. It takes precedence over Token when set.
+	TokenSource tokensource.TokenSource
+
 	// EnabledToolsets is a list of toolsets to enable
 	// See: https://github.com/github/github-mcp-server?tab=readme-ov-file#tool-configuration
 	EnabledToolsets []string
@@ -55,6 +70,10 @@ type MCPServerConfig struct {
 	// LockdownMode indicates if we should enable lockdown mode
 	LockdownMode bool
 
+	// StrictToolAliases rejects calls made through deprecated tool aliases instead of
+	// routing them to the replacement tool with a deprecation notice.
+	StrictToolAliases bool
+
 	// InsidersMode indicates if we should enable experimental features
 	InsidersMode bool
 
@@ -63,11 +82,67 @@ type MCPServerConfig struct {
 	// RepoAccessTTL overrides the default TTL for repository access cache entries.
 	RepoAccessTTL *time.Duration
 
+	// RepoAccessStore keeps the repository access cache. Defaults to an in-memory store.
+	RepoAccessStore lockdown.RepoAccessStore
+
+	// LockdownPolicy extends the content trusted in lockdown mode
+	LockdownPolicy *lockdown.Policy
+
+	// LockdownPlaceholders lists a placeholder for every item lockdown mode hides in the
+	// note added to tool results, instead of only counting them.
+	LockdownPlaceholders bool
+
+	// InjectionDetector marks the user-authored text of tool results that looks like a
+	// prompt injection. Defaults to sanitize.DefaultInjectionDetector.
+	InjectionDetector *sanitize.InjectionDetector
+
+	// DisableInjectionMarkers leaves user-authored text that looks like a prompt injection
+	// unmarked, and results without the InjectionMetaKey warning.
+	DisableInjectionMarkers bool
+
 	// TokenScopes contains the OAuth scopes available to the token.
 	// When non-nil, tools requiring scopes not in this list will be hidden.
 	// This is used for PAT scope filtering where we can't issue scope challenges.
 	TokenScopes []string
 
+	// TokenPermissions contains the fine-grained permissions known to be granted to or
+	// missing from the token. When non-nil, tools needing a missing permission are hidden.
+	TokenPermissions scopes.PermissionSet
+
+	// Prompts are registered in addition to the built-in prompts, for example
+	// user-defined prompts loaded with LoadPromptLibrary.
+	Prompts []inventory.ServerPrompt
+
+	// ResourcePollInterval enables resources/subscribe when positive. Subscribed
+	// resources are polled for changes at this interval.
+	ResourcePollInterval time.Duration
+
+	// MaxResourceSubscriptions caps the number of resources a single session may
+	// subscribe to. Zero means no limit.
+	MaxResourceSubscriptions int
+
+	// Metrics records request, tool call and GitHub API metrics when set.
+	Metrics *metrics.Metrics
+
+	// TracerProvider records a span for every MCP request, and for the GitHub API requests
+	// it makes, when set.
+	TracerProvider trace.TracerProvider
+
+	// Audit records every tool call when set.
+	Audit *audit.Logger
+
+	// APICache caches GitHub REST responses and revalidates them with conditional
+	// requests when set.
+	APICache transport.CacheStore
+
+	// RateLimitMaxWait enables retries of GitHub requests rejected by a rate limit, waiting
+	// up to this long in total for it to reset. Zero disables retries.
+	RateLimitMaxWait time.Duration
+
+	// RateLimitMeta attaches the GitHub rate limits reported during each tool call to its
+	// result's _meta. The API clients must record them with transport.RateLimitTransport.
+	RateLimitMeta bool
+
 	// Additional server options to apply
 	ServerOptions []MCPServerOption
 }
@@ -79,7 +154,19 @@ func NewMCPServer(ctx context.Context, cfg *MCPServerConfig, deps ToolDependenci
 	serverOpts := &mcp.ServerOptions{
 		Instructions:      inv.Instructions(),
 		Logger:            cfg.Logger,
-		CompletionHandler: CompletionsHandler(deps.GetClient),
+		CompletionHandler: CompletionsHandler(deps.GetClient, inv),
+	}
+
+	// Resource subscriptions need a long-lived session, so they are only enabled on request
+	var subscriptions *ResourceSubscriptions
+	if cfg.ResourcePollInterval > 0 {
+		subscriptions = NewResourceSubscriptions(ctx, deps.GetClient, ResourceSubscriptionOptions{
+			PollInterval:  cfg.ResourcePollInterval,
+			MaxPerSession: cfg.MaxResourceSubscriptions,
+			Logger:        cfg.Logger,
+		})
+		serverOpts.SubscribeHandler = subscriptions.Subscribe
+		serverOpts.UnsubscribeHandler = subscriptions.Unsubscribe
 	}
 
 	// Apply any additional server options
@@ -92,17 +179,42 @@ func NewMCPServer(ctx context.Context, cfg *MCPServerConfig, deps ToolDependenci
 	if cfg.DynamicToolsets {
 		serverOpts.Capabilities = &mcp.ServerCapabilities{
 			Tools:     &mcp.ToolCapabilities{},
-			Resources: &mcp.ResourceCapabilities{},
+			Resources: &mcp.ResourceCapabilities{Subscribe: serverOpts.SubscribeHandler != nil},
 			Prompts:   &mcp.PromptCapabilities{},
 		}
 	}
 
 	ghServer := NewServer(cfg.Version, serverOpts)
+	if subscriptions != nil {
+		subscriptions.Attach(ghServer)
+	}
 
 	// Add middlewares
 	ghServer.AddReceivingMiddleware(addGitHubAPIErrorToContext)
+	ghServer.AddReceivingMiddleware(notifyRateLimitWaits)
 	ghServer.AddReceivingMiddleware(InjectDepsMiddleware(deps))
 	ghServer.AddReceivingMiddleware(InjectGitDepsMiddleware(deps))
+	ghServer.AddReceivingMiddleware(DeprecatedToolAliasMiddleware(inv, cfg.StrictToolAliases, cfg.Logger))
+	if cfg.Metrics != nil {
+		ghServer.AddReceivingMiddleware(MetricsMiddleware(cfg.Metrics, inv))
+	}
+	if cfg.Audit != nil {
+		ghServer.AddReceivingMiddleware(AuditMiddleware(cfg.Audit, inv, deps.GetClient))
+	}
+	if cfg.TracerProvider != nil {
+		ghServer.AddReceivingMiddleware(TracingMiddleware(cfg.TracerProvider.Tracer(tracing.ScopeName)))
+	}
+	if cfg.RateLimitMeta {
+		ghServer.AddReceivingMiddleware(RateLimitMetaMiddleware)
+	}
+	ghServer.AddReceivingMiddleware(LockdownReportMiddleware(cfg.LockdownPlaceholders))
+	if !cfg.DisableInjectionMarkers {
+		injectionDetector := cfg.InjectionDetector
+		if injectionDetector == nil {
+			injectionDetector = sanitize.DefaultInjectionDetector()
+		}
+		ghServer.AddReceivingMiddleware(InjectionReportMiddleware(injectionDetector))
+	}
 
 	if unrecognized := inv.UnrecognizedToolsets(); len(unrecognized) > 0 {
 		cfg.Logger.Warn("Warning: unrecognized toolsets ignored", "toolsets", strings.Join(unrecognized, ", "))
@@ -171,6 +283,32 @@ func addGitHubAPIErrorToContext(next mcp.MethodHandler) mcp.MethodHandler {
 	}
 }
 
+// notifyRateLimitWaits sends a progress notification whenever a tool call waits for a
+// GitHub rate limit to reset, if the client asked for progress.
+func notifyRateLimitWaits(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		callReq, ok := req.(*mcp.CallToolRequest)
+		if method != "tools/call" || !ok || callReq.Params == nil || callReq.Session == nil {
+			return next(ctx, method, req)
+		}
+		progressToken := callReq.Params.GetProgressToken()
+		if progressToken == nil {
+			return next(ctx, method, req)
+		}
+
+		// Progress must increase, even across the several API requests of a tool call
+		var waits atomic.Int64
+		ctx = ghcontext.WithRateLimitNotifier(ctx, func(ctx context.Context, wait time.Duration, attempt int) {
+			_ = callReq.Session.NotifyProgress(ctx, &mcp.ProgressNotificationParams{
+				ProgressToken: progressToken,
+				Progress:      float64(waits.Add(1)),
+				Message:       fmt.Sprintf("GitHub rate limit reached, retrying in %s (retry %d)", wait.Round(time.Second), attempt),
+			})
+		})
+		return next(ctx, method, req)
+	}
+}
+
 // InjectGitDepsMiddleware creates a middleware that injects git.ToolDependencies into the context.
 // This allows git tools to retrieve their dependencies from context at call time.
 func InjectGitDepsMiddleware(deps ToolDependencies) mcp.Middleware {
@@ -200,16 +338,22 @@ func NewServer(version string, opts *mcp.ServerOptions) *mcp.Server {
 	return s
 }
 
-func CompletionsHandler(getClient GetClientFn) func(ctx context.Context, req *mcp.CompleteRequest) (*mcp.CompleteResult, error) {
+// CompletionsHandler returns the completion/complete handler. Resource completions are
+// served for repo://, issue:// and pr:// templates; prompt completions are delegated to the prompts in inv.
+func CompletionsHandler(getClient GetClientFn, inv *inventory.Inventory) func(ctx context.Context, req *mcp.CompleteRequest) (*mcp.CompleteResult, error) {
 	return func(ctx context.Context, req *mcp.CompleteRequest) (*mcp.CompleteResult, error) {
 		switch req.Params.Ref.Type {
 		case "ref/resource":
-			if strings.HasPrefix(req.Params.Ref.URI, "repo://") {
+			uri := req.Params.Ref.URI
+			if strings.HasPrefix(uri, "repo://") || strings.HasPrefix(uri, "issue://") || strings.HasPrefix(uri, "pr://") {
 				return RepositoryResourceCompletionHandler(getClient)(ctx, req)
 			}
 			return nil, fmt.Errorf("unsupported resource URI: %s", req.Params.Ref.URI)
 		case "ref/prompt":
-			return nil, nil
+			if inv == nil {
+				return nil, nil
+			}
+			return inv.CompletePrompt(ctx, req)
 		default:
 			return nil, fmt.Errorf("unsupported ref type: %s", req.Params.Ref.Type)
 		}
@@ -4,16 +4,18 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
-	"fmt"
 	"net/http"
 	"testing"
 	"time"
 
+	ghcontext "github.com/github/github-mcp-server/pkg/context"
 	"github.com/github/github-mcp-server/pkg/git/gitops"
 	"github.com/github/github-mcp-server/pkg/lockdown"
 	"github.com/github/github-mcp-server/pkg/raw"
 	"github.com/github/github-mcp-server/pkg/translations"
+	"github.com/github/github-mcp-server/pkg/utils"
 	gogithub "github.com/google/go-github/v82/github"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/shurcooL/githubv4"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -83,8 +85,7 @@ func stubGQLClientFnErr(errMsg string) func(context.Context) (*githubv4.Client,
 }
 
 func stubRepoAccessCache(client *githubv4.Client, ttl time.Duration) *lockdown.RepoAccessCache {
-	cacheName := fmt.Sprintf("repo-access-cache-test-%d", time.Now().UnixNano())
-	return lockdown.GetInstance(client, lockdown.WithTTL(ttl), lockdown.WithCacheName(cacheName))
+	return lockdown.NewRepoAccessCache(client, lockdown.WithTTL(ttl))
 }
 
 func stubFeatureFlags(enabledFlags map[string]bool) FeatureFlags {
@@ -131,7 +132,7 @@ func TestNewMCPServer_CreatesSuccessfully(t *testing.T) {
 
 	// Build inventory
 	inv, err := NewInventory(cfg.Translator).
-		WithDeprecatedAliases(DeprecatedToolAliases).
+		WithDeprecatedAliases(DeprecatedToolAliasTargets()).
 		WithToolsets(cfg.EnabledToolsets).
 		Build()
 
@@ -222,3 +223,53 @@ func TestResolveEnabledToolsets(t *testing.T) {
 		})
 	}
 }
+
+func TestNotifyRateLimitWaits(t *testing.T) {
+	t.Parallel()
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "test"}, nil)
+	server.AddReceivingMiddleware(notifyRateLimitWaits)
+	server.AddTool(&mcp.Tool{Name: "search", InputSchema: json.RawMessage(`{"type":"object"}`)},
+		func(ctx context.Context, _ *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			notify := ghcontext.GetRateLimitNotifier(ctx)
+			if notify == nil {
+				return utils.NewToolResultText("no notifier"), nil
+			}
+			notify(ctx, 30*time.Second, 1)
+			notify(ctx, 12*time.Second, 1)
+			return utils.NewToolResultText("ok"), nil
+		})
+
+	progress := make(chan *mcp.ProgressNotificationParams, 2)
+	client := mcp.NewClient(&mcp.Implementation{Name: "client"}, &mcp.ClientOptions{
+		ProgressNotificationHandler: func(_ context.Context, req *mcp.ProgressNotificationClientRequest) {
+			progress <- req.Params
+		},
+	})
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	_, err := server.Connect(t.Context(), serverTransport, nil)
+	require.NoError(t, err)
+	session, err := client.Connect(t.Context(), clientTransport, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = session.Close() })
+
+	// Without a progress token there is nobody to notify
+	result, err := session.CallTool(t.Context(), &mcp.CallToolParams{Name: "search"})
+	require.NoError(t, err)
+	assert.Equal(t, "no notifier", result.Content[0].(*mcp.TextContent).Text)
+
+	result, err = session.CallTool(t.Context(), &mcp.CallToolParams{Name: "search", Meta: mcp.Meta{"progressToken": "call-1"}})
+	require.NoError(t, err)
+	assert.Equal(t, "ok", result.Content[0].(*mcp.TextContent).Text)
+
+	for i, expected := range []string{"retrying in 30s (retry 1)", "retrying in 12s (retry 1)"} {
+		select {
+		case p := <-progress:
+			assert.Equal(t, "call-1", p.ProgressToken)
+			assert.Equal(t, float64(i+1), p.Progress, "progress increases across requests")
+			assert.Contains(t, p.Message, expected)
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for progress notification")
+		}
+	}
+}
@@ -162,6 +162,7 @@ func AllTools(t translations.TranslationHelperFunc) []inventory.ServerTool {
 		GetMe(t),
 		GetTeams(t),
 		GetTeamMembers(t),
+		GetRateLimit(t),
 
 		// Repository tools
 		SearchRepositories(t),
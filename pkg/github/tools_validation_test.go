@@ -1,8 +1,11 @@
 package github
 
 import (
+	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/github/github-mcp-server/internal/toolsnaps"
 	"github.com/github/github-mcp-server/pkg/inventory"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -127,6 +130,28 @@ func TestNoDuplicateToolNames(t *testing.T) {
 	}
 }
 
+// TestAllToolsHaveSnapshots ensures every tool has a schema snapshot, and that every snapshot
+// belongs to a tool, so that compare-tools checks the current build against all of them. Tools
+// with feature-flagged variants are snapshotted by their first registration, which is the one
+// compare-tools uses.
+func TestAllToolsHaveSnapshots(t *testing.T) {
+	seen := make(map[string]bool)
+	for _, tool := range AllTools(stubTranslation) {
+		if seen[tool.Tool.Name] {
+			continue
+		}
+		seen[tool.Tool.Name] = true
+		require.NoError(t, toolsnaps.Test(tool.Tool.Name, tool.Tool))
+	}
+
+	snaps, err := filepath.Glob(filepath.Join("__toolsnaps__", "*.snap"))
+	require.NoError(t, err)
+	for _, snap := range snaps {
+		name := strings.TrimSuffix(filepath.Base(snap), ".snap")
+		assert.True(t, seen[name], "snapshot %s belongs to no tool; remove it", snap)
+	}
+}
+
 // TestNoDuplicateResourceNames ensures all resources have unique names
 func TestNoDuplicateResourceNames(t *testing.T) {
 	resources := AllResources(stubTranslation)
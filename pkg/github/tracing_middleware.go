@@ -0,0 +1,69 @@
+package github
+
+import (
+	"context"
+
+	gherrors "github.com/github/github-mcp-server/pkg/errors"
+	"github.com/github/github-mcp-server/pkg/tracing"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracingMiddleware records a server span for every MCP request. GitHub API requests made
+// while handling it become child spans through the traced client transport. It is added
+// last so that the span covers the other middleware.
+func TracingMiddleware(tracer trace.Tracer) mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			// Stateful sessions handle requests in the context of the initialize request,
+			// so continue the trace of the HTTP request that carried this one instead
+			if extra := req.GetExtra(); extra != nil && extra.Header != nil {
+				ctx = tracing.Propagator.Extract(ctx, propagation.HeaderCarrier(extra.Header))
+			}
+
+			name, attrs := mcpSpanName(method, req)
+			ctx, span := tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindServer), trace.WithAttributes(attrs...))
+			defer span.End()
+
+			if method == "tools/call" {
+				ctx = gherrors.ContextWithGitHubErrors(ctx)
+			}
+			result, err := next(ctx, method, req)
+			if err != nil {
+				span.SetStatus(codes.Error, err.Error())
+				return result, err
+			}
+
+			if toolResult, ok := result.(*mcp.CallToolResult); ok && toolResult != nil && toolResult.IsError {
+				errorClass := toolCallErrorClass(ctx, result, nil)
+				span.SetAttributes(attribute.String("error.type", errorClass))
+				span.SetStatus(codes.Error, errorClass)
+			}
+			return result, nil
+		}
+	}
+}
+
+// mcpSpanName names the span after the method and, where there is one, its target.
+func mcpSpanName(method string, req mcp.Request) (string, []attribute.KeyValue) {
+	attrs := []attribute.KeyValue{attribute.String("mcp.method.name", method)}
+	switch r := req.(type) {
+	case *mcp.CallToolRequest:
+		if r.Params != nil {
+			// Deprecated aliases are rewritten further in, so this is the name the client used
+			return method + " " + r.Params.Name, append(attrs, attribute.String("gen_ai.tool.name", r.Params.Name))
+		}
+	case *mcp.ReadResourceRequest:
+		if r.Params != nil {
+			return method, append(attrs, attribute.String("mcp.resource.uri", r.Params.URI))
+		}
+	case *mcp.GetPromptRequest:
+		if r.Params != nil {
+			return method + " " + r.Params.Name, append(attrs, attribute.String("gen_ai.prompt.name", r.Params.Name))
+		}
+	}
+	return method, attrs
+}
@@ -0,0 +1,123 @@
+package github
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	gherrors "github.com/github/github-mcp-server/pkg/errors"
+	"github.com/github/github-mcp-server/pkg/http/transport"
+	"github.com/github/github-mcp-server/pkg/tracing"
+	"github.com/github/github-mcp-server/pkg/utils"
+	gogithub "github.com/google/go-github/v82/github"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracingMiddleware(t *testing.T) {
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/repos/octo-org/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message": "Not Found"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"login": "octocat"}`))
+	}))
+	t.Cleanup(api.Close)
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	client := gogithub.NewClient(&http.Client{Transport: transport.NewTracingTransport(nil, provider)})
+	baseURL, err := url.Parse(api.URL + "/")
+	require.NoError(t, err)
+	client.BaseURL = baseURL
+
+	handler := TracingMiddleware(provider.Tracer(tracing.ScopeName))(func(ctx context.Context, _ string, req mcp.Request) (mcp.Result, error) {
+		if _, _, err := client.Users.Get(ctx, ""); err != nil {
+			return nil, err
+		}
+		callReq, ok := req.(*mcp.CallToolRequest)
+		if !ok || callReq.Params.Name == "get_me" {
+			return utils.NewToolResultText("ok"), nil
+		}
+		_, resp, err := client.Repositories.Get(ctx, "octo-org", "missing")
+		return gherrors.NewGitHubAPIErrorResponse(ctx, "failed to get repository", resp, err), nil
+	})
+
+	header := http.Header{}
+	header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	ctx := tracing.Propagator.Extract(context.Background(), propagation.HeaderCarrier(header))
+	remote := trace.SpanContextFromContext(ctx)
+
+	_, err = handler(ctx, "tools/call", &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{Name: "get_me"}})
+	require.NoError(t, err)
+	_, err = handler(context.Background(), "tools/call", &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{Name: "get_repository"}})
+	require.NoError(t, err)
+	// In a stateful session the trace is carried by the request's own headers
+	header = http.Header{}
+	header.Set("traceparent", "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01")
+	_, err = handler(ctx, "resources/read", &mcp.ReadResourceRequest{
+		Params: &mcp.ReadResourceParams{URI: "repo://octo-org/octo-repo/contents/README.md"},
+		Extra:  &mcp.RequestExtra{Header: header},
+	})
+	require.NoError(t, err)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 7)
+	spanNamed := func(name string) sdktrace.ReadOnlySpan {
+		for _, span := range spans {
+			if span.Name() == name {
+				return span
+			}
+		}
+		t.Fatalf("no span named %q", name)
+		return nil
+	}
+	attributes := func(span sdktrace.ReadOnlySpan) map[attribute.Key]string {
+		attrs := map[attribute.Key]string{}
+		for _, attr := range span.Attributes() {
+			attrs[attr.Key] = attr.Value.Emit()
+		}
+		return attrs
+	}
+
+	getMe := spanNamed("tools/call get_me")
+	assert.Equal(t, remote.TraceID(), getMe.SpanContext().TraceID(), "the caller's trace is continued")
+	assert.Equal(t, remote.SpanID(), getMe.Parent().SpanID())
+	assert.Equal(t, trace.SpanKindServer, getMe.SpanKind())
+	assert.Equal(t, "tools/call", attributes(getMe)["mcp.method.name"])
+	assert.Equal(t, "get_me", attributes(getMe)["gen_ai.tool.name"])
+	assert.NotEqual(t, codes.Error, getMe.Status().Code)
+
+	failed := spanNamed("tools/call get_repository")
+	assert.Equal(t, codes.Error, failed.Status().Code)
+	assert.Equal(t, "github_not_found", attributes(failed)["error.type"])
+
+	read := spanNamed("resources/read")
+	assert.Equal(t, "repo://octo-org/octo-repo/contents/README.md", attributes(read)["mcp.resource.uri"])
+	assert.Equal(t, "0af7651916cd43dd8448eb211c80319c", read.SpanContext().TraceID().String())
+	assert.Equal(t, "b7ad6b7169203331", read.Parent().SpanID().String())
+
+	// Each GitHub request is a child of the MCP request that made it
+	children := map[trace.SpanID][]string{}
+	for _, span := range spans {
+		if span.SpanKind() == trace.SpanKindClient {
+			u, err := url.Parse(attributes(span)["url.full"])
+			require.NoError(t, err)
+			children[span.Parent().SpanID()] = append(children[span.Parent().SpanID()], u.Path)
+		}
+	}
+	assert.Equal(t, []string{"/user"}, children[getMe.SpanContext().SpanID()])
+	assert.ElementsMatch(t, []string{"/user", "/repos/octo-org/missing"}, children[failed.SpanContext().SpanID()])
+	assert.Equal(t, []string{"/user"}, children[read.SpanContext().SpanID()])
+}
@@ -0,0 +1,181 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/github/github-mcp-server/pkg/lockdown"
+	"github.com/github/github-mcp-server/pkg/sanitize"
+)
+
+// contentAuthor identifies the author of user-authored content returned by a tool, and where
+// lockdown mode checks whether they are trusted.
+type contentAuthor struct {
+	// Kind names the content in lockdown placeholders, such as "comment".
+	Kind string
+
+	// Login is empty when the author is unknown, such as for commits by an email address not
+	// linked to an account, or for notifications. Unknown authors are not trusted.
+	Login string
+
+	// Association is the author association GitHub reports for the content, if any.
+	Association string
+
+	// Owner and Repo are the repository of the content. Content outside of a repository,
+	// such as a gist, is only trusted from the viewer and trusted bots.
+	Owner string
+	Repo  string
+
+	// Private marks content of a private repository, which lockdown mode trusts without
+	// looking up its author.
+	Private bool
+}
+
+// filterUserContent is the pipeline every tool returning user-authored text goes through. In
+// lockdown mode, it drops the items whose author is not trusted, resolving the authors of
+// each repository with one batched query first, and records them for
+// LockdownReportMiddleware. It then sanitizes the text of the kept items with sanitizeItem,
+// if set.
+func filterUserContent[T any](ctx context.Context, deps ToolDependencies, items []T, author func(T) contentAuthor, sanitizeItem func(context.Context, T)) ([]T, error) {
+	items, hidden, err := lockdownFilter(ctx, deps, items, author)
+	if err != nil {
+		return nil, err
+	}
+	if recorder := getLockdownRecorder(ctx); recorder != nil {
+		for _, h := range hidden {
+			recorder.record(h.author, h.reason)
+		}
+	}
+	if sanitizeItem != nil {
+		for _, item := range items {
+			sanitizeItem(ctx, item)
+		}
+	}
+	return items, nil
+}
+
+// checkUserContent reports whether a tool may return a single item by author, which it then
+// sanitizes itself. Tools return an error rather than the item when it is not safe, so it is
+// not recorded as hidden.
+func checkUserContent(ctx context.Context, deps ToolDependencies, author contentAuthor) (bool, error) {
+	_, hidden, err := lockdownFilter(ctx, deps, []contentAuthor{author}, func(a contentAuthor) contentAuthor { return a })
+	if err != nil {
+		return false, err
+	}
+	return len(hidden) == 0, nil
+}
+
+// hiddenContent is an item dropped by lockdown mode.
+type hiddenContent struct {
+	author contentAuthor
+	reason string
+}
+
+// lockdownFilter drops the items whose author is not trusted in lockdown mode, returning the
+// kept items and why the others were dropped.
+func lockdownFilter[T any](ctx context.Context, deps ToolDependencies, items []T, author func(T) contentAuthor) ([]T, []hiddenContent, error) {
+	if !deps.GetFlags(ctx).LockdownMode {
+		return items, nil, nil
+	}
+	cache, err := deps.GetRepoAccessCache(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get repo access cache: %w", err)
+	}
+	if cache == nil {
+		return nil, nil, fmt.Errorf("lockdown cache is not configured")
+	}
+	kept, hidden, err := filterUntrustedContent(ctx, cache, items, author)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to check lockdown mode: %w", err)
+	}
+	return kept, hidden, nil
+}
+
+func filterUntrustedContent[T any](ctx context.Context, cache *lockdown.RepoAccessCache, items []T, author func(T) contentAuthor) ([]T, []hiddenContent, error) {
+	authors := make([]contentAuthor, len(items))
+	logins := make(map[[2]string][]string)
+	for i, item := range items {
+		authors[i] = author(item)
+		a := authors[i]
+		if a.Login != "" && a.Repo != "" && !a.Private {
+			repo := [2]string{a.Owner, a.Repo}
+			logins[repo] = append(logins[repo], a.Login)
+		}
+	}
+	for repo, repoLogins := range logins {
+		// A single author is resolved as well by checking them
+		if len(repoLogins) < 2 {
+			continue
+		}
+		if err := cache.Prefetch(ctx, repo[0], repo[1], repoLogins); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	kept := make([]T, 0, len(items))
+	var hidden []hiddenContent
+	for i, item := range items {
+		reason, err := untrustedContentReason(ctx, cache, authors[i])
+		if err != nil {
+			return nil, nil, err
+		}
+		if reason == "" {
+			kept = append(kept, item)
+		} else {
+			hidden = append(hidden, hiddenContent{author: authors[i], reason: reason})
+		}
+	}
+	return kept, hidden, nil
+}
+
+// untrustedContentReason returns why content by a is not trusted, or "" if it is.
+func untrustedContentReason(ctx context.Context, cache *lockdown.RepoAccessCache, a contentAuthor) (string, error) {
+	switch {
+	case a.Private:
+		return "", nil
+	case a.Login == "":
+		return LockdownReasonUnknownAuthor, nil
+	case a.Repo == "":
+		safe, err := cache.IsSafeUserContent(ctx, a.Login)
+		if err != nil || safe {
+			return "", err
+		}
+		return LockdownReasonUntrustedAuthor, nil
+	default:
+		safe, err := cache.IsSafeContent(ctx, a.Login, a.Association, a.Owner, a.Repo)
+		if err != nil || safe {
+			return "", err
+		}
+		return LockdownReasonNoPushAccess, nil
+	}
+}
+
+// sanitizeText sanitizes user-authored text in place, and marks the segments that look like
+// a prompt injection.
+func sanitizeText(ctx context.Context, texts ...*string) {
+	for _, text := range texts {
+		if text != nil {
+			*text = markInjection(ctx, sanitize.Sanitize(*text))
+		}
+	}
+}
+
+// repoFromAPIURL returns the owner and name of the repository of a REST API URL such as
+// https://api.github.com/repos/octo-org/octo-repo/issues/1.
+func repoFromAPIURL(apiURL string) (owner, repo string) {
+	u, err := url.Parse(apiURL)
+	if err != nil {
+		return "", ""
+	}
+	_, path, ok := strings.Cut(u.Path, "/repos/")
+	if !ok {
+		return "", ""
+	}
+	parts := strings.SplitN(path, "/", 3)
+	if len(parts) < 2 {
+		return "", ""
+	}
+	return parts[0], parts[1]
+}
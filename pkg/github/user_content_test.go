@@ -0,0 +1,636 @@
+package github
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/github/github-mcp-server/pkg/translations"
+	"github.com/google/go-github/v82/github"
+	"github.com/shurcooL/githubv4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// lockdownExemptTools are the read-only tools that return no user-authored text from
+// untrusted authors, and so skip the lockdown and sanitize pipeline.
+var lockdownExemptTools = map[string]string{
+	"get_me":                          "returns the viewer's own profile",
+	"get_teams":                       "returns team names managed by organization owners",
+	"get_team_members":                "returns logins only",
+	"get_rate_limit":                  "returns rate limit counters",
+	"search_code":                     "repository contents are only changed by users with push access, and are code rather than text",
+	"list_branches":                   "returns branch names and commit SHAs",
+	"list_tags":                       "returns tag names and commit SHAs",
+	"get_tag":                         "tags are only created by users with push access",
+	"list_issue_types":                "issue types are managed by organization owners",
+	"search_users":                    "returns logins and profile URLs",
+	"search_orgs":                     "returns logins and profile URLs",
+	"get_label":                       "labels are managed by users with triage access",
+	"list_label":                      "labels are managed by users with triage access",
+	"list_discussion_categories":      "categories are managed by users with maintain access",
+	"get_code_scanning_alert":         "alerts are generated by code scanning tools",
+	"list_code_scanning_alerts":       "alerts are generated by code scanning tools",
+	"get_secret_scanning_alert":       "alerts are generated by secret scanning",
+	"list_secret_scanning_alerts":     "alerts are generated by secret scanning",
+	"get_dependabot_alert":            "alerts are generated by Dependabot",
+	"list_dependabot_alerts":          "alerts are generated by Dependabot",
+	"list_global_security_advisories": "advisories are reviewed by GitHub",
+	"get_global_security_advisory":    "advisories are reviewed by GitHub",
+	"git_status":                      "reads a local repository of the user",
+	"git_diff_unstaged":               "reads a local repository of the user",
+	"git_diff_staged":                 "reads a local repository of the user",
+	"git_diff":                        "reads a local repository of the user",
+	"git_log":                         "reads a local repository of the user",
+	"git_show":                        "reads a local repository of the user",
+	"git_list_repositories":           "reads local repositories of the user",
+}
+
+// userContentLockdownCases checks that every tool returning user-authored text drops the
+// text of "testuser", who only has read access to owner/repo, in lockdown mode. Lists keep
+// the text of "maintainer", who has write access, or of the viewer.
+var userContentLockdownCases = []struct {
+	tool        string
+	args        map[string]any
+	handlers    func(t *testing.T) map[string]http.HandlerFunc
+	wantTrusted bool
+}{
+	{
+		tool: "issue_read",
+		args: map[string]any{"method": "get", "owner": "owner", "repo": "repo", "issue_number": float64(1)},
+		handlers: func(t *testing.T) map[string]http.HandlerFunc {
+			return map[string]http.HandlerFunc{
+				GetReposIssuesByOwnerByRepoByIssueNumber: mockResponse(t, http.StatusOK, untrustedIssue()),
+			}
+		},
+	},
+	{
+		tool: "issue_read",
+		args: map[string]any{"method": "get_comments", "owner": "owner", "repo": "repo", "issue_number": float64(1)},
+		handlers: func(t *testing.T) map[string]http.HandlerFunc {
+			return map[string]http.HandlerFunc{
+				GetReposIssuesCommentsByOwnerByRepoByIssueNumber: mockResponse(t, http.StatusOK, []*github.IssueComment{
+					{Body: github.Ptr("untrusted text"), User: &github.User{Login: github.Ptr("testuser")}},
+					{Body: github.Ptr("safe text"), User: &github.User{Login: github.Ptr("maintainer")}},
+				}),
+			}
+		},
+		wantTrusted: true,
+	},
+	{
+		tool: "pull_request_read",
+		args: map[string]any{"method": "get", "owner": "owner", "repo": "repo", "pullNumber": float64(1)},
+		handlers: func(t *testing.T) map[string]http.HandlerFunc {
+			return map[string]http.HandlerFunc{
+				GetReposPullsByOwnerByRepoByPullNumber: mockResponse(t, http.StatusOK, untrustedPullRequest()),
+			}
+		},
+	},
+	{
+		tool: "pull_request_read",
+		args: map[string]any{"method": "get_diff", "owner": "owner", "repo": "repo", "pullNumber": float64(1)},
+		handlers: func(t *testing.T) map[string]http.HandlerFunc {
+			return map[string]http.HandlerFunc{
+				GetReposPullsByOwnerByRepoByPullNumber: mockResponse(t, http.StatusOK, untrustedPullRequest()),
+			}
+		},
+	},
+	{
+		tool: "pull_request_read",
+		args: map[string]any{"method": "get_reviews", "owner": "owner", "repo": "repo", "pullNumber": float64(1)},
+		handlers: func(t *testing.T) map[string]http.HandlerFunc {
+			return map[string]http.HandlerFunc{
+				GetReposPullsReviewsByOwnerByRepoByPullNumber: mockResponse(t, http.StatusOK, []*github.PullRequestReview{
+					{Body: github.Ptr("untrusted text"), User: &github.User{Login: github.Ptr("testuser")}},
+					{Body: github.Ptr("safe text"), User: &github.User{Login: github.Ptr("maintainer")}},
+				}),
+			}
+		},
+		wantTrusted: true,
+	},
+	{
+		tool: "list_issues",
+		args: map[string]any{"owner": "owner", "repo": "repo"},
+		handlers: func(t *testing.T) map[string]http.HandlerFunc {
+			return map[string]http.HandlerFunc{
+				"POST /graphql": mockResponse(t, http.StatusOK, map[string]any{
+					"data": map[string]any{"repository": map[string]any{"issues": map[string]any{
+						"nodes": []any{
+							map[string]any{"number": 1, "title": "untrusted text", "author": map[string]any{"login": "testuser"}},
+							map[string]any{"number": 2, "title": "safe text", "author": map[string]any{"login": "maintainer"}},
+						},
+					}}},
+				}),
+			}
+		},
+		wantTrusted: true,
+	},
+	{
+		tool: "list_pull_requests",
+		args: map[string]any{"owner": "owner", "repo": "repo"},
+		handlers: func(t *testing.T) map[string]http.HandlerFunc {
+			return map[string]http.HandlerFunc{
+				GetReposPullsByOwnerByRepo: mockResponse(t, http.StatusOK, []*github.PullRequest{
+					untrustedPullRequest(),
+					{Number: github.Ptr(2), Title: github.Ptr("safe text"), User: &github.User{Login: github.Ptr("maintainer")}},
+				}),
+			}
+		},
+		wantTrusted: true,
+	},
+	{
+		tool: "search_issues",
+		args: map[string]any{"query": "bug"},
+		handlers: func(t *testing.T) map[string]http.HandlerFunc {
+			return map[string]http.HandlerFunc{
+				GetSearchIssues: mockResponse(t, http.StatusOK, searchedIssues()),
+			}
+		},
+		wantTrusted: true,
+	},
+	{
+		tool: "search_pull_requests",
+		args: map[string]any{"query": "fix"},
+		handlers: func(t *testing.T) map[string]http.HandlerFunc {
+			return map[string]http.HandlerFunc{
+				GetSearchIssues: mockResponse(t, http.StatusOK, searchedIssues()),
+			}
+		},
+		wantTrusted: true,
+	},
+	{
+		tool: "search_repositories",
+		args: map[string]any{"query": "cli"},
+		handlers: func(t *testing.T) map[string]http.HandlerFunc {
+			return map[string]http.HandlerFunc{
+				GetSearchRepositories: mockResponse(t, http.StatusOK, &github.RepositoriesSearchResult{
+					Total:        github.Ptr(2),
+					Repositories: []*github.Repository{untrustedRepository(), trustedRepository()},
+				}),
+			}
+		},
+		wantTrusted: true,
+	},
+	{
+		tool: "list_starred_repositories",
+		args: map[string]any{},
+		handlers: func(t *testing.T) map[string]http.HandlerFunc {
+			return map[string]http.HandlerFunc{
+				GetUserStarred: mockResponse(t, http.StatusOK, []*github.StarredRepository{
+					{Repository: untrustedRepository()},
+					{Repository: trustedRepository()},
+				}),
+			}
+		},
+		wantTrusted: true,
+	},
+	{
+		tool: "get_commit",
+		args: map[string]any{"owner": "owner", "repo": "repo", "sha": "abc123"},
+		handlers: func(t *testing.T) map[string]http.HandlerFunc {
+			return map[string]http.HandlerFunc{
+				GetReposCommitsByOwnerByRepoByRef: mockResponse(t, http.StatusOK, untrustedCommit()),
+			}
+		},
+	},
+	{
+		tool: "list_commits",
+		args: map[string]any{"owner": "owner", "repo": "repo"},
+		handlers: func(t *testing.T) map[string]http.HandlerFunc {
+			return map[string]http.HandlerFunc{
+				GetReposCommitsByOwnerByRepo: mockResponse(t, http.StatusOK, []*github.RepositoryCommit{
+					untrustedCommit(),
+					{SHA: github.Ptr("def456"), Commit: &github.Commit{Message: github.Ptr("safe text")}, Author: &github.User{Login: github.Ptr("maintainer")}},
+				}),
+			}
+		},
+		wantTrusted: true,
+	},
+	{
+		tool: "list_releases",
+		args: map[string]any{"owner": "owner", "repo": "repo"},
+		handlers: func(t *testing.T) map[string]http.HandlerFunc {
+			return map[string]http.HandlerFunc{
+				GetReposReleasesByOwnerByRepo: mockResponse(t, http.StatusOK, []*github.RepositoryRelease{
+					untrustedRelease(),
+					{Name: github.Ptr("v2"), Body: github.Ptr("safe text"), Author: &github.User{Login: github.Ptr("maintainer")}},
+				}),
+			}
+		},
+		wantTrusted: true,
+	},
+	{
+		tool: "get_latest_release",
+		args: map[string]any{"owner": "owner", "repo": "repo"},
+		handlers: func(t *testing.T) map[string]http.HandlerFunc {
+			return map[string]http.HandlerFunc{
+				GetReposReleasesLatestByOwnerByRepo: mockResponse(t, http.StatusOK, untrustedRelease()),
+			}
+		},
+	},
+	{
+		tool: "get_release_by_tag",
+		args: map[string]any{"owner": "owner", "repo": "repo", "tag": "v1"},
+		handlers: func(t *testing.T) map[string]http.HandlerFunc {
+			return map[string]http.HandlerFunc{
+				GetReposReleasesTagsByOwnerByRepoByTag: mockResponse(t, http.StatusOK, untrustedRelease()),
+			}
+		},
+	},
+	{
+		tool: "list_notifications",
+		args: map[string]any{},
+		handlers: func(t *testing.T) map[string]http.HandlerFunc {
+			return map[string]http.HandlerFunc{
+				GetNotifications: mockResponse(t, http.StatusOK, []*github.Notification{
+					untrustedNotification(),
+					{
+						ID:         github.Ptr("2"),
+						Subject:    &github.NotificationSubject{Title: github.Ptr("safe text")},
+						Repository: &github.Repository{Private: github.Ptr(true)},
+					},
+				}),
+			}
+		},
+		wantTrusted: true,
+	},
+	{
+		tool: "get_notification_details",
+		args: map[string]any{"notificationID": "1"},
+		handlers: func(t *testing.T) map[string]http.HandlerFunc {
+			return map[string]http.HandlerFunc{
+				GetNotificationsThreadsByThreadID: mockResponse(t, http.StatusOK, untrustedNotification()),
+			}
+		},
+	},
+	{
+		tool: "list_discussions",
+		args: map[string]any{"owner": "owner", "repo": "repo"},
+		handlers: func(t *testing.T) map[string]http.HandlerFunc {
+			return map[string]http.HandlerFunc{
+				"POST /graphql": mockResponse(t, http.StatusOK, map[string]any{
+					"data": map[string]any{"repository": map[string]any{"discussions": map[string]any{
+						"nodes": []any{
+							map[string]any{"number": 1, "title": "untrusted text", "author": map[string]any{"login": "testuser"}},
+							map[string]any{"number": 2, "title": "safe text", "author": map[string]any{"login": "maintainer"}},
+						},
+					}}},
+				}),
+			}
+		},
+		wantTrusted: true,
+	},
+	{
+		tool: "get_discussion",
+		args: map[string]any{"owner": "owner", "repo": "repo", "discussionNumber": float64(1)},
+		handlers: func(t *testing.T) map[string]http.HandlerFunc {
+			return map[string]http.HandlerFunc{
+				"POST /graphql": mockResponse(t, http.StatusOK, map[string]any{
+					"data": map[string]any{"repository": map[string]any{"discussion": map[string]any{
+						"number": 1, "body": "untrusted text", "author": map[string]any{"login": "testuser"},
+					}}},
+				}),
+			}
+		},
+	},
+	{
+		tool: "get_discussion_comments",
+		args: map[string]any{"owner": "owner", "repo": "repo", "discussionNumber": float64(1)},
+		handlers: func(t *testing.T) map[string]http.HandlerFunc {
+			return map[string]http.HandlerFunc{
+				"POST /graphql": mockResponse(t, http.StatusOK, map[string]any{
+					"data": map[string]any{"repository": map[string]any{"discussion": map[string]any{"comments": map[string]any{
+						"nodes": []any{
+							map[string]any{"body": "untrusted text", "author": map[string]any{"login": "testuser"}},
+							map[string]any{"body": "safe text", "author": map[string]any{"login": "maintainer"}},
+						},
+					}}}},
+				}),
+			}
+		},
+		wantTrusted: true,
+	},
+	{
+		tool: "list_gists",
+		args: map[string]any{},
+		handlers: func(t *testing.T) map[string]http.HandlerFunc {
+			return map[string]http.HandlerFunc{
+				GetGists: mockResponse(t, http.StatusOK, []*github.Gist{
+					untrustedGist(),
+					{ID: github.Ptr("2"), Description: github.Ptr("safe text"), Owner: &github.User{Login: github.Ptr("viewer")}},
+				}),
+			}
+		},
+		wantTrusted: true,
+	},
+	{
+		tool: "get_gist",
+		args: map[string]any{"gist_id": "1"},
+		handlers: func(t *testing.T) map[string]http.HandlerFunc {
+			return map[string]http.HandlerFunc{
+				GetGistsByGistID: mockResponse(t, http.StatusOK, untrustedGist()),
+			}
+		},
+	},
+	{
+		tool: "get_file_contents",
+		args: map[string]any{"owner": "owner", "repo": "repo", "path": "README.md", "ref": "refs/pull/1/head"},
+		handlers: func(t *testing.T) map[string]http.HandlerFunc {
+			return map[string]http.HandlerFunc{
+				GetReposPullsByOwnerByRepoByPullNumber: mockResponse(t, http.StatusOK, untrustedPullRequest()),
+			}
+		},
+	},
+	{
+		tool: "get_repository_tree",
+		args: map[string]any{"owner": "owner", "repo": "repo", "tree_sha": "abc123abc123abc123abc123abc123abc123abc1"},
+		handlers: func(t *testing.T) map[string]http.HandlerFunc {
+			return map[string]http.HandlerFunc{
+				GetReposByOwnerByRepo:              mockResponse(t, http.StatusOK, &github.Repository{DefaultBranch: github.Ptr("main"), Private: github.Ptr(false)}),
+				GetReposCompareByOwnerByRepoByBase: mockResponse(t, http.StatusOK, &github.CommitsComparison{Status: github.Ptr("diverged")}),
+				GetReposCommitsByOwnerByRepoByRef:  mockResponse(t, http.StatusOK, untrustedCommit()),
+			}
+		},
+	},
+	{
+		tool: "actions_list",
+		args: map[string]any{"method": "list_workflow_runs", "owner": "owner", "repo": "repo"},
+		handlers: func(t *testing.T) map[string]http.HandlerFunc {
+			return map[string]http.HandlerFunc{
+				GetReposActionsRunsByOwnerByRepo: mockResponse(t, http.StatusOK, &github.WorkflowRuns{
+					TotalCount: github.Ptr(2),
+					WorkflowRuns: []*github.WorkflowRun{
+						untrustedWorkflowRun(),
+						{ID: github.Ptr(int64(2)), DisplayTitle: github.Ptr("safe text"), Actor: &github.User{Login: github.Ptr("maintainer")}},
+					},
+				}),
+			}
+		},
+		wantTrusted: true,
+	},
+	{
+		tool: "actions_get",
+		args: map[string]any{"method": "get_workflow_run", "owner": "owner", "repo": "repo", "resource_id": "1"},
+		handlers: func(t *testing.T) map[string]http.HandlerFunc {
+			return map[string]http.HandlerFunc{
+				GetReposActionsRunsByOwnerByRepoByRunID: mockResponse(t, http.StatusOK, untrustedWorkflowRun()),
+			}
+		},
+	},
+	{
+		tool: "get_job_logs",
+		args: map[string]any{"owner": "owner", "repo": "repo", "job_id": float64(1)},
+		handlers: func(t *testing.T) map[string]http.HandlerFunc {
+			return map[string]http.HandlerFunc{
+				GetReposActionsJobsByOwnerByRepoByJobID: mockResponse(t, http.StatusOK, &github.WorkflowJob{ID: github.Ptr(int64(1)), RunID: github.Ptr(int64(1))}),
+				GetReposActionsRunsByOwnerByRepoByRunID: mockResponse(t, http.StatusOK, untrustedWorkflowRun()),
+			}
+		},
+	},
+	{
+		tool: "list_repository_security_advisories",
+		args: map[string]any{"owner": "owner", "repo": "repo"},
+		handlers: func(t *testing.T) map[string]http.HandlerFunc {
+			return map[string]http.HandlerFunc{
+				GetReposSecurityAdvisoriesByOwnerByRepo: mockResponse(t, http.StatusOK, []*github.SecurityAdvisory{
+					untrustedAdvisory(),
+					{GHSAID: github.Ptr("GHSA-2"), Summary: github.Ptr("safe text"), Author: &github.User{Login: github.Ptr("testuser")}, Publisher: &github.User{Login: github.Ptr("maintainer")}},
+				}),
+			}
+		},
+		wantTrusted: true,
+	},
+	{
+		tool: "list_org_repository_security_advisories",
+		args: map[string]any{"org": "owner"},
+		handlers: func(t *testing.T) map[string]http.HandlerFunc {
+			return map[string]http.HandlerFunc{
+				GetOrgsSecurityAdvisoriesByOrg: mockResponse(t, http.StatusOK, []*github.SecurityAdvisory{
+					untrustedAdvisory(),
+					{GHSAID: github.Ptr("GHSA-2"), Summary: github.Ptr("safe text"), Publisher: &github.User{Login: github.Ptr("maintainer")}, URL: github.Ptr("https://api.github.com/repos/owner/repo/security-advisories/GHSA-2")},
+				}),
+			}
+		},
+		wantTrusted: true,
+	},
+	{
+		tool: "projects_list",
+		args: map[string]any{"method": "list_project_items", "owner": "octo-org", "owner_type": "org", "project_number": float64(1)},
+		handlers: func(t *testing.T) map[string]http.HandlerFunc {
+			return map[string]http.HandlerFunc{
+				GetOrgsProjectsV2ItemsByProject: mockResponse(t, http.StatusOK, []*github.ProjectV2Item{
+					untrustedProjectItem(),
+					{
+						ID:          github.Ptr(int64(2)),
+						ContentType: github.Ptr(github.ProjectV2ItemContentTypeIssue),
+						Content: &github.ProjectV2ItemContent{Issue: &github.Issue{
+							Number:        github.Ptr(2),
+							Title:         github.Ptr("safe text"),
+							User:          &github.User{Login: github.Ptr("maintainer")},
+							RepositoryURL: github.Ptr("https://api.github.com/repos/owner/repo"),
+						}},
+					},
+				}),
+			}
+		},
+		wantTrusted: true,
+	},
+	{
+		tool: "projects_get",
+		args: map[string]any{"method": "get_project_item", "owner": "octo-org", "owner_type": "org", "project_number": float64(1), "item_id": float64(1)},
+		handlers: func(t *testing.T) map[string]http.HandlerFunc {
+			return map[string]http.HandlerFunc{
+				GetOrgsProjectsV2ItemsByProjectByItemID: mockResponse(t, http.StatusOK, untrustedProjectItem()),
+			}
+		},
+	},
+}
+
+func untrustedIssue() *github.Issue {
+	return &github.Issue{Number: github.Ptr(1), Title: github.Ptr("untrusted text"), User: &github.User{Login: github.Ptr("testuser")}}
+}
+
+func untrustedPullRequest() *github.PullRequest {
+	return &github.PullRequest{Number: github.Ptr(1), Title: github.Ptr("untrusted text"), User: &github.User{Login: github.Ptr("testuser")}}
+}
+
+func untrustedRepository() *github.Repository {
+	return &github.Repository{
+		ID:          github.Ptr(int64(1)),
+		Description: github.Ptr("untrusted text"),
+		Topics:      []string{"untrusted text"},
+		Owner:       &github.User{Login: github.Ptr("testuser")},
+		Permissions: &github.RepositoryPermissions{Pull: github.Ptr(true)},
+	}
+}
+
+func trustedRepository() *github.Repository {
+	return &github.Repository{
+		ID:          github.Ptr(int64(2)),
+		Description: github.Ptr("safe text"),
+		Owner:       &github.User{Login: github.Ptr("octo-org")},
+		Permissions: &github.RepositoryPermissions{Pull: github.Ptr(true), Push: github.Ptr(true)},
+	}
+}
+
+func untrustedCommit() *github.RepositoryCommit {
+	return &github.RepositoryCommit{SHA: github.Ptr("abc123"), Commit: &github.Commit{Message: github.Ptr("untrusted text")}, Author: &github.User{Login: github.Ptr("testuser")}}
+}
+
+func untrustedRelease() *github.RepositoryRelease {
+	return &github.RepositoryRelease{Name: github.Ptr("v1"), Body: github.Ptr("untrusted text"), Author: &github.User{Login: github.Ptr("testuser")}}
+}
+
+func untrustedNotification() *github.Notification {
+	return &github.Notification{
+		ID:         github.Ptr("1"),
+		Subject:    &github.NotificationSubject{Title: github.Ptr("untrusted text")},
+		Repository: &github.Repository{Private: github.Ptr(false)},
+	}
+}
+
+func untrustedGist() *github.Gist {
+	return &github.Gist{ID: github.Ptr("1"), Description: github.Ptr("untrusted text"), Owner: &github.User{Login: github.Ptr("testuser")}}
+}
+
+func untrustedWorkflowRun() *github.WorkflowRun {
+	return &github.WorkflowRun{ID: github.Ptr(int64(1)), DisplayTitle: github.Ptr("untrusted text"), Actor: &github.User{Login: github.Ptr("testuser")}}
+}
+
+func untrustedAdvisory() *github.SecurityAdvisory {
+	return &github.SecurityAdvisory{
+		GHSAID:  github.Ptr("GHSA-1"),
+		Summary: github.Ptr("untrusted text"),
+		Author:  &github.User{Login: github.Ptr("testuser")},
+		URL:     github.Ptr("https://api.github.com/repos/owner/repo/security-advisories/GHSA-1"),
+	}
+}
+
+func untrustedProjectItem() *github.ProjectV2Item {
+	return &github.ProjectV2Item{
+		ID:          github.Ptr(int64(1)),
+		ContentType: github.Ptr(github.ProjectV2ItemContentTypeIssue),
+		Content: &github.ProjectV2ItemContent{Issue: &github.Issue{
+			Number:        github.Ptr(1),
+			Title:         github.Ptr("untrusted text"),
+			User:          &github.User{Login: github.Ptr("testuser")},
+			RepositoryURL: github.Ptr("https://api.github.com/repos/owner/repo"),
+		}},
+	}
+}
+
+func searchedIssues() *github.IssuesSearchResult {
+	return &github.IssuesSearchResult{
+		Total: github.Ptr(2),
+		Issues: []*github.Issue{
+			{Number: github.Ptr(1), Title: github.Ptr("untrusted text"), User: &github.User{Login: github.Ptr("testuser")}, RepositoryURL: github.Ptr("https://api.github.com/repos/owner/repo")},
+			{Number: github.Ptr(2), Title: github.Ptr("safe text"), User: &github.User{Login: github.Ptr("maintainer")}, RepositoryURL: github.Ptr("https://api.github.com/repos/owner/repo")},
+		},
+	}
+}
+
+// TestReadOnlyToolsFilterUserContent fails when a read-only tool is neither checked by
+// userContentLockdownCases nor listed in lockdownExemptTools, so that new tools returning
+// user-authored text have to go through filterUserContent.
+func TestReadOnlyToolsFilterUserContent(t *testing.T) {
+	checked := make(map[string]bool)
+	for _, tc := range userContentLockdownCases {
+		checked[tc.tool] = true
+	}
+
+	readOnly := make(map[string]bool)
+	for _, tool := range AllTools(stubTranslation) {
+		if !tool.Tool.Annotations.ReadOnlyHint {
+			continue
+		}
+		name := tool.Tool.Name
+		readOnly[name] = true
+		if _, exempt := lockdownExemptTools[name]; !exempt && !checked[name] {
+			t.Errorf("read-only tool %q must filter user content with filterUserContent and be checked in userContentLockdownCases, or be listed in lockdownExemptTools", name)
+		}
+	}
+
+	for name := range lockdownExemptTools {
+		assert.True(t, readOnly[name], "exempt tool %q is not a read-only tool", name)
+	}
+	for name := range checked {
+		assert.True(t, readOnly[name], "checked tool %q is not a read-only tool", name)
+	}
+}
+
+func TestUserContentLockdown(t *testing.T) {
+	byName := make(map[string]int)
+	all := AllTools(translations.NullTranslationHelper)
+	for i, tool := range all {
+		byName[tool.Tool.Name] = i
+	}
+
+	for _, tc := range userContentLockdownCases {
+		t.Run(tc.tool+"/"+toString(tc.args["method"]), func(t *testing.T) {
+			i, ok := byName[tc.tool]
+			require.True(t, ok, "unknown tool %q", tc.tool)
+
+			httpClient := MockHTTPClientWithHandlers(tc.handlers(t))
+			deps := BaseDeps{
+				Client:          github.NewClient(httpClient),
+				GQLClient:       githubv4.NewClient(httpClient),
+				RepoAccessCache: stubRepoAccessCache(githubv4.NewClient(newRepoAccessHTTPClient()), 15*time.Minute),
+				Flags:           stubFeatureFlags(map[string]bool{"lockdown-mode": true}),
+			}
+			handler := all[i].Handler(deps)
+
+			request := createMCPRequest(tc.args)
+			result, err := handler(ContextWithDeps(context.Background(), deps), &request)
+			require.NoError(t, err)
+
+			if !tc.wantTrusted {
+				errorContent := getErrorResult(t, result)
+				assert.Contains(t, errorContent.Text, "restricted by lockdown mode")
+				return
+			}
+			textContent := getTextResult(t, result)
+			assert.NotContains(t, textContent.Text, "untrusted text")
+			assert.Contains(t, textContent.Text, "safe text")
+		})
+	}
+}
+
+func TestRepoFromAPIURL(t *testing.T) {
+	owner, repo := repoFromAPIURL("https://api.github.com/repos/octo-org/octo-repo")
+	assert.Equal(t, "octo-org", owner)
+	assert.Equal(t, "octo-repo", repo)
+
+	owner, repo = repoFromAPIURL("https://ghe.example.com/api/v3/repos/octo-org/octo-repo/issues/1")
+	assert.Equal(t, "octo-org", owner)
+	assert.Equal(t, "octo-repo", repo)
+
+	owner, repo = repoFromAPIURL("https://api.github.com/users/octocat")
+	assert.Empty(t, owner)
+	assert.Empty(t, repo)
+}
+
+func TestRestrictRefContent(t *testing.T) {
+	const sha = "abc123abc123abc123abc123abc123abc123abc1"
+	httpClient := MockHTTPClientWithHandlers(map[string]http.HandlerFunc{
+		GetReposByOwnerByRepo:              mockResponse(t, http.StatusOK, &github.Repository{DefaultBranch: github.Ptr("main"), Private: github.Ptr(false)}),
+		GetReposCompareByOwnerByRepoByBase: mockResponse(t, http.StatusOK, &github.CommitsComparison{Status: github.Ptr("ahead")}),
+	})
+	client := github.NewClient(httpClient)
+	deps := BaseDeps{
+		Client:          client,
+		RepoAccessCache: stubRepoAccessCache(githubv4.NewClient(newRepoAccessHTTPClient()), 15*time.Minute),
+		Flags:           stubFeatureFlags(map[string]bool{"lockdown-mode": true}),
+	}
+	ctx := context.Background()
+
+	// Branches and tags are trusted without looking them up
+	result, err := restrictRefContent(ctx, client, deps, "owner", "repo", "refs/heads/feature")
+	require.NoError(t, err)
+	assert.Nil(t, result)
+
+	// Commits on the default branch are trusted whoever authored them
+	result, err = restrictRefContent(ctx, client, deps, "owner", "repo", sha)
+	require.NoError(t, err)
+	assert.Nil(t, result)
+}
@@ -0,0 +1,179 @@
+// Package eventstore provides stores for the events of streamable HTTP sessions, so that
+// clients can resume an interrupted SSE stream with Last-Event-ID and receive the
+// progress notifications and results they missed.
+package eventstore
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"iter"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// DefaultMemoryBytes is the default bound on the events kept in memory across all sessions.
+const DefaultMemoryBytes = 10 << 20
+
+// NewMemory returns a store that keeps at most maxBytes of events in memory across all
+// sessions, dropping the oldest events of every stream first once the bound is reached.
+// A maxBytes of zero uses DefaultMemoryBytes.
+func NewMemory(maxBytes int) *mcp.MemoryEventStore {
+	store := mcp.NewMemoryEventStore(nil)
+	if maxBytes <= 0 {
+		maxBytes = DefaultMemoryBytes
+	}
+	store.SetMaxBytes(maxBytes)
+	return store
+}
+
+// sessionDirPrefix marks the directories a File store owns, so that nothing else in its
+// directory is removed.
+const sessionDirPrefix = "session-"
+
+// recordHeaderSize is the size of the length prefix of every stored event.
+const recordHeaderSize = 4
+
+// File is an mcp.EventStore that appends the events of each stream to a file, keeping
+// memory use independent of how much sessions have buffered. Storage is reclaimed when
+// a session closes, including when it is closed for being idle.
+type File struct {
+	dir string
+	mu  sync.Mutex
+}
+
+var _ mcp.EventStore = (*File)(nil)
+
+// NewFile creates a store under dir, creating the directory if needed. Sessions do not
+// survive a restart, so events left behind by a previous process are removed.
+func NewFile(dir string) (*File, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create event directory: %w", err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read event directory: %w", err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() && strings.HasPrefix(entry.Name(), sessionDirPrefix) {
+			if err := os.RemoveAll(filepath.Join(dir, entry.Name())); err != nil {
+				return nil, fmt.Errorf("failed to remove stale session events: %w", err)
+			}
+		}
+	}
+	return &File{dir: dir}, nil
+}
+
+// Open implements mcp.EventStore.
+func (s *File) Open(_ context.Context, sessionID, streamID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, err := s.openStream(sessionID, streamID, os.O_CREATE|os.O_WRONLY)
+	if err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// Append implements mcp.EventStore.
+func (s *File) Append(_ context.Context, sessionID, streamID string, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, err := s.openStream(sessionID, streamID, os.O_CREATE|os.O_WRONLY|os.O_APPEND)
+	if err != nil {
+		return err
+	}
+
+	record := make([]byte, recordHeaderSize+len(data))
+	binary.BigEndian.PutUint32(record, uint32(len(data))) //nolint:gosec // events are far smaller than 4 GiB
+	copy(record[recordHeaderSize:], data)
+	if _, err := f.Write(record); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to append event: %w", err)
+	}
+	return f.Close()
+}
+
+// After implements mcp.EventStore.
+func (s *File) After(_ context.Context, sessionID, streamID string, index int) iter.Seq2[[]byte, error] {
+	return func(yield func([]byte, error) bool) {
+		events, err := s.readStream(sessionID, streamID)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		for i := index + 1; i < len(events); i++ {
+			if !yield(events[i], nil) {
+				return
+			}
+		}
+	}
+}
+
+// SessionClosed implements mcp.EventStore.
+func (s *File) SessionClosed(_ context.Context, sessionID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return os.RemoveAll(s.sessionDir(sessionID))
+}
+
+// readStream returns all events of a stream. Events are read in full before any are
+// yielded, so that a partial read is reported as an error rather than as a short replay.
+func (s *File) readStream(sessionID, streamID string) ([][]byte, error) {
+	s.mu.Lock()
+	data, err := os.ReadFile(s.streamPath(sessionID, streamID))
+	s.mu.Unlock()
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("unknown stream %q in session %q", streamID, sessionID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read events: %w", err)
+	}
+
+	var events [][]byte
+	r := bytes.NewReader(data)
+	for r.Len() > 0 {
+		var size uint32
+		if err := binary.Read(r, binary.BigEndian, &size); err != nil {
+			return nil, fmt.Errorf("corrupt event stream %q in session %q: %w", streamID, sessionID, err)
+		}
+		if int64(size) > int64(r.Len()) {
+			return nil, fmt.Errorf("corrupt event stream %q in session %q: truncated event", streamID, sessionID)
+		}
+		event := make([]byte, size)
+		_, _ = r.Read(event)
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// openStream opens the file of a stream, creating its session directory if needed.
+// It must be called with s.mu held.
+func (s *File) openStream(sessionID, streamID string, flag int) (*os.File, error) {
+	if err := os.MkdirAll(s.sessionDir(sessionID), 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create session event directory: %w", err)
+	}
+	f, err := os.OpenFile(s.streamPath(sessionID, streamID), flag, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open event stream: %w", err)
+	}
+	return f, nil
+}
+
+// Session and stream IDs are chosen by the SDK and the client, so they are hex encoded
+// rather than used as path elements directly.
+func (s *File) sessionDir(sessionID string) string {
+	return filepath.Join(s.dir, sessionDirPrefix+hex.EncodeToString([]byte(sessionID)))
+}
+
+func (s *File) streamPath(sessionID, streamID string) string {
+	return filepath.Join(s.sessionDir(sessionID), hex.EncodeToString([]byte(streamID))+".events")
+}
@@ -0,0 +1,90 @@
+package eventstore
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func collect(t *testing.T, store mcp.EventStore, sessionID, streamID string, index int) []string {
+	t.Helper()
+	var events []string
+	for data, err := range store.After(context.Background(), sessionID, streamID, index) {
+		require.NoError(t, err)
+		events = append(events, string(data))
+	}
+	return events
+}
+
+func TestFile(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	store, err := NewFile(dir)
+	require.NoError(t, err)
+
+	require.NoError(t, store.Open(ctx, "session/1", "0"))
+	assert.Empty(t, collect(t, store, "session/1", "0", -1), "an opened stream has no events")
+
+	for _, event := range []string{"first", "", "third"} {
+		require.NoError(t, store.Append(ctx, "session/1", "0", []byte(event)))
+	}
+	require.NoError(t, store.Append(ctx, "session/1", "1", []byte("other stream")))
+	require.NoError(t, store.Append(ctx, "session-2", "0", []byte("other session")))
+
+	assert.Equal(t, []string{"first", "", "third"}, collect(t, store, "session/1", "0", -1))
+	assert.Equal(t, []string{"third"}, collect(t, store, "session/1", "0", 1))
+	assert.Empty(t, collect(t, store, "session/1", "0", 2))
+	assert.Equal(t, []string{"other stream"}, collect(t, store, "session/1", "1", -1))
+
+	for _, err := range store.After(ctx, "session/1", "missing", -1) {
+		require.ErrorContains(t, err, `unknown stream "missing"`)
+	}
+
+	require.NoError(t, store.SessionClosed(ctx, "session/1"))
+	for _, err := range store.After(ctx, "session/1", "0", -1) {
+		require.Error(t, err, "events of closed sessions are removed")
+	}
+	assert.Equal(t, []string{"other session"}, collect(t, store, "session-2", "0", -1))
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "session IDs are not used as paths")
+}
+
+func TestFileRemovesStaleSessions(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewFile(dir)
+	require.NoError(t, err)
+	require.NoError(t, store.Append(context.Background(), "stale", "0", []byte("event")))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "unrelated"), nil, 0o600))
+
+	_, err = NewFile(dir)
+	require.NoError(t, err)
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "unrelated", entries[0].Name(), "only session directories are removed")
+}
+
+func TestFileCorruptStream(t *testing.T) {
+	store, err := NewFile(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, store.Append(context.Background(), "session", "0", []byte("event")))
+
+	path := store.streamPath("session", "0")
+	require.NoError(t, os.Truncate(path, 6))
+	for _, err := range store.After(context.Background(), "session", "0", -1) {
+		require.ErrorContains(t, err, "truncated event")
+	}
+}
+
+func TestNewMemory(t *testing.T) {
+	assert.Equal(t, DefaultMemoryBytes, NewMemory(0).MaxBytes())
+	assert.Equal(t, 1024, NewMemory(1024).MaxBytes())
+}
@@ -6,16 +6,20 @@ import (
 	"log/slog"
 	"net/http"
 
+	"github.com/github/github-mcp-server/pkg/audit"
 	ghcontext "github.com/github/github-mcp-server/pkg/context"
 	"github.com/github/github-mcp-server/pkg/github"
+	"github.com/github/github-mcp-server/pkg/http/eventstore"
 	"github.com/github/github-mcp-server/pkg/http/middleware"
 	"github.com/github/github-mcp-server/pkg/http/oauth"
 	"github.com/github/github-mcp-server/pkg/inventory"
+	"github.com/github/github-mcp-server/pkg/metrics"
 	"github.com/github/github-mcp-server/pkg/scopes"
 	"github.com/github/github-mcp-server/pkg/translations"
 	"github.com/github/github-mcp-server/pkg/utils"
 	"github.com/go-chi/chi/v5"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type InventoryFactoryFunc func(r *http.Request) (*inventory.Inventory, error)
@@ -33,6 +37,11 @@ type Handler struct {
 	oauthCfg               *oauth.Config
 	scopeFetcher           scopes.FetcherInterface
 	schemaCache            *mcp.SchemaCache
+	metrics                *metrics.Metrics
+	tracerProvider         trace.TracerProvider
+	audit                  *audit.Logger
+	sessions               *sessionManager
+	rateLimit              *middleware.RateLimitConfig
 }
 
 type HandlerOptions struct {
@@ -41,6 +50,12 @@ type HandlerOptions struct {
 	OAuthConfig            *oauth.Config
 	ScopeFetcher           scopes.FetcherInterface
 	FeatureChecker         inventory.FeatureFlagChecker
+	Prompts                []inventory.ServerPrompt
+	Metrics                *metrics.Metrics
+	TracerProvider         trace.TracerProvider
+	Audit                  *audit.Logger
+	EventStore             mcp.EventStore
+	RateLimit              *middleware.RateLimitConfig
 }
 
 type HandlerOption func(*HandlerOptions)
@@ -75,6 +90,52 @@ func WithFeatureChecker(checker inventory.FeatureFlagChecker) HandlerOption {
 	}
 }
 
+// WithPrompts registers additional prompts, such as a user-defined prompt library,
+// with the default inventory factory.
+func WithPrompts(prompts []inventory.ServerPrompt) HandlerOption {
+	return func(o *HandlerOptions) {
+		o.Prompts = prompts
+	}
+}
+
+// WithMetrics records MCP request and tool call metrics, and counts active sessions. In
+// stateless mode every in-flight request is counted as a session.
+func WithMetrics(m *metrics.Metrics) HandlerOption {
+	return func(o *HandlerOptions) {
+		o.Metrics = m
+	}
+}
+
+// WithTracerProvider records a span for every MCP request, continuing the trace of an
+// incoming traceparent header.
+func WithTracerProvider(provider trace.TracerProvider) HandlerOption {
+	return func(o *HandlerOptions) {
+		o.TracerProvider = provider
+	}
+}
+
+// WithAudit records every tool call in the audit log.
+func WithAudit(auditor *audit.Logger) HandlerOption {
+	return func(o *HandlerOptions) {
+		o.Audit = auditor
+	}
+}
+
+// WithEventStore sets the store that events of stateful sessions are kept in for
+// resumption. It defaults to an in-memory store bounded by the server configuration.
+func WithEventStore(store mcp.EventStore) HandlerOption {
+	return func(o *HandlerOptions) {
+		o.EventStore = store
+	}
+}
+
+// WithRateLimit limits the rate and concurrency of tool calls per client.
+func WithRateLimit(cfg middleware.RateLimitConfig) HandlerOption {
+	return func(o *HandlerOptions) {
+		o.RateLimit = &cfg
+	}
+}
+
 func NewHTTPMcpHandler(
 	ctx context.Context,
 	cfg *ServerConfig,
@@ -100,13 +161,22 @@ func NewHTTPMcpHandler(
 
 	inventoryFactory := opts.InventoryFactory
 	if inventoryFactory == nil {
-		inventoryFactory = DefaultInventoryFactory(cfg, t, opts.FeatureChecker, scopeFetcher)
+		inventoryFactory = DefaultInventoryFactory(cfg, t, opts.FeatureChecker, scopeFetcher, opts.Prompts...)
 	}
 
 	// Create a shared schema cache to avoid repeated JSON schema reflection
 	// when a new MCP Server is created per request in stateless mode.
 	schemaCache := mcp.NewSchemaCache()
 
+	var sessions *sessionManager
+	if cfg.StatefulSessions {
+		eventStore := opts.EventStore
+		if eventStore == nil {
+			eventStore = eventstore.NewMemory(cfg.SessionEventBufferBytes)
+		}
+		sessions = newSessionManager(cfg, eventStore, opts.Metrics, githubUserResolver(deps), logger)
+	}
+
 	return &Handler{
 		ctx:                    ctx,
 		config:                 cfg,
@@ -119,17 +189,34 @@ func NewHTTPMcpHandler(
 		oauthCfg:               opts.OAuthConfig,
 		scopeFetcher:           scopeFetcher,
 		schemaCache:            schemaCache,
+		metrics:                opts.Metrics,
+		tracerProvider:         opts.TracerProvider,
+		audit:                  opts.Audit,
+		sessions:               sessions,
+		rateLimit:              opts.RateLimit,
 	}
 }
 
 func (h *Handler) RegisterMiddleware(r chi.Router) {
+	// Only the MCP routes require a client certificate, so that health probes without
+	// one still reach the rest of the listener
+	if h.config.TLSClientCAFile != "" {
+		r.Use(middleware.RequireClientCert)
+	}
 	r.Use(
+		middleware.WithClientIdentity(h.logger),
+		middleware.WithTraceContext,
 		middleware.ExtractUserToken(h.oauthCfg),
 		middleware.WithRequestConfig,
 		middleware.WithMCPParse(),
-		middleware.WithPATScopes(h.logger, h.scopeFetcher),
 	)
 
+	// Rate limit before scopes are fetched, so rejected calls cost no GitHub requests
+	if h.rateLimit != nil {
+		r.Use(middleware.WithRateLimit(*h.rateLimit))
+	}
+	r.Use(middleware.WithPATScopes(h.logger, h.scopeFetcher))
+
 	if h.config.ScopeChallenge {
 		r.Use(middleware.WithScopeChallenge(h.oauthCfg, h.scopeFetcher))
 	}
@@ -177,6 +264,44 @@ func withInsiders(next http.Handler) http.Handler {
 }
 
 func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.sessions != nil {
+		h.serveSession(w, r)
+		return
+	}
+
+	// Each request is served by its own stateless MCP server, so it is counted as a session
+	if h.metrics != nil {
+		h.metrics.SessionStarted()
+		defer h.metrics.SessionEnded()
+	}
+
+	inv, ok := h.buildInventory(w, r)
+	if !ok {
+		return
+	}
+
+	invToUse := inv
+	if methodInfo, ok := ghcontext.MCPMethod(r.Context()); ok && methodInfo != nil {
+		invToUse = inv.ForMCPRequest(methodInfo.Method, methodInfo.ItemName)
+	}
+
+	ghServer, ok := h.buildServer(w, r, invToUse)
+	if !ok {
+		return
+	}
+
+	mcpHandler := mcp.NewStreamableHTTPHandler(func(_ *http.Request) *mcp.Server {
+		return ghServer
+	}, &mcp.StreamableHTTPOptions{
+		Stateless: true,
+	})
+
+	mcpHandler.ServeHTTP(w, r)
+}
+
+// buildInventory builds the inventory for a request, writing an error response if it
+// can't be built.
+func (h *Handler) buildInventory(w http.ResponseWriter, r *http.Request) (*inventory.Inventory, bool) {
 	inv, err := h.inventoryFactoryFunc(r)
 	if err != nil {
 		if errors.Is(err, inventory.ErrUnknownTools) {
@@ -184,60 +309,69 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			if _, writeErr := w.Write([]byte(err.Error())); writeErr != nil {
 				h.logger.Error("failed to write response", "error", writeErr)
 			}
-			return
+			return nil, false
 		}
 
 		w.WriteHeader(http.StatusInternalServerError)
-		return
-	}
-
-	invToUse := inv
-	if methodInfo, ok := ghcontext.MCPMethod(r.Context()); ok && methodInfo != nil {
-		invToUse = inv.ForMCPRequest(methodInfo.Method, methodInfo.ItemName)
+		return nil, false
 	}
+	return inv, true
+}
 
-	ghServer, err := h.githubMcpServerFactory(r, h.deps, invToUse, &github.MCPServerConfig{
-		Version:           h.config.Version,
-		Translator:        h.t,
-		ContentWindowSize: h.config.ContentWindowSize,
-		Logger:            h.logger,
-		RepoAccessTTL:     h.config.RepoAccessCacheTTL,
+// buildServer creates the MCP server for a request, writing an error response if it
+// can't be created.
+func (h *Handler) buildServer(w http.ResponseWriter, r *http.Request, inv *inventory.Inventory) (*mcp.Server, bool) {
+	serverConfig := &github.MCPServerConfig{
+		Version:                 h.config.Version,
+		Translator:              h.t,
+		ContentWindowSize:       h.config.ContentWindowSize,
+		StrictToolAliases:       h.config.StrictToolAliases,
+		Logger:                  h.logger,
+		RepoAccessTTL:           h.config.RepoAccessCacheTTL,
+		Metrics:                 h.metrics,
+		TracerProvider:          h.tracerProvider,
+		Audit:                   h.audit,
+		RateLimitMeta:           h.config.RateLimitMeta,
+		LockdownPlaceholders:    h.config.LockdownPlaceholders,
+		InjectionDetector:       h.config.InjectionDetector,
+		DisableInjectionMarkers: h.config.DisableInjectionMarkers,
 		// Explicitly set empty capabilities. inv.ForMCPRequest currently returns nothing for Initialize.
 		ServerOptions: []github.MCPServerOption{
 			func(so *mcp.ServerOptions) {
 				so.Capabilities = &mcp.ServerCapabilities{
 					Tools:     &mcp.ToolCapabilities{},
-					Resources: &mcp.ResourceCapabilities{},
+					Resources: &mcp.ResourceCapabilities{Subscribe: so.SubscribeHandler != nil},
 					Prompts:   &mcp.PromptCapabilities{},
 				}
 				so.SchemaCache = h.schemaCache
 			},
 		},
-	})
+	}
+	// Only stateful sessions live long enough to be notified of resource updates
+	if h.sessions != nil {
+		serverConfig.ResourcePollInterval = h.config.ResourcePollInterval
+		serverConfig.MaxResourceSubscriptions = h.config.MaxResourceSubscriptions
+	}
 
+	ghServer, err := h.githubMcpServerFactory(r, h.deps, inv, serverConfig)
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
-		return
+		return nil, false
 	}
-
-	mcpHandler := mcp.NewStreamableHTTPHandler(func(_ *http.Request) *mcp.Server {
-		return ghServer
-	}, &mcp.StreamableHTTPOptions{
-		Stateless: true,
-	})
-
-	mcpHandler.ServeHTTP(w, r)
+	return ghServer, true
 }
 
 func DefaultGitHubMCPServerFactory(r *http.Request, deps github.ToolDependencies, inventory *inventory.Inventory, cfg *github.MCPServerConfig) (*mcp.Server, error) {
 	return github.NewMCPServer(r.Context(), cfg, deps, inventory)
 }
 
-// DefaultInventoryFactory creates the default inventory factory for HTTP mode
-func DefaultInventoryFactory(_ *ServerConfig, t translations.TranslationHelperFunc, featureChecker inventory.FeatureFlagChecker, scopeFetcher scopes.FetcherInterface) InventoryFactoryFunc {
+// DefaultInventoryFactory creates the default inventory factory for HTTP mode.
+// Any prompts given are registered in addition to the built-in prompts.
+func DefaultInventoryFactory(_ *ServerConfig, t translations.TranslationHelperFunc, featureChecker inventory.FeatureFlagChecker, scopeFetcher scopes.FetcherInterface, prompts ...inventory.ServerPrompt) InventoryFactoryFunc {
 	return func(r *http.Request) (*inventory.Inventory, error) {
 		b := github.NewInventory(t).
-			WithDeprecatedAliases(github.DeprecatedToolAliases).
+			AddPrompts(prompts...).
+			WithDeprecatedAliases(github.DeprecatedToolAliasTargets()).
 			WithFeatureChecker(featureChecker)
 
 		b = InventoryFiltersForRequest(r, b)
@@ -0,0 +1,193 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/github/github-mcp-server/pkg/scopes"
+	"github.com/github/github-mcp-server/pkg/utils"
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	// HealthzPath reports whether the process is alive.
+	HealthzPath = "/healthz"
+	// ReadyzPath reports whether the server can serve MCP requests.
+	ReadyzPath = "/readyz"
+
+	healthStatusOK          = "ok"
+	healthStatusUnavailable = "unavailable"
+
+	// readinessTimeout bounds the time spent running readiness checks.
+	readinessTimeout = 5 * time.Second
+	// githubAPICheckInterval is how long a GitHub API reachability result is reused, so
+	// that frequent probes don't turn into a request per probe.
+	githubAPICheckInterval = 15 * time.Second
+)
+
+// HealthCheck is a named readiness check. A non-nil error marks the server as not ready.
+type HealthCheck struct {
+	Name  string
+	Check func(ctx context.Context) error
+}
+
+// HealthHandler serves the liveness and readiness probes.
+type HealthHandler struct {
+	checks       []HealthCheck
+	shuttingDown atomic.Bool
+}
+
+// HealthResponse is the JSON body of the health endpoints.
+type HealthResponse struct {
+	Status     string                     `json:"status"`
+	Components map[string]ComponentStatus `json:"components,omitempty"`
+}
+
+// ComponentStatus is the result of one readiness check.
+type ComponentStatus struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// NewHealthHandler creates a health handler that runs checks for readiness, in addition
+// to reporting shutdown.
+func NewHealthHandler(checks ...HealthCheck) *HealthHandler {
+	return &HealthHandler{checks: checks}
+}
+
+// SetShuttingDown marks the server as not ready, so that load balancers stop routing new
+// requests to it while in-flight requests drain.
+func (h *HealthHandler) SetShuttingDown() {
+	h.shuttingDown.Store(true)
+}
+
+// RegisterRoutes registers the health endpoints.
+func (h *HealthHandler) RegisterRoutes(r chi.Router) {
+	r.Get(HealthzPath, h.handleHealthz)
+	r.Get(ReadyzPath, h.handleReadyz)
+}
+
+func (h *HealthHandler) handleHealthz(w http.ResponseWriter, _ *http.Request) {
+	writeHealthResponse(w, http.StatusOK, HealthResponse{Status: healthStatusOK})
+}
+
+func (h *HealthHandler) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), readinessTimeout)
+	defer cancel()
+
+	components := make(map[string]ComponentStatus, len(h.checks)+1)
+	if h.shuttingDown.Load() {
+		components["shutdown"] = ComponentStatus{Status: healthStatusUnavailable, Error: "server is shutting down"}
+	} else {
+		components["shutdown"] = ComponentStatus{Status: healthStatusOK}
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, check := range h.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			status := ComponentStatus{Status: healthStatusOK}
+			if err := check.Check(ctx); err != nil {
+				status = ComponentStatus{Status: healthStatusUnavailable, Error: err.Error()}
+			}
+			mu.Lock()
+			components[check.Name] = status
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	resp := HealthResponse{Status: healthStatusOK, Components: components}
+	code := http.StatusOK
+	for _, status := range components {
+		if status.Status != healthStatusOK {
+			resp.Status = healthStatusUnavailable
+			code = http.StatusServiceUnavailable
+			break
+		}
+	}
+	writeHealthResponse(w, code, resp)
+}
+
+func writeHealthResponse(w http.ResponseWriter, code int, resp HealthResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// GitHubAPIHealthCheck checks that the configured GitHub API host is reachable. It calls
+// the rate limit endpoint, which does not count against the rate limit and does not need
+// a token. Results are reused for a short while.
+func GitHubAPIHealthCheck(apiHost utils.APIHostResolver, client *http.Client) HealthCheck {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	var mu sync.Mutex
+	var checkedAt time.Time
+	var lastErr error
+	return HealthCheck{
+		Name: "github_api",
+		Check: func(ctx context.Context) error {
+			mu.Lock()
+			defer mu.Unlock()
+			if !checkedAt.IsZero() && time.Since(checkedAt) < githubAPICheckInterval {
+				return lastErr
+			}
+			lastErr = pingGitHubAPI(ctx, apiHost, client)
+			checkedAt = time.Now()
+			return lastErr
+		},
+	}
+}
+
+func pingGitHubAPI(ctx context.Context, apiHost utils.APIHostResolver, client *http.Client) error {
+	baseURL, err := apiHost.BaseRESTURL(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get base REST URL: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL.JoinPath("rate_limit").String(), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("GitHub API is unreachable: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	// Any response other than a server error shows the API is reachable
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("GitHub API returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// ToolScopeMapHealthCheck checks that the tool scope map used for scope filtering and
+// challenges has been initialized.
+func ToolScopeMapHealthCheck() HealthCheck {
+	return HealthCheck{
+		Name: "tool_scope_map",
+		Check: func(context.Context) error {
+			scopeMap, err := scopes.GetToolScopeMap()
+			if err != nil {
+				return err
+			}
+			if len(scopeMap) == 0 {
+				return errors.New("tool scope map is not initialized")
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,184 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+
+	"github.com/github/github-mcp-server/pkg/scopes"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testAPIHostResolver struct {
+	baseURL string
+}
+
+func (t testAPIHostResolver) BaseRESTURL(_ context.Context) (*url.URL, error) {
+	return url.Parse(t.baseURL)
+}
+func (t testAPIHostResolver) GraphqlURL(_ context.Context) (*url.URL, error) {
+	return nil, nil
+}
+func (t testAPIHostResolver) UploadURL(_ context.Context) (*url.URL, error) {
+	return nil, nil
+}
+func (t testAPIHostResolver) RawURL(_ context.Context) (*url.URL, error) {
+	return nil, nil
+}
+
+func serveHealth(t *testing.T, h *HealthHandler, path string) (int, HealthResponse) {
+	t.Helper()
+	r := chi.NewRouter()
+	h.RegisterRoutes(r)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+	var resp HealthResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	return rec.Code, resp
+}
+
+func TestHealthz(t *testing.T) {
+	h := NewHealthHandler(HealthCheck{Name: "github_api", Check: func(context.Context) error {
+		return errors.New("unreachable")
+	}})
+	h.SetShuttingDown()
+
+	// Liveness does not depend on readiness checks or shutdown
+	code, resp := serveHealth(t, h, HealthzPath)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, HealthResponse{Status: "ok"}, resp)
+}
+
+func TestReadyz(t *testing.T) {
+	passing := HealthCheck{Name: "github_api", Check: func(context.Context) error { return nil }}
+	failing := HealthCheck{Name: "tool_scope_map", Check: func(context.Context) error {
+		return errors.New("tool scope map is not initialized")
+	}}
+
+	tests := []struct {
+		name             string
+		checks           []HealthCheck
+		shuttingDown     bool
+		expectedCode     int
+		expectedResponse HealthResponse
+	}{
+		{
+			name:         "all checks pass",
+			checks:       []HealthCheck{passing},
+			expectedCode: http.StatusOK,
+			expectedResponse: HealthResponse{
+				Status: "ok",
+				Components: map[string]ComponentStatus{
+					"github_api": {Status: "ok"},
+					"shutdown":   {Status: "ok"},
+				},
+			},
+		},
+		{
+			name:         "a check fails",
+			checks:       []HealthCheck{passing, failing},
+			expectedCode: http.StatusServiceUnavailable,
+			expectedResponse: HealthResponse{
+				Status: "unavailable",
+				Components: map[string]ComponentStatus{
+					"github_api":     {Status: "ok"},
+					"tool_scope_map": {Status: "unavailable", Error: "tool scope map is not initialized"},
+					"shutdown":       {Status: "ok"},
+				},
+			},
+		},
+		{
+			name:         "shutting down",
+			checks:       []HealthCheck{passing},
+			shuttingDown: true,
+			expectedCode: http.StatusServiceUnavailable,
+			expectedResponse: HealthResponse{
+				Status: "unavailable",
+				Components: map[string]ComponentStatus{
+					"github_api": {Status: "ok"},
+					"shutdown":   {Status: "unavailable", Error: "server is shutting down"},
+				},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			h := NewHealthHandler(tc.checks...)
+			if tc.shuttingDown {
+				h.SetShuttingDown()
+			}
+
+			code, resp := serveHealth(t, h, ReadyzPath)
+			assert.Equal(t, tc.expectedCode, code)
+			assert.Equal(t, tc.expectedResponse, resp)
+		})
+	}
+}
+
+func TestGitHubAPIHealthCheck(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		expectedErr string
+	}{
+		{name: "reachable", status: http.StatusOK},
+		{name: "unauthenticated responses are reachable", status: http.StatusUnauthorized},
+		{name: "server error", status: http.StatusBadGateway, expectedErr: "GitHub API returned status 502"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var calls atomic.Int32
+			api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls.Add(1)
+				assert.Equal(t, "/rate_limit", r.URL.Path)
+				assert.Empty(t, r.Header.Get("Authorization"))
+				w.WriteHeader(tc.status)
+			}))
+			t.Cleanup(api.Close)
+
+			check := GitHubAPIHealthCheck(testAPIHostResolver{baseURL: api.URL + "/"}, api.Client())
+			assert.Equal(t, "github_api", check.Name)
+
+			for range 3 {
+				err := check.Check(context.Background())
+				if tc.expectedErr == "" {
+					assert.NoError(t, err)
+				} else {
+					assert.EqualError(t, err, tc.expectedErr)
+				}
+			}
+			assert.Equal(t, int32(1), calls.Load(), "results are reused between probes")
+		})
+	}
+}
+
+func TestGitHubAPIHealthCheckUnreachable(t *testing.T) {
+	api := httptest.NewServer(http.NotFoundHandler())
+	api.Close()
+
+	check := GitHubAPIHealthCheck(testAPIHostResolver{baseURL: api.URL + "/"}, nil)
+	require.ErrorContains(t, check.Check(context.Background()), "GitHub API is unreachable")
+}
+
+func TestToolScopeMapHealthCheck(t *testing.T) {
+	t.Cleanup(func() { scopes.SetGlobalToolScopeMap(nil) })
+	check := ToolScopeMapHealthCheck()
+
+	scopes.SetGlobalToolScopeMap(nil)
+	require.EqualError(t, check.Check(context.Background()), "tool scope map is not initialized")
+
+	scopes.SetGlobalToolScopeMap(scopes.ToolScopeMap{"get_me": {}})
+	require.NoError(t, check.Check(context.Background()))
+}
@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+
+	ghcontext "github.com/github/github-mcp-server/pkg/context"
+)
+
+// WithClientIdentity stores the identity of a verified mutual TLS client certificate in the
+// request context, where it is available for logging and policy decisions. Requests without
+// a verified client certificate pass through unchanged.
+func WithClientIdentity(logger *slog.Logger) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			identity := ClientIdentityFromCertificate(r.TLS.VerifiedChains[0][0])
+			if logger != nil {
+				logger.Debug("client certificate verified",
+					"common_name", identity.CommonName,
+					"serial", identity.SerialNumber,
+					"path", r.URL.Path,
+				)
+			}
+
+			next.ServeHTTP(w, r.WithContext(ghcontext.WithClientIdentity(r.Context(), identity)))
+		})
+	}
+}
+
+// RequireClientCert rejects requests that did not present a client certificate verified
+// against the client CA bundle. The TLS handshake only rejects certificates that fail
+// verification, so that endpoints such as health checks stay reachable without one; the
+// routes that require mutual TLS are wrapped with this middleware instead.
+func RequireClientCert(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 {
+			http.Error(w, "a client certificate signed by a trusted CA is required", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// ClientIdentityFromCertificate extracts the client identity from a certificate.
+func ClientIdentityFromCertificate(cert *x509.Certificate) *ghcontext.ClientIdentity {
+	fingerprint := sha256.Sum256(cert.Raw)
+	identity := &ghcontext.ClientIdentity{
+		CommonName:     cert.Subject.CommonName,
+		Organization:   cert.Subject.Organization,
+		DNSNames:       cert.DNSNames,
+		EmailAddresses: cert.EmailAddresses,
+		SerialNumber:   cert.SerialNumber.Text(16),
+		Fingerprint:    hex.EncodeToString(fingerprint[:]),
+	}
+	for _, uri := range cert.URIs {
+		identity.URIs = append(identity.URIs, uri.String())
+	}
+	return identity
+}
@@ -0,0 +1,115 @@
+package middleware
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	ghcontext "github.com/github/github-mcp-server/pkg/context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithClientIdentity(t *testing.T) {
+	spiffeID, err := url.Parse("spiffe://example.org/ci")
+	require.NoError(t, err)
+	cert := &x509.Certificate{
+		Raw:            []byte("certificate"),
+		SerialNumber:   big.NewInt(0xbeef),
+		Subject:        pkix.Name{CommonName: "ci-runner", Organization: []string{"Octo Org"}},
+		DNSNames:       []string{"ci.example.org"},
+		EmailAddresses: []string{"ci@example.org"},
+		URIs:           []*url.URL{spiffeID},
+	}
+
+	tests := []struct {
+		name             string
+		tls              *tls.ConnectionState
+		expectedIdentity *ghcontext.ClientIdentity
+	}{
+		{
+			name: "plain HTTP",
+		},
+		{
+			name: "TLS without client certificate",
+			tls:  &tls.ConnectionState{},
+		},
+		{
+			name: "verified client certificate",
+			tls:  &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}},
+			expectedIdentity: &ghcontext.ClientIdentity{
+				CommonName:     "ci-runner",
+				Organization:   []string{"Octo Org"},
+				DNSNames:       []string{"ci.example.org"},
+				EmailAddresses: []string{"ci@example.org"},
+				URIs:           []string{"spiffe://example.org/ci"},
+				SerialNumber:   "beef",
+				Fingerprint:    "03d66dd08835c1ca3f128cceacd1f31ac94163096b20f445ae84285bc0832d72",
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var identity *ghcontext.ClientIdentity
+			var found bool
+			handler := WithClientIdentity(nil)(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				identity, found = ghcontext.GetClientIdentity(r.Context())
+			}))
+
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			req.TLS = tc.tls
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			if tc.expectedIdentity == nil {
+				assert.False(t, found)
+				return
+			}
+			require.True(t, found)
+			assert.Equal(t, tc.expectedIdentity, identity)
+		})
+	}
+}
+
+func TestRequireClientCert(t *testing.T) {
+	tests := []struct {
+		name         string
+		tls          *tls.ConnectionState
+		expectedCode int
+	}{
+		{
+			name:         "plain HTTP",
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:         "TLS without client certificate",
+			tls:          &tls.ConnectionState{},
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:         "verified client certificate",
+			tls:          &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{{Raw: []byte("certificate")}}}},
+			expectedCode: http.StatusOK,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			handler := RequireClientCert(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			req.TLS = tc.tls
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedCode, rec.Code)
+		})
+	}
+}
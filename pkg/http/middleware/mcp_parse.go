@@ -12,8 +12,9 @@ import (
 // mcpJSONRPCRequest represents the structure of an MCP JSON-RPC request.
 // We only parse the fields needed for routing and optimization.
 type mcpJSONRPCRequest struct {
-	JSONRPC string `json:"jsonrpc"`
-	Method  string `json:"method"`
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
 	Params  struct {
 		// For tools/call
 		Name      string          `json:"name,omitempty"`
@@ -22,6 +23,11 @@ type mcpJSONRPCRequest struct {
 		// Name is shared with tools/call
 		// For resources/read
 		URI string `json:"uri,omitempty"`
+		// For completion/complete
+		Ref struct {
+			Type string `json:"type,omitempty"`
+			Name string `json:"name,omitempty"`
+		} `json:"ref"`
 	} `json:"params"`
 }
 
@@ -85,6 +91,7 @@ func WithMCPParse() func(http.Handler) http.Handler {
 
 			// Build the MCPMethodInfo
 			methodInfo := &ghcontext.MCPMethodInfo{
+				ID:     mcpReq.ID,
 				Method: mcpReq.Method,
 			}
 
@@ -112,6 +119,10 @@ func WithMCPParse() func(http.Handler) http.Handler {
 				methodInfo.ItemName = mcpReq.Params.Name
 			case "resources/read":
 				methodInfo.ItemName = mcpReq.Params.URI
+			case "completion/complete":
+				if mcpReq.Params.Ref.Type == "ref/prompt" {
+					methodInfo.ItemName = mcpReq.Params.Ref.Name
+				}
 			default:
 				// Whatever
 			}
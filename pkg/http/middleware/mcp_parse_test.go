@@ -123,6 +123,23 @@ func TestWithMCPParse(t *testing.T) {
 			expectedMethod: "resources/read",
 			expectedItem:   "repo://github/github-mcp-server",
 		},
+		{
+			name:           "completion/complete parses prompt name",
+			method:         http.MethodPost,
+			path:           "/mcp",
+			body:           `{"jsonrpc":"2.0","method":"completion/complete","params":{"ref":{"type":"ref/prompt","name":"my_prompt"},"argument":{"name":"owner","value":"gi"}}}`,
+			expectInfo:     true,
+			expectedMethod: "completion/complete",
+			expectedItem:   "my_prompt",
+		},
+		{
+			name:           "completion/complete for a resource has no item name",
+			method:         http.MethodPost,
+			path:           "/mcp",
+			body:           `{"jsonrpc":"2.0","method":"completion/complete","params":{"ref":{"type":"ref/resource","uri":"repo://{owner}/{repo}/contents{/path*}"},"argument":{"name":"owner","value":"gi"}}}`,
+			expectInfo:     true,
+			expectedMethod: "completion/complete",
+		},
 		{
 			name:           "initialize method parses correctly",
 			method:         http.MethodPost,
@@ -189,3 +206,30 @@ func TestWithMCPParse_BodyRestoration(t *testing.T) {
 
 	assert.Equal(t, originalBody, capturedBody, "body should be restored for downstream handlers")
 }
+
+func TestWithMCPParse_RequestID(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		expectedID string
+	}{
+		{name: "numeric ID", body: `{"jsonrpc":"2.0","id":42,"method":"tools/list"}`, expectedID: `42`},
+		{name: "string ID", body: `{"jsonrpc":"2.0","id":"abc","method":"tools/list"}`, expectedID: `"abc"`},
+		{name: "notification", body: `{"jsonrpc":"2.0","method":"notifications/initialized"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var capturedInfo *ghcontext.MCPMethodInfo
+			handler := WithMCPParse()(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				capturedInfo, _ = ghcontext.MCPMethod(r.Context())
+			}))
+
+			req := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(tt.body))
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			require.NotNil(t, capturedInfo)
+			assert.Equal(t, tt.expectedID, string(capturedInfo.ID))
+		})
+	}
+}
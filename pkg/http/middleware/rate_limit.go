@@ -0,0 +1,351 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	ghcontext "github.com/github/github-mcp-server/pkg/context"
+)
+
+// ToolClass groups tools that share a rate limit.
+type ToolClass string
+
+const (
+	// ToolClassDefault applies to tools that are in no other class, and to classes
+	// without limits of their own. Those classes share its buckets and concurrency, so
+	// leaving a class unconfigured folds it into the default limit.
+	ToolClassDefault ToolClass = "default"
+	// ToolClassSearch applies to search tools, which GitHub rate limits separately and
+	// more strictly.
+	ToolClassSearch ToolClass = "search"
+	// ToolClassWrite applies to tools that modify GitHub state.
+	ToolClassWrite ToolClass = "write"
+)
+
+// RateLimitErrorCode is the JSON-RPC error code of rejected tool calls. It is in the
+// range reserved for server errors, and echoes HTTP 429.
+const RateLimitErrorCode = -32029
+
+const (
+	// idleClientTTL is how long the state of a client without calls is kept.
+	idleClientTTL = 10 * time.Minute
+	// concurrencyRetryAfter is the retry hint for calls rejected by a concurrency limit,
+	// which has no schedule to derive a hint from.
+	concurrencyRetryAfter = time.Second
+)
+
+// Limit bounds the tool calls of one class made by one client.
+type Limit struct {
+	// Rate is the sustained number of calls per second. Zero disables rate limiting.
+	Rate float64
+	// Burst is the number of calls that can be made at once. It defaults to one.
+	Burst int
+	// MaxConcurrent is the number of calls that can be in flight at once. Zero means no limit.
+	MaxConcurrent int
+}
+
+// RateLimitConfig configures WithRateLimit.
+type RateLimitConfig struct {
+	// Limits per tool class. Classes without an entry share the ToolClassDefault limit
+	// and its state.
+	Limits map[ToolClass]Limit
+	// Classify returns the class of a tool. When nil, every tool is in ToolClassDefault.
+	Classify func(toolName string) ToolClass
+	// Logger reports rejected calls.
+	Logger *slog.Logger
+}
+
+// WithRateLimit limits the rate and concurrency of tool calls per client, so that one
+// runaway client can't exhaust a shared GitHub rate limit. Clients are identified by
+// their mutual TLS certificate, or otherwise by a hash of their token. Rejected calls are
+// answered with a JSON-RPC error that includes a retry hint, also sent as Retry-After.
+//
+// It must run after WithMCPParse and ExtractUserToken.
+func WithRateLimit(cfg RateLimitConfig) func(http.Handler) http.Handler {
+	limiter := newRateLimiter(cfg, time.Now)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			methodInfo, ok := ghcontext.MCPMethod(r.Context())
+			if !ok || methodInfo == nil || methodInfo.Method != "tools/call" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			key := rateLimitKey(r)
+			if key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			class := limiter.classify(methodInfo.ItemName)
+			release, rejection := limiter.acquire(key, class)
+			if rejection != nil {
+				if cfg.Logger != nil {
+					cfg.Logger.Warn("tool call rate limited",
+						"tool", methodInfo.ItemName, "class", class, "reason", rejection.Reason)
+				}
+				writeRateLimitError(w, methodInfo.ID, methodInfo.ItemName, rejection)
+				return
+			}
+			defer release()
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// rateLimitKey identifies the client making a request.
+func rateLimitKey(r *http.Request) string {
+	if identity, ok := ghcontext.GetClientIdentity(r.Context()); ok && identity != nil {
+		return "client:" + identity.Fingerprint
+	}
+	if tokenInfo, ok := ghcontext.GetTokenInfo(r.Context()); ok && tokenInfo != nil && tokenInfo.Token != "" {
+		sum := sha256.Sum256([]byte(tokenInfo.Token))
+		return "token:" + hex.EncodeToString(sum[:])
+	}
+	return ""
+}
+
+// rateLimitRejection describes why a call was rejected.
+type rateLimitRejection struct {
+	Class      ToolClass
+	Reason     string
+	RetryAfter time.Duration
+}
+
+type rateLimiter struct {
+	cfg RateLimitConfig
+	now func() time.Time
+
+	mu        sync.Mutex
+	clients   map[string]*clientLimits
+	lastSweep time.Time
+}
+
+// clientLimits is the state of one client.
+type clientLimits struct {
+	buckets  map[ToolClass]*tokenBucket
+	inFlight map[ToolClass]int
+	lastUsed time.Time
+}
+
+type tokenBucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+func newRateLimiter(cfg RateLimitConfig, now func() time.Time) *rateLimiter {
+	return &rateLimiter{
+		cfg:       cfg,
+		now:       now,
+		clients:   make(map[string]*clientLimits),
+		lastSweep: now(),
+	}
+}
+
+func (l *rateLimiter) classify(toolName string) ToolClass {
+	if l.cfg.Classify == nil {
+		return ToolClassDefault
+	}
+	return l.cfg.Classify(toolName)
+}
+
+// limit returns the class whose limit and state apply to calls of class, and that limit.
+// Classes without a limit of their own are counted as ToolClassDefault.
+func (l *rateLimiter) limit(class ToolClass) (ToolClass, Limit) {
+	if limit, ok := l.cfg.Limits[class]; ok {
+		return class, limit
+	}
+	return ToolClassDefault, l.cfg.Limits[ToolClassDefault]
+}
+
+// acquire admits a call, returning a function that must be called when it completes, or
+// the reason it was rejected.
+func (l *rateLimiter) acquire(key string, class ToolClass) (func(), *rateLimitRejection) {
+	class, limit := l.limit(class)
+	now := l.now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.sweep(now)
+
+	client, ok := l.clients[key]
+	if !ok {
+		client = &clientLimits{
+			buckets:  make(map[ToolClass]*tokenBucket),
+			inFlight: make(map[ToolClass]int),
+		}
+		l.clients[key] = client
+	}
+	client.lastUsed = now
+
+	if limit.MaxConcurrent > 0 && client.inFlight[class] >= limit.MaxConcurrent {
+		return nil, &rateLimitRejection{Class: class, Reason: "concurrency", RetryAfter: concurrencyRetryAfter}
+	}
+
+	if limit.Rate > 0 {
+		burst := float64(max(limit.Burst, 1))
+		bucket, ok := client.buckets[class]
+		if !ok {
+			bucket = &tokenBucket{tokens: burst, updated: now}
+			client.buckets[class] = bucket
+		}
+		bucket.tokens = math.Min(burst, bucket.tokens+now.Sub(bucket.updated).Seconds()*limit.Rate)
+		bucket.updated = now
+		if bucket.tokens < 1 {
+			wait := time.Duration((1 - bucket.tokens) / limit.Rate * float64(time.Second))
+			return nil, &rateLimitRejection{Class: class, Reason: "rate", RetryAfter: wait}
+		}
+		bucket.tokens--
+	}
+
+	client.inFlight[class]++
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.mu.Lock()
+			defer l.mu.Unlock()
+			client.inFlight[class]--
+			client.lastUsed = l.now()
+		})
+	}, nil
+}
+
+// sweep forgets clients that have been idle long enough for their buckets to refill. It
+// must be called with l.mu held.
+func (l *rateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < idleClientTTL {
+		return
+	}
+	l.lastSweep = now
+	for key, client := range l.clients {
+		if now.Sub(client.lastUsed) < idleClientTTL {
+			continue
+		}
+		busy := false
+		for _, n := range client.inFlight {
+			busy = busy || n > 0
+		}
+		if !busy {
+			delete(l.clients, key)
+		}
+	}
+}
+
+// writeRateLimitError answers a rejected call with a JSON-RPC error. The HTTP status is
+// 200 so that MCP clients deliver the error to the caller instead of failing the transport.
+func writeRateLimitError(w http.ResponseWriter, id json.RawMessage, toolName string, rejection *rateLimitRejection) {
+	retrySeconds := int(math.Ceil(rejection.RetryAfter.Seconds()))
+	retrySeconds = max(retrySeconds, 1)
+
+	message := fmt.Sprintf("rate limit exceeded for %s tools, retry after %ds", rejection.Class, retrySeconds)
+	if rejection.Reason == "concurrency" {
+		message = fmt.Sprintf("too many concurrent calls to %s tools, retry after %ds", rejection.Class, retrySeconds)
+	}
+
+	if len(id) == 0 {
+		id = json.RawMessage("null")
+	}
+	resp := map[string]any{
+		"jsonrpc": "2.0",
+		"id":      id,
+		"error": map[string]any{
+			"code":    RateLimitErrorCode,
+			"message": message,
+			"data": map[string]any{
+				"tool":              toolName,
+				"toolClass":         rejection.Class,
+				"reason":            rejection.Reason,
+				"retryAfterSeconds": retrySeconds,
+			},
+		},
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Retry-After", strconv.Itoa(retrySeconds))
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// ParseRateLimits parses per-class limits from a rate specification such as
+// "default=600/m,search=30/m,write=60/m" and a concurrency specification such as
+// "default=8,search=2". A rate of N per second, minute or hour also allows bursts of N
+// calls. Either specification may be empty.
+func ParseRateLimits(rates, concurrency string) (map[ToolClass]Limit, error) {
+	limits := make(map[ToolClass]Limit)
+
+	err := parseClassSpec(rates, func(class ToolClass, value string) error {
+		count, unit, ok := strings.Cut(value, "/")
+		if !ok {
+			return fmt.Errorf("rate %q must be of the form N/s, N/m or N/h", value)
+		}
+		n, err := strconv.Atoi(count)
+		if err != nil || n <= 0 {
+			return fmt.Errorf("rate %q must have a positive count", value)
+		}
+		var per time.Duration
+		switch unit {
+		case "s":
+			per = time.Second
+		case "m":
+			per = time.Minute
+		case "h":
+			per = time.Hour
+		default:
+			return fmt.Errorf("rate %q must be per s, m or h", value)
+		}
+		limit := limits[class]
+		limit.Rate = float64(n) / per.Seconds()
+		limit.Burst = n
+		limits[class] = limit
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = parseClassSpec(concurrency, func(class ToolClass, value string) error {
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			return fmt.Errorf("concurrency limit %q must be a positive number", value)
+		}
+		limit := limits[class]
+		limit.MaxConcurrent = n
+		limits[class] = limit
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return limits, nil
+}
+
+func parseClassSpec(spec string, parse func(ToolClass, string) error) error {
+	if strings.TrimSpace(spec) == "" {
+		return nil
+	}
+	for _, entry := range strings.Split(spec, ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok {
+			return fmt.Errorf("limit %q must be of the form class=value", entry)
+		}
+		class := ToolClass(strings.TrimSpace(name))
+		switch class {
+		case ToolClassDefault, ToolClassSearch, ToolClassWrite:
+		default:
+			return fmt.Errorf("unknown tool class %q, expected default, search or write", class)
+		}
+		if err := parse(class, strings.TrimSpace(value)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,241 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	ghcontext "github.com/github/github-mcp-server/pkg/context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeClock struct{ now time.Time }
+
+func (c *fakeClock) Now() time.Time          { return c.now }
+func (c *fakeClock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+func TestRateLimiterTokenBucket(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	limiter := newRateLimiter(RateLimitConfig{
+		Limits: map[ToolClass]Limit{ToolClassDefault: {Rate: 1, Burst: 2}},
+	}, clock.Now)
+
+	for range 2 {
+		release, rejection := limiter.acquire("alice", ToolClassDefault)
+		require.Nil(t, rejection, "calls up to the burst are admitted")
+		release()
+	}
+
+	_, rejection := limiter.acquire("alice", ToolClassDefault)
+	require.NotNil(t, rejection)
+	assert.Equal(t, "rate", rejection.Reason)
+	assert.Equal(t, time.Second, rejection.RetryAfter)
+
+	_, rejection = limiter.acquire("bob", ToolClassDefault)
+	assert.Nil(t, rejection, "clients are limited separately")
+
+	clock.Advance(500 * time.Millisecond)
+	_, rejection = limiter.acquire("alice", ToolClassDefault)
+	require.NotNil(t, rejection)
+	assert.Equal(t, 500*time.Millisecond, rejection.RetryAfter)
+
+	clock.Advance(500 * time.Millisecond)
+	_, rejection = limiter.acquire("alice", ToolClassDefault)
+	assert.Nil(t, rejection, "tokens are refilled at the rate")
+}
+
+func TestRateLimiterClasses(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	limiter := newRateLimiter(RateLimitConfig{
+		Limits: map[ToolClass]Limit{
+			ToolClassDefault: {Rate: 1, Burst: 1},
+			ToolClassSearch:  {Rate: 1, Burst: 1},
+		},
+	}, clock.Now)
+
+	_, rejection := limiter.acquire("alice", ToolClassSearch)
+	require.Nil(t, rejection)
+	_, rejection = limiter.acquire("alice", ToolClassSearch)
+	require.NotNil(t, rejection)
+	assert.Equal(t, ToolClassSearch, rejection.Class)
+
+	_, rejection = limiter.acquire("alice", ToolClassDefault)
+	assert.Nil(t, rejection, "classes have separate buckets")
+
+	_, rejection = limiter.acquire("alice", ToolClassWrite)
+	require.NotNil(t, rejection, "classes without limits of their own share the default bucket")
+	assert.Equal(t, ToolClassDefault, rejection.Class)
+
+	clock.Advance(time.Second)
+	_, rejection = limiter.acquire("alice", ToolClassWrite)
+	require.Nil(t, rejection)
+	_, rejection = limiter.acquire("alice", ToolClassDefault)
+	assert.NotNil(t, rejection, "calls of unconfigured classes drain the default bucket")
+}
+
+func TestRateLimiterSharesDefaultConcurrency(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	limiter := newRateLimiter(RateLimitConfig{
+		Limits: map[ToolClass]Limit{ToolClassDefault: {MaxConcurrent: 1}},
+	}, clock.Now)
+
+	release, rejection := limiter.acquire("alice", ToolClassWrite)
+	require.Nil(t, rejection)
+	_, rejection = limiter.acquire("alice", ToolClassDefault)
+	require.NotNil(t, rejection)
+	assert.Equal(t, "concurrency", rejection.Reason)
+
+	release()
+	_, rejection = limiter.acquire("alice", ToolClassSearch)
+	assert.Nil(t, rejection)
+}
+
+func TestRateLimiterConcurrency(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	limiter := newRateLimiter(RateLimitConfig{
+		Limits: map[ToolClass]Limit{ToolClassWrite: {MaxConcurrent: 1}},
+	}, clock.Now)
+
+	release, rejection := limiter.acquire("alice", ToolClassWrite)
+	require.Nil(t, rejection)
+
+	_, rejection = limiter.acquire("alice", ToolClassWrite)
+	require.NotNil(t, rejection)
+	assert.Equal(t, "concurrency", rejection.Reason)
+	assert.Equal(t, concurrencyRetryAfter, rejection.RetryAfter)
+
+	release()
+	release()
+	_, rejection = limiter.acquire("alice", ToolClassWrite)
+	assert.Nil(t, rejection, "completed calls free their slot, once")
+}
+
+func TestRateLimiterForgetsIdleClients(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	limiter := newRateLimiter(RateLimitConfig{
+		Limits: map[ToolClass]Limit{ToolClassDefault: {Rate: 1, Burst: 1, MaxConcurrent: 1}},
+	}, clock.Now)
+
+	release, _ := limiter.acquire("alice", ToolClassDefault)
+	release()
+	_, _ = limiter.acquire("bob", ToolClassDefault)
+
+	clock.Advance(idleClientTTL)
+	_, _ = limiter.acquire("carol", ToolClassDefault)
+	assert.NotContains(t, limiter.clients, "alice")
+	assert.Contains(t, limiter.clients, "bob", "clients with calls in flight are kept")
+	assert.Contains(t, limiter.clients, "carol")
+}
+
+func TestWithRateLimit(t *testing.T) {
+	handler := WithMCPParse()(WithRateLimit(RateLimitConfig{
+		Limits: map[ToolClass]Limit{
+			ToolClassDefault: {Rate: 100, Burst: 100},
+			ToolClassSearch:  {Rate: 0.5, Burst: 1},
+		},
+		Classify: func(toolName string) ToolClass {
+			if strings.HasPrefix(toolName, "search_") {
+				return ToolClassSearch
+			}
+			return ToolClassDefault
+		},
+	})(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	})))
+
+	call := func(token string, identity *ghcontext.ClientIdentity, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		ctx := ghcontext.WithTokenInfo(req.Context(), &ghcontext.TokenInfo{Token: token})
+		if identity != nil {
+			ctx = ghcontext.WithClientIdentity(ctx, identity)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req.WithContext(ctx))
+		return rec
+	}
+	const searchCall = `{"jsonrpc":"2.0","id":7,"method":"tools/call","params":{"name":"search_code"}}`
+
+	require.Equal(t, http.StatusAccepted, call("ghp_alice", nil, searchCall).Code)
+
+	rec := call("ghp_alice", nil, searchCall)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	assert.Equal(t, "2", rec.Header().Get("Retry-After"))
+	assert.JSONEq(t, `{
+		"jsonrpc": "2.0",
+		"id": 7,
+		"error": {
+			"code": -32029,
+			"message": "rate limit exceeded for search tools, retry after 2s",
+			"data": {"tool": "search_code", "toolClass": "search", "reason": "rate", "retryAfterSeconds": 2}
+		}
+	}`, rec.Body.String())
+
+	assert.Equal(t, http.StatusAccepted, call("ghp_alice", nil, `{"jsonrpc":"2.0","id":8,"method":"tools/call","params":{"name":"get_me"}}`).Code,
+		"other tool classes are not affected")
+	assert.Equal(t, http.StatusAccepted, call("ghp_alice", nil, `{"jsonrpc":"2.0","id":9,"method":"tools/list"}`).Code,
+		"only tool calls are limited")
+	assert.Equal(t, http.StatusAccepted, call("ghp_bob", nil, searchCall).Code, "tokens are limited separately")
+
+	// Clients with a certificate are limited by it, whichever token they use
+	identity := &ghcontext.ClientIdentity{Fingerprint: "abc123"}
+	require.Equal(t, http.StatusAccepted, call("ghp_carol", identity, searchCall).Code)
+	rec = call("ghp_dave", identity, searchCall)
+	var resp struct {
+		Error struct{ Code int } `json:"error"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, RateLimitErrorCode, resp.Error.Code)
+}
+
+func TestParseRateLimits(t *testing.T) {
+	tests := []struct {
+		name        string
+		rates       string
+		concurrency string
+		expected    map[ToolClass]Limit
+		expectedErr string
+	}{
+		{
+			name:     "empty",
+			expected: map[ToolClass]Limit{},
+		},
+		{
+			name:        "rates and concurrency",
+			rates:       "default=600/m, search=30/m,write=2/s",
+			concurrency: "default=8,write=4",
+			expected: map[ToolClass]Limit{
+				ToolClassDefault: {Rate: 10, Burst: 600, MaxConcurrent: 8},
+				ToolClassSearch:  {Rate: 0.5, Burst: 30},
+				ToolClassWrite:   {Rate: 2, Burst: 2, MaxConcurrent: 4},
+			},
+		},
+		{
+			name:     "per hour",
+			rates:    "write=3600/h",
+			expected: map[ToolClass]Limit{ToolClassWrite: {Rate: 1, Burst: 3600}},
+		},
+		{name: "unknown class", rates: "admin=1/s", expectedErr: `unknown tool class "admin"`},
+		{name: "missing unit", rates: "default=10", expectedErr: "must be of the form N/s, N/m or N/h"},
+		{name: "unknown unit", rates: "default=10/d", expectedErr: "must be per s, m or h"},
+		{name: "zero rate", rates: "default=0/s", expectedErr: "must have a positive count"},
+		{name: "missing value", concurrency: "search", expectedErr: "must be of the form class=value"},
+		{name: "invalid concurrency", concurrency: "search=-1", expectedErr: "must be a positive number"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			limits, err := ParseRateLimits(tc.rates, tc.concurrency)
+			if tc.expectedErr != "" {
+				require.ErrorContains(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, limits)
+		})
+	}
+}
@@ -0,0 +1,18 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/github/github-mcp-server/pkg/tracing"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// WithTraceContext continues the caller's trace: a valid W3C traceparent header becomes
+// the parent of the span recorded for the MCP request. Invalid headers are ignored and a
+// new trace is started.
+func WithTraceContext(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := tracing.Propagator.Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
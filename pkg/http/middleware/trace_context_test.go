@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestWithTraceContext(t *testing.T) {
+	tests := []struct {
+		name           string
+		traceparent    string
+		expectedParent string
+	}{
+		{
+			name:           "valid traceparent",
+			traceparent:    "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			expectedParent: "00f067aa0ba902b7",
+		},
+		{name: "invalid traceparent", traceparent: "00-zzz-00f067aa0ba902b7-01"},
+		{name: "no traceparent"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := tracetest.NewSpanRecorder()
+			tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+			handler := WithTraceContext(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				_, span := tracer.Start(r.Context(), "initialize", trace.WithSpanKind(trace.SpanKindServer))
+				span.End()
+			}))
+
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			if tc.traceparent != "" {
+				req.Header.Set("traceparent", tc.traceparent)
+			}
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			spans := recorder.Ended()
+			require.Len(t, spans, 1)
+			span := spans[0]
+			if tc.expectedParent == "" {
+				assert.False(t, span.Parent().IsValid(), "a new trace is started")
+				return
+			}
+			assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.SpanContext().TraceID().String())
+			assert.Equal(t, tc.expectedParent, span.Parent().SpanID().String())
+		})
+	}
+}
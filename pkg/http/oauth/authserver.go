@@ -0,0 +1,932 @@
+package oauth
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	// AuthorizationServerMetadataPath is the well-known path of the authorization server
+	// metadata (RFC 8414).
+	AuthorizationServerMetadataPath = "/.well-known/oauth-authorization-server"
+
+	// AuthorizePath is the path of the authorization endpoint.
+	AuthorizePath = "/oauth/authorize"
+	// TokenPath is the path of the token endpoint.
+	TokenPath = "/oauth/token"
+	// RegisterPath is the path of the dynamic client registration endpoint (RFC 7591).
+	RegisterPath = "/oauth/register"
+	// CallbackPath is the path GitHub redirects to after the user authorizes.
+	CallbackPath = "/oauth/callback"
+)
+
+const (
+	// pendingAuthorizationTTL is how long a user has to authorize on GitHub.
+	pendingAuthorizationTTL = 10 * time.Minute
+	// authorizationCodeTTL is how long a client has to redeem an authorization code.
+	authorizationCodeTTL = 5 * time.Minute
+	// maxPendingAuthorizations bounds the memory used by authorizations in progress.
+	maxPendingAuthorizations = 10000
+	// clientIDPrefix marks client IDs issued by dynamic registration.
+	clientIDPrefix = "mcp_"
+	// refreshTokenPrefix marks refresh tokens wrapped for a client.
+	refreshTokenPrefix = "mcpr_"
+	// consentCookiePrefix names the cookies that tie a consent form to the browser it was
+	// shown in.
+	consentCookiePrefix = "mcp_consent_"
+)
+
+// Token endpoint authentication methods of registered clients.
+const (
+	authMethodNone              = "none"
+	authMethodClientSecretPost  = "client_secret_post"
+	authMethodClientSecretBasic = "client_secret_basic"
+)
+
+// AuthorizationServerConfig configures the authorization server facade.
+type AuthorizationServerConfig struct {
+	// BaseURL is the publicly accessible URL of this server. It is the issuer of the
+	// authorization server, and the base of its endpoints.
+	BaseURL string
+
+	// UpstreamURL is the OAuth base URL of GitHub, such as https://github.com/login/oauth.
+	// Defaults to DefaultAuthorizationServer.
+	UpstreamURL string
+
+	// ClientID and ClientSecret are the credentials of the GitHub OAuth App or GitHub App
+	// that users authorize. Its callback URL must be BaseURL followed by CallbackPath.
+	ClientID     string
+	ClientSecret string
+
+	// Scopes are requested from GitHub when a client requests none. Defaults to
+	// SupportedScopes. GitHub Apps ignore scopes.
+	Scopes []string
+
+	// AllowedRedirectURIs are the redirect URIs clients may register besides loopback
+	// addresses, matched exactly. Any other redirect URI is rejected, so that registered
+	// clients can't send codes to hosts the operator hasn't approved.
+	AllowedRedirectURIs []string
+
+	// HTTPClient is used for requests to GitHub. Defaults to a client with a timeout.
+	HTTPClient *http.Client
+
+	// Logger reports failed exchanges with GitHub.
+	Logger *slog.Logger
+}
+
+// AuthorizationServer is an OAuth 2.1 authorization server facade for clients that need
+// dynamic client registration and PKCE, which GitHub doesn't offer. It registers clients
+// itself and proxies authorization to a GitHub OAuth App or GitHub App, handing the
+// resulting GitHub token to the client.
+//
+// Users confirm each authorization on a consent page that names the client and the host
+// it redirects to, before being sent to GitHub. Refresh tokens are encrypted for the
+// client they were issued to, so no other client can redeem them.
+//
+// Registered clients are not stored: their client ID carries their metadata, signed with
+// a key derived from the upstream client secret, so registrations survive restarts and
+// are valid on every replica. Authorizations in progress and unredeemed codes are kept in
+// memory, so a client must complete a flow on the replica that started it.
+type AuthorizationServer struct {
+	cfg        AuthorizationServerConfig
+	issuer     string
+	signingKey []byte
+	refreshKey cipher.AEAD
+	now        func() time.Time
+
+	mu      sync.Mutex
+	pending map[string]*pendingAuthorization
+	codes   map[string]*authorizationCode
+}
+
+// registeredClient is the metadata carried by a client ID.
+type registeredClient struct {
+	RedirectURIs []string `json:"r"`
+	AuthMethod   string   `json:"a"`
+	Name         string   `json:"n,omitempty"`
+	IssuedAt     int64    `json:"t"`
+	// Nonce tells apart clients registered with the same metadata at the same time
+	Nonce string `json:"i"`
+}
+
+// pendingAuthorization is an authorization request waiting for the user to consent and
+// to authorize on GitHub. It is keyed by the state sent to GitHub.
+type pendingAuthorization struct {
+	clientID       string
+	clientName     string
+	redirectURI    string
+	consented      bool
+	state          string
+	codeChallenge  string
+	upstreamPKCE   string
+	upstreamScopes string
+	expiresAt      time.Time
+}
+
+// authorizationCode is a code issued to a client, redeemable for the GitHub token.
+type authorizationCode struct {
+	clientID      string
+	redirectURI   string
+	codeChallenge string
+	token         *upstreamToken
+	expiresAt     time.Time
+}
+
+// authorizationServerMetadata is the authorization server metadata (RFC 8414).
+type authorizationServerMetadata struct {
+	Issuer                            string   `json:"issuer"`
+	AuthorizationEndpoint             string   `json:"authorization_endpoint"`
+	TokenEndpoint                     string   `json:"token_endpoint"`
+	RegistrationEndpoint              string   `json:"registration_endpoint"`
+	ScopesSupported                   []string `json:"scopes_supported,omitempty"`
+	ResponseTypesSupported            []string `json:"response_types_supported"`
+	GrantTypesSupported               []string `json:"grant_types_supported,omitempty"`
+	TokenEndpointAuthMethodsSupported []string `json:"token_endpoint_auth_methods_supported,omitempty"`
+	CodeChallengeMethodsSupported     []string `json:"code_challenge_methods_supported,omitempty"`
+}
+
+// clientMetadata is the metadata of a client registration request (RFC 7591).
+type clientMetadata struct {
+	RedirectURIs            []string `json:"redirect_uris"`
+	TokenEndpointAuthMethod string   `json:"token_endpoint_auth_method,omitempty"`
+	GrantTypes              []string `json:"grant_types,omitempty"`
+	ResponseTypes           []string `json:"response_types,omitempty"`
+	ClientName              string   `json:"client_name,omitempty"`
+	Scope                   string   `json:"scope,omitempty"`
+}
+
+// clientInformation is the response to a successful client registration (RFC 7591).
+type clientInformation struct {
+	clientMetadata
+	ClientID              string `json:"client_id"`
+	ClientSecret          string `json:"client_secret,omitempty"`
+	ClientIDIssuedAt      int64  `json:"client_id_issued_at"`
+	ClientSecretExpiresAt *int64 `json:"client_secret_expires_at,omitempty"`
+}
+
+// upstreamToken is a token response from GitHub.
+type upstreamToken struct {
+	AccessToken           string `json:"access_token"`
+	TokenType             string `json:"token_type,omitempty"`
+	Scope                 string `json:"scope,omitempty"`
+	ExpiresIn             int64  `json:"expires_in,omitempty"`
+	RefreshToken          string `json:"refresh_token,omitempty"`
+	RefreshTokenExpiresIn int64  `json:"refresh_token_expires_in,omitempty"`
+	Error                 string `json:"error,omitempty"`
+	ErrorDescription      string `json:"error_description,omitempty"`
+}
+
+// NewAuthorizationServer creates an authorization server facade.
+func NewAuthorizationServer(cfg AuthorizationServerConfig) (*AuthorizationServer, error) {
+	if cfg.BaseURL == "" {
+		return nil, errors.New("base URL is required for the OAuth authorization server")
+	}
+	if cfg.ClientID == "" || cfg.ClientSecret == "" {
+		return nil, errors.New("OAuth client ID and secret are required for the OAuth authorization server")
+	}
+	if cfg.UpstreamURL == "" {
+		cfg.UpstreamURL = DefaultAuthorizationServer
+	}
+	cfg.UpstreamURL = strings.TrimSuffix(cfg.UpstreamURL, "/")
+	if len(cfg.Scopes) == 0 {
+		cfg.Scopes = SupportedScopes
+	}
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = &http.Client{Timeout: 30 * time.Second}
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = slog.New(slog.DiscardHandler)
+	}
+
+	for _, uri := range cfg.AllowedRedirectURIs {
+		if err := checkRedirectURI(uri); err != nil {
+			return nil, fmt.Errorf("invalid allowed redirect URI: %w", err)
+		}
+	}
+
+	mac := hmac.New(sha256.New, []byte(cfg.ClientSecret))
+	mac.Write([]byte("github-mcp-server client registration"))
+	signingKey := mac.Sum(nil)
+
+	mac = hmac.New(sha256.New, []byte(cfg.ClientSecret))
+	mac.Write([]byte("github-mcp-server refresh tokens"))
+	block, err := aes.NewCipher(mac.Sum(nil))
+	if err != nil {
+		return nil, err
+	}
+	refreshKey, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AuthorizationServer{
+		cfg:        cfg,
+		issuer:     strings.TrimSuffix(cfg.BaseURL, "/"),
+		signingKey: signingKey,
+		refreshKey: refreshKey,
+		now:        time.Now,
+		pending:    make(map[string]*pendingAuthorization),
+		codes:      make(map[string]*authorizationCode),
+	}, nil
+}
+
+// Issuer returns the issuer identifier, which protected resource metadata should list
+// as the authorization server.
+func (s *AuthorizationServer) Issuer() string {
+	return s.issuer
+}
+
+// RegisterRoutes registers the authorization server endpoints.
+func (s *AuthorizationServer) RegisterRoutes(r chi.Router) {
+	// RFC 8414 inserts the well-known path before the path of the issuer, if any
+	r.Get(AuthorizationServerMetadataPath, s.handleMetadata)
+	if u, err := url.Parse(s.issuer); err == nil && u.Path != "" && u.Path != "/" {
+		r.Get(AuthorizationServerMetadataPath+u.Path, s.handleMetadata)
+	}
+	r.Get(AuthorizePath, s.handleAuthorize)
+	r.Post(AuthorizePath, s.handleConsent)
+	r.Get(CallbackPath, s.handleCallback)
+	r.Post(TokenPath, s.handleToken)
+	r.Post(RegisterPath, s.handleRegister)
+}
+
+func (s *AuthorizationServer) handleMetadata(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, &authorizationServerMetadata{
+		Issuer:                            s.issuer,
+		AuthorizationEndpoint:             s.issuer + AuthorizePath,
+		TokenEndpoint:                     s.issuer + TokenPath,
+		RegistrationEndpoint:              s.issuer + RegisterPath,
+		ScopesSupported:                   SupportedScopes,
+		ResponseTypesSupported:            []string{"code"},
+		GrantTypesSupported:               []string{"authorization_code", "refresh_token"},
+		TokenEndpointAuthMethodsSupported: []string{authMethodNone, authMethodClientSecretPost, authMethodClientSecretBasic},
+		CodeChallengeMethodsSupported:     []string{"S256"},
+	})
+}
+
+// handleRegister registers a client (RFC 7591).
+func (s *AuthorizationServer) handleRegister(w http.ResponseWriter, r *http.Request) {
+	var meta clientMetadata
+	if err := json.NewDecoder(io.LimitReader(r.Body, 64*1024)).Decode(&meta); err != nil {
+		writeRegistrationError(w, "invalid_client_metadata", "request body must be client metadata JSON")
+		return
+	}
+	if len(meta.RedirectURIs) == 0 {
+		writeRegistrationError(w, "invalid_redirect_uri", "at least one redirect URI is required")
+		return
+	}
+	for _, uri := range meta.RedirectURIs {
+		if err := s.validateRedirectURI(uri); err != nil {
+			writeRegistrationError(w, "invalid_redirect_uri", err.Error())
+			return
+		}
+	}
+	for _, grantType := range meta.GrantTypes {
+		if grantType != "authorization_code" && grantType != "refresh_token" {
+			writeRegistrationError(w, "invalid_client_metadata", fmt.Sprintf("grant type %q is not supported", grantType))
+			return
+		}
+	}
+	for _, responseType := range meta.ResponseTypes {
+		if responseType != "code" {
+			writeRegistrationError(w, "invalid_client_metadata", fmt.Sprintf("response type %q is not supported", responseType))
+			return
+		}
+	}
+	switch meta.TokenEndpointAuthMethod {
+	case "":
+		meta.TokenEndpointAuthMethod = authMethodClientSecretBasic
+	case authMethodNone, authMethodClientSecretPost, authMethodClientSecretBasic:
+	default:
+		writeRegistrationError(w, "invalid_client_metadata", fmt.Sprintf("token endpoint auth method %q is not supported", meta.TokenEndpointAuthMethod))
+		return
+	}
+
+	issuedAt := s.now()
+	clientID, err := s.signClient(registeredClient{
+		RedirectURIs: meta.RedirectURIs,
+		AuthMethod:   meta.TokenEndpointAuthMethod,
+		Name:         meta.ClientName,
+		IssuedAt:     issuedAt.Unix(),
+		Nonce:        randomToken()[:16],
+	})
+	if err != nil {
+		http.Error(w, "failed to register client", http.StatusInternalServerError)
+		return
+	}
+
+	resp := &clientInformation{
+		clientMetadata: clientMetadata{
+			RedirectURIs:            meta.RedirectURIs,
+			TokenEndpointAuthMethod: meta.TokenEndpointAuthMethod,
+			GrantTypes:              []string{"authorization_code", "refresh_token"},
+			ResponseTypes:           []string{"code"},
+			ClientName:              meta.ClientName,
+			Scope:                   meta.Scope,
+		},
+		ClientID:         clientID,
+		ClientIDIssuedAt: issuedAt.Unix(),
+	}
+	if meta.TokenEndpointAuthMethod != authMethodNone {
+		// Secrets are derived from the client ID, so they never expire
+		var never int64
+		resp.ClientSecret = s.clientSecret(clientID)
+		resp.ClientSecretExpiresAt = &never
+	}
+	writeJSON(w, http.StatusCreated, resp)
+}
+
+// handleAuthorize starts an authorization code flow, asking the user to consent.
+func (s *AuthorizationServer) handleAuthorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	clientID := query.Get("client_id")
+	redirectURI := query.Get("redirect_uri")
+
+	// Errors about the client or redirect URI must not redirect (RFC 6749, section 4.1.2.1)
+	client, err := s.verifyClient(clientID)
+	if err != nil {
+		http.Error(w, "invalid client_id", http.StatusBadRequest)
+		return
+	}
+	if redirectURI == "" && len(client.RedirectURIs) == 1 {
+		redirectURI = client.RedirectURIs[0]
+	}
+	if !slices.Contains(client.RedirectURIs, redirectURI) {
+		http.Error(w, "redirect_uri is not registered for this client", http.StatusBadRequest)
+		return
+	}
+	// The allow-list may have changed since the client registered
+	if err := s.validateRedirectURI(redirectURI); err != nil {
+		http.Error(w, "redirect_uri is not allowed", http.StatusBadRequest)
+		return
+	}
+
+	state := query.Get("state")
+	redirectError := func(code, description string) {
+		redirectWithParams(w, r, redirectURI, url.Values{
+			"error":             {code},
+			"error_description": {description},
+			"state":             {state},
+			"iss":               {s.issuer},
+		})
+	}
+	if query.Get("response_type") != "code" {
+		redirectError("unsupported_response_type", "only the code response type is supported")
+		return
+	}
+	codeChallenge := query.Get("code_challenge")
+	if codeChallenge == "" || query.Get("code_challenge_method") != "S256" {
+		redirectError("invalid_request", "PKCE with the S256 code challenge method is required")
+		return
+	}
+
+	scopes := query.Get("scope")
+	if scopes == "" {
+		scopes = strings.Join(s.cfg.Scopes, " ")
+	}
+	upstreamState := randomToken()
+	upstreamPKCE := randomToken()
+
+	s.mu.Lock()
+	s.sweep()
+	if len(s.pending) >= maxPendingAuthorizations {
+		s.mu.Unlock()
+		redirectError("temporarily_unavailable", "too many authorizations in progress")
+		return
+	}
+	s.pending[upstreamState] = &pendingAuthorization{
+		clientID:       clientID,
+		clientName:     client.Name,
+		redirectURI:    redirectURI,
+		state:          state,
+		codeChallenge:  codeChallenge,
+		upstreamPKCE:   upstreamPKCE,
+		upstreamScopes: scopes,
+		expiresAt:      s.now().Add(pendingAuthorizationTTL),
+	}
+	s.mu.Unlock()
+
+	s.writeConsentPage(w, upstreamState, clientID, client.Name, redirectURI, scopes)
+}
+
+// handleConsent records the user's answer on the consent page, sending them to GitHub if
+// they approved. The answer must come from the browser that was shown the page, so a
+// consent form can't be submitted on the user's behalf by another site.
+func (s *AuthorizationServer) handleConsent(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid consent form", http.StatusBadRequest)
+		return
+	}
+	request := r.PostForm.Get("request")
+	clientID := r.PostForm.Get("client_id")
+
+	cookie, err := r.Cookie(consentCookieName(request))
+	if err != nil || !hmac.Equal([]byte(cookie.Value), []byte(s.consentToken(request, clientID))) {
+		http.Error(w, "consent was not given in this browser", http.StatusForbidden)
+		return
+	}
+	http.SetCookie(w, s.consentCookie(request, "", -1))
+
+	approved := r.PostForm.Get("action") == "approve"
+	s.mu.Lock()
+	pending, ok := s.pending[request]
+	ok = ok && pending.clientID == clientID && !s.now().After(pending.expiresAt)
+	if ok && approved {
+		pending.consented = true
+	} else if ok {
+		delete(s.pending, request)
+	}
+	s.mu.Unlock()
+	if !ok {
+		http.Error(w, "unknown or expired authorization request", http.StatusBadRequest)
+		return
+	}
+
+	if !approved {
+		redirectWithParams(w, r, pending.redirectURI, url.Values{
+			"error":             {"access_denied"},
+			"error_description": {"the user denied the authorization"},
+			"state":             {pending.state},
+			"iss":               {s.issuer},
+		})
+		return
+	}
+	redirectWithParams(w, r, s.cfg.UpstreamURL+"/authorize", url.Values{
+		"client_id":             {s.cfg.ClientID},
+		"redirect_uri":          {s.issuer + CallbackPath},
+		"scope":                 {pending.upstreamScopes},
+		"state":                 {request},
+		"code_challenge":        {pkceChallenge(pending.upstreamPKCE)},
+		"code_challenge_method": {"S256"},
+	})
+}
+
+// handleCallback completes the authorization on GitHub, exchanging GitHub's code for a
+// token and redirecting the user back to the client with a code of our own.
+func (s *AuthorizationServer) handleCallback(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	s.mu.Lock()
+	pending, ok := s.pending[query.Get("state")]
+	delete(s.pending, query.Get("state"))
+	s.mu.Unlock()
+	if !ok || !pending.consented || s.now().After(pending.expiresAt) {
+		http.Error(w, "unknown or expired authorization request", http.StatusBadRequest)
+		return
+	}
+
+	redirectError := func(code, description string) {
+		redirectWithParams(w, r, pending.redirectURI, url.Values{
+			"error":             {code},
+			"error_description": {description},
+			"state":             {pending.state},
+			"iss":               {s.issuer},
+		})
+	}
+	if upstreamError := query.Get("error"); upstreamError != "" {
+		redirectError(upstreamError, query.Get("error_description"))
+		return
+	}
+
+	token, err := s.exchangeUpstream(r, url.Values{
+		"code":          {query.Get("code")},
+		"redirect_uri":  {s.issuer + CallbackPath},
+		"code_verifier": {pending.upstreamPKCE},
+	})
+	if err != nil {
+		s.cfg.Logger.Warn("failed to exchange GitHub authorization code", "error", err)
+		redirectError("server_error", "GitHub did not issue a token")
+		return
+	}
+
+	code := randomToken()
+	s.mu.Lock()
+	s.codes[code] = &authorizationCode{
+		clientID:      pending.clientID,
+		redirectURI:   pending.redirectURI,
+		codeChallenge: pending.codeChallenge,
+		token:         token,
+		expiresAt:     s.now().Add(authorizationCodeTTL),
+	}
+	s.mu.Unlock()
+
+	redirectWithParams(w, r, pending.redirectURI, url.Values{
+		"code":  {code},
+		"state": {pending.state},
+		"iss":   {s.issuer},
+	})
+}
+
+// handleToken redeems authorization codes and refresh tokens.
+func (s *AuthorizationServer) handleToken(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeTokenError(w, http.StatusBadRequest, "invalid_request", "request body must be form encoded")
+		return
+	}
+
+	clientID, ok := s.authenticateClient(r)
+	if !ok {
+		w.Header().Set("WWW-Authenticate", `Basic realm="oauth"`)
+		writeTokenError(w, http.StatusUnauthorized, "invalid_client", "client authentication failed")
+		return
+	}
+
+	switch r.PostForm.Get("grant_type") {
+	case "authorization_code":
+		s.redeemCode(w, r, clientID)
+	case "refresh_token":
+		if r.PostForm.Get("refresh_token") == "" {
+			writeTokenError(w, http.StatusBadRequest, "invalid_request", "refresh_token is required")
+			return
+		}
+		refreshToken, err := s.unwrapRefreshToken(r.PostForm.Get("refresh_token"), clientID)
+		if err != nil {
+			writeTokenError(w, http.StatusBadRequest, "invalid_grant", "refresh token was not issued to this client")
+			return
+		}
+		token, err := s.exchangeUpstream(r, url.Values{
+			"grant_type":    {"refresh_token"},
+			"refresh_token": {refreshToken},
+		})
+		if err != nil {
+			writeTokenError(w, http.StatusBadRequest, "invalid_grant", err.Error())
+			return
+		}
+		s.writeToken(w, token, clientID)
+	default:
+		writeTokenError(w, http.StatusBadRequest, "unsupported_grant_type", "only authorization_code and refresh_token grants are supported")
+	}
+}
+
+func (s *AuthorizationServer) redeemCode(w http.ResponseWriter, r *http.Request, clientID string) {
+	// Codes are single use, whether or not redemption succeeds
+	s.mu.Lock()
+	code, ok := s.codes[r.PostForm.Get("code")]
+	delete(s.codes, r.PostForm.Get("code"))
+	s.mu.Unlock()
+
+	switch {
+	case !ok || s.now().After(code.expiresAt):
+		writeTokenError(w, http.StatusBadRequest, "invalid_grant", "unknown or expired authorization code")
+	case code.clientID != clientID:
+		writeTokenError(w, http.StatusBadRequest, "invalid_grant", "authorization code was issued to another client")
+	case r.PostForm.Get("redirect_uri") != "" && r.PostForm.Get("redirect_uri") != code.redirectURI:
+		writeTokenError(w, http.StatusBadRequest, "invalid_grant", "redirect_uri does not match the authorization request")
+	case subtle.ConstantTimeCompare([]byte(pkceChallenge(r.PostForm.Get("code_verifier"))), []byte(code.codeChallenge)) != 1:
+		writeTokenError(w, http.StatusBadRequest, "invalid_grant", "code_verifier does not match the code challenge")
+	default:
+		s.writeToken(w, code.token, clientID)
+	}
+}
+
+// authenticateClient returns the ID of the client making a token request, verifying its
+// secret when it has one.
+func (s *AuthorizationServer) authenticateClient(r *http.Request) (string, bool) {
+	clientID, secret, basic := r.BasicAuth()
+	if basic {
+		clientID, _ = url.QueryUnescape(clientID)
+		secret, _ = url.QueryUnescape(secret)
+	} else {
+		clientID = r.PostForm.Get("client_id")
+		secret = r.PostForm.Get("client_secret")
+	}
+
+	client, err := s.verifyClient(clientID)
+	if err != nil {
+		return "", false
+	}
+	switch client.AuthMethod {
+	case authMethodNone:
+		return clientID, true
+	case authMethodClientSecretBasic, authMethodClientSecretPost:
+		if secret == "" || !hmac.Equal([]byte(secret), []byte(s.clientSecret(clientID))) {
+			return "", false
+		}
+		return clientID, true
+	default:
+		return "", false
+	}
+}
+
+// exchangeUpstream requests a token from GitHub with the app's credentials.
+func (s *AuthorizationServer) exchangeUpstream(r *http.Request, params url.Values) (*upstreamToken, error) {
+	params.Set("client_id", s.cfg.ClientID)
+	params.Set("client_secret", s.cfg.ClientSecret)
+
+	req, err := http.NewRequestWithContext(r.Context(), http.MethodPost, s.cfg.UpstreamURL+"/access_token", strings.NewReader(params.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := s.cfg.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var token upstreamToken
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 64*1024)).Decode(&token); err != nil {
+		return nil, fmt.Errorf("unexpected token response with status %d", resp.StatusCode)
+	}
+	// GitHub reports errors with a 200 status
+	if token.Error != "" {
+		return nil, fmt.Errorf("%s: %s", token.Error, token.ErrorDescription)
+	}
+	if resp.StatusCode != http.StatusOK || token.AccessToken == "" {
+		return nil, fmt.Errorf("unexpected token response with status %d", resp.StatusCode)
+	}
+	return &token, nil
+}
+
+// signClient encodes client metadata as a signed client ID.
+func (s *AuthorizationServer) signClient(client registeredClient) (string, error) {
+	payload, err := json.Marshal(client)
+	if err != nil {
+		return "", err
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return clientIDPrefix + encoded + "." + s.sign(encoded), nil
+}
+
+// verifyClient decodes a client ID issued by signClient.
+func (s *AuthorizationServer) verifyClient(clientID string) (*registeredClient, error) {
+	encoded, signature, ok := strings.Cut(strings.TrimPrefix(clientID, clientIDPrefix), ".")
+	if !ok || !strings.HasPrefix(clientID, clientIDPrefix) || !hmac.Equal([]byte(signature), []byte(s.sign(encoded))) {
+		return nil, errors.New("invalid client ID")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+	var client registeredClient
+	if err := json.Unmarshal(payload, &client); err != nil {
+		return nil, err
+	}
+	return &client, nil
+}
+
+// wrapRefreshToken encrypts a GitHub refresh token for the client it is issued to, so that
+// it can't be redeemed by another client or used with GitHub directly.
+func (s *AuthorizationServer) wrapRefreshToken(refreshToken, clientID string) string {
+	nonce := make([]byte, s.refreshKey.NonceSize())
+	_, _ = rand.Read(nonce)
+	sealed := s.refreshKey.Seal(nonce, nonce, []byte(refreshToken), []byte(clientID))
+	return refreshTokenPrefix + base64.RawURLEncoding.EncodeToString(sealed)
+}
+
+// unwrapRefreshToken decrypts a refresh token issued by wrapRefreshToken to clientID.
+func (s *AuthorizationServer) unwrapRefreshToken(wrapped, clientID string) (string, error) {
+	encoded, ok := strings.CutPrefix(wrapped, refreshTokenPrefix)
+	if !ok {
+		return "", errors.New("invalid refresh token")
+	}
+	sealed, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil || len(sealed) < s.refreshKey.NonceSize() {
+		return "", errors.New("invalid refresh token")
+	}
+	nonce, ciphertext := sealed[:s.refreshKey.NonceSize()], sealed[s.refreshKey.NonceSize():]
+	refreshToken, err := s.refreshKey.Open(nil, nonce, ciphertext, []byte(clientID))
+	if err != nil {
+		return "", err
+	}
+	return string(refreshToken), nil
+}
+
+// consentToken is the value of the cookie that ties a consent form to the browser it was
+// shown in and the client it was shown for.
+func (s *AuthorizationServer) consentToken(request, clientID string) string {
+	return s.sign("consent:" + request + ":" + clientID)
+}
+
+// consentCookieName names the consent cookie of a request, so that authorizations in
+// several tabs don't overwrite each other's cookies.
+func consentCookieName(request string) string {
+	return consentCookiePrefix + request[:min(len(request), 16)]
+}
+
+func (s *AuthorizationServer) consentCookie(request, value string, maxAge int) *http.Cookie {
+	path := AuthorizePath
+	secure := false
+	if u, err := url.Parse(s.issuer); err == nil {
+		path = strings.TrimSuffix(u.Path, "/") + AuthorizePath
+		secure = u.Scheme == "https"
+	}
+	return &http.Cookie{
+		Name:     consentCookieName(request),
+		Value:    value,
+		Path:     path,
+		MaxAge:   maxAge,
+		Secure:   secure,
+		HttpOnly: true,
+		SameSite: http.SameSiteStrictMode,
+	}
+}
+
+// consentPage asks the user to approve an authorization.
+var consentPage = template.Must(template.New("consent").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Authorize {{.ClientName}}</title>
+</head>
+<body>
+<h1>Authorize {{.ClientName}}</h1>
+<p><strong>{{.ClientName}}</strong> wants to use the GitHub MCP Server with your GitHub account.</p>
+<p>If you approve, you will sign in to GitHub and then be sent to <strong>{{.RedirectHost}}</strong>, which receives access to your account.</p>
+{{if .Scopes}}<p>Requested scopes: <code>{{.Scopes}}</code></p>{{end}}
+<p>Only continue if you started this sign-in and recognize {{.RedirectHost}}.</p>
+<form method="post" action="{{.Action}}">
+<input type="hidden" name="request" value="{{.Request}}">
+<input type="hidden" name="client_id" value="{{.ClientID}}">
+<button type="submit" name="action" value="approve">Authorize</button>
+<button type="submit" name="action" value="deny">Cancel</button>
+</form>
+</body>
+</html>
+`))
+
+// writeConsentPage shows the consent page of a pending authorization, setting the cookie
+// the answer must be submitted with.
+func (s *AuthorizationServer) writeConsentPage(w http.ResponseWriter, request, clientID, clientName, redirectURI, scopes string) {
+	if clientName == "" {
+		clientName = "An unnamed application"
+	}
+	redirectHost := redirectURI
+	if u, err := url.Parse(redirectURI); err == nil && u.Host != "" {
+		redirectHost = u.Host
+	}
+
+	http.SetCookie(w, s.consentCookie(request, s.consentToken(request, clientID), int(pendingAuthorizationTTL.Seconds())))
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	// The page must not be framed, or another site could trick the user into approving
+	w.Header().Set("X-Frame-Options", "DENY")
+	w.Header().Set("Content-Security-Policy", "default-src 'none'; form-action 'self'; frame-ancestors 'none'")
+	_ = consentPage.Execute(w, map[string]string{
+		"ClientName":   clientName,
+		"RedirectHost": redirectHost,
+		"Scopes":       scopes,
+		"Action":       s.issuer + AuthorizePath,
+		"Request":      request,
+		"ClientID":     clientID,
+	})
+}
+
+func (s *AuthorizationServer) clientSecret(clientID string) string {
+	return s.sign("secret:" + clientID)
+}
+
+func (s *AuthorizationServer) sign(value string) string {
+	mac := hmac.New(sha256.New, s.signingKey)
+	mac.Write([]byte(value))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// sweep removes expired authorizations and codes. It must be called with s.mu held.
+func (s *AuthorizationServer) sweep() {
+	now := s.now()
+	for key, pending := range s.pending {
+		if now.After(pending.expiresAt) {
+			delete(s.pending, key)
+		}
+	}
+	for key, code := range s.codes {
+		if now.After(code.expiresAt) {
+			delete(s.codes, key)
+		}
+	}
+}
+
+// validateRedirectURI accepts loopback addresses, as used by native clients (RFC 8252),
+// and the configured allowed redirect URIs.
+func (s *AuthorizationServer) validateRedirectURI(uri string) error {
+	if err := checkRedirectURI(uri); err != nil {
+		return err
+	}
+	if slices.Contains(s.cfg.AllowedRedirectURIs, uri) {
+		return nil
+	}
+	u, _ := url.Parse(uri)
+	if scheme := strings.ToLower(u.Scheme); scheme == "http" || scheme == "https" {
+		host := u.Hostname()
+		if host == "localhost" {
+			return nil
+		}
+		if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+			return nil
+		}
+	}
+	return fmt.Errorf("redirect URI %q must be a loopback address or an allowed redirect URI", uri)
+}
+
+// checkRedirectURI accepts absolute URIs without fragments. Plain HTTP is only allowed for
+// loopback addresses.
+func checkRedirectURI(uri string) error {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme == "" {
+		return fmt.Errorf("redirect URI %q must be an absolute URI", uri)
+	}
+	if u.Fragment != "" {
+		return fmt.Errorf("redirect URI %q must not have a fragment", uri)
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "https":
+		return nil
+	case "http":
+		host := u.Hostname()
+		if host == "localhost" {
+			return nil
+		}
+		if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+			return nil
+		}
+		return fmt.Errorf("redirect URI %q must use https unless it is a loopback address", uri)
+	case "javascript", "data", "file", "vbscript":
+		return fmt.Errorf("redirect URI %q has a disallowed scheme", uri)
+	default:
+		// Private-use schemes of native apps, such as vscode://
+		return nil
+	}
+}
+
+// redirectWithParams redirects to target with params added to its query.
+func redirectWithParams(w http.ResponseWriter, r *http.Request, target string, params url.Values) {
+	u, err := url.Parse(target)
+	if err != nil {
+		http.Error(w, "invalid redirect URI", http.StatusBadRequest)
+		return
+	}
+	query := u.Query()
+	for key, values := range params {
+		if len(values) > 0 && values[0] != "" {
+			query.Set(key, values[0])
+		}
+	}
+	u.RawQuery = query.Encode()
+	http.Redirect(w, r, u.String(), http.StatusFound)
+}
+
+// writeToken responds with a GitHub token, wrapping its refresh token for the client.
+func (s *AuthorizationServer) writeToken(w http.ResponseWriter, upstream *upstreamToken, clientID string) {
+	token := *upstream
+	if token.TokenType == "" {
+		token.TokenType = "bearer"
+	}
+	if token.RefreshToken != "" {
+		token.RefreshToken = s.wrapRefreshToken(token.RefreshToken, clientID)
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, http.StatusOK, &token)
+}
+
+func writeTokenError(w http.ResponseWriter, status int, code, description string) {
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, status, map[string]string{"error": code, "error_description": description})
+}
+
+func writeRegistrationError(w http.ResponseWriter, code, description string) {
+	writeJSON(w, http.StatusBadRequest, map[string]string{"error": code, "error_description": description})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// pkceChallenge returns the S256 code challenge of a verifier (RFC 7636).
+func pkceChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// randomToken returns an unguessable token.
+func randomToken() string {
+	b := make([]byte, 32)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,589 @@
+package oauth
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/github/github-mcp-server/pkg/http/oauth/oauthtest"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testRedirectURI    = "http://127.0.0.1:33418/callback"
+	allowedRedirectURI = "https://example.com/callback"
+)
+
+// consentRequestPattern extracts the request of a consent form.
+var consentRequestPattern = regexp.MustCompile(`name="request" value="([^"]+)"`)
+
+// authServerFixture is an authorization server facade in front of a fake GitHub.
+type authServerFixture struct {
+	server   *AuthorizationServer
+	upstream *oauthtest.Server
+	url      string
+	client   *http.Client
+}
+
+func newAuthServerFixture(t *testing.T, basePath string) *authServerFixture {
+	t.Helper()
+	upstream := oauthtest.NewServer(t)
+
+	r := chi.NewRouter()
+	ts := httptest.NewServer(r)
+	t.Cleanup(ts.Close)
+
+	server, err := NewAuthorizationServer(AuthorizationServerConfig{
+		BaseURL:             ts.URL + basePath,
+		UpstreamURL:         upstream.URL(),
+		ClientID:            upstream.ClientID,
+		ClientSecret:        upstream.ClientSecret,
+		AllowedRedirectURIs: []string{allowedRedirectURI},
+	})
+	require.NoError(t, err)
+	server.RegisterRoutes(r)
+
+	jar, err := cookiejar.New(nil)
+	require.NoError(t, err)
+	return &authServerFixture{
+		server:   server,
+		upstream: upstream,
+		url:      ts.URL,
+		client: &http.Client{
+			Jar: jar,
+			CheckRedirect: func(_ *http.Request, _ []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+// register registers a client with the given token endpoint auth method.
+func (f *authServerFixture) register(t *testing.T, authMethod string) clientInformation {
+	t.Helper()
+	body, err := json.Marshal(clientMetadata{
+		RedirectURIs:            []string{testRedirectURI},
+		TokenEndpointAuthMethod: authMethod,
+		ClientName:              "Test Client",
+	})
+	require.NoError(t, err)
+
+	resp, err := f.client.Post(f.url+RegisterPath, "application/json", strings.NewReader(string(body)))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	var info clientInformation
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&info))
+	return info
+}
+
+// startAuthorization requests the consent page of an authorization and returns its body
+// and the request it submits.
+func (f *authServerFixture) startAuthorization(t *testing.T, clientID, verifier string) (string, string) {
+	t.Helper()
+	params := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {clientID},
+		"redirect_uri":          {testRedirectURI},
+		"state":                 {"client-state"},
+		"code_challenge":        {pkceChallenge(verifier)},
+		"code_challenge_method": {"S256"},
+	}
+	resp, err := f.client.Get(f.url + AuthorizePath + "?" + params.Encode())
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	match := consentRequestPattern.FindSubmatch(body)
+	require.NotNil(t, match, "consent page has no request")
+	return string(body), string(match[1])
+}
+
+// consent submits the consent form of a request.
+func (f *authServerFixture) consent(t *testing.T, request, clientID, action string) *http.Response {
+	t.Helper()
+	resp, err := f.client.PostForm(f.url+AuthorizePath, url.Values{
+		"request":   {request},
+		"client_id": {clientID},
+		"action":    {action},
+	})
+	require.NoError(t, err)
+	resp.Body.Close()
+	return resp
+}
+
+// authorize runs the authorization flow through the consent page and the fake GitHub, and
+// returns the query of the final redirect to the client.
+func (f *authServerFixture) authorize(t *testing.T, clientID, verifier string) url.Values {
+	t.Helper()
+	_, request := f.startAuthorization(t, clientID, verifier)
+	resp := f.consent(t, request, clientID, "approve")
+
+	// Follow redirects to GitHub and back until we reach the client
+	for range 3 {
+		require.Equal(t, http.StatusFound, resp.StatusCode)
+		location := resp.Header.Get("Location")
+		if strings.HasPrefix(location, testRedirectURI) {
+			u, err := url.Parse(location)
+			require.NoError(t, err)
+			return u.Query()
+		}
+		var err error
+		resp, err = f.client.Get(location)
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+	t.Fatalf("authorization flow did not redirect to the client")
+	return nil
+}
+
+// token posts a token request and decodes the response.
+func (f *authServerFixture) token(t *testing.T, form url.Values, basicUser, basicPassword string) (int, map[string]any) {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPost, f.url+TokenPath, strings.NewReader(form.Encode()))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if basicUser != "" {
+		req.SetBasicAuth(url.QueryEscape(basicUser), url.QueryEscape(basicPassword))
+	}
+	resp, err := f.client.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	var body map[string]any
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	return resp.StatusCode, body
+}
+
+func TestNewAuthorizationServer(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewAuthorizationServer(AuthorizationServerConfig{ClientID: "id", ClientSecret: "secret"})
+	assert.ErrorContains(t, err, "base URL is required")
+
+	_, err = NewAuthorizationServer(AuthorizationServerConfig{BaseURL: "https://mcp.example.com", ClientID: "id"})
+	assert.ErrorContains(t, err, "client ID and secret are required")
+
+	server, err := NewAuthorizationServer(AuthorizationServerConfig{BaseURL: "https://mcp.example.com/", ClientID: "id", ClientSecret: "secret"})
+	require.NoError(t, err)
+	assert.Equal(t, "https://mcp.example.com", server.Issuer())
+	assert.Equal(t, DefaultAuthorizationServer, server.cfg.UpstreamURL)
+}
+
+func TestAuthorizationServerMetadata(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		basePath string
+		path     string
+	}{
+		{name: "issuer without path", path: AuthorizationServerMetadataPath},
+		{name: "issuer with path", basePath: "/mcp", path: AuthorizationServerMetadataPath + "/mcp"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			f := newAuthServerFixture(t, tc.basePath)
+
+			resp, err := f.client.Get(f.url + tc.path)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+
+			var meta authorizationServerMetadata
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&meta))
+			issuer := f.url + tc.basePath
+			assert.Equal(t, issuer, meta.Issuer)
+			assert.Equal(t, issuer+AuthorizePath, meta.AuthorizationEndpoint)
+			assert.Equal(t, issuer+TokenPath, meta.TokenEndpoint)
+			assert.Equal(t, issuer+RegisterPath, meta.RegistrationEndpoint)
+			assert.Equal(t, []string{"S256"}, meta.CodeChallengeMethodsSupported)
+			assert.Contains(t, meta.TokenEndpointAuthMethodsSupported, "none")
+		})
+	}
+}
+
+func TestAuthorizationServerRegistration(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		body          string
+		expectedError string
+	}{
+		{
+			name:          "malformed body",
+			body:          `not json`,
+			expectedError: "invalid_client_metadata",
+		},
+		{
+			name:          "no redirect URIs",
+			body:          `{"client_name":"x"}`,
+			expectedError: "invalid_redirect_uri",
+		},
+		{
+			name:          "plain HTTP redirect URI to a remote host",
+			body:          `{"redirect_uris":["http://example.com/callback"]}`,
+			expectedError: "invalid_redirect_uri",
+		},
+		{
+			name:          "redirect URI with a fragment",
+			body:          `{"redirect_uris":["https://example.com/callback#frag"]}`,
+			expectedError: "invalid_redirect_uri",
+		},
+		{
+			name:          "javascript redirect URI",
+			body:          `{"redirect_uris":["javascript:alert(1)"]}`,
+			expectedError: "invalid_redirect_uri",
+		},
+		{
+			name:          "redirect URI that is not allowed",
+			body:          `{"redirect_uris":["https://attacker.example.com/callback"]}`,
+			expectedError: "invalid_redirect_uri",
+		},
+		{
+			name:          "private-use scheme redirect URI that is not allowed",
+			body:          `{"redirect_uris":["vscode://github.mcp/callback"]}`,
+			expectedError: "invalid_redirect_uri",
+		},
+		{
+			name:          "unsupported grant type",
+			body:          `{"redirect_uris":["https://example.com/callback"],"grant_types":["client_credentials"]}`,
+			expectedError: "invalid_client_metadata",
+		},
+		{
+			name:          "unsupported auth method",
+			body:          `{"redirect_uris":["https://example.com/callback"],"token_endpoint_auth_method":"private_key_jwt"}`,
+			expectedError: "invalid_client_metadata",
+		},
+		{
+			name: "loopback redirect URI",
+			body: `{"redirect_uris":["http://localhost:8080/callback"],"token_endpoint_auth_method":"none"}`,
+		},
+		{
+			name: "allowed redirect URI",
+			body: `{"redirect_uris":["https://example.com/callback"]}`,
+		},
+	}
+
+	f := newAuthServerFixture(t, "")
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			resp, err := f.client.Post(f.url+RegisterPath, "application/json", strings.NewReader(tc.body))
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			var body map[string]any
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+			if tc.expectedError != "" {
+				assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+				assert.Equal(t, tc.expectedError, body["error"])
+				return
+			}
+			assert.Equal(t, http.StatusCreated, resp.StatusCode)
+			assert.True(t, strings.HasPrefix(body["client_id"].(string), clientIDPrefix))
+		})
+	}
+}
+
+func TestAuthorizationServerPublicClientFlow(t *testing.T) {
+	t.Parallel()
+	f := newAuthServerFixture(t, "")
+
+	client := f.register(t, authMethodNone)
+	assert.Empty(t, client.ClientSecret)
+
+	verifier := "public-client-verifier-0123456789-0123456789"
+	query := f.authorize(t, client.ClientID, verifier)
+	assert.Equal(t, "client-state", query.Get("state"))
+	assert.Equal(t, f.server.Issuer(), query.Get("iss"))
+	code := query.Get("code")
+	require.NotEmpty(t, code)
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {testRedirectURI},
+		"client_id":     {client.ClientID},
+		"code_verifier": {verifier},
+	}
+	status, body := f.token(t, form, "", "")
+	require.Equal(t, http.StatusOK, status)
+	assert.True(t, strings.HasPrefix(body["access_token"].(string), "gho_"))
+	refreshToken := body["refresh_token"].(string)
+	require.NotEmpty(t, refreshToken)
+
+	// Codes are single use
+	status, body = f.token(t, form, "", "")
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "invalid_grant", body["error"])
+
+	// Refresh tokens are exchanged with GitHub
+	status, body = f.token(t, url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {refreshToken},
+		"client_id":     {client.ClientID},
+	}, "", "")
+	require.Equal(t, http.StatusOK, status)
+	assert.NotEqual(t, refreshToken, body["refresh_token"])
+
+	// Refresh tokens are bound to the client they were issued to
+	other := f.register(t, authMethodNone)
+	status, body = f.token(t, url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {body["refresh_token"].(string)},
+		"client_id":     {other.ClientID},
+	}, "", "")
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "invalid_grant", body["error"])
+
+	// GitHub rotates refresh tokens, so the old one is rejected
+	status, body = f.token(t, url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {refreshToken},
+		"client_id":     {client.ClientID},
+	}, "", "")
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "invalid_grant", body["error"])
+}
+
+func TestAuthorizationServerConfidentialClientFlow(t *testing.T) {
+	t.Parallel()
+	f := newAuthServerFixture(t, "")
+
+	client := f.register(t, "")
+	assert.Equal(t, authMethodClientSecretBasic, client.TokenEndpointAuthMethod)
+	require.NotEmpty(t, client.ClientSecret)
+
+	verifier := "confidential-client-verifier-0123456789-0123"
+	code := f.authorize(t, client.ClientID, verifier).Get("code")
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"code_verifier": {verifier},
+	}
+
+	// A wrong secret fails without consuming the code
+	status, body := f.token(t, form, client.ClientID, "wrong")
+	assert.Equal(t, http.StatusUnauthorized, status)
+	assert.Equal(t, "invalid_client", body["error"])
+
+	status, body = f.token(t, form, client.ClientID, client.ClientSecret)
+	require.Equal(t, http.StatusOK, status)
+	assert.NotEmpty(t, body["access_token"])
+}
+
+func TestAuthorizationServerRejectsInvalidRedemptions(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		modify func(form url.Values, other clientInformation)
+	}{
+		{
+			name:   "wrong code verifier",
+			modify: func(form url.Values, _ clientInformation) { form.Set("code_verifier", "wrong-verifier") },
+		},
+		{
+			name:   "different redirect URI",
+			modify: func(form url.Values, _ clientInformation) { form.Set("redirect_uri", "http://127.0.0.1:1/other") },
+		},
+		{
+			name:   "code issued to another client",
+			modify: func(form url.Values, other clientInformation) { form.Set("client_id", other.ClientID) },
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			f := newAuthServerFixture(t, "")
+			client := f.register(t, authMethodNone)
+			other := f.register(t, authMethodNone)
+
+			verifier := "redemption-verifier-0123456789-0123456789"
+			form := url.Values{
+				"grant_type":    {"authorization_code"},
+				"code":          {f.authorize(t, client.ClientID, verifier).Get("code")},
+				"client_id":     {client.ClientID},
+				"code_verifier": {verifier},
+			}
+			tc.modify(form, other)
+
+			status, body := f.token(t, form, "", "")
+			assert.Equal(t, http.StatusBadRequest, status)
+			assert.Equal(t, "invalid_grant", body["error"])
+		})
+	}
+}
+
+func TestAuthorizationServerAuthorizeErrors(t *testing.T) {
+	t.Parallel()
+	f := newAuthServerFixture(t, "")
+	client := f.register(t, authMethodNone)
+
+	get := func(params url.Values) *http.Response {
+		resp, err := f.client.Get(f.url + AuthorizePath + "?" + params.Encode())
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp
+	}
+
+	// Unknown clients and redirect URIs are not redirected to
+	resp := get(url.Values{"client_id": {"mcp_forged.signature"}, "redirect_uri": {testRedirectURI}})
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	resp = get(url.Values{"client_id": {client.ClientID}, "redirect_uri": {"https://attacker.example.com/callback"}})
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	// Other errors are reported to the client
+	resp = get(url.Values{"client_id": {client.ClientID}, "response_type": {"code"}, "state": {"s"}})
+	require.Equal(t, http.StatusFound, resp.StatusCode)
+	location, err := url.Parse(resp.Header.Get("Location"))
+	require.NoError(t, err)
+	assert.Equal(t, "invalid_request", location.Query().Get("error"))
+	assert.Equal(t, "s", location.Query().Get("state"))
+}
+
+func TestAuthorizationServerUpstreamDenied(t *testing.T) {
+	t.Parallel()
+	f := newAuthServerFixture(t, "")
+	client := f.register(t, authMethodNone)
+
+	f.upstream.DenyNext()
+	query := f.authorize(t, client.ClientID, "denied-verifier-0123456789-0123456789")
+	assert.Equal(t, "access_denied", query.Get("error"))
+	assert.Equal(t, "client-state", query.Get("state"))
+	assert.Empty(t, query.Get("code"))
+}
+
+func TestAuthorizationServerExpiredCode(t *testing.T) {
+	t.Parallel()
+	f := newAuthServerFixture(t, "")
+	client := f.register(t, authMethodNone)
+
+	verifier := "expired-verifier-0123456789-0123456789"
+	code := f.authorize(t, client.ClientID, verifier).Get("code")
+
+	f.server.now = func() time.Time { return time.Now().Add(authorizationCodeTTL + time.Minute) }
+
+	status, body := f.token(t, url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"client_id":     {client.ClientID},
+		"code_verifier": {verifier},
+	}, "", "")
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "invalid_grant", body["error"])
+}
+
+func TestAuthorizationServerClientIDsSurviveRestarts(t *testing.T) {
+	t.Parallel()
+	cfg := AuthorizationServerConfig{BaseURL: "https://mcp.example.com", ClientID: "id", ClientSecret: "secret"}
+
+	first, err := NewAuthorizationServer(cfg)
+	require.NoError(t, err)
+	clientID, err := first.signClient(registeredClient{RedirectURIs: []string{testRedirectURI}, AuthMethod: authMethodNone})
+	require.NoError(t, err)
+
+	second, err := NewAuthorizationServer(cfg)
+	require.NoError(t, err)
+	client, err := second.verifyClient(clientID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{testRedirectURI}, client.RedirectURIs)
+
+	cfg.ClientSecret = "rotated"
+	rotated, err := NewAuthorizationServer(cfg)
+	require.NoError(t, err)
+	_, err = rotated.verifyClient(clientID)
+	assert.Error(t, err)
+}
+
+func TestAuthorizationServerConsent(t *testing.T) {
+	t.Parallel()
+
+	t.Run("page names the client and redirect host", func(t *testing.T) {
+		f := newAuthServerFixture(t, "")
+		client := f.register(t, authMethodNone)
+
+		body, _ := f.startAuthorization(t, client.ClientID, "consent-verifier-0123456789-0123456789")
+		assert.Contains(t, body, "Test Client")
+		assert.Contains(t, body, "127.0.0.1:33418")
+	})
+
+	t.Run("denied", func(t *testing.T) {
+		f := newAuthServerFixture(t, "")
+		client := f.register(t, authMethodNone)
+
+		_, request := f.startAuthorization(t, client.ClientID, "consent-verifier-0123456789-0123456789")
+		resp := f.consent(t, request, client.ClientID, "deny")
+		require.Equal(t, http.StatusFound, resp.StatusCode)
+		location, err := url.Parse(resp.Header.Get("Location"))
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(location.String(), testRedirectURI))
+		assert.Equal(t, "access_denied", location.Query().Get("error"))
+		assert.Equal(t, "client-state", location.Query().Get("state"))
+	})
+
+	t.Run("without the cookie", func(t *testing.T) {
+		f := newAuthServerFixture(t, "")
+		client := f.register(t, authMethodNone)
+
+		_, request := f.startAuthorization(t, client.ClientID, "consent-verifier-0123456789-0123456789")
+		f.client.Jar, _ = cookiejar.New(nil)
+		resp := f.consent(t, request, client.ClientID, "approve")
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("for another client", func(t *testing.T) {
+		f := newAuthServerFixture(t, "")
+		client := f.register(t, authMethodNone)
+		other := f.register(t, authMethodNone)
+
+		_, request := f.startAuthorization(t, client.ClientID, "consent-verifier-0123456789-0123456789")
+		resp := f.consent(t, request, other.ClientID, "approve")
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("callback without consent", func(t *testing.T) {
+		f := newAuthServerFixture(t, "")
+		client := f.register(t, authMethodNone)
+
+		_, request := f.startAuthorization(t, client.ClientID, "consent-verifier-0123456789-0123456789")
+		resp, err := f.client.Get(f.url + CallbackPath + "?" + url.Values{"state": {request}, "code": {"stolen"}}.Encode())
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
+
+func TestAuthorizationServerRefreshTokenBinding(t *testing.T) {
+	t.Parallel()
+	server, err := NewAuthorizationServer(AuthorizationServerConfig{BaseURL: "https://mcp.example.com", ClientID: "id", ClientSecret: "secret"})
+	require.NoError(t, err)
+
+	wrapped := server.wrapRefreshToken("ghr_upstream", "mcp_client")
+	assert.NotContains(t, wrapped, "ghr_upstream")
+
+	refreshToken, err := server.unwrapRefreshToken(wrapped, "mcp_client")
+	require.NoError(t, err)
+	assert.Equal(t, "ghr_upstream", refreshToken)
+
+	_, err = server.unwrapRefreshToken(wrapped, "mcp_other")
+	assert.Error(t, err)
+	_, err = server.unwrapRefreshToken("ghr_upstream", "mcp_client")
+	assert.Error(t, err)
+}
@@ -0,0 +1,174 @@
+// Package oauthtest provides a stand-in for GitHub's OAuth authorization server, for tests
+// of flows that are proxied to GitHub.
+package oauthtest
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+)
+
+// Server is a fake GitHub OAuth authorization server. Its authorize endpoint approves every
+// request immediately, redirecting back with a code, and its access token endpoint
+// exchanges codes and refresh tokens for tokens, reporting errors with a 200 status as
+// GitHub does.
+type Server struct {
+	// ClientID and ClientSecret are the credentials the server accepts.
+	ClientID     string
+	ClientSecret string
+
+	server *httptest.Server
+
+	mu            sync.Mutex
+	codes         map[string]grant
+	refreshTokens map[string]string
+	denyNext      bool
+}
+
+// grant is an authorization code issued by the authorize endpoint.
+type grant struct {
+	redirectURI   string
+	codeChallenge string
+	scope         string
+}
+
+// NewServer starts a server that is closed when the test finishes.
+func NewServer(t testing.TB) *Server {
+	t.Helper()
+	s := &Server{
+		ClientID:      "Iv1.test-client",
+		ClientSecret:  "test-client-secret",
+		codes:         make(map[string]grant),
+		refreshTokens: make(map[string]string),
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /authorize", s.handleAuthorize)
+	mux.HandleFunc("POST /access_token", s.handleAccessToken)
+	s.server = httptest.NewServer(mux)
+	t.Cleanup(s.server.Close)
+	return s
+}
+
+// URL returns the OAuth base URL of the server, the counterpart of
+// https://github.com/login/oauth.
+func (s *Server) URL() string {
+	return s.server.URL
+}
+
+// DenyNext makes the next authorization request fail as if the user denied access.
+func (s *Server) DenyNext() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.denyNext = true
+}
+
+func (s *Server) handleAuthorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if query.Get("client_id") != s.ClientID {
+		http.Error(w, "unknown client", http.StatusNotFound)
+		return
+	}
+	target, err := url.Parse(query.Get("redirect_uri"))
+	if err != nil || target.Scheme == "" {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+
+	params := target.Query()
+	params.Set("state", query.Get("state"))
+
+	s.mu.Lock()
+	if s.denyNext {
+		s.denyNext = false
+		params.Set("error", "access_denied")
+		params.Set("error_description", "The user has denied your application access.")
+	} else {
+		code := randomString()
+		s.codes[code] = grant{
+			redirectURI:   target.String(),
+			codeChallenge: query.Get("code_challenge"),
+			scope:         query.Get("scope"),
+		}
+		params.Set("code", code)
+	}
+	s.mu.Unlock()
+
+	target.RawQuery = params.Encode()
+	http.Redirect(w, r, target.String(), http.StatusFound)
+}
+
+func (s *Server) handleAccessToken(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeError(w, "invalid_request", "request body must be form encoded")
+		return
+	}
+	if r.PostForm.Get("client_id") != s.ClientID || r.PostForm.Get("client_secret") != s.ClientSecret {
+		writeError(w, "incorrect_client_credentials", "The client_id and/or client_secret passed are incorrect.")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var scope string
+	if refreshToken := r.PostForm.Get("refresh_token"); r.PostForm.Get("grant_type") == "refresh_token" {
+		var ok bool
+		if scope, ok = s.refreshTokens[refreshToken]; !ok {
+			writeError(w, "bad_refresh_token", "The refresh token passed is incorrect or expired.")
+			return
+		}
+		delete(s.refreshTokens, refreshToken)
+	} else {
+		code, ok := s.codes[r.PostForm.Get("code")]
+		delete(s.codes, r.PostForm.Get("code"))
+		switch {
+		case !ok:
+			writeError(w, "bad_verification_code", "The code passed is incorrect or expired.")
+			return
+		case r.PostForm.Get("redirect_uri") != "" && r.PostForm.Get("redirect_uri") != code.redirectURI:
+			writeError(w, "redirect_uri_mismatch", "The redirect_uri MUST match the registered callback URL for this application.")
+			return
+		case code.codeChallenge != "" && pkceChallenge(r.PostForm.Get("code_verifier")) != code.codeChallenge:
+			writeError(w, "bad_verification_code", "The code_verifier does not match the code_challenge.")
+			return
+		}
+		scope = code.scope
+	}
+
+	refreshToken := "ghr_" + randomString()
+	s.refreshTokens[refreshToken] = scope
+	writeJSON(w, map[string]any{
+		"access_token":  "gho_" + randomString(),
+		"token_type":    "bearer",
+		"scope":         scope,
+		"expires_in":    28800,
+		"refresh_token": refreshToken,
+	})
+}
+
+func writeError(w http.ResponseWriter, code, description string) {
+	writeJSON(w, map[string]string{"error": code, "error_description": description})
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func pkceChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func randomString() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
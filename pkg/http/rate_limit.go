@@ -0,0 +1,38 @@
+package http
+
+import (
+	"strings"
+
+	"github.com/github/github-mcp-server/pkg/github"
+	"github.com/github/github-mcp-server/pkg/http/middleware"
+	"github.com/github/github-mcp-server/pkg/inventory"
+)
+
+// ToolClassifier returns a function that sorts the tools of an inventory into rate limit
+// classes: search tools, tools that modify GitHub state, and everything else. Deprecated
+// aliases share the class of the tool they resolve to.
+func ToolClassifier(inv *inventory.Inventory) func(toolName string) middleware.ToolClass {
+	classes := make(map[string]middleware.ToolClass)
+	for _, tool := range inv.AllTools() {
+		switch {
+		case strings.HasPrefix(tool.Tool.Name, "search_"):
+			classes[tool.Tool.Name] = middleware.ToolClassSearch
+		case !tool.IsReadOnly():
+			classes[tool.Tool.Name] = middleware.ToolClassWrite
+		default:
+			classes[tool.Tool.Name] = middleware.ToolClassDefault
+		}
+	}
+	for alias, name := range github.DeprecatedToolAliasTargets() {
+		if class, ok := classes[name]; ok {
+			classes[alias] = class
+		}
+	}
+
+	return func(toolName string) middleware.ToolClass {
+		if class, ok := classes[toolName]; ok {
+			return class
+		}
+		return middleware.ToolClassDefault
+	}
+}
@@ -0,0 +1,29 @@
+package http
+
+import (
+	"testing"
+
+	"github.com/github/github-mcp-server/pkg/github"
+	"github.com/github/github-mcp-server/pkg/http/middleware"
+	"github.com/github/github-mcp-server/pkg/inventory"
+	"github.com/github/github-mcp-server/pkg/translations"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToolClassifier(t *testing.T) {
+	inv, err := inventory.NewBuilder().SetTools(github.AllTools(translations.NullTranslationHelper)).Build()
+	require.NoError(t, err)
+	classify := ToolClassifier(inv)
+
+	assert.Equal(t, middleware.ToolClassSearch, classify("search_code"))
+	assert.Equal(t, middleware.ToolClassSearch, classify("search_issues"))
+	assert.Equal(t, middleware.ToolClassWrite, classify("issue_write"))
+	assert.Equal(t, middleware.ToolClassWrite, classify("merge_pull_request"))
+	assert.Equal(t, middleware.ToolClassDefault, classify("get_me"))
+	assert.Equal(t, middleware.ToolClassDefault, classify("unknown_tool"))
+
+	for alias, name := range github.DeprecatedToolAliasTargets() {
+		assert.Equal(t, classify(name), classify(alias), "alias %s is classified like %s", alias, name)
+	}
+}
@@ -12,15 +12,25 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/github/github-mcp-server/pkg/audit"
 	ghcontext "github.com/github/github-mcp-server/pkg/context"
 	"github.com/github/github-mcp-server/pkg/github"
+	"github.com/github/github-mcp-server/pkg/http/eventstore"
+	"github.com/github/github-mcp-server/pkg/http/middleware"
 	"github.com/github/github-mcp-server/pkg/http/oauth"
+	"github.com/github/github-mcp-server/pkg/http/tlsconfig"
+	"github.com/github/github-mcp-server/pkg/http/transport"
 	"github.com/github/github-mcp-server/pkg/inventory"
 	"github.com/github/github-mcp-server/pkg/lockdown"
+	"github.com/github/github-mcp-server/pkg/metrics"
+	"github.com/github/github-mcp-server/pkg/sanitize"
 	"github.com/github/github-mcp-server/pkg/scopes"
+	"github.com/github/github-mcp-server/pkg/tracing"
 	"github.com/github/github-mcp-server/pkg/translations"
 	"github.com/github/github-mcp-server/pkg/utils"
 	"github.com/go-chi/chi/v5"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // knownFeatureFlags are the feature flags that can be enabled via X-MCP-Features header.
@@ -61,12 +71,130 @@ type ServerConfig struct {
 	// LockdownMode indicates if we should enable lockdown mode
 	LockdownMode bool
 
+	// StrictToolAliases rejects calls made through deprecated tool aliases
+	StrictToolAliases bool
+
+	// PromptsDir is a directory of user-defined prompt files to load
+	PromptsDir string
+
 	// RepoAccessCacheTTL overrides the default TTL for repository access cache entries.
 	RepoAccessCacheTTL *time.Duration
 
+	// RepoAccessCacheSize bounds the repositories kept by the in-memory repository access
+	// cache. Defaults to lockdown.DefaultRepoAccessCacheSize.
+	RepoAccessCacheSize int
+
+	// RepoAccessCacheDir keeps the repository access cache in files under this directory
+	// instead of in memory. Replicas of the server can share it.
+	RepoAccessCacheDir string
+
+	// LockdownPolicy extends the content trusted in lockdown mode
+	LockdownPolicy *lockdown.Policy
+
+	// LockdownPlaceholders lists a placeholder for every item lockdown mode hides from a
+	// tool result
+	LockdownPlaceholders bool
+
+	// InjectionDetector marks user-authored text that looks like a prompt injection in
+	// tool results. Defaults to the built-in rules.
+	InjectionDetector *sanitize.InjectionDetector
+
+	// DisableInjectionMarkers leaves user-authored text that looks like a prompt injection
+	// unmarked in tool results and resources.
+	DisableInjectionMarkers bool
+
+	// OAuthClientID and OAuthClientSecret enable the OAuth authorization server facade,
+	// which registers clients dynamically and proxies authorization to this GitHub OAuth
+	// App or GitHub App. BaseURL is required when they are set.
+	OAuthClientID     string
+	OAuthClientSecret string
+
+	// OAuthUpstreamURL is the OAuth base URL of GitHub used by the authorization server
+	// facade. Defaults to oauth.DefaultAuthorizationServer.
+	OAuthUpstreamURL string
+
+	// OAuthRedirectURIs are the redirect URIs clients of the authorization server facade
+	// may register besides loopback addresses.
+	OAuthRedirectURIs []string
+
 	// ScopeChallenge indicates if we should return OAuth scope challenges, and if we should perform
 	// tool filtering based on token scopes.
 	ScopeChallenge bool
+
+	// TLSCertFile and TLSKeyFile enable HTTPS with the given PEM certificate and key.
+	// The files are reloaded when they change.
+	TLSCertFile string
+	TLSKeyFile  string
+
+	// TLSClientCAFile enables mutual TLS: clients of the MCP endpoints must present a
+	// certificate signed by one of the CAs in this PEM bundle. Health endpoints don't
+	// require one.
+	TLSClientCAFile string
+
+	// MetricsAddr serves Prometheus metrics at /metrics on a separate listener on this
+	// address (e.g. "127.0.0.1:9090"). When empty, metrics are not collected. They are never
+	// served on the main listener, which is public.
+	MetricsAddr string
+
+	// OTLPEndpoint enables tracing, exporting spans to the OpenTelemetry collector at this
+	// base URL (e.g. "http://localhost:4318") over OTLP/HTTP.
+	OTLPEndpoint string
+
+	// ShutdownDelay is how long the server keeps serving after readiness is withdrawn on
+	// shutdown, giving load balancers time to stop routing new requests to it.
+	ShutdownDelay time.Duration
+
+	// StatefulSessions keeps an MCP session per client instead of serving every request
+	// statelessly, so that clients can resume interrupted SSE streams with Last-Event-ID.
+	StatefulSessions bool
+
+	// SessionIdleTimeout closes stateful sessions that receive no requests for this long.
+	// Zero keeps idle sessions open.
+	SessionIdleTimeout time.Duration
+
+	// MaxSessions limits the number of concurrent stateful sessions. Zero means no limit.
+	MaxSessions int
+
+	// SessionEventDir keeps the events of stateful sessions in files under this directory
+	// instead of in memory.
+	SessionEventDir string
+
+	// SessionEventBufferBytes bounds the events kept in memory across all stateful
+	// sessions. Zero uses eventstore.DefaultMemoryBytes.
+	SessionEventBufferBytes int
+
+	// ResourcePollInterval enables resources/subscribe in stateful sessions when positive.
+	// Subscribed resources are polled for changes at this interval. Stateless requests
+	// have no session to notify, so they never support subscriptions.
+	ResourcePollInterval time.Duration
+
+	// MaxResourceSubscriptions caps the number of resources a single stateful session may
+	// subscribe to. Zero means no limit.
+	MaxResourceSubscriptions int
+
+	// Audit configures the audit log of tool calls, which is disabled when it has neither
+	// a file path nor a webhook URL.
+	Audit audit.Options
+
+	// APICacheBytes bounds the cache of GitHub REST responses, shared by all clients and
+	// scoped per token. Zero disables the cache.
+	APICacheBytes int64
+
+	// APICacheDir keeps cached GitHub responses in files under this directory instead of
+	// in memory.
+	APICacheDir string
+
+	// RateLimitMaxWait is the longest a GitHub request waits in total for rate limits to
+	// reset before it is retried. Zero, the default, disables retries.
+	RateLimitMaxWait time.Duration
+
+	// RateLimitMeta attaches the GitHub rate limits reported during each tool call to its
+	// result in _meta.
+	RateLimitMeta bool
+
+	// RateLimits limits the rate and concurrency of tool calls per client and tool class.
+	// Tool calls are not limited when empty.
+	RateLimits map[middleware.ToolClass]middleware.Limit
 }
 
 func RunHTTPServer(cfg ServerConfig) error {
@@ -97,25 +225,82 @@ func RunHTTPServer(cfg ServerConfig) error {
 		return fmt.Errorf("failed to parse API host: %w", err)
 	}
 
+	tlsCfg := tlsconfig.Config{
+		CertFile:     cfg.TLSCertFile,
+		KeyFile:      cfg.TLSKeyFile,
+		ClientCAFile: cfg.TLSClientCAFile,
+		Logger:       logger.With("component", "tls"),
+	}
+	if err := tlsCfg.Validate(); err != nil {
+		return fmt.Errorf("invalid TLS configuration: %w", err)
+	}
+
 	repoAccessOpts := []lockdown.RepoAccessOption{
 		lockdown.WithLogger(logger.With("component", "lockdown")),
 	}
 	if cfg.RepoAccessCacheTTL != nil {
 		repoAccessOpts = append(repoAccessOpts, lockdown.WithTTL(*cfg.RepoAccessCacheTTL))
 	}
+	repoAccessStore, err := lockdown.NewRepoAccessStore(cfg.RepoAccessCacheDir, cfg.RepoAccessCacheSize)
+	if err != nil {
+		return fmt.Errorf("failed to open repo access cache: %w", err)
+	}
+	repoAccessOpts = append(repoAccessOpts, lockdown.WithStore(repoAccessStore), lockdown.WithPolicy(cfg.LockdownPolicy))
+	repoAccessCache := lockdown.NewRepoAccessCache(nil, repoAccessOpts...)
 
 	featureChecker := createHTTPFeatureChecker()
 
+	// Metrics are only served on their own listener, so that they are never exposed
+	// unauthenticated on the public one
+	var serverMetrics *metrics.Metrics
+	if cfg.MetricsAddr != "" {
+		serverMetrics = metrics.New()
+		serverMetrics.RegisterLockdownCache(repoAccessCache.Stats)
+	}
+
 	deps := github.NewRequestDeps(
 		apiHost,
 		cfg.Version,
 		cfg.LockdownMode,
-		repoAccessOpts,
+		repoAccessCache,
 		t,
 		cfg.ContentWindowSize,
 		featureChecker,
 	)
 
+	var apiTransport http.RoundTripper = &transport.MetricsTransport{Transport: http.DefaultTransport, Metrics: serverMetrics}
+	var tracerProvider trace.TracerProvider
+	if cfg.OTLPEndpoint != "" {
+		provider, err := tracing.NewProvider(ctx, tracing.ProviderOptions{
+			Endpoint:       cfg.OTLPEndpoint,
+			ServiceVersion: cfg.Version,
+			Logger:         logger.With("component", "tracing"),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to configure tracing: %w", err)
+		}
+		defer shutdownTracerProvider(provider, logger)
+		tracerProvider = provider
+		apiTransport = transport.NewTracingTransport(apiTransport, provider)
+		logger.Info("exporting traces", "endpoint", cfg.OTLPEndpoint)
+	}
+	apiCache, err := transport.NewCacheStore(cfg.APICacheDir, cfg.APICacheBytes)
+	if err != nil {
+		return fmt.Errorf("failed to open API cache: %w", err)
+	}
+	if apiCache != nil {
+		// The cache sees the Authorization header, which scopes its entries to the token
+		apiTransport = &transport.CachingTransport{Transport: apiTransport, Store: apiCache}
+		logger.Info("caching GitHub API responses", "max_bytes", cfg.APICacheBytes, "dir", cfg.APICacheDir)
+	}
+	if cfg.RateLimitMaxWait > 0 {
+		apiTransport = &transport.RetryTransport{Transport: apiTransport, MaxWait: cfg.RateLimitMaxWait}
+	}
+	if cfg.RateLimitMeta {
+		apiTransport = &transport.RateLimitTransport{Transport: apiTransport}
+	}
+	deps.Transport = apiTransport
+
 	// Initialize the global tool scope map
 	err = initGlobalToolScopeMap(t)
 	if err != nil {
@@ -128,11 +313,72 @@ func RunHTTPServer(cfg ServerConfig) error {
 		ResourcePath: cfg.ResourcePath,
 	}
 
-	serverOptions := []HandlerOption{}
+	serverOptions := []HandlerOption{WithMetrics(serverMetrics), WithTracerProvider(tracerProvider)}
+	if cfg.PromptsDir != "" {
+		prompts, err := github.LoadPromptLibrary(cfg.PromptsDir)
+		if err != nil {
+			return fmt.Errorf("failed to load prompt library: %w", err)
+		}
+		logger.Info("loaded prompt library", "dir", cfg.PromptsDir, "prompts", len(prompts))
+		serverOptions = append(serverOptions, WithPrompts(prompts))
+	}
 	if cfg.ScopeChallenge {
 		scopeFetcher := scopes.NewFetcher(apiHost, scopes.FetcherOptions{})
 		serverOptions = append(serverOptions, WithScopeFetcher(scopeFetcher))
 	}
+	if len(cfg.RateLimits) > 0 {
+		classifierInventory, err := inventory.NewBuilder().SetTools(github.AllTools(t)).Build()
+		if err != nil {
+			return fmt.Errorf("failed to build inventory for rate limits: %w", err)
+		}
+		serverOptions = append(serverOptions, WithRateLimit(middleware.RateLimitConfig{
+			Limits:   cfg.RateLimits,
+			Classify: ToolClassifier(classifierInventory),
+			Logger:   logger,
+		}))
+		logger.Info("tool call rate limits enabled", "limits", cfg.RateLimits)
+	}
+	cfg.Audit.Logger = logger.With("component", "audit")
+	auditor, err := audit.Open(cfg.Audit)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	if auditor != nil {
+		defer func() {
+			if err := auditor.Close(); err != nil {
+				logger.Warn("failed to close audit log", "error", err)
+			}
+		}()
+		serverOptions = append(serverOptions, WithAudit(auditor))
+		logger.Info("auditing tool calls", "file", cfg.Audit.FilePath, "webhook", cfg.Audit.WebhookURL)
+	}
+	if cfg.StatefulSessions {
+		if cfg.SessionEventDir != "" {
+			store, err := eventstore.NewFile(cfg.SessionEventDir)
+			if err != nil {
+				return fmt.Errorf("failed to create session event store: %w", err)
+			}
+			serverOptions = append(serverOptions, WithEventStore(store))
+		}
+		logger.Info("stateful sessions enabled", "idleTimeout", cfg.SessionIdleTimeout, "maxSessions", cfg.MaxSessions, "eventDir", cfg.SessionEventDir)
+	}
+
+	var authServer *oauth.AuthorizationServer
+	if cfg.OAuthClientID != "" {
+		authServer, err = oauth.NewAuthorizationServer(oauth.AuthorizationServerConfig{
+			BaseURL:      cfg.BaseURL,
+			UpstreamURL:  cfg.OAuthUpstreamURL,
+			ClientID:     cfg.OAuthClientID,
+			ClientSecret: cfg.OAuthClientSecret,
+			Logger:       logger.With("component", "oauth"),
+
+			AllowedRedirectURIs: cfg.OAuthRedirectURIs,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create OAuth authorization server: %w", err)
+		}
+		oauthCfg.AuthorizationServer = authServer.Issuer()
+	}
 
 	r := chi.NewRouter()
 	handler := NewHTTPMcpHandler(ctx, &cfg, deps, t, logger, apiHost, append(serverOptions, WithFeatureChecker(featureChecker), WithOAuthConfig(oauthCfg))...)
@@ -156,6 +402,31 @@ func RunHTTPServer(cfg ServerConfig) error {
 	})
 	logger.Info("OAuth protected resource endpoints registered", "baseURL", cfg.BaseURL)
 
+	if authServer != nil {
+		r.Group(func(r chi.Router) {
+			authServer.RegisterRoutes(r)
+		})
+		logger.Info("OAuth authorization server endpoints registered", "issuer", authServer.Issuer())
+	}
+
+	if serverMetrics != nil {
+		go func() {
+			if err := metrics.ListenAndServe(ctx, cfg.MetricsAddr, serverMetrics.Handler()); err != nil {
+				logger.Error("metrics server failed", "addr", cfg.MetricsAddr, "error", err)
+			}
+		}()
+		logger.Info("metrics server listening", "addr", cfg.MetricsAddr)
+	}
+
+	healthHandler := NewHealthHandler(
+		GitHubAPIHealthCheck(apiHost, &http.Client{Timeout: readinessTimeout}),
+		ToolScopeMapHealthCheck(),
+	)
+	r.Group(func(r chi.Router) {
+		healthHandler.RegisterRoutes(r)
+	})
+	logger.Info("health endpoints registered", "liveness", HealthzPath, "readiness", ReadyzPath)
+
 	addr := fmt.Sprintf(":%d", cfg.Port)
 	httpSvr := http.Server{
 		Addr:              addr,
@@ -163,14 +434,34 @@ func RunHTTPServer(cfg ServerConfig) error {
 		ReadHeaderTimeout: 60 * time.Second,
 	}
 
+	if tlsCfg.Enabled() {
+		reloader, err := tlsconfig.NewReloader(tlsCfg)
+		if err != nil {
+			return fmt.Errorf("failed to load TLS configuration: %w", err)
+		}
+		httpSvr.TLSConfig = reloader.TLSConfig()
+		go reloader.Watch(ctx)
+	}
+
+	// ListenAndServe returns as soon as Shutdown is called, so wait for in-flight requests
+	// to drain before returning
+	shutdownDone := make(chan struct{})
 	go func() {
+		defer close(shutdownDone)
 		<-ctx.Done()
+		healthHandler.SetShuttingDown()
+		if cfg.ShutdownDelay > 0 {
+			logger.Info("readiness withdrawn, waiting before shutdown", "delay", cfg.ShutdownDelay)
+			time.Sleep(cfg.ShutdownDelay)
+		}
 		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
 		logger.Info("shutting down server")
 		if err := httpSvr.Shutdown(shutdownCtx); err != nil {
 			logger.Error("error during server shutdown", "error", err)
 		}
+		// Sessions outlive requests, so close them once no more requests can arrive
+		handler.CloseSessions()
 	}()
 
 	if cfg.ExportTranslations {
@@ -178,15 +469,32 @@ func RunHTTPServer(cfg ServerConfig) error {
 		dumpTranslations()
 	}
 
-	logger.Info("HTTP server listening", "addr", addr)
-	if err := httpSvr.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+	if tlsCfg.Enabled() {
+		logger.Info("HTTPS server listening", "addr", addr, "mtls", tlsCfg.ClientCAFile != "")
+		// The certificate is served from TLSConfig, so no files are passed here
+		err = httpSvr.ListenAndServeTLS("", "")
+	} else {
+		logger.Info("HTTP server listening", "addr", addr)
+		err = httpSvr.ListenAndServe()
+	}
+	if err != nil && err != http.ErrServerClosed {
 		return fmt.Errorf("HTTP server error: %w", err)
 	}
+	<-shutdownDone
 
 	logger.Info("server stopped gracefully")
 	return nil
 }
 
+// shutdownTracerProvider exports the spans still queued when the server stops.
+func shutdownTracerProvider(provider *sdktrace.TracerProvider, logger *slog.Logger) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := provider.Shutdown(ctx); err != nil {
+		logger.Warn("failed to export remaining spans", "error", err)
+	}
+}
+
 func initGlobalToolScopeMap(t translations.TranslationHelperFunc) error {
 	// Build inventory with all tools to extract scope information
 	inv, err := inventory.NewBuilder().
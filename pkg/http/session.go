@@ -0,0 +1,284 @@
+package http
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+
+	ghcontext "github.com/github/github-mcp-server/pkg/context"
+	"github.com/github/github-mcp-server/pkg/github"
+	"github.com/github/github-mcp-server/pkg/metrics"
+	"github.com/github/github-mcp-server/pkg/utils"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// sessionIDHeader is the header streamable HTTP clients address their session with.
+const sessionIDHeader = "Mcp-Session-Id"
+
+// sessionRetryAfter is the Retry-After hint, in seconds, sent when the session limit is reached.
+const sessionRetryAfter = 30
+
+// sessionManager keeps an MCP session per client in stateful mode. Events of every stream
+// are recorded in an event store, so that a client that loses its connection during a
+// long tool call can resume the stream with Last-Event-ID and receive the progress
+// notifications and result it missed.
+//
+// A session is bound to the GitHub user whose token initialized it, so that it survives
+// the rotation of an OAuth token, and every request uses the token it was sent with. Its
+// server, inventory and request configuration are fixed when it is initialized, and it is
+// closed after being idle for the configured timeout.
+type sessionManager struct {
+	handler     *mcp.StreamableHTTPHandler
+	maxSessions int
+	metrics     *metrics.Metrics
+	logger      *slog.Logger
+
+	// resolveUser looks up the ID of the GitHub user the token in ctx belongs to. When
+	// nil, sessions are bound to the token that initialized them.
+	resolveUser func(ctx context.Context) (int64, error)
+
+	mu       sync.Mutex
+	sessions map[string]*trackedSession
+}
+
+type trackedSession struct {
+	session *mcp.ServerSession
+	// owner is the hash of the last token the session was used with
+	owner string
+	// userID is the GitHub user the session belongs to, or 0 if it could not be resolved,
+	// in which case only the token that initialized the session may use it
+	userID int64
+}
+
+// sessionServerKey carries the server built for a new session to the SDK handler.
+type sessionServerKey struct{}
+
+func newSessionManager(cfg *ServerConfig, eventStore mcp.EventStore, m *metrics.Metrics, resolveUser func(context.Context) (int64, error), logger *slog.Logger) *sessionManager {
+	sm := &sessionManager{
+		maxSessions: cfg.MaxSessions,
+		metrics:     m,
+		logger:      logger,
+		resolveUser: resolveUser,
+		sessions:    make(map[string]*trackedSession),
+	}
+	sm.handler = mcp.NewStreamableHTTPHandler(func(r *http.Request) *mcp.Server {
+		server, _ := r.Context().Value(sessionServerKey{}).(*mcp.Server)
+		return server
+	}, &mcp.StreamableHTTPOptions{
+		EventStore:     eventStore,
+		SessionTimeout: cfg.SessionIdleTimeout,
+		Logger:         logger,
+	})
+	return sm
+}
+
+// serveSession serves a request in stateful mode. Requests that start a session get a
+// server of their own; the SDK handler routes all other requests to their session.
+func (h *Handler) serveSession(w http.ResponseWriter, r *http.Request) {
+	sessionID := r.Header.Get(sessionIDHeader)
+	if sessionID != "" {
+		if !h.sessions.has(sessionID) {
+			http.Error(w, "session not found", http.StatusNotFound)
+			return
+		}
+		if !h.sessions.ownedBy(r.Context(), sessionID) {
+			http.Error(w, "session user mismatch", http.StatusForbidden)
+			return
+		}
+		h.sessions.handler.ServeHTTP(w, r)
+		return
+	}
+
+	if r.Method == http.MethodPost {
+		if !h.sessions.hasCapacity() {
+			w.Header().Set("Retry-After", strconv.Itoa(sessionRetryAfter))
+			http.Error(w, "too many sessions", http.StatusServiceUnavailable)
+			return
+		}
+
+		inv, ok := h.buildInventory(w, r)
+		if !ok {
+			return
+		}
+		// The server outlives the request that initializes its session, and so do the
+		// pollers of its resource subscriptions
+		server, ok := h.buildServer(w, r.WithContext(context.WithoutCancel(r.Context())), inv)
+		if !ok {
+			return
+		}
+		server.AddReceivingMiddleware(h.sessions.trackMiddleware)
+		r = r.WithContext(context.WithValue(r.Context(), sessionServerKey{}, server))
+	}
+	h.sessions.handler.ServeHTTP(w, r)
+}
+
+// trackMiddleware records sessions as they are initialized, and forgets them once closed.
+// The SDK handles every request of a session in the context of the initialize request, so
+// it also replaces the token of that request with the one the current request was sent
+// with.
+func (sm *sessionManager) trackMiddleware(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		if extra := req.GetExtra(); extra != nil && extra.Header != nil {
+			if tokenType, token, err := utils.ParseAuthorizationHeader(&http.Request{Header: extra.Header}); err == nil {
+				ctx = ghcontext.WithTokenInfo(ctx, &ghcontext.TokenInfo{Token: token, TokenType: tokenType})
+			}
+		}
+		if method == "initialize" {
+			if session, ok := req.GetSession().(*mcp.ServerSession); ok {
+				sm.track(ctx, session)
+			}
+		}
+		return next(ctx, method, req)
+	}
+}
+
+func (sm *sessionManager) track(ctx context.Context, session *mcp.ServerSession) {
+	id := session.ID()
+	sm.mu.Lock()
+	_, ok := sm.sessions[id]
+	sm.mu.Unlock()
+	if ok {
+		return
+	}
+
+	tracked := &trackedSession{session: session, owner: tokenOwner(ctx)}
+	if sm.resolveUser != nil {
+		userID, err := sm.resolveUser(ctx)
+		if err != nil {
+			sm.logger.Debug("failed to resolve session user, binding the session to its token", "session_id", id, "error", err)
+		}
+		tracked.userID = userID
+	}
+
+	sm.mu.Lock()
+	if _, ok := sm.sessions[id]; ok {
+		sm.mu.Unlock()
+		return
+	}
+	sm.sessions[id] = tracked
+	sm.mu.Unlock()
+
+	if sm.metrics != nil {
+		sm.metrics.SessionStarted()
+	}
+	sm.logger.Debug("session started", "session_id", id)
+
+	go func() {
+		_ = session.Wait()
+		sm.mu.Lock()
+		delete(sm.sessions, id)
+		sm.mu.Unlock()
+
+		if sm.metrics != nil {
+			sm.metrics.SessionEnded()
+		}
+		sm.logger.Debug("session ended", "session_id", id)
+	}()
+}
+
+// has reports whether a session is tracked.
+func (sm *sessionManager) has(sessionID string) bool {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	_, ok := sm.sessions[sessionID]
+	return ok
+}
+
+// ownedBy reports whether the token in ctx may use a session: either it is the token the
+// session was last used with, or it belongs to the same GitHub user, as after an OAuth
+// token was refreshed. Sessions that are not tracked are owned by no one.
+func (sm *sessionManager) ownedBy(ctx context.Context, sessionID string) bool {
+	owner := tokenOwner(ctx)
+	sm.mu.Lock()
+	tracked, ok := sm.sessions[sessionID]
+	if !ok {
+		sm.mu.Unlock()
+		return false
+	}
+	if tracked.owner == owner {
+		sm.mu.Unlock()
+		return true
+	}
+	userID := tracked.userID
+	sm.mu.Unlock()
+
+	if userID == 0 || sm.resolveUser == nil {
+		return false
+	}
+	requestUserID, err := sm.resolveUser(ctx)
+	if err != nil || requestUserID != userID {
+		return false
+	}
+
+	// Remember the new token, so that its next requests don't look the user up again
+	sm.mu.Lock()
+	tracked.owner = owner
+	sm.mu.Unlock()
+	return true
+}
+
+// hasCapacity reports whether another session may be started. Concurrent initializations
+// may overshoot the limit slightly.
+func (sm *sessionManager) hasCapacity() bool {
+	if sm.maxSessions <= 0 {
+		return true
+	}
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	return len(sm.sessions) < sm.maxSessions
+}
+
+// closeAll closes every session, releasing their events.
+func (sm *sessionManager) closeAll() {
+	sm.mu.Lock()
+	sessions := make([]*mcp.ServerSession, 0, len(sm.sessions))
+	for _, tracked := range sm.sessions {
+		sessions = append(sessions, tracked.session)
+	}
+	sm.mu.Unlock()
+
+	for _, session := range sessions {
+		_ = session.Close()
+	}
+}
+
+// CloseSessions closes all stateful sessions. It is a no-op in stateless mode.
+func (h *Handler) CloseSessions() {
+	if h.sessions != nil {
+		h.sessions.closeAll()
+	}
+}
+
+// githubUserResolver looks up the GitHub user of the token in the context with deps, or
+// returns nil without deps.
+func githubUserResolver(deps github.ToolDependencies) func(context.Context) (int64, error) {
+	if deps == nil {
+		return nil
+	}
+	return func(ctx context.Context) (int64, error) {
+		client, err := deps.GetClient(ctx)
+		if err != nil {
+			return 0, err
+		}
+		user, _, err := client.Users.Get(ctx, "")
+		if err != nil {
+			return 0, err
+		}
+		return user.GetID(), nil
+	}
+}
+
+// tokenOwner identifies the owner of a session by a hash of the request's token, so that
+// the token itself is not retained.
+func tokenOwner(ctx context.Context) string {
+	tokenInfo, ok := ghcontext.GetTokenInfo(ctx)
+	if !ok || tokenInfo == nil {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(tokenInfo.Token))
+	return hex.EncodeToString(sum[:])
+}
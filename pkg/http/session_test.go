@@ -0,0 +1,393 @@
+package http
+
+import (
+	"bufio"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/github/github-mcp-server/pkg/github"
+	"github.com/github/github-mcp-server/pkg/http/headers"
+	"github.com/github/github-mcp-server/pkg/inventory"
+	"github.com/github/github-mcp-server/pkg/translations"
+	"github.com/github/github-mcp-server/pkg/utils"
+	"github.com/go-chi/chi/v5"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	initializeMessage  = `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18","capabilities":{},"clientInfo":{"name":"test","version":"1.0.0"}}}`
+	initializedMessage = `{"jsonrpc":"2.0","method":"notifications/initialized"}`
+	slowToolMessage    = `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"slow_tool","arguments":{},"_meta":{"progressToken":"p1"}}}`
+)
+
+// newSessionTestServer serves stateful sessions with a slow_tool that reports progress and
+// then waits for release to be closed.
+func newSessionTestServer(t *testing.T, cfg *ServerConfig, release <-chan struct{}) *httptest.Server {
+	t.Helper()
+	cfg.StatefulSessions = true
+
+	apiHost, err := utils.NewAPIHost("https://api.github.com")
+	require.NoError(t, err)
+
+	inventoryFactory := func(_ *http.Request) (*inventory.Inventory, error) {
+		return inventory.NewBuilder().Build()
+	}
+	mcpServerFactory := func(_ *http.Request, _ github.ToolDependencies, _ *inventory.Inventory, _ *github.MCPServerConfig) (*mcp.Server, error) {
+		server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "0.0.1"}, nil)
+		mcp.AddTool(server, &mcp.Tool{Name: "slow_tool"}, func(ctx context.Context, req *mcp.CallToolRequest, _ struct{}) (*mcp.CallToolResult, any, error) {
+			err := req.Session.NotifyProgress(ctx, &mcp.ProgressNotificationParams{
+				ProgressToken: req.Params.GetProgressToken(),
+				Message:       "working",
+				Progress:      1,
+			})
+			if err != nil {
+				return nil, nil, err
+			}
+			<-release
+			return utils.NewToolResultText("done"), nil, nil
+		})
+		return server, nil
+	}
+
+	handler := NewHTTPMcpHandler(
+		context.Background(),
+		cfg,
+		nil,
+		translations.NullTranslationHelper,
+		slog.Default(),
+		apiHost,
+		WithInventoryFactory(inventoryFactory),
+		WithGitHubMCPServerFactory(mcpServerFactory),
+		WithScopeFetcher(allScopesFetcher{}),
+	)
+	t.Cleanup(handler.CloseSessions)
+
+	r := chi.NewRouter()
+	handler.RegisterMiddleware(r)
+	handler.RegisterRoutes(r)
+
+	server := httptest.NewServer(r)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func sessionRequest(t *testing.T, method, url, token, sessionID, body string) *http.Response {
+	t.Helper()
+	req, err := http.NewRequestWithContext(t.Context(), method, url, strings.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set(headers.AuthorizationHeader, "Bearer "+token)
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	req.Header.Set("Content-Type", "application/json")
+	if sessionID != "" {
+		req.Header.Set(sessionIDHeader, sessionID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	return resp
+}
+
+// initializeSession starts a session for token and returns its ID.
+func initializeSession(t *testing.T, url, token string) string {
+	t.Helper()
+	resp := sessionRequest(t, http.MethodPost, url, token, "", initializeMessage)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	_ = resp.Body.Close()
+	sessionID := resp.Header.Get(sessionIDHeader)
+	require.NotEmpty(t, sessionID)
+
+	resp = sessionRequest(t, http.MethodPost, url, token, sessionID, initializedMessage)
+	require.Equal(t, http.StatusAccepted, resp.StatusCode)
+	_ = resp.Body.Close()
+	return sessionID
+}
+
+type sseEvent struct {
+	id   string
+	data string
+}
+
+func readEvent(t *testing.T, scanner *bufio.Scanner) sseEvent {
+	t.Helper()
+	var event sseEvent
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if event.data != "" {
+				return event
+			}
+		case strings.HasPrefix(line, "id: "):
+			event.id = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "data: "):
+			event.data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+	require.NoError(t, scanner.Err())
+	require.FailNow(t, "stream ended before an event was received")
+	return event
+}
+
+func TestStatefulSessionResumesStream(t *testing.T) {
+	release := make(chan struct{})
+	server := newSessionTestServer(t, &ServerConfig{Version: "test"}, release)
+	sessionID := initializeSession(t, server.URL, "ghp_alice")
+
+	// The client disconnects after the progress notification
+	resp := sessionRequest(t, http.MethodPost, server.URL, "ghp_alice", sessionID, slowToolMessage)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	progress := readEvent(t, bufio.NewScanner(resp.Body))
+	_ = resp.Body.Close()
+	assert.Contains(t, progress.data, `"method":"notifications/progress"`)
+	require.NotEmpty(t, progress.id)
+
+	close(release)
+
+	// Resuming the stream delivers the result that was produced while disconnected
+	req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+	req.Header.Set(headers.AuthorizationHeader, "Bearer ghp_alice")
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set(sessionIDHeader, sessionID)
+	req.Header.Set("Last-Event-ID", progress.id)
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	result := readEvent(t, bufio.NewScanner(resp.Body))
+	assert.Contains(t, result.data, `"id":2`)
+	assert.Contains(t, result.data, `"text":"done"`)
+}
+
+func TestStatefulSessionIsBoundToToken(t *testing.T) {
+	server := newSessionTestServer(t, &ServerConfig{Version: "test"}, nil)
+	sessionID := initializeSession(t, server.URL, "ghp_alice")
+
+	resp := sessionRequest(t, http.MethodPost, server.URL, "ghp_bob", sessionID, `{"jsonrpc":"2.0","id":2,"method":"ping"}`)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	resp = sessionRequest(t, http.MethodPost, server.URL, "ghp_alice", sessionID, `{"jsonrpc":"2.0","id":2,"method":"ping"}`)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp = sessionRequest(t, http.MethodPost, server.URL, "ghp_alice", "unknown", `{"jsonrpc":"2.0","id":3,"method":"ping"}`)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestStatefulSessionLimit(t *testing.T) {
+	server := newSessionTestServer(t, &ServerConfig{Version: "test", MaxSessions: 1}, nil)
+	sessionID := initializeSession(t, server.URL, "ghp_alice")
+
+	resp := sessionRequest(t, http.MethodPost, server.URL, "ghp_bob", "", initializeMessage)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal(t, "30", resp.Header.Get("Retry-After"))
+
+	resp = sessionRequest(t, http.MethodDelete, server.URL, "ghp_alice", sessionID, "")
+	_ = resp.Body.Close()
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	require.Eventually(t, func() bool {
+		resp := sessionRequest(t, http.MethodPost, server.URL, "ghp_bob", "", initializeMessage)
+		_ = resp.Body.Close()
+		return resp.StatusCode == http.StatusOK
+	}, 5*time.Second, 10*time.Millisecond, "closed sessions free their slot")
+}
+
+func TestStatefulSessionIdleTimeout(t *testing.T) {
+	server := newSessionTestServer(t, &ServerConfig{Version: "test", SessionIdleTimeout: 50 * time.Millisecond}, nil)
+	sessionID := initializeSession(t, server.URL, "ghp_alice")
+
+	require.Eventually(t, func() bool {
+		resp := sessionRequest(t, http.MethodPost, server.URL, "ghp_alice", sessionID, `{"jsonrpc":"2.0","id":2,"method":"ping"}`)
+		_ = resp.Body.Close()
+		return resp.StatusCode == http.StatusNotFound
+	}, 5*time.Second, 100*time.Millisecond, "idle sessions are closed")
+}
+
+func TestStatefulSessionUsesRequestToken(t *testing.T) {
+	var mu sync.Mutex
+	var tokens []string
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization := r.Header.Get(headers.AuthorizationHeader)
+		mu.Lock()
+		tokens = append(tokens, authorization)
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		switch authorization {
+		case "Bearer ghp_alice", "Bearer ghp_alice_refreshed":
+			_, _ = w.Write([]byte(`{"login": "alice", "id": 1}`))
+		default:
+			_, _ = w.Write([]byte(`{"login": "bob", "id": 2}`))
+		}
+	}))
+	t.Cleanup(api.Close)
+	takeTokens := func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		taken := tokens
+		tokens = nil
+		return taken
+	}
+
+	apiHost := testAPIHostResolver{baseURL: api.URL + "/"}
+	deps := github.NewRequestDeps(apiHost, "test", false, nil, translations.NullTranslationHelper, 0, nil)
+	handler := NewHTTPMcpHandler(
+		context.Background(),
+		&ServerConfig{Version: "test", StatefulSessions: true},
+		deps,
+		translations.NullTranslationHelper,
+		slog.Default(),
+		apiHost,
+		WithInventoryFactory(func(_ *http.Request) (*inventory.Inventory, error) {
+			return github.NewInventory(translations.NullTranslationHelper).WithToolsets([]string{}).WithTools([]string{"get_me"}).Build()
+		}),
+		WithScopeFetcher(allScopesFetcher{}),
+	)
+	t.Cleanup(handler.CloseSessions)
+	r := chi.NewRouter()
+	handler.RegisterMiddleware(r)
+	handler.RegisterRoutes(r)
+	server := httptest.NewServer(r)
+	t.Cleanup(server.Close)
+
+	sessionID := initializeSession(t, server.URL, "ghp_alice")
+	assert.Equal(t, []string{"Bearer ghp_alice"}, takeTokens(), "the session user is looked up")
+
+	getMe := `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"get_me","arguments":{}}}`
+	callGetMe := func(token string) (int, string) {
+		resp := sessionRequest(t, http.MethodPost, server.URL, token, sessionID, getMe)
+		defer func() { _ = resp.Body.Close() }()
+		if resp.StatusCode != http.StatusOK {
+			return resp.StatusCode, ""
+		}
+		return resp.StatusCode, readEvent(t, bufio.NewScanner(resp.Body)).data
+	}
+
+	// After the OAuth token is refreshed, the session's tool calls use the new token
+	code, result := callGetMe("ghp_alice_refreshed")
+	require.Equal(t, http.StatusOK, code)
+	assert.Contains(t, result, "alice")
+	assert.Equal(t, []string{"Bearer ghp_alice_refreshed", "Bearer ghp_alice_refreshed"}, takeTokens(),
+		"the new token's user is looked up once, and the tool call uses the new token")
+
+	code, _ = callGetMe("ghp_alice_refreshed")
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, []string{"Bearer ghp_alice_refreshed"}, takeTokens())
+
+	// Tokens of other users are rejected before reaching the session
+	code, _ = callGetMe("ghp_bob")
+	assert.Equal(t, http.StatusForbidden, code)
+	assert.Equal(t, []string{"Bearer ghp_bob"}, takeTokens())
+}
+
+func TestSessionOwnedByFailsClosed(t *testing.T) {
+	sm := newSessionManager(&ServerConfig{}, nil, nil, nil, slog.Default())
+	assert.False(t, sm.ownedBy(context.Background(), "untracked"), "sessions that are not tracked are owned by no one")
+}
+
+func TestStatefulSessionResourceSubscriptions(t *testing.T) {
+	var mu sync.Mutex
+	etag := `"a"`
+	var pollTokens []string
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/user" {
+			_, _ = w.Write([]byte(`{"login": "alice", "id": 1}`))
+			return
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		pollTokens = append(pollTokens, r.Header.Get(headers.AuthorizationHeader))
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		_, _ = w.Write([]byte(`{"number": 42}`))
+	}))
+	t.Cleanup(api.Close)
+
+	apiHost := testAPIHostResolver{baseURL: api.URL + "/"}
+	deps := github.NewRequestDeps(apiHost, "test", false, nil, translations.NullTranslationHelper, 0, nil)
+	handler := NewHTTPMcpHandler(
+		context.Background(),
+		&ServerConfig{
+			Version:                  "test",
+			StatefulSessions:         true,
+			ResourcePollInterval:     10 * time.Millisecond,
+			MaxResourceSubscriptions: 1,
+		},
+		deps,
+		translations.NullTranslationHelper,
+		slog.Default(),
+		apiHost,
+		WithInventoryFactory(func(_ *http.Request) (*inventory.Inventory, error) {
+			return github.NewInventory(translations.NullTranslationHelper).WithToolsets([]string{"issues"}).Build()
+		}),
+		WithScopeFetcher(allScopesFetcher{}),
+	)
+	t.Cleanup(handler.CloseSessions)
+	r := chi.NewRouter()
+	handler.RegisterMiddleware(r)
+	handler.RegisterRoutes(r)
+	server := httptest.NewServer(r)
+	t.Cleanup(server.Close)
+
+	resp := sessionRequest(t, http.MethodPost, server.URL, "ghp_alice", "", initializeMessage)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	initialized := readEvent(t, bufio.NewScanner(resp.Body))
+	_ = resp.Body.Close()
+	assert.Contains(t, initialized.data, `"subscribe":true`)
+	sessionID := resp.Header.Get(sessionIDHeader)
+	resp = sessionRequest(t, http.MethodPost, server.URL, "ghp_alice", sessionID, initializedMessage)
+	require.Equal(t, http.StatusAccepted, resp.StatusCode)
+	_ = resp.Body.Close()
+
+	subscribe := func(uri string) string {
+		resp := sessionRequest(t, http.MethodPost, server.URL, "ghp_alice_refreshed", sessionID,
+			`{"jsonrpc":"2.0","id":2,"method":"resources/subscribe","params":{"uri":"`+uri+`"}}`)
+		defer func() { _ = resp.Body.Close() }()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		return readEvent(t, bufio.NewScanner(resp.Body)).data
+	}
+	assert.NotContains(t, subscribe("issue://owner/repo/42"), `"error"`)
+	assert.Contains(t, subscribe("issue://owner/repo/43"), "subscription limit reached")
+
+	// Polling continues after the subscribe request has completed, with its token
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(pollTokens) >= 2
+	}, 5*time.Second, 10*time.Millisecond)
+	mu.Lock()
+	assert.Equal(t, "Bearer ghp_alice_refreshed", pollTokens[0])
+	etag = `"b"`
+	mu.Unlock()
+
+	req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+	req.Header.Set(headers.AuthorizationHeader, "Bearer ghp_alice")
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set(sessionIDHeader, sessionID)
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	updated := readEvent(t, bufio.NewScanner(resp.Body))
+	assert.Contains(t, updated.data, `"method":"notifications/resources/updated"`)
+	assert.Contains(t, updated.data, `"uri":"issue://owner/repo/42"`)
+}
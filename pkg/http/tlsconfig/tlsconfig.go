@@ -0,0 +1,209 @@
+// Package tlsconfig builds the TLS configuration for the HTTP server, reloading the
+// certificate and client CA bundle when their files change.
+package tlsconfig
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// DefaultReloadInterval is how often certificate files are checked for changes.
+const DefaultReloadInterval = 30 * time.Second
+
+// Config describes the server certificate and optional client certificate verification.
+type Config struct {
+	// CertFile and KeyFile are the PEM-encoded server certificate chain and private key.
+	CertFile string
+	KeyFile  string
+
+	// ClientCAFile is a PEM bundle of CAs used to verify client certificates (mutual TLS).
+	// Certificates signed by other CAs are rejected during the handshake, but connections
+	// without one are accepted, so that health probes can connect; routes that need a
+	// certificate require it with middleware.RequireClientCert.
+	ClientCAFile string
+
+	// ReloadInterval is how often the files are checked for changes. Defaults to
+	// DefaultReloadInterval.
+	ReloadInterval time.Duration
+
+	// Logger reports reloads and reload failures.
+	Logger *slog.Logger
+}
+
+// Validate reports whether the configuration is complete.
+func (c Config) Validate() error {
+	if c.CertFile == "" && c.KeyFile == "" {
+		if c.ClientCAFile != "" {
+			return errors.New("a client CA bundle requires a TLS certificate and key")
+		}
+		return nil
+	}
+	if c.CertFile == "" || c.KeyFile == "" {
+		return errors.New("both a TLS certificate and key are required")
+	}
+	return nil
+}
+
+// Enabled reports whether TLS is configured.
+func (c Config) Enabled() bool {
+	return c.CertFile != "" && c.KeyFile != ""
+}
+
+// Reloader holds the current certificate and client CA pool, replacing them when the
+// underlying files change. If a changed file fails to load, the previous values are kept.
+type Reloader struct {
+	cfg Config
+
+	mu       sync.RWMutex
+	cert     *tls.Certificate
+	clientCA *x509.CertPool
+	versions map[string]fileVersion
+}
+
+type fileVersion struct {
+	modTime time.Time
+	size    int64
+}
+
+// NewReloader loads the certificate and client CA bundle described by cfg.
+func NewReloader(cfg Config) (*Reloader, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	if !cfg.Enabled() {
+		return nil, errors.New("no TLS certificate configured")
+	}
+	if cfg.ReloadInterval <= 0 {
+		cfg.ReloadInterval = DefaultReloadInterval
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = slog.Default()
+	}
+
+	r := &Reloader{cfg: cfg}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// TLSConfig returns a server TLS configuration that always uses the most recently loaded
+// certificate and client CA bundle.
+func (r *Reloader) TLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			r.mu.RLock()
+			defer r.mu.RUnlock()
+
+			cfg := &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				Certificates: []tls.Certificate{*r.cert},
+				NextProtos:   []string{"h2", "http/1.1"},
+			}
+			if r.clientCA != nil {
+				cfg.ClientCAs = r.clientCA
+				cfg.ClientAuth = tls.VerifyClientCertIfGiven
+			}
+			return cfg, nil
+		},
+	}
+}
+
+// Reload loads the certificate and client CA bundle from disk unconditionally.
+func (r *Reloader) Reload() error {
+	versions, err := r.statFiles()
+	if err != nil {
+		return err
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.cfg.CertFile, r.cfg.KeyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load TLS certificate: %w", err)
+	}
+
+	var clientCA *x509.CertPool
+	if r.cfg.ClientCAFile != "" {
+		pem, err := os.ReadFile(r.cfg.ClientCAFile)
+		if err != nil {
+			return fmt.Errorf("failed to read client CA bundle: %w", err)
+		}
+		clientCA = x509.NewCertPool()
+		if !clientCA.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no certificates found in client CA bundle %s", r.cfg.ClientCAFile)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cert = &cert
+	r.clientCA = clientCA
+	r.versions = versions
+	return nil
+}
+
+// Watch reloads the files whenever they change until ctx is done.
+func (r *Reloader) Watch(ctx context.Context) {
+	ticker := time.NewTicker(r.cfg.ReloadInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.reloadIfChanged()
+		}
+	}
+}
+
+// reloadIfChanged reloads the files if any of them changed since the last load, and
+// reports whether a reload succeeded.
+func (r *Reloader) reloadIfChanged() bool {
+	versions, err := r.statFiles()
+	if err != nil {
+		r.cfg.Logger.Warn("failed to check TLS files for changes", "error", err)
+		return false
+	}
+
+	r.mu.RLock()
+	changed := false
+	for name, version := range versions {
+		if r.versions[name] != version {
+			changed = true
+			break
+		}
+	}
+	r.mu.RUnlock()
+	if !changed {
+		return false
+	}
+
+	if err := r.Reload(); err != nil {
+		r.cfg.Logger.Error("failed to reload TLS certificate, keeping the previous one", "error", err)
+		return false
+	}
+	r.cfg.Logger.Info("reloaded TLS certificate", "cert", r.cfg.CertFile, "client_ca", r.cfg.ClientCAFile)
+	return true
+}
+
+func (r *Reloader) statFiles() (map[string]fileVersion, error) {
+	versions := make(map[string]fileVersion, 3)
+	for _, name := range []string{r.cfg.CertFile, r.cfg.KeyFile, r.cfg.ClientCAFile} {
+		if name == "" {
+			continue
+		}
+		info, err := os.Stat(name)
+		if err != nil {
+			return nil, err
+		}
+		versions[name] = fileVersion{modTime: info.ModTime(), size: info.Size()}
+	}
+	return versions, nil
+}
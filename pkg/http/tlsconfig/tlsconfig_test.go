@@ -0,0 +1,242 @@
+package tlsconfig
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testCert struct {
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+	certPEM []byte
+	keyPEM  []byte
+}
+
+// newTestCert issues a certificate for commonName, signed by parent (self-signed if nil).
+func newTestCert(t *testing.T, commonName string, parent *testCert, isCA bool) *testCert {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  isCA,
+		DNSNames:              []string{"localhost"},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+	}
+
+	signer, signerKey := template, key
+	if parent != nil {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	return &testCert{
+		cert:    cert,
+		key:     key,
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		keyPEM:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+	}
+}
+
+func (c *testCert) tlsCertificate(t *testing.T) tls.Certificate {
+	t.Helper()
+	cert, err := tls.X509KeyPair(c.certPEM, c.keyPEM)
+	require.NoError(t, err)
+	return cert
+}
+
+func writeFile(t *testing.T, path string, data []byte, modTime time.Time) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, data, 0600))
+	require.NoError(t, os.Chtimes(path, modTime, modTime))
+}
+
+// serve starts an HTTPS server on a random port and returns its address.
+func serve(t *testing.T, tlsConfig *tls.Config, handler http.Handler) string {
+	t.Helper()
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", tlsConfig)
+	require.NoError(t, err)
+	server := &http.Server{Handler: handler, ReadHeaderTimeout: time.Second}
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(func() { _ = server.Close() })
+	return "https://" + listener.Addr().String()
+}
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name        string
+		cfg         Config
+		expectedErr string
+	}{
+		{name: "TLS disabled", cfg: Config{}},
+		{name: "certificate and key", cfg: Config{CertFile: "c", KeyFile: "k"}},
+		{name: "mutual TLS", cfg: Config{CertFile: "c", KeyFile: "k", ClientCAFile: "ca"}},
+		{name: "missing key", cfg: Config{CertFile: "c"}, expectedErr: "both a TLS certificate and key are required"},
+		{name: "missing certificate", cfg: Config{KeyFile: "k"}, expectedErr: "both a TLS certificate and key are required"},
+		{name: "client CA without certificate", cfg: Config{ClientCAFile: "ca"}, expectedErr: "requires a TLS certificate"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.cfg.Validate()
+			if tc.expectedErr != "" {
+				require.ErrorContains(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestReloaderReloadsChangedCertificate(t *testing.T) {
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "server.crt")
+	keyFile := filepath.Join(dir, "server.key")
+
+	first := newTestCert(t, "first", nil, false)
+	modTime := time.Now().Add(-time.Minute)
+	writeFile(t, certFile, first.certPEM, modTime)
+	writeFile(t, keyFile, first.keyPEM, modTime)
+
+	reloader, err := NewReloader(Config{CertFile: certFile, KeyFile: keyFile})
+	require.NoError(t, err)
+	assert.False(t, reloader.reloadIfChanged(), "unchanged files should not be reloaded")
+
+	url := serve(t, reloader.TLSConfig(), http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	servedCommonName := func() string {
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}} //nolint:gosec // inspecting the served certificate
+		defer client.CloseIdleConnections()
+		resp, err := client.Get(url)
+		require.NoError(t, err)
+		defer func() { _ = resp.Body.Close() }()
+		return resp.TLS.PeerCertificates[0].Subject.CommonName
+	}
+	assert.Equal(t, "first", servedCommonName())
+
+	// A broken certificate is rejected and the previous one keeps being served
+	writeFile(t, certFile, []byte("not a certificate"), modTime.Add(time.Second))
+	assert.False(t, reloader.reloadIfChanged())
+	assert.Equal(t, "first", servedCommonName())
+
+	second := newTestCert(t, "second", nil, false)
+	writeFile(t, certFile, second.certPEM, modTime.Add(2*time.Second))
+	writeFile(t, keyFile, second.keyPEM, modTime.Add(2*time.Second))
+	assert.True(t, reloader.reloadIfChanged())
+	assert.Equal(t, "second", servedCommonName())
+}
+
+func TestReloaderMutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCert(t, "test-ca", nil, true)
+	server := newTestCert(t, "server", ca, false)
+	client := newTestCert(t, "trusted-client", ca, false)
+	untrusted := newTestCert(t, "untrusted-client", nil, false)
+
+	certFile := filepath.Join(dir, "server.crt")
+	keyFile := filepath.Join(dir, "server.key")
+	caFile := filepath.Join(dir, "ca.crt")
+	writeFile(t, certFile, server.certPEM, time.Now())
+	writeFile(t, keyFile, server.keyPEM, time.Now())
+	writeFile(t, caFile, ca.certPEM, time.Now())
+
+	reloader, err := NewReloader(Config{CertFile: certFile, KeyFile: keyFile, ClientCAFile: caFile})
+	require.NoError(t, err)
+
+	url := serve(t, reloader.TLSConfig(), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(r.TLS.VerifiedChains) == 0 {
+			_, _ = w.Write([]byte("no certificate"))
+			return
+		}
+		_, _ = w.Write([]byte(r.TLS.VerifiedChains[0][0].Subject.CommonName))
+	}))
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	get := func(certs ...tls.Certificate) (*http.Response, error) {
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{
+			RootCAs:      roots,
+			Certificates: certs,
+			MinVersion:   tls.VersionTLS12,
+		}}}
+		defer client.CloseIdleConnections()
+		return client.Get(url)
+	}
+
+	body := func(certs ...tls.Certificate) string {
+		resp, err := get(certs...)
+		require.NoError(t, err)
+		defer func() { _ = resp.Body.Close() }()
+		data, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return string(data)
+	}
+	assert.Equal(t, "trusted-client", body(client.tlsCertificate(t)))
+
+	// Clients without a certificate, such as health probes, connect; the routes that need
+	// one reject them
+	assert.Equal(t, "no certificate", body())
+
+	// Clients only send a certificate that matches the CAs the server asks for, so present
+	// the untrusted one regardless
+	untrustedCert := untrusted.tlsCertificate(t)
+	untrustedClient := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{
+		RootCAs: roots,
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return &untrustedCert, nil
+		},
+		MinVersion: tls.VersionTLS12,
+	}}}
+	defer untrustedClient.CloseIdleConnections()
+	_, err = untrustedClient.Get(url)
+	require.Error(t, err, "clients with a certificate from an unknown CA must be rejected")
+}
+
+func TestNewReloaderErrors(t *testing.T) {
+	dir := t.TempDir()
+	cert := newTestCert(t, "server", nil, false)
+	certFile := filepath.Join(dir, "server.crt")
+	keyFile := filepath.Join(dir, "server.key")
+	emptyCA := filepath.Join(dir, "empty.crt")
+	writeFile(t, certFile, cert.certPEM, time.Now())
+	writeFile(t, keyFile, cert.keyPEM, time.Now())
+	writeFile(t, emptyCA, []byte("no certificates here"), time.Now())
+
+	_, err := NewReloader(Config{})
+	require.ErrorContains(t, err, "no TLS certificate configured")
+
+	_, err = NewReloader(Config{CertFile: filepath.Join(dir, "missing.crt"), KeyFile: keyFile})
+	require.Error(t, err)
+
+	_, err = NewReloader(Config{CertFile: certFile, KeyFile: keyFile, ClientCAFile: emptyCA})
+	require.ErrorContains(t, err, "no certificates found in client CA bundle")
+}
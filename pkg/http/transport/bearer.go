@@ -1,21 +1,67 @@
 package transport
 
 import (
+	"fmt"
+	"io"
 	"net/http"
 	"strings"
 
 	ghcontext "github.com/github/github-mcp-server/pkg/context"
 	headers "github.com/github/github-mcp-server/pkg/http/headers"
+	"github.com/github/github-mcp-server/pkg/tokensource"
 )
 
 type BearerAuthTransport struct {
 	Transport http.RoundTripper
 	Token     string
+	// Source provides the token for each request when set, taking precedence over Token.
+	// When GitHub rejects a token that Source can invalidate, the request is retried once
+	// with a fresh token.
+	Source tokensource.TokenSource
 }
 
 func (t *BearerAuthTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token := t.Token
+	if t.Source != nil {
+		var err error
+		if token, err = t.Source.Token(req.Context()); err != nil {
+			return nil, fmt.Errorf("failed to get GitHub token: %w", err)
+		}
+	}
+
+	resp, err := t.roundTrip(req, token)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+
+	// The token may have been revoked or rotated since it was cached
+	invalidator, ok := t.Source.(tokensource.Invalidator)
+	if !ok {
+		return resp, nil
+	}
+	invalidator.Invalidate(token)
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return resp, nil
+	}
+	fresh, err := t.Source.Token(req.Context())
+	if err != nil || fresh == token {
+		return resp, nil
+	}
+
+	retry := req.Clone(req.Context())
+	if req.GetBody != nil {
+		if retry.Body, err = req.GetBody(); err != nil {
+			return resp, nil
+		}
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+	return t.roundTrip(retry, fresh)
+}
+
+func (t *BearerAuthTransport) roundTrip(req *http.Request, token string) (*http.Response, error) {
 	req = req.Clone(req.Context())
-	req.Header.Set(headers.AuthorizationHeader, "Bearer "+t.Token)
+	req.Header.Set(headers.AuthorizationHeader, "Bearer "+token)
 
 	// Check for GraphQL-Features in context and add header if present
 	if features := ghcontext.GetGraphQLFeatures(req.Context()); len(features) > 0 {
@@ -0,0 +1,175 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/github/github-mcp-server/pkg/http/headers"
+	"github.com/github/github-mcp-server/pkg/tokensource"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rotatingSource hands out ghs_1, ghs_2, ... moving on when the current token is invalidated.
+type rotatingSource struct {
+	mu      sync.Mutex
+	current int
+	limit   int
+}
+
+func (s *rotatingSource) Token(context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return "ghs_" + strconv.Itoa(s.current), nil
+}
+
+func (s *rotatingSource) Invalidate(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if token == "ghs_"+strconv.Itoa(s.current) && s.current < s.limit {
+		s.current++
+	}
+}
+
+type failingSource struct{}
+
+func (failingSource) Token(context.Context) (string, error) {
+	return "", errors.New("token endpoint unavailable")
+}
+
+func TestBearerAuthTransport(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		transport     *BearerAuthTransport
+		expectedAuth  string
+		expectedError string
+	}{
+		{
+			name:         "static token",
+			transport:    &BearerAuthTransport{Token: "ghp_static"},
+			expectedAuth: "Bearer ghp_static",
+		},
+		{
+			name:         "token source takes precedence",
+			transport:    &BearerAuthTransport{Token: "ghp_static", Source: tokensource.Static("ghs_installation")},
+			expectedAuth: "Bearer ghs_installation",
+		},
+		{
+			name:          "token source error",
+			transport:     &BearerAuthTransport{Source: failingSource{}},
+			expectedError: "token endpoint unavailable",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var capturedAuth string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				capturedAuth = r.Header.Get(headers.AuthorizationHeader)
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			tc.transport.Transport = http.DefaultTransport
+			req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, server.URL, nil)
+			require.NoError(t, err)
+
+			resp, err := tc.transport.RoundTrip(req)
+			if tc.expectedError != "" {
+				require.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			assert.Equal(t, tc.expectedAuth, capturedAuth)
+			assert.Empty(t, req.Header.Get(headers.AuthorizationHeader), "the original request is not modified")
+		})
+	}
+}
+
+func TestBearerAuthTransportRetriesRejectedToken(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name             string
+		limit            int
+		body             func() io.Reader
+		expectedStatus   int
+		expectedAttempts []string
+	}{
+		{
+			name:             "fresh token is used",
+			limit:            2,
+			body:             func() io.Reader { return strings.NewReader(`{"query":"{viewer{login}}"}`) },
+			expectedStatus:   http.StatusOK,
+			expectedAttempts: []string{"Bearer ghs_1", "Bearer ghs_2"},
+		},
+		{
+			name:             "no fresh token",
+			limit:            1,
+			body:             func() io.Reader { return nil },
+			expectedStatus:   http.StatusUnauthorized,
+			expectedAttempts: []string{"Bearer ghs_1"},
+		},
+		{
+			name:  "body can't be replayed",
+			limit: 2,
+			body: func() io.Reader {
+				return io.NopCloser(strings.NewReader("body"))
+			},
+			expectedStatus:   http.StatusUnauthorized,
+			expectedAttempts: []string{"Bearer ghs_1"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var mu sync.Mutex
+			var attempts []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				mu.Lock()
+				attempts = append(attempts, r.Header.Get(headers.AuthorizationHeader))
+				mu.Unlock()
+				if r.Header.Get(headers.AuthorizationHeader) != "Bearer ghs_2" {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				_, _ = w.Write(body)
+			}))
+			defer server.Close()
+
+			bearer := &BearerAuthTransport{
+				Transport: http.DefaultTransport,
+				Source:    &rotatingSource{current: 1, limit: tc.limit},
+			}
+			req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, server.URL, tc.body())
+			require.NoError(t, err)
+
+			resp, err := bearer.RoundTrip(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			assert.Equal(t, tc.expectedStatus, resp.StatusCode)
+			assert.Equal(t, tc.expectedAttempts, attempts)
+			if resp.StatusCode == http.StatusOK {
+				body, err := io.ReadAll(resp.Body)
+				require.NoError(t, err)
+				assert.Equal(t, `{"query":"{viewer{login}}"}`, string(body), "the body is sent again")
+			}
+		})
+	}
+}
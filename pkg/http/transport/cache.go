@@ -0,0 +1,189 @@
+package transport
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/github/github-mcp-server/pkg/http/headers"
+)
+
+// CacheHeader is set on responses served from the cache after GitHub confirmed that they
+// were still current.
+const CacheHeader = "X-From-Cache"
+
+// DefaultMaxCacheEntryBytes is the size of the largest response body cached by default.
+const DefaultMaxCacheEntryBytes = 1 << 20
+
+// CachingTransport is an http.RoundTripper that caches GitHub responses carrying an ETag
+// or Last-Modified validator, and revalidates them with conditional requests. GitHub does
+// not count 304 Not Modified responses against the primary rate limit, so repeated reads
+// of unchanged data are free.
+//
+// Every cached response is revalidated, so responses are as current as without the cache.
+// Entries are keyed by the Authorization header among others, so that a response is only
+// ever served to the token that fetched it. It must therefore sit beneath authentication.
+// Only GET requests are cached.
+type CachingTransport struct {
+	// Transport is the underlying HTTP transport. If nil, http.DefaultTransport is used.
+	Transport http.RoundTripper
+	Store     CacheStore
+
+	// MaxEntryBytes is the size of the largest response body cached. Defaults to
+	// DefaultMaxCacheEntryBytes.
+	MaxEntryBytes int64
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *CachingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	transport := t.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	if t.Store == nil || !cacheable(req) {
+		return transport.RoundTrip(req)
+	}
+
+	key := cacheKey(req)
+	cached, ok := t.Store.Get(key)
+	if ok {
+		req = req.Clone(req.Context())
+		if etag := cached.Header.Get("ETag"); etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		if lastModified := cached.Header.Get("Last-Modified"); lastModified != "" {
+			req.Header.Set("If-Modified-Since", lastModified)
+		}
+	}
+
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+	if ok && resp.StatusCode == http.StatusNotModified {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+		return cached.response(req, resp), nil
+	}
+	if resp.StatusCode != http.StatusOK || !storable(resp) {
+		if ok && resp.StatusCode == http.StatusOK {
+			t.Store.Delete(key)
+		}
+		return resp, nil
+	}
+
+	maxBytes := t.MaxEntryBytes
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxCacheEntryBytes
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBytes+1))
+	if err != nil {
+		_ = resp.Body.Close()
+		return nil, err
+	}
+	if int64(len(body)) > maxBytes {
+		// Too large to cache: hand back what was read followed by the rest
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+		if ok {
+			t.Store.Delete(key)
+		}
+		return resp, nil
+	}
+	_ = resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	t.Store.Set(key, &CachedResponse{
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header.Clone(),
+		Body:       body,
+	})
+	return resp, nil
+}
+
+// cacheable reports whether a request may be answered from the cache. Requests that are
+// already conditional or ask for part of a resource are left alone.
+func cacheable(req *http.Request) bool {
+	return req.Method == http.MethodGet &&
+		req.Header.Get("Range") == "" &&
+		req.Header.Get("If-None-Match") == "" &&
+		req.Header.Get("If-Modified-Since") == "" &&
+		!strings.Contains(req.Header.Get("Cache-Control"), "no-store")
+}
+
+// storable reports whether a response can be cached and revalidated later.
+func storable(resp *http.Response) bool {
+	if resp.Header.Get("ETag") == "" && resp.Header.Get("Last-Modified") == "" {
+		return false
+	}
+	return !strings.Contains(resp.Header.Get("Cache-Control"), "no-store") && resp.Header.Get("Vary") != "*"
+}
+
+// cacheKey identifies a response by the request URL and the request headers GitHub varies
+// responses on. The key is hashed, so tokens are not kept in the cache.
+func cacheKey(req *http.Request) string {
+	h := sha256.New()
+	for _, part := range []string{
+		req.URL.String(),
+		req.Header.Get(headers.AuthorizationHeader),
+		req.Header.Get("Accept"),
+		req.Header.Get("X-GitHub-Api-Version"),
+	} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// CachedResponse is a response kept by a CacheStore.
+type CachedResponse struct {
+	StatusCode int         `json:"status"`
+	Header     http.Header `json:"header"`
+	Body       []byte      `json:"body"`
+}
+
+// size approximates the memory used by the entry.
+func (c *CachedResponse) size() int64 {
+	n := int64(len(c.Body))
+	for name, values := range c.Header {
+		n += int64(len(name))
+		for _, value := range values {
+			n += int64(len(value))
+		}
+	}
+	return n
+}
+
+// response rebuilds the cached response for req, updated with the headers of the 304
+// response that confirmed it, such as the current rate limit.
+func (c *CachedResponse) response(req *http.Request, notModified *http.Response) *http.Response {
+	header := c.Header.Clone()
+	for name, values := range notModified.Header {
+		switch name {
+		case "Content-Length", "Content-Encoding", "Transfer-Encoding":
+			continue
+		}
+		header[name] = values
+	}
+	header.Set("Content-Length", strconv.Itoa(len(c.Body)))
+	header.Set(CacheHeader, "1")
+
+	return &http.Response{
+		Status:        strconv.Itoa(c.StatusCode) + " " + http.StatusText(c.StatusCode),
+		StatusCode:    c.StatusCode,
+		Proto:         notModified.Proto,
+		ProtoMajor:    notModified.ProtoMajor,
+		ProtoMinor:    notModified.ProtoMinor,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(c.Body)),
+		ContentLength: int64(len(c.Body)),
+		Request:       req,
+		TLS:           notModified.TLS,
+	}
+}
@@ -0,0 +1,264 @@
+package transport
+
+import (
+	"container/list"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CacheStore keeps responses for CachingTransport. Implementations must be safe for
+// concurrent use.
+type CacheStore interface {
+	Get(key string) (*CachedResponse, bool)
+	Set(key string, resp *CachedResponse)
+	Delete(key string)
+}
+
+// NewCacheStore returns a store bounded to maxBytes: on disk under dir if it is set, in
+// memory otherwise. It returns nil when maxBytes is not positive, disabling the cache.
+func NewCacheStore(dir string, maxBytes int64) (CacheStore, error) {
+	switch {
+	case maxBytes <= 0:
+		return nil, nil
+	case dir != "":
+		return NewDiskCacheStore(dir, maxBytes)
+	default:
+		return NewMemoryCacheStore(maxBytes), nil
+	}
+}
+
+// MemoryCacheStore keeps responses in memory, evicting the least recently used once they
+// exceed its size.
+type MemoryCacheStore struct {
+	maxBytes int64
+
+	mu      sync.Mutex
+	size    int64
+	lru     *list.List // of *memoryCacheEntry, most recently used first
+	entries map[string]*list.Element
+}
+
+type memoryCacheEntry struct {
+	key  string
+	resp *CachedResponse
+}
+
+// NewMemoryCacheStore creates a store holding up to maxBytes of responses.
+func NewMemoryCacheStore(maxBytes int64) *MemoryCacheStore {
+	return &MemoryCacheStore{
+		maxBytes: maxBytes,
+		lru:      list.New(),
+		entries:  make(map[string]*list.Element),
+	}
+}
+
+// Get implements CacheStore.
+func (s *MemoryCacheStore) Get(key string) (*CachedResponse, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	elem, ok := s.entries[key]
+	if !ok {
+		return nil, false
+	}
+	s.lru.MoveToFront(elem)
+	return elem.Value.(*memoryCacheEntry).resp, true
+}
+
+// Set implements CacheStore.
+func (s *MemoryCacheStore) Set(key string, resp *CachedResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.remove(key)
+	if resp.size() > s.maxBytes {
+		return
+	}
+	s.entries[key] = s.lru.PushFront(&memoryCacheEntry{key: key, resp: resp})
+	s.size += resp.size()
+	for s.size > s.maxBytes {
+		s.remove(s.lru.Back().Value.(*memoryCacheEntry).key)
+	}
+}
+
+// Delete implements CacheStore.
+func (s *MemoryCacheStore) Delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.remove(key)
+}
+
+// remove must be called with s.mu held.
+func (s *MemoryCacheStore) remove(key string) {
+	elem, ok := s.entries[key]
+	if !ok {
+		return
+	}
+	s.lru.Remove(elem)
+	delete(s.entries, key)
+	s.size -= elem.Value.(*memoryCacheEntry).resp.size()
+}
+
+// cacheTempPrefix names the files entries are written to before they are complete.
+const cacheTempPrefix = ".tmp-"
+
+// DiskCacheStore keeps responses in files under a directory, so that they survive restarts.
+// Once the files exceed its size, the least recently used are removed. The directory and
+// files are only accessible to the owner, as they hold private repository data.
+type DiskCacheStore struct {
+	dir      string
+	maxBytes int64
+
+	mu   sync.Mutex
+	size int64
+}
+
+// NewDiskCacheStore creates a store holding up to maxBytes of responses under dir, which
+// is created if it doesn't exist.
+func NewDiskCacheStore(dir string, maxBytes int64) (*DiskCacheStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	s := &DiskCacheStore{dir: dir, maxBytes: maxBytes}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.prune(); err != nil {
+		return nil, fmt.Errorf("failed to read cache directory: %w", err)
+	}
+	return s, nil
+}
+
+// Get implements CacheStore.
+func (s *DiskCacheStore) Get(key string) (*CachedResponse, bool) {
+	path := s.path(key)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	var resp CachedResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		s.Delete(key)
+		return nil, false
+	}
+	// The modification time records use for eviction
+	now := time.Now()
+	_ = os.Chtimes(path, now, now)
+	return &resp, true
+}
+
+// Set implements CacheStore.
+func (s *DiskCacheStore) Set(key string, resp *CachedResponse) {
+	data, err := json.Marshal(resp)
+	if err != nil || int64(len(data)) > s.maxBytes {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Write to a temporary file and rename, so readers never see a partial entry
+	tmp, err := os.CreateTemp(s.dir, cacheTempPrefix+"*")
+	if err != nil {
+		return
+	}
+	_, writeErr := tmp.Write(data)
+	closeErr := tmp.Close()
+	if writeErr != nil || closeErr != nil {
+		_ = os.Remove(tmp.Name())
+		return
+	}
+	path := s.path(key)
+	if info, err := os.Stat(path); err == nil {
+		s.size -= info.Size()
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		_ = os.Remove(tmp.Name())
+		return
+	}
+	s.size += int64(len(data))
+	if s.size > s.maxBytes {
+		_ = s.prune()
+	}
+}
+
+// Delete implements CacheStore.
+func (s *DiskCacheStore) Delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	path := s.path(key)
+	if info, err := os.Stat(path); err == nil && os.Remove(path) == nil {
+		s.size -= info.Size()
+	}
+}
+
+func (s *DiskCacheStore) path(key string) string {
+	return filepath.Join(s.dir, key+".json")
+}
+
+// prune recomputes the size of the store and removes the least recently used entries until
+// it is within bounds, along with temporary files left behind by a crash. It must be called
+// with s.mu held.
+func (s *DiskCacheStore) prune() error {
+	type entry struct {
+		path    string
+		size    int64
+		modTime time.Time
+	}
+	var entries []entry
+	var total int64
+	err := filepath.WalkDir(s.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != s.dir {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if strings.HasPrefix(d.Name(), cacheTempPrefix) {
+			if time.Since(info.ModTime()) > time.Hour {
+				_ = os.Remove(path)
+			}
+			return nil
+		}
+		if filepath.Ext(path) != ".json" {
+			return nil
+		}
+		entries = append(entries, entry{path: path, size: info.Size(), modTime: info.ModTime()})
+		total += info.Size()
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Evict down to 90% of the bound, so that pruning is not needed on every write
+	if total > s.maxBytes {
+		slices.SortFunc(entries, func(a, b entry) int { return a.modTime.Compare(b.modTime) })
+		target := s.maxBytes / 10 * 9
+		for _, e := range entries {
+			if total <= target {
+				break
+			}
+			if os.Remove(e.path) == nil {
+				total -= e.size
+			}
+		}
+	}
+	s.size = total
+	return nil
+}
@@ -0,0 +1,230 @@
+package transport
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// etagServer serves body with an ETag, answering matching conditional requests with 304.
+type etagServer struct {
+	body        atomic.Value
+	requests    atomic.Int32
+	notModified atomic.Int32
+}
+
+func newETagServer(t *testing.T, body string) (*etagServer, *httptest.Server) {
+	t.Helper()
+	s := &etagServer{}
+	s.body.Store(body)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := s.requests.Add(1)
+		body := s.body.Load().(string)
+		etag := `"` + body + `"`
+		w.Header().Set("ETag", etag)
+		w.Header().Set("X-RateLimit-Used", strconv.Itoa(int(n)))
+		if r.Header.Get("If-None-Match") == etag {
+			s.notModified.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, body)
+	}))
+	t.Cleanup(server.Close)
+	return s, server
+}
+
+func get(t *testing.T, rt http.RoundTripper, url, token string) (*http.Response, string) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, url, nil)
+	req.RequestURI = ""
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := rt.RoundTrip(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp, string(body)
+}
+
+func TestCachingTransportRevalidates(t *testing.T) {
+	t.Parallel()
+	upstream, server := newETagServer(t, "v1")
+	rt := &CachingTransport{Store: NewMemoryCacheStore(1 << 20)}
+
+	resp, body := get(t, rt, server.URL+"/repos/o/r", "token-a")
+	assert.Equal(t, "v1", body)
+	assert.Empty(t, resp.Header.Get(CacheHeader))
+
+	resp, body = get(t, rt, server.URL+"/repos/o/r", "token-a")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "v1", body)
+	assert.Equal(t, "1", resp.Header.Get(CacheHeader))
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"), "cached headers are kept")
+	assert.Equal(t, "2", resp.Header.Get("X-RateLimit-Used"), "headers of the 304 response are current")
+	assert.Equal(t, int32(1), upstream.notModified.Load())
+
+	// A change is picked up on the next request
+	upstream.body.Store("v2")
+	resp, body = get(t, rt, server.URL+"/repos/o/r", "token-a")
+	assert.Equal(t, "v2", body)
+	assert.Empty(t, resp.Header.Get(CacheHeader))
+	assert.Equal(t, int32(3), upstream.requests.Load())
+}
+
+func TestCachingTransportScopesEntriesPerToken(t *testing.T) {
+	t.Parallel()
+	upstream, server := newETagServer(t, "v1")
+	rt := &CachingTransport{Store: NewMemoryCacheStore(1 << 20)}
+
+	get(t, rt, server.URL+"/repos/o/r", "token-a")
+	resp, _ := get(t, rt, server.URL+"/repos/o/r", "token-b")
+	assert.Empty(t, resp.Header.Get(CacheHeader), "another token's response is not served")
+	assert.Equal(t, int32(0), upstream.notModified.Load())
+}
+
+func TestCachingTransportSkipsUncacheableRequests(t *testing.T) {
+	t.Parallel()
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		assert.Empty(t, r.Header.Get("If-None-Match"))
+		if r.URL.Path != "/no-validator" {
+			w.Header().Set("ETag", `"x"`)
+		}
+		if r.URL.Path == "/no-store" {
+			w.Header().Set("Cache-Control", "no-store")
+		}
+		_, _ = io.WriteString(w, strings.Repeat("x", 64))
+	}))
+	t.Cleanup(server.Close)
+
+	store := NewMemoryCacheStore(1 << 20)
+	rt := &CachingTransport{Store: store, MaxEntryBytes: 32}
+
+	for _, path := range []string{"/no-validator", "/no-store", "/too-large"} {
+		for range 2 {
+			_, body := get(t, rt, server.URL+path, "token")
+			assert.Len(t, body, 64, "bodies are passed through whole")
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodPost, server.URL+"/graphql", strings.NewReader("{}"))
+	req.RequestURI = ""
+	resp, err := rt.RoundTrip(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, int32(7), requests.Load())
+	assert.Empty(t, store.entries)
+}
+
+func TestMemoryCacheStoreEvictsLeastRecentlyUsed(t *testing.T) {
+	t.Parallel()
+	entry := func(body string) *CachedResponse { return &CachedResponse{StatusCode: 200, Body: []byte(body)} }
+
+	store := NewMemoryCacheStore(10)
+	store.Set("a", entry("aaaa"))
+	store.Set("b", entry("bbbb"))
+	_, _ = store.Get("a")
+	store.Set("c", entry("cccc"))
+
+	_, ok := store.Get("b")
+	assert.False(t, ok, "the least recently used entry is evicted")
+	_, ok = store.Get("a")
+	assert.True(t, ok)
+	_, ok = store.Get("c")
+	assert.True(t, ok)
+
+	store.Set("big", entry(strings.Repeat("x", 11)))
+	_, ok = store.Get("big")
+	assert.False(t, ok, "entries larger than the store are not kept")
+
+	store.Delete("a")
+	_, ok = store.Get("a")
+	assert.False(t, ok)
+	assert.Equal(t, int64(4), store.size)
+}
+
+func TestDiskCacheStore(t *testing.T) {
+	t.Parallel()
+	dir := filepath.Join(t.TempDir(), "cache")
+
+	store, err := NewDiskCacheStore(dir, 1<<20)
+	require.NoError(t, err)
+	store.Set("k1", &CachedResponse{StatusCode: 200, Header: http.Header{"Etag": {`"1"`}}, Body: []byte("one")})
+
+	info, err := os.Stat(dir)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o700), info.Mode().Perm())
+	info, err = os.Stat(filepath.Join(dir, "k1.json"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	// Entries survive reopening the store
+	reopened, err := NewDiskCacheStore(dir, 1<<20)
+	require.NoError(t, err)
+	got, ok := reopened.Get("k1")
+	require.True(t, ok)
+	assert.Equal(t, []byte("one"), got.Body)
+	assert.Equal(t, `"1"`, got.Header.Get("ETag"))
+
+	reopened.Delete("k1")
+	_, ok = reopened.Get("k1")
+	assert.False(t, ok)
+	assert.Equal(t, int64(0), reopened.size)
+}
+
+func TestDiskCacheStoreEvicts(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	unrelated := filepath.Join(dir, "notes.txt")
+	require.NoError(t, os.WriteFile(unrelated, []byte("keep me"), 0o600))
+
+	entry := &CachedResponse{StatusCode: 200, Body: []byte(strings.Repeat("x", 100))}
+	size := int64(len(`{"status":200,"header":null,"body":""}`) + 136)
+	store, err := NewDiskCacheStore(dir, 3*size)
+	require.NoError(t, err)
+	for _, key := range []string{"a", "b", "c", "d"} {
+		store.Set(key, entry)
+	}
+
+	var kept int
+	for _, key := range []string{"a", "b", "c", "d"} {
+		if _, err := os.Stat(filepath.Join(dir, key+".json")); err == nil {
+			kept++
+		}
+	}
+	assert.Less(t, kept, 4)
+	assert.LessOrEqual(t, store.size, 3*size)
+	assert.FileExists(t, unrelated, "files that are not cache entries are left alone")
+}
+
+func TestNewCacheStore(t *testing.T) {
+	t.Parallel()
+
+	store, err := NewCacheStore("", 0)
+	require.NoError(t, err)
+	assert.Nil(t, store)
+
+	store, err = NewCacheStore("", 1<<20)
+	require.NoError(t, err)
+	assert.IsType(t, &MemoryCacheStore{}, store)
+
+	store, err = NewCacheStore(t.TempDir(), 1<<20)
+	require.NoError(t, err)
+	assert.IsType(t, &DiskCacheStore{}, store)
+}
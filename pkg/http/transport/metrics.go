@@ -0,0 +1,42 @@
+package transport
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/github/github-mcp-server/pkg/metrics"
+)
+
+// MetricsTransport is an http.RoundTripper that records GitHub API request counts by API
+// and status, and the rate limit reported in response headers.
+type MetricsTransport struct {
+	// Transport is the underlying HTTP transport. If nil, http.DefaultTransport is used.
+	Transport http.RoundTripper
+	Metrics   *metrics.Metrics
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *MetricsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	transport := t.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	resp, err := transport.RoundTrip(req)
+	if t.Metrics != nil {
+		t.Metrics.ObserveGitHubResponse(requestAPI(req), resp)
+	}
+	return resp, err
+}
+
+// requestAPI classifies a request as REST, GraphQL or raw content based on its URL.
+func requestAPI(req *http.Request) string {
+	switch {
+	case strings.HasSuffix(req.URL.Path, "/graphql"):
+		return metrics.APIGraphQL
+	case strings.HasPrefix(req.URL.Host, "raw.") || strings.HasPrefix(req.URL.Path, "/raw/"):
+		return metrics.APIRaw
+	default:
+		return metrics.APIREST
+	}
+}
@@ -0,0 +1,59 @@
+package transport
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/github/github-mcp-server/pkg/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+func TestMetricsTransport(t *testing.T) {
+	m := metrics.New()
+	transport := &MetricsTransport{
+		Metrics: m,
+		Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			if req.URL.Host == "unreachable.example.com" {
+				return nil, errors.New("connection refused")
+			}
+			header := http.Header{}
+			header.Set("X-RateLimit-Resource", "graphql")
+			header.Set("X-RateLimit-Remaining", "4999")
+			header.Set("X-RateLimit-Limit", "5000")
+			return &http.Response{StatusCode: http.StatusOK, Header: header, Request: req}, nil
+		}),
+	}
+
+	for _, url := range []string{
+		"https://api.github.com/repos/octo-org/octo-repo",
+		"https://api.github.com/graphql",
+		"https://ghes.example.com/api/graphql",
+		"https://raw.githubusercontent.com/octo-org/octo-repo/HEAD/README.md",
+		"https://ghes.example.com/raw/octo-org/octo-repo/HEAD/README.md",
+	} {
+		resp, err := transport.RoundTrip(httptest.NewRequest(http.MethodGet, url, nil))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+	_, err := transport.RoundTrip(httptest.NewRequest(http.MethodGet, "https://unreachable.example.com/user", nil))
+	require.Error(t, err)
+
+	out := httptest.NewRecorder()
+	m.Handler().ServeHTTP(out, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	for _, line := range []string{
+		`github_mcp_github_requests_total{api="rest",status="200"} 1`,
+		`github_mcp_github_requests_total{api="graphql",status="200"} 2`,
+		`github_mcp_github_requests_total{api="raw",status="200"} 2`,
+		`github_mcp_github_requests_total{api="rest",status="error"} 1`,
+		`github_mcp_github_rate_limit_remaining{resource="graphql"} 4999`,
+	} {
+		assert.Contains(t, out.Body.String(), line+"\n")
+	}
+}
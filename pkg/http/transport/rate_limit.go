@@ -0,0 +1,28 @@
+package transport
+
+import (
+	"net/http"
+
+	ghcontext "github.com/github/github-mcp-server/pkg/context"
+)
+
+// RateLimitTransport is an http.RoundTripper that records the rate limit reported by each
+// GitHub API response in the RateLimits of the request context, if there is one.
+type RateLimitTransport struct {
+	// Transport is the underlying HTTP transport. If nil, http.DefaultTransport is used.
+	Transport http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *RateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	transport := t.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	resp, err := transport.RoundTrip(req)
+	if limits := ghcontext.GetRateLimits(req.Context()); limits != nil && resp != nil {
+		limits.Observe(resp.Header)
+	}
+	return resp, err
+}
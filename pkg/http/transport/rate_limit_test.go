@@ -0,0 +1,48 @@
+package transport
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	ghcontext "github.com/github/github-mcp-server/pkg/context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimitTransport(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/graphql" {
+			w.Header().Set("X-RateLimit-Resource", "graphql")
+			w.Header().Set("X-RateLimit-Limit", "5000")
+			w.Header().Set("X-RateLimit-Remaining", "4900")
+			w.Header().Set("X-RateLimit-Used", "100")
+			w.Header().Set("X-RateLimit-Reset", "1792324800")
+		}
+	}))
+	t.Cleanup(server.Close)
+	rt := &RateLimitTransport{}
+
+	limits := &ghcontext.RateLimits{}
+	ctx := ghcontext.WithRateLimits(context.Background(), limits)
+	for _, path := range []string{"/graphql", "/no-headers"} {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, server.URL+path, nil)
+		require.NoError(t, err)
+		resp, err := rt.RoundTrip(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+	assert.Equal(t, map[string]ghcontext.RateLimit{
+		"graphql": {Limit: 5000, Remaining: 4900, Used: 100, Reset: time.Unix(1792324800, 0).UTC()},
+	}, limits.Resources())
+
+	// Requests without a collector in their context are passed through
+	req, err := http.NewRequest(http.MethodPost, server.URL+"/graphql", nil)
+	require.NoError(t, err)
+	resp, err := rt.RoundTrip(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+}
@@ -0,0 +1,207 @@
+package transport
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	ghcontext "github.com/github/github-mcp-server/pkg/context"
+)
+
+const (
+	// DefaultMaxRetries is the number of times a rate limited request is retried by default.
+	DefaultMaxRetries = 3
+
+	// DefaultMaxRetryWait is the longest a request waits for rate limits in total by default.
+	DefaultMaxRetryWait = time.Minute
+
+	// defaultRetryBackoff is the first backoff delay for secondary rate limits that do
+	// not say how long to wait. It doubles with every retry.
+	defaultRetryBackoff = 10 * time.Second
+)
+
+// maxRateLimitBodyBytes bounds the error body read to recognize a secondary rate limit.
+const maxRateLimitBodyBytes = 64 << 10
+
+// RetryTransport is an http.RoundTripper that retries GitHub API requests rejected by a
+// primary or secondary rate limit, instead of failing the tool call. It waits as long as
+// GitHub asks with Retry-After or X-RateLimit-Reset, or backs off with jitter when it
+// doesn't say, and tells the RateLimitNotifier in the request context before waiting.
+//
+// Only idempotent requests are retried: GET, HEAD, OPTIONS, PUT and DELETE, and GraphQL
+// queries. POST, PATCH and GraphQL mutations are never retried, as GitHub may have acted
+// on them before limiting them. A request waits at most MaxWait in total across its
+// retries; one whose next wait would exceed that fails with the rate limit response.
+type RetryTransport struct {
+	// Transport is the underlying HTTP transport. If nil, http.DefaultTransport is used.
+	Transport http.RoundTripper
+
+	// MaxRetries defaults to DefaultMaxRetries.
+	MaxRetries int
+
+	// MaxWait defaults to DefaultMaxRetryWait.
+	MaxWait time.Duration
+
+	// sleep waits for d unless ctx is done first. Tests replace it to avoid waiting.
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	transport := t.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	maxRetries := t.MaxRetries
+	if maxRetries <= 0 {
+		maxRetries = DefaultMaxRetries
+	}
+	maxWait := t.MaxWait
+	if maxWait <= 0 {
+		maxWait = DefaultMaxRetryWait
+	}
+	sleep := t.sleep
+	if sleep == nil {
+		sleep = sleepContext
+	}
+
+	retryable := idempotent(req)
+	var waited time.Duration
+	for attempt := 1; ; attempt++ {
+		resp, err := transport.RoundTrip(req)
+		if err != nil || !retryable || attempt > maxRetries || !rateLimited(resp) {
+			return resp, err
+		}
+		wait := retryDelay(resp, attempt)
+		if waited+wait > maxWait {
+			return resp, nil
+		}
+		waited += wait
+
+		// The next attempt needs a fresh body; without one the response is final
+		next := req.Clone(req.Context())
+		if req.GetBody != nil {
+			if next.Body, err = req.GetBody(); err != nil {
+				return resp, nil
+			}
+		}
+
+		if notify := ghcontext.GetRateLimitNotifier(req.Context()); notify != nil {
+			notify(req.Context(), wait, attempt)
+		}
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+		if err := sleep(req.Context(), wait); err != nil {
+			return nil, err
+		}
+		req = next
+	}
+}
+
+// rateLimited reports whether GitHub rejected a request because of a rate limit. Secondary
+// rate limits are 403 responses that can only be told apart from others by their message,
+// so the body is read and replaced.
+func rateLimited(resp *http.Response) bool {
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return true
+	case resp.StatusCode != http.StatusForbidden:
+		return false
+	case resp.Header.Get("X-RateLimit-Remaining") == "0", resp.Header.Get("Retry-After") != "":
+		return true
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxRateLimitBodyBytes))
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+	if err != nil {
+		return false
+	}
+	message := strings.ToLower(string(body))
+	return strings.Contains(message, "secondary rate limit") || strings.Contains(message, "abuse detection")
+}
+
+// retryDelay returns how long to wait before retrying a rate limited request, following
+// GitHub's guidance: Retry-After if present, then X-RateLimit-Reset once the primary rate
+// limit is exhausted, and otherwise an exponential backoff.
+func retryDelay(resp *http.Response, attempt int) time.Duration {
+	if retryAfter := resp.Header.Get("Retry-After"); retryAfter != "" {
+		if seconds, err := strconv.Atoi(retryAfter); err == nil && seconds >= 0 {
+			return time.Duration(seconds)*time.Second + jitter(time.Second)
+		}
+		if at, err := http.ParseTime(retryAfter); err == nil {
+			return max(time.Until(at), 0) + jitter(time.Second)
+		}
+	}
+	if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+		if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			// The extra second allows for clock skew
+			return max(time.Until(time.Unix(reset, 0)), 0) + time.Second + jitter(time.Second)
+		}
+	}
+
+	// Spread retries over the upper half of the backoff, so that clients limited at the
+	// same time don't retry in step
+	backoff := defaultRetryBackoff << (attempt - 1)
+	return backoff/2 + jitter(backoff/2)
+}
+
+func jitter(d time.Duration) time.Duration {
+	if d <= 0 {
+		return 0
+	}
+	return rand.N(d)
+}
+
+// idempotent reports whether a request can safely be sent again. GraphQL requests are
+// POSTs, so their body is read to tell queries from mutations.
+func idempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+	case http.MethodPost:
+		return strings.HasSuffix(req.URL.Path, "/graphql") && graphQLQuery(req)
+	default:
+		return false
+	}
+}
+
+// graphQLQuery reports whether a GraphQL request is a query rather than a mutation.
+func graphQLQuery(req *http.Request) bool {
+	if req.GetBody == nil {
+		return false
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return false
+	}
+	defer body.Close()
+
+	var payload struct {
+		Query string `json:"query"`
+	}
+	if err := json.NewDecoder(body).Decode(&payload); err != nil {
+		return false
+	}
+	query := strings.TrimSpace(payload.Query)
+	return strings.HasPrefix(query, "{") || strings.HasPrefix(query, "query")
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,240 @@
+package transport
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	ghcontext "github.com/github/github-mcp-server/pkg/context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// limitedServer rejects the first limited requests with respond, then succeeds.
+func limitedServer(t *testing.T, limited int32, respond func(w http.ResponseWriter)) (*atomic.Int32, *httptest.Server) {
+	t.Helper()
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) <= limited {
+			respond(w)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		_, _ = w.Write(append([]byte("ok "), body...))
+	}))
+	t.Cleanup(server.Close)
+	return &requests, server
+}
+
+func secondaryRateLimit(w http.ResponseWriter) {
+	w.WriteHeader(http.StatusForbidden)
+	_, _ = io.WriteString(w, `{"message":"You have exceeded a secondary rate limit. Please wait a few minutes before you try again."}`)
+}
+
+// recordWaits returns a transport that records its waits instead of sleeping.
+func recordWaits(rt *RetryTransport) *[]time.Duration {
+	var waits []time.Duration
+	rt.sleep = func(_ context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return nil
+	}
+	return &waits
+}
+
+func TestRetryTransportHonorsRateLimitHeaders(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		respond func(w http.ResponseWriter)
+		minWait time.Duration
+		maxWait time.Duration
+	}{
+		{
+			name: "retry after",
+			respond: func(w http.ResponseWriter) {
+				w.Header().Set("Retry-After", "30")
+				w.WriteHeader(http.StatusForbidden)
+			},
+			minWait: 30 * time.Second,
+			maxWait: 31 * time.Second,
+		},
+		{
+			name: "primary rate limit reset",
+			respond: func(w http.ResponseWriter) {
+				w.Header().Set("X-RateLimit-Remaining", "0")
+				w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(20*time.Second).Unix(), 10))
+				w.WriteHeader(http.StatusForbidden)
+			},
+			minWait: 19 * time.Second,
+			maxWait: 23 * time.Second,
+		},
+		{
+			name:    "secondary rate limit without headers",
+			respond: secondaryRateLimit,
+			minWait: defaultRetryBackoff / 2,
+			maxWait: defaultRetryBackoff,
+		},
+		{
+			name:    "too many requests",
+			respond: func(w http.ResponseWriter) { w.WriteHeader(http.StatusTooManyRequests) },
+			minWait: defaultRetryBackoff / 2,
+			maxWait: defaultRetryBackoff,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			requests, server := limitedServer(t, 1, tc.respond)
+			rt := &RetryTransport{}
+			waits := recordWaits(rt)
+
+			resp, body := get(t, rt, server.URL+"/repos/o/r", "token")
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+			assert.Equal(t, "ok ", body)
+			assert.Equal(t, int32(2), requests.Load())
+			require.Len(t, *waits, 1)
+			assert.GreaterOrEqual(t, (*waits)[0], tc.minWait)
+			assert.LessOrEqual(t, (*waits)[0], tc.maxWait)
+		})
+	}
+}
+
+func TestRetryTransportBacksOffAndGivesUp(t *testing.T) {
+	t.Parallel()
+	requests, server := limitedServer(t, 10, secondaryRateLimit)
+	rt := &RetryTransport{MaxRetries: 2}
+	waits := recordWaits(rt)
+
+	resp, body := get(t, rt, server.URL+"/search/code", "token")
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	assert.Contains(t, body, "secondary rate limit", "the last response is returned whole")
+	assert.Equal(t, int32(3), requests.Load())
+	require.Len(t, *waits, 2)
+	assert.GreaterOrEqual(t, (*waits)[1], defaultRetryBackoff, "the backoff doubles")
+}
+
+func TestRetryTransportDoesNotWaitPastMaxWait(t *testing.T) {
+	t.Parallel()
+	requests, server := limitedServer(t, 1, func(w http.ResponseWriter) {
+		w.Header().Set("Retry-After", "3600")
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+	rt := &RetryTransport{MaxWait: time.Minute}
+	waits := recordWaits(rt)
+
+	resp, _ := get(t, rt, server.URL+"/repos/o/r", "token")
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	assert.Equal(t, int32(1), requests.Load())
+	assert.Empty(t, *waits)
+}
+
+func TestRetryTransportBoundsTotalWait(t *testing.T) {
+	t.Parallel()
+	requests, server := limitedServer(t, 10, func(w http.ResponseWriter) {
+		w.Header().Set("Retry-After", "3")
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+	// Each wait is 3-4s with jitter, so two fit and a third doesn't
+	rt := &RetryTransport{MaxWait: 8500 * time.Millisecond}
+	waits := recordWaits(rt)
+
+	resp, _ := get(t, rt, server.URL+"/repos/o/r", "token")
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	assert.Equal(t, int32(3), requests.Load(), "a third wait would exceed the total")
+	require.Len(t, *waits, 2)
+}
+
+func TestRetryTransportOnlyRetriesIdempotentRequests(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		method        string
+		path          string
+		body          string
+		expectRetried bool
+	}{
+		{name: "issue creation", method: http.MethodPost, path: "/repos/o/r/issues", body: `{"title":"x"}`},
+		{name: "issue update", method: http.MethodPatch, path: "/repos/o/r/issues/1", body: `{"state":"closed"}`},
+		{name: "graphql mutation", method: http.MethodPost, path: "/graphql", body: `{"query":"mutation($input:AddCommentInput!){addComment(input:$input){clientMutationId}}"}`},
+		{name: "graphql query", method: http.MethodPost, path: "/graphql", body: `{"query":"query($owner:String!){repository(owner:$owner){id}}"}`, expectRetried: true},
+		{name: "label replacement", method: http.MethodPut, path: "/repos/o/r/issues/1/labels", body: `["bug"]`, expectRetried: true},
+		{name: "deletion", method: http.MethodDelete, path: "/repos/o/r/issues/comments/1", expectRetried: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			requests, server := limitedServer(t, 1, secondaryRateLimit)
+			rt := &RetryTransport{}
+			waits := recordWaits(rt)
+
+			var body io.Reader
+			if tc.body != "" {
+				body = strings.NewReader(tc.body)
+			}
+			req, err := http.NewRequest(tc.method, server.URL+tc.path, body)
+			require.NoError(t, err)
+			resp, err := rt.RoundTrip(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			respBody, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+
+			if tc.expectRetried {
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+				assert.Equal(t, "ok "+tc.body, string(respBody), "the body is sent again")
+				assert.Equal(t, int32(2), requests.Load())
+			} else {
+				assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+				assert.Equal(t, int32(1), requests.Load())
+				assert.Empty(t, *waits)
+			}
+		})
+	}
+}
+
+func TestRetryTransportPassesOtherErrorsThrough(t *testing.T) {
+	t.Parallel()
+	requests, server := limitedServer(t, 1, func(w http.ResponseWriter) {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = io.WriteString(w, `{"message":"Resource not accessible by integration"}`)
+	})
+	rt := &RetryTransport{}
+	recordWaits(rt)
+
+	resp, body := get(t, rt, server.URL+"/repos/o/r/issues", "token")
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	assert.Contains(t, body, "Resource not accessible", "the body read to look for a rate limit is kept")
+	assert.Equal(t, int32(1), requests.Load())
+}
+
+func TestRetryTransportNotifiesAndStopsWhenCanceled(t *testing.T) {
+	t.Parallel()
+	_, server := limitedServer(t, 10, func(w http.ResponseWriter) {
+		w.Header().Set("Retry-After", "5")
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var notified []int
+	ctx = ghcontext.WithRateLimitNotifier(ctx, func(_ context.Context, wait time.Duration, attempt int) {
+		assert.GreaterOrEqual(t, wait, 5*time.Second)
+		notified = append(notified, attempt)
+		cancel()
+	})
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/repos/o/r", nil)
+	require.NoError(t, err)
+	_, err = (&RetryTransport{}).RoundTrip(req)
+	require.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, []int{1}, notified)
+}
@@ -0,0 +1,49 @@
+package transport
+
+import (
+	"net/http"
+
+	"github.com/github/github-mcp-server/pkg/tracing"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// NewTracingTransport returns an http.RoundTripper that records a client span with
+// provider for each GitHub API request made while a span is active in the request
+// context, such as during a traced MCP request, and passes the trace on in the traceparent
+// header. If base is nil, http.DefaultTransport is used.
+func NewTracingTransport(base http.RoundTripper, provider trace.TracerProvider) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return otelhttp.NewTransport(&apiSpanTransport{transport: base},
+		otelhttp.WithTracerProvider(provider),
+		otelhttp.WithPropagators(tracing.Propagator),
+		otelhttp.WithFilter(func(req *http.Request) bool {
+			return trace.SpanContextFromContext(req.Context()).IsValid()
+		}),
+		otelhttp.WithSpanNameFormatter(func(_ string, req *http.Request) string {
+			return req.Method + " " + requestAPI(req)
+		}),
+	)
+}
+
+// apiSpanTransport runs inside the client span started by otelhttp, and adds the GitHub
+// API the request is made to.
+type apiSpanTransport struct {
+	transport http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *apiSpanTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// The query string is left out of the recorded URL as it can contain search terms
+	u := *req.URL
+	u.RawQuery = ""
+	u.User = nil
+	trace.SpanFromContext(req.Context()).SetAttributes(
+		attribute.String("github.api", requestAPI(req)),
+		attribute.String("url.full", u.String()),
+	)
+	return t.transport.RoundTrip(req)
+}
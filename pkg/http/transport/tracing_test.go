@@ -0,0 +1,96 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// spanAttribute returns the value of the attribute with the given key, or an empty value.
+func spanAttribute(span sdktrace.ReadOnlySpan, key attribute.Key) attribute.Value {
+	for _, attr := range span.Attributes() {
+		if attr.Key == key {
+			return attr.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func TestTracingTransport(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	var traceparent string
+	transport := NewTracingTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		traceparent = req.Header.Get("traceparent")
+		switch req.URL.Path {
+		case "/graphql":
+			return &http.Response{StatusCode: http.StatusBadGateway, Header: http.Header{}, Body: http.NoBody, Request: req}, nil
+		case "/unreachable":
+			return nil, errors.New("connection refused")
+		default:
+			return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: http.NoBody, Request: req}, nil
+		}
+	}), provider)
+
+	// Without an active span requests are not traced
+	resp, err := transport.RoundTrip(httptest.NewRequest(http.MethodGet, "https://api.github.com/user", nil))
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Empty(t, traceparent)
+	assert.Empty(t, recorder.Ended())
+
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "tools/call get_me", trace.WithSpanKind(trace.SpanKindServer))
+	for _, url := range []string{
+		"https://api.github.com/user?per_page=100",
+		"https://api.github.com/graphql",
+		"https://api.github.com/unreachable",
+	} {
+		req := httptest.NewRequest(http.MethodPost, url, nil).WithContext(ctx)
+		// Client spans end when the response body is closed
+		if resp, err := transport.RoundTrip(req); err == nil {
+			_ = resp.Body.Close()
+		}
+		assert.Contains(t, traceparent, parent.SpanContext().TraceID().String(), "the request carries the trace")
+		assert.NotContains(t, traceparent, parent.SpanContext().SpanID().String(), "the request carries the client span")
+	}
+	parent.End()
+
+	byURL := map[string]sdktrace.ReadOnlySpan{}
+	for _, span := range recorder.Ended() {
+		if span.SpanKind() == trace.SpanKindClient {
+			byURL[spanAttribute(span, "url.full").AsString()] = span
+		}
+	}
+	require.Len(t, byURL, 3, "query strings are not recorded")
+
+	rest := byURL["https://api.github.com/user"]
+	require.NotNil(t, rest)
+	assert.Equal(t, "POST rest", rest.Name())
+	assert.Equal(t, parent.SpanContext().SpanID(), rest.Parent().SpanID())
+	assert.Equal(t, "rest", spanAttribute(rest, "github.api").AsString())
+	assert.Equal(t, "api.github.com", spanAttribute(rest, "server.address").AsString())
+	assert.Equal(t, int64(200), spanAttribute(rest, "http.response.status_code").AsInt64())
+	assert.NotEqual(t, codes.Error, rest.Status().Code)
+
+	graphql := byURL["https://api.github.com/graphql"]
+	require.NotNil(t, graphql)
+	assert.Equal(t, "POST graphql", graphql.Name())
+	assert.Equal(t, int64(502), spanAttribute(graphql, "http.response.status_code").AsInt64())
+	assert.Equal(t, codes.Error, graphql.Status().Code)
+
+	failed := byURL["https://api.github.com/unreachable"]
+	require.NotNil(t, failed)
+	assert.Equal(t, codes.Error, failed.Status().Code)
+	assert.Contains(t, failed.Status().Description, "connection refused")
+}
@@ -77,6 +77,13 @@ func (b *Builder) SetPrompts(prompts []ServerPrompt) *Builder {
 	return b
 }
 
+// AddPrompts appends prompts to those already set on the builder.
+// Returns self for chaining.
+func (b *Builder) AddPrompts(prompts ...ServerPrompt) *Builder {
+	b.prompts = append(slices.Clip(b.prompts), prompts...)
+	return b
+}
+
 // WithDeprecatedAliases adds deprecated tool name aliases that map to canonical names.
 // Returns self for chaining.
 func (b *Builder) WithDeprecatedAliases(aliases map[string]string) *Builder {
@@ -1,6 +1,13 @@
 package inventory
 
-import "github.com/modelcontextprotocol/go-sdk/mcp"
+import (
+	"context"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// PromptCompletionHandler completes a prompt argument for a completion/complete request.
+type PromptCompletionHandler func(ctx context.Context, req *mcp.CompleteRequest) (*mcp.CompleteResult, error)
 
 // ServerPrompt pairs a prompt with its toolset metadata.
 type ServerPrompt struct {
@@ -14,6 +21,8 @@ type ServerPrompt struct {
 	// FeatureFlagDisable specifies a feature flag that, when enabled, causes this prompt
 	// to be omitted. Used to disable prompts when a feature flag is on.
 	FeatureFlagDisable string
+	// CompletionHandler optionally provides argument completions for this prompt.
+	CompletionHandler PromptCompletionHandler
 }
 
 // NewServerPrompt creates a new ServerPrompt with toolset metadata.
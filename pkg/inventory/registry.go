@@ -78,6 +78,7 @@ const (
 	MCPMethodResourcesTemplatesList = "resources/templates/list"
 	MCPMethodPromptsList            = "prompts/list"
 	MCPMethodPromptsGet             = "prompts/get"
+	MCPMethodCompletionComplete     = "completion/complete"
 )
 
 // ForMCPRequest returns a Registry optimized for a specific MCP request.
@@ -96,6 +97,7 @@ const (
 //   - MCPMethodResourcesRead: All resources (SDK handles URI template matching)
 //   - MCPMethodPromptsList: All available prompts (no tools/resources)
 //   - MCPMethodPromptsGet: Only the named prompt
+//   - MCPMethodCompletionComplete: Only the named prompt when completing a prompt argument (no tools/resources)
 //   - Unknown methods: Empty (no items registered)
 //
 // All existing filters (read-only, toolsets, etc.) still apply to the returned items.
@@ -140,7 +142,7 @@ func (r *Inventory) ForMCPRequest(method string, itemName string) *Inventory {
 		result.tools, result.prompts = nil, nil
 	case MCPMethodPromptsList:
 		result.tools, result.resourceTemplates = nil, nil
-	case MCPMethodPromptsGet:
+	case MCPMethodPromptsGet, MCPMethodCompletionComplete:
 		result.tools, result.resourceTemplates = nil, nil
 		if itemName != "" {
 			result.prompts = r.filterPromptsByName(itemName)
@@ -214,6 +216,25 @@ func (r *Inventory) RegisterAll(ctx context.Context, s *mcp.Server, deps any) {
 	r.RegisterPrompts(ctx, s)
 }
 
+// CompletePrompt dispatches a completion request for a prompt argument to the
+// prompt's completion handler. It returns a nil result when the prompt is not
+// available or does not provide completions.
+func (r *Inventory) CompletePrompt(ctx context.Context, req *mcp.CompleteRequest) (*mcp.CompleteResult, error) {
+	if req == nil || req.Params == nil || req.Params.Ref == nil {
+		return nil, nil
+	}
+	for _, prompt := range r.AvailablePrompts(ctx) {
+		if prompt.Prompt.Name != req.Params.Ref.Name {
+			continue
+		}
+		if prompt.CompletionHandler == nil {
+			return nil, nil
+		}
+		return prompt.CompletionHandler(ctx, req)
+	}
+	return nil, nil
+}
+
 // ResolveToolAliases resolves deprecated tool aliases to their canonical names.
 // It logs a warning to stderr for each deprecated alias that is resolved.
 // Returns:
@@ -234,6 +255,23 @@ func (r *Inventory) ResolveToolAliases(toolNames []string) (resolved []string, a
 	return resolved, aliasesUsed
 }
 
+// ResolveDeprecatedAlias returns the canonical tool name for a deprecated alias.
+// It reports false when name is not an alias, or when a tool is still registered
+// under that name (for example, behind a feature flag), in which case no
+// rewriting should take place.
+func (r *Inventory) ResolveDeprecatedAlias(name string) (canonical string, ok bool) {
+	canonical, isAlias := r.deprecatedAliases[name]
+	if !isAlias {
+		return "", false
+	}
+	for i := range r.tools {
+		if r.tools[i].Tool.Name == name {
+			return "", false
+		}
+	}
+	return canonical, true
+}
+
 // FindToolByName searches all tools for one matching the given name.
 // Returns the tool, its toolset ID, and an error if not found.
 // This searches ALL tools regardless of filters.
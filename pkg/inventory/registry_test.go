@@ -534,6 +534,33 @@ func TestResolveToolAliases(t *testing.T) {
 	}
 }
 
+func TestResolveDeprecatedAlias(t *testing.T) {
+	tools := []ServerTool{
+		mockTool("issue_read", "toolset1", true),
+		mockTool("legacy_tool", "toolset1", true),
+	}
+
+	reg := mustBuild(t, NewBuilder().SetTools(tools).
+		WithDeprecatedAliases(map[string]string{
+			"get_issue":   "issue_read",
+			"legacy_tool": "issue_read",
+		}))
+
+	canonical, ok := reg.ResolveDeprecatedAlias("get_issue")
+	if !ok || canonical != "issue_read" {
+		t.Errorf("expected 'get_issue' to resolve to 'issue_read', got %q (ok=%v)", canonical, ok)
+	}
+
+	// A tool that still exists under the aliased name must not be rewritten
+	if _, ok := reg.ResolveDeprecatedAlias("legacy_tool"); ok {
+		t.Error("expected 'legacy_tool' not to resolve while a tool with that name exists")
+	}
+
+	if _, ok := reg.ResolveDeprecatedAlias("issue_read"); ok {
+		t.Error("expected canonical name not to resolve as an alias")
+	}
+}
+
 func TestFindToolByName(t *testing.T) {
 	tools := []ServerTool{
 		mockTool("issue_read", "toolset1", true),
@@ -938,6 +965,83 @@ func TestForMCPRequest_PromptsGet(t *testing.T) {
 	}
 }
 
+func TestForMCPRequest_CompletionComplete(t *testing.T) {
+	tools := []ServerTool{
+		mockTool("tool1", "repos", true),
+	}
+	prompts := []ServerPrompt{
+		mockPrompt("prompt1", "repos"),
+		mockPrompt("prompt2", "issues"),
+	}
+
+	reg := mustBuild(t, NewBuilder().SetTools(tools).SetPrompts(prompts).WithToolsets([]string{"all"}))
+	filtered := reg.ForMCPRequest(MCPMethodCompletionComplete, "prompt2")
+
+	if len(filtered.AvailableTools(context.Background())) != 0 {
+		t.Errorf("Expected 0 tools for completion/complete, got %d", len(filtered.AvailableTools(context.Background())))
+	}
+	available := filtered.AvailablePrompts(context.Background())
+	if len(available) != 1 || available[0].Prompt.Name != "prompt2" {
+		t.Errorf("Expected only 'prompt2' for completion/complete, got %v", available)
+	}
+}
+
+func TestAddPrompts(t *testing.T) {
+	builtin := []ServerPrompt{mockPrompt("prompt1", "repos")}
+
+	reg := mustBuild(t, NewBuilder().
+		SetPrompts(builtin).
+		AddPrompts(mockPrompt("prompt2", "repos"), mockPrompt("prompt3", "issues")).
+		WithToolsets([]string{"repos"}))
+
+	available := reg.AvailablePrompts(context.Background())
+	if len(available) != 2 {
+		t.Fatalf("Expected 2 prompts in the repos toolset, got %d", len(available))
+	}
+	if len(builtin) != 1 {
+		t.Errorf("Expected AddPrompts not to modify the slice passed to SetPrompts")
+	}
+}
+
+func TestCompletePrompt(t *testing.T) {
+	withCompletion := mockPrompt("prompt1", "repos")
+	withCompletion.CompletionHandler = func(_ context.Context, req *mcp.CompleteRequest) (*mcp.CompleteResult, error) {
+		return &mcp.CompleteResult{
+			Completion: mcp.CompletionResultDetails{Values: []string{req.Params.Argument.Value + "-done"}},
+		}, nil
+	}
+	prompts := []ServerPrompt{
+		withCompletion,
+		mockPrompt("prompt2", "repos"),
+		mockPrompt("prompt3", "issues"),
+	}
+
+	reg := mustBuild(t, NewBuilder().SetPrompts(prompts).WithToolsets([]string{"repos"}))
+
+	request := func(name string) *mcp.CompleteRequest {
+		return &mcp.CompleteRequest{Params: &mcp.CompleteParams{
+			Ref:      &mcp.CompleteReference{Type: "ref/prompt", Name: name},
+			Argument: mcp.CompleteParamsArgument{Name: "arg", Value: "x"},
+		}}
+	}
+
+	result, err := reg.CompletePrompt(context.Background(), request("prompt1"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result == nil || len(result.Completion.Values) != 1 || result.Completion.Values[0] != "x-done" {
+		t.Errorf("Expected completion from prompt handler, got %v", result)
+	}
+
+	// No handler, disabled toolset, and unknown prompts all yield no result
+	for _, name := range []string{"prompt2", "prompt3", "missing"} {
+		result, err := reg.CompletePrompt(context.Background(), request(name))
+		if err != nil || result != nil {
+			t.Errorf("Expected nil result for %q, got %v (err=%v)", name, result, err)
+		}
+	}
+}
+
 func TestForMCPRequest_UnknownMethod(t *testing.T) {
 	tools := []ServerTool{
 		mockTool("tool1", "repos", true),
@@ -4,31 +4,47 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"maps"
+	"reflect"
+	"slices"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
-	"github.com/muesli/cache2go"
+	"github.com/github/github-mcp-server/pkg/tracing"
 	"github.com/shurcooL/githubv4"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // RepoAccessCache caches repository metadata related to lockdown checks so that
 // multiple tools can reuse the same access information safely across goroutines.
 type RepoAccessCache struct {
 	client           *githubv4.Client
-	mu               sync.Mutex
-	cache            *cache2go.CacheTable
+	store            RepoAccessStore
 	ttl              time.Duration
 	logger           *slog.Logger
 	trustedBotLogins map[string]struct{}
-}
+	policy           *Policy
+	shared           *sharedCacheState
 
-type repoAccessCacheEntry struct {
-	isPrivate   bool
-	knownUsers  map[string]bool // normalized login -> has push access
+	// mu guards logger and the fields below, which depend on the client
+	mu          sync.Mutex
 	viewerLogin string
 }
 
+// sharedCacheState is shared by the caches returned by WithClient.
+type sharedCacheState struct {
+	hits   atomic.Int64
+	misses atomic.Int64
+
+	// mu serializes the updates of store entries, which read an entry and store a new
+	// one. It is never held during GitHub requests.
+	mu sync.Mutex
+}
+
 // RepoAccessInfo captures repository metadata needed for lockdown decisions.
 type RepoAccessInfo struct {
 	IsPrivate     bool
@@ -37,13 +53,10 @@ type RepoAccessInfo struct {
 }
 
 const (
-	defaultRepoAccessTTL      = 20 * time.Minute
-	defaultRepoAccessCacheKey = "repo-access-cache"
-)
+	defaultRepoAccessTTL = 20 * time.Minute
 
-var (
-	instance   *RepoAccessCache
-	instanceMu sync.Mutex
+	// maxPrefetchBatch bounds the users whose access is resolved by a single query.
+	maxPrefetchBatch = 50
 )
 
 // RepoAccessOption configures RepoAccessCache at construction time.
@@ -64,39 +77,61 @@ func WithLogger(logger *slog.Logger) RepoAccessOption {
 	}
 }
 
-// WithCacheName overrides the cache table name used for storing entries. This option is intended for tests
-// that need isolated cache instances.
-func WithCacheName(name string) RepoAccessOption {
+// WithStore sets the store of repository access information. Defaults to an in-memory
+// store of DefaultRepoAccessCacheSize repositories.
+func WithStore(store RepoAccessStore) RepoAccessOption {
 	return func(c *RepoAccessCache) {
-		if name != "" {
-			c.cache = cache2go.Cache(name)
+		if store != nil {
+			c.store = store
 		}
 	}
 }
 
-// GetInstance returns the singleton instance of RepoAccessCache.
-// It initializes the instance on first call with the provided client and options.
-// Subsequent calls ignore the client and options parameters and return the existing instance.
-// This is the preferred way to access the cache in production code.
-func GetInstance(client *githubv4.Client, opts ...RepoAccessOption) *RepoAccessCache {
-	instanceMu.Lock()
-	defer instanceMu.Unlock()
-	if instance == nil {
-		instance = &RepoAccessCache{
-			client: client,
-			cache:  cache2go.Cache(defaultRepoAccessCacheKey),
-			ttl:    defaultRepoAccessTTL,
-			trustedBotLogins: map[string]struct{}{
-				"copilot": {},
-			},
-		}
-		for _, opt := range opts {
-			if opt != nil {
-				opt(instance)
-			}
+// WithPolicy sets the trust policy applied by IsSafeContent.
+func WithPolicy(policy *Policy) RepoAccessOption {
+	return func(c *RepoAccessCache) {
+		c.policy = policy
+	}
+}
+
+// NewRepoAccessCache creates a cache that resolves repository access with client.
+// Each cache has its own store unless one is shared with WithStore.
+func NewRepoAccessCache(client *githubv4.Client, opts ...RepoAccessOption) *RepoAccessCache {
+	c := &RepoAccessCache{
+		client: client,
+		ttl:    defaultRepoAccessTTL,
+		trustedBotLogins: map[string]struct{}{
+			"copilot": {},
+		},
+		shared: &sharedCacheState{},
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(c)
 		}
 	}
-	return instance
+	if c.store == nil {
+		c.store = NewMemoryRepoAccessStore(DefaultRepoAccessCacheSize)
+	}
+	return c
+}
+
+// WithClient returns a cache that resolves repository access with client, sharing the
+// store, options, statistics and lock of c. The HTTP server uses it to query GitHub with
+// the token of each request. The viewer is not shared, as it depends on the client.
+func (c *RepoAccessCache) WithClient(client *githubv4.Client) *RepoAccessCache {
+	c.mu.Lock()
+	logger := c.logger
+	c.mu.Unlock()
+	return &RepoAccessCache{
+		client:           client,
+		store:            c.store,
+		ttl:              c.ttl,
+		logger:           logger,
+		trustedBotLogins: c.trustedBotLogins,
+		policy:           c.policy,
+		shared:           c.shared,
+	}
 }
 
 // SetLogger updates the logger used for cache diagnostics.
@@ -113,25 +148,95 @@ type CacheStats struct {
 	Evictions int64
 }
 
+// Stats returns the cache activity counters. A lookup is a hit when the user's access to
+// the repository was already cached, and an eviction is an entry dropped by the store,
+// when it implements Evictions() int64.
+func (c *RepoAccessCache) Stats() CacheStats {
+	if c == nil {
+		return CacheStats{}
+	}
+	stats := CacheStats{
+		Hits:   c.shared.hits.Load(),
+		Misses: c.shared.misses.Load(),
+	}
+	if store, ok := c.store.(interface{ Evictions() int64 }); ok {
+		stats.Evictions = store.Evictions()
+	}
+	return stats
+}
+
 // IsSafeContent determines if the specified user can safely access the requested repository content.
 // Safe access applies when any of the following is true:
-// - the content was created by a trusted bot;
+// - the content was created by a trusted bot, Copilot or one of the policy's;
+// - the content's author association meets the policy's minimum;
 // - the author currently has push access to the repository;
 // - the repository is private;
-// - the content was created by the viewer.
-func (c *RepoAccessCache) IsSafeContent(ctx context.Context, username, owner, repo string) (bool, error) {
+// - the content was created by the viewer;
+// - the author is a member of an organization or team trusted by the policy.
+//
+// authorAssociation is the association GitHub reports for the content, or empty if unknown.
+func (c *RepoAccessCache) IsSafeContent(ctx context.Context, username, authorAssociation, owner, repo string) (bool, error) {
+	ctx, span := tracing.StartSpan(ctx, "lockdown.is_safe_content", trace.WithAttributes(
+		attribute.String("github.repository", owner+"/"+repo),
+		attribute.String("github.user", username),
+	))
+	defer span.End()
+
+	policy := c.policy.forRepo(owner, repo)
+	if c.isTrustedBot(username) || policy.trustsBot(username) {
+		return true, nil
+	}
+	if policy.trustsAssociation(authorAssociation) {
+		c.logDebug(ctx, fmt.Sprintf("trusted content by %s in %s/%s with author association %s", username, owner, repo, authorAssociation))
+		return true, nil
+	}
+
 	repoInfo, err := c.getRepoAccessInfo(ctx, username, owner, repo)
 	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
 		return false, err
 	}
 
 	c.logDebug(ctx, fmt.Sprintf("evaluated repo access for user %s to %s/%s for content filtering, result: hasPushAccess=%t, isPrivate=%t",
 		username, owner, repo, repoInfo.HasPushAccess, repoInfo.IsPrivate))
 
-	if c.isTrustedBot(username) || repoInfo.IsPrivate || repoInfo.ViewerLogin == strings.ToLower(username) {
+	if repoInfo.IsPrivate || repoInfo.HasPushAccess {
+		return true, nil
+	}
+
+	// Entries shared with other clients don't tell who the viewer is
+	viewerLogin := repoInfo.ViewerLogin
+	if viewerLogin == "" {
+		if viewerLogin, err = c.getViewerLogin(ctx); err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			return false, err
+		}
+	}
+	if strings.EqualFold(viewerLogin, username) {
+		return true, nil
+	}
+
+	if !policy.trustsMembers() {
+		return false, nil
+	}
+	return c.isTrustedMember(ctx, username, owner, repo, policy), nil
+}
+
+// IsSafeUserContent determines if content outside of a repository, such as a gist, can be
+// safely returned. Without a repository to check access to, it is only safe when created by
+// a trusted bot or by the viewer.
+func (c *RepoAccessCache) IsSafeUserContent(ctx context.Context, username string) (bool, error) {
+	if c == nil {
+		return false, fmt.Errorf("nil repo access cache")
+	}
+	if c.isTrustedBot(username) || c.policy.forRepo("", "").trustsBot(username) {
 		return true, nil
 	}
-	return repoInfo.HasPushAccess, nil
+	viewerLogin, err := c.getViewerLogin(ctx)
+	if err != nil {
+		return false, err
+	}
+	return strings.EqualFold(viewerLogin, username), nil
 }
 
 func (c *RepoAccessCache) getRepoAccessInfo(ctx context.Context, username, owner, repo string) (RepoAccessInfo, error) {
@@ -141,61 +246,205 @@ func (c *RepoAccessCache) getRepoAccessInfo(ctx context.Context, username, owner
 
 	key := cacheKey(owner, repo)
 	userKey := strings.ToLower(username)
-	c.mu.Lock()
-	defer c.mu.Unlock()
 
-	// Try to get entry from cache - this will keep the item alive if it exists
-	cacheItem, err := c.cache.Value(key)
-	if err == nil {
-		entry := cacheItem.Data().(*repoAccessCacheEntry)
-		if cachedHasPush, known := entry.knownUsers[userKey]; known {
+	entry, found := c.store.Get(key)
+	if found {
+		if cachedHasPush, known := entry.PushAccess[userKey]; known {
+			c.shared.hits.Add(1)
+			trace.SpanFromContext(ctx).SetAttributes(attribute.Bool("lockdown.cache_hit", true))
 			c.logDebug(ctx, fmt.Sprintf("repo access cache hit for user %s to %s/%s", username, owner, repo))
+			c.mu.Lock()
+			viewerLogin := c.viewerLogin
+			c.mu.Unlock()
 			return RepoAccessInfo{
-				IsPrivate:     entry.isPrivate,
+				IsPrivate:     entry.IsPrivate,
 				HasPushAccess: cachedHasPush,
-				ViewerLogin:   entry.viewerLogin,
+				ViewerLogin:   viewerLogin,
 			}, nil
 		}
-
 		c.logDebug(ctx, "known users cache miss, fetching from graphql API")
+	} else {
+		c.logDebug(ctx, fmt.Sprintf("repo access cache miss for user %s to %s/%s", username, owner, repo))
+	}
+	c.shared.misses.Add(1)
+	trace.SpanFromContext(ctx).SetAttributes(attribute.Bool("lockdown.cache_hit", false))
+
+	info, queryErr := c.queryRepoAccessInfo(ctx, username, owner, repo)
+	if queryErr != nil {
+		return RepoAccessInfo{}, queryErr
+	}
+	c.setViewerLogin(info.ViewerLogin)
+
+	c.updateEntry(key, true, func(entry *RepoAccessEntry) {
+		entry.IsPrivate = info.IsPrivate
+		entry.PushAccess[userKey] = info.HasPushAccess
+	})
+	return info, nil
+}
+
+// updateEntry stores a copy of the current entry of key with update applied, as stored
+// entries may be read concurrently. The entry is read again under the shared lock, so
+// that updates made by other requests meanwhile are kept. Without an entry, a new one is
+// created if create is set, and nothing is stored otherwise.
+func (c *RepoAccessCache) updateEntry(key string, create bool, update func(*RepoAccessEntry)) {
+	c.shared.mu.Lock()
+	defer c.shared.mu.Unlock()
+
+	entry, found := c.store.Get(key)
+	switch {
+	case found:
+		entry = entry.clone()
+	case create:
+		entry = &RepoAccessEntry{PushAccess: make(map[string]bool, 1)}
+	default:
+		return
+	}
+	update(entry)
+	c.store.Set(key, entry, c.ttl)
+}
+
+// setViewerLogin remembers the login of the user the client is authenticated as.
+func (c *RepoAccessCache) setViewerLogin(login string) {
+	c.mu.Lock()
+	c.viewerLogin = login
+	c.mu.Unlock()
+}
+
+// getViewerLogin returns the login of the user the client is authenticated as.
+func (c *RepoAccessCache) getViewerLogin(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	viewerLogin := c.viewerLogin
+	c.mu.Unlock()
+	if viewerLogin != "" {
+		return viewerLogin, nil
+	}
+	if c.client == nil {
+		return "", fmt.Errorf("nil GraphQL client")
+	}
+
+	var query struct {
+		Viewer struct {
+			Login githubv4.String
+		}
+	}
+	if err := c.client.Query(ctx, &query, nil); err != nil {
+		return "", fmt.Errorf("failed to query viewer login: %w", err)
+	}
+	c.setViewerLogin(string(query.Viewer.Login))
+	return string(query.Viewer.Login), nil
+}
 
-		info, queryErr := c.queryRepoAccessInfo(ctx, username, owner, repo)
-		if queryErr != nil {
-			return RepoAccessInfo{}, queryErr
+// isTrustedMember reports whether username belongs to an organization or team trusted by
+// policy, caching the answer with the repository's access information. Failed lookups, such
+// as for users that don't exist or teams the viewer can't see, are not trusted.
+func (c *RepoAccessCache) isTrustedMember(ctx context.Context, username, owner, repo string, policy RepoPolicy) bool {
+	key := cacheKey(owner, repo)
+	userKey := strings.ToLower(username)
+
+	if entry, found := c.store.Get(key); found {
+		if member, known := entry.TrustedMembers[userKey]; known {
+			return member
 		}
+	}
 
-		entry.knownUsers[userKey] = info.HasPushAccess
-		entry.viewerLogin = info.ViewerLogin
-		entry.isPrivate = info.IsPrivate
-		c.cache.Add(key, c.ttl, entry)
+	member, err := c.queryTrustedMembership(ctx, username, policy)
+	if err != nil {
+		c.logDebug(ctx, fmt.Sprintf("failed to resolve trusted membership of %s: %v", username, err))
+		return false
+	}
+	c.updateEntry(key, false, func(entry *RepoAccessEntry) {
+		if entry.TrustedMembers == nil {
+			entry.TrustedMembers = make(map[string]bool, 1)
+		}
+		entry.TrustedMembers[userKey] = member
+	})
+	return member
+}
 
-		return RepoAccessInfo{
-			IsPrivate:     entry.isPrivate,
-			HasPushAccess: entry.knownUsers[userKey],
-			ViewerLogin:   entry.viewerLogin,
-		}, nil
+// queryTrustedMembership queries whether username belongs to any of the organizations and
+// teams trusted by policy, aliasing one field per organization and team. Like
+// queryRepoAccessBatch, the query type is built at run time.
+func (c *RepoAccessCache) queryTrustedMembership(ctx context.Context, username string, policy RepoPolicy) (bool, error) {
+	if c.client == nil {
+		return false, fmt.Errorf("nil GraphQL client")
 	}
 
-	c.logDebug(ctx, fmt.Sprintf("repo access cache miss for user %s to %s/%s", username, owner, repo))
+	variables := map[string]any{"login": githubv4.String(username)}
+	var fields []reflect.StructField
+	if len(policy.TrustedOrgs) > 0 {
+		var orgFields []reflect.StructField
+		for i, org := range policy.TrustedOrgs {
+			alias := fmt.Sprintf("o%d", i)
+			variables[alias] = githubv4.String(org)
+			orgFields = append(orgFields, reflect.StructField{
+				Name: fmt.Sprintf("O%d", i),
+				Type: reflect.TypeFor[struct{ Login githubv4.String }](),
+				Tag:  reflect.StructTag(fmt.Sprintf(`graphql:"%s: organization(login: $%s)"`, alias, alias)),
+			})
+		}
+		fields = append(fields, reflect.StructField{
+			Name: "User",
+			Type: reflect.StructOf(orgFields),
+			Tag:  `graphql:"user(login: $login)"`,
+		})
+	}
+	for i, team := range policy.TrustedTeams {
+		alias := fmt.Sprintf("t%d", i)
+		org, slug, _ := strings.Cut(team, "/")
+		variables[alias+"org"] = githubv4.String(org)
+		variables[alias+"slug"] = githubv4.String(slug)
+		teamType := reflect.StructOf([]reflect.StructField{{
+			Name: "Team",
+			Type: reflect.TypeFor[teamMembers](),
+			Tag:  reflect.StructTag(fmt.Sprintf(`graphql:"team(slug: $%sslug)"`, alias)),
+		}})
+		fields = append(fields, reflect.StructField{
+			Name: fmt.Sprintf("T%d", i),
+			Type: teamType,
+			Tag:  reflect.StructTag(fmt.Sprintf(`graphql:"%s: organization(login: $%sorg)"`, alias, alias)),
+		})
+	}
+	query := reflect.New(reflect.StructOf(fields))
 
-	info, queryErr := c.queryRepoAccessInfo(ctx, username, owner, repo)
-	if queryErr != nil {
-		return RepoAccessInfo{}, queryErr
+	if err := c.client.Query(ctx, query.Interface(), variables); err != nil {
+		return false, fmt.Errorf("failed to query trusted membership: %w", err)
 	}
 
-	// Create new entry
-	entry := &repoAccessCacheEntry{
-		knownUsers:  map[string]bool{userKey: info.HasPushAccess},
-		isPrivate:   info.IsPrivate,
-		viewerLogin: info.ViewerLogin,
+	result := query.Elem()
+	for i := range result.NumField() {
+		field := result.Field(i)
+		if result.Type().Field(i).Name == "User" {
+			for j := range field.NumField() {
+				if field.Field(j).Field(0).String() != "" {
+					return true, nil
+				}
+			}
+			continue
+		}
+		if field.Field(0).Interface().(teamMembers).has(username) {
+			return true, nil
+		}
 	}
-	c.cache.Add(key, c.ttl, entry)
+	return false, nil
+}
 
-	return RepoAccessInfo{
-		IsPrivate:     entry.isPrivate,
-		HasPushAccess: entry.knownUsers[userKey],
-		ViewerLogin:   entry.viewerLogin,
-	}, nil
+// teamMembers is the result of a team members search for a user.
+type teamMembers struct {
+	Members struct {
+		Nodes []struct {
+			Login githubv4.String
+		}
+	} `graphql:"members(query: $login, first: 10)"`
+}
+
+// has reports whether username is among the members found.
+func (t teamMembers) has(username string) bool {
+	for _, node := range t.Members.Nodes {
+		if strings.EqualFold(string(node.Login), username) {
+			return true
+		}
+	}
+	return false
 }
 
 func (c *RepoAccessCache) queryRepoAccessInfo(ctx context.Context, username, owner, repo string) (RepoAccessInfo, error) {
@@ -209,14 +458,7 @@ func (c *RepoAccessCache) queryRepoAccessInfo(ctx context.Context, username, own
 		}
 		Repository struct {
 			IsPrivate     githubv4.Boolean
-			Collaborators struct {
-				Edges []struct {
-					Permission githubv4.String
-					Node       struct {
-						Login githubv4.String
-					}
-				}
-			} `graphql:"collaborators(query: $username, first: 1)"`
+			Collaborators collaboratorEdges `graphql:"collaborators(query: $username, first: 1)"`
 		} `graphql:"repository(owner: $owner, name: $name)"`
 	}
 
@@ -230,15 +472,7 @@ func (c *RepoAccessCache) queryRepoAccessInfo(ctx context.Context, username, own
 		return RepoAccessInfo{}, fmt.Errorf("failed to query repository access info: %w", err)
 	}
 
-	hasPush := false
-	for _, edge := range query.Repository.Collaborators.Edges {
-		login := string(edge.Node.Login)
-		if strings.EqualFold(login, username) {
-			permission := string(edge.Permission)
-			hasPush = permission == "WRITE" || permission == "ADMIN" || permission == "MAINTAIN"
-			break
-		}
-	}
+	hasPush := query.Repository.Collaborators.hasPushAccess(username)
 
 	c.logDebug(ctx, fmt.Sprintf("queried repo access info for user %s to %s/%s: isPrivate=%t, hasPushAccess=%t, viewerLogin=%s",
 		username, owner, repo, bool(query.Repository.IsPrivate), hasPush, query.Viewer.Login))
@@ -250,14 +484,145 @@ func (c *RepoAccessCache) queryRepoAccessInfo(ctx context.Context, username, own
 	}, nil
 }
 
+// Prefetch resolves the access of the given users to a repository that are not cached
+// yet, with one aliased GraphQL query per batch of users instead of one query per user, so
+// that subsequent IsSafeContent calls for them are served from the cache. Tools call it
+// before checking the authors of every comment or review in a list.
+func (c *RepoAccessCache) Prefetch(ctx context.Context, owner, repo string, usernames []string) error {
+	if c == nil {
+		return fmt.Errorf("nil repo access cache")
+	}
+
+	key := cacheKey(owner, repo)
+	entry, found := c.store.Get(key)
+	policy := c.policy.forRepo(owner, repo)
+	var unknown []string
+	seen := make(map[string]bool, len(usernames))
+	for _, username := range usernames {
+		userKey := strings.ToLower(username)
+		if userKey == "" || seen[userKey] || c.isTrustedBot(username) || policy.trustsBot(username) {
+			continue
+		}
+		seen[userKey] = true
+		if found {
+			if _, known := entry.PushAccess[userKey]; known {
+				continue
+			}
+		}
+		unknown = append(unknown, username)
+	}
+	if len(unknown) == 0 {
+		return nil
+	}
+
+	ctx, span := tracing.StartSpan(ctx, "lockdown.prefetch", trace.WithAttributes(
+		attribute.String("github.repository", owner+"/"+repo),
+		attribute.Int("lockdown.users", len(unknown)),
+	))
+	defer span.End()
+
+	var isPrivate bool
+	pushAccess := make(map[string]bool, len(unknown))
+	for batch := range slices.Chunk(unknown, maxPrefetchBatch) {
+		info, hasPush, err := c.queryRepoAccessBatch(ctx, batch, owner, repo)
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			return err
+		}
+		c.shared.misses.Add(int64(len(batch)))
+		c.setViewerLogin(info.ViewerLogin)
+		isPrivate = info.IsPrivate
+		for i, username := range batch {
+			pushAccess[strings.ToLower(username)] = hasPush[i]
+		}
+	}
+	c.updateEntry(key, true, func(entry *RepoAccessEntry) {
+		entry.IsPrivate = isPrivate
+		maps.Copy(entry.PushAccess, pushAccess)
+	})
+	return nil
+}
+
+// queryRepoAccessBatch queries repository metadata and the push access of each of
+// usernames, aliasing one collaborators field per user. The query type is built at run
+// time, as the number of fields depends on the batch.
+func (c *RepoAccessCache) queryRepoAccessBatch(ctx context.Context, usernames []string, owner, repo string) (RepoAccessInfo, []bool, error) {
+	if c.client == nil {
+		return RepoAccessInfo{}, nil, fmt.Errorf("nil GraphQL client")
+	}
+
+	variables := map[string]any{
+		"owner": githubv4.String(owner),
+		"name":  githubv4.String(repo),
+	}
+	repoFields := []reflect.StructField{{Name: "IsPrivate", Type: reflect.TypeFor[githubv4.Boolean]()}}
+	for i, username := range usernames {
+		alias := fmt.Sprintf("u%d", i)
+		variables[alias] = githubv4.String(username)
+		repoFields = append(repoFields, reflect.StructField{
+			Name: fmt.Sprintf("U%d", i),
+			Type: reflect.TypeFor[collaboratorEdges](),
+			Tag:  reflect.StructTag(fmt.Sprintf(`graphql:"%s: collaborators(query: $%s, first: 1)"`, alias, alias)),
+		})
+	}
+	query := reflect.New(reflect.StructOf([]reflect.StructField{
+		{Name: "Viewer", Type: reflect.TypeFor[struct{ Login githubv4.String }]()},
+		{Name: "Repository", Type: reflect.StructOf(repoFields), Tag: `graphql:"repository(owner: $owner, name: $name)"`},
+	}))
+
+	if err := c.client.Query(ctx, query.Interface(), variables); err != nil {
+		return RepoAccessInfo{}, nil, fmt.Errorf("failed to query repository access info: %w", err)
+	}
+
+	result := query.Elem()
+	repository := result.Field(1)
+	info := RepoAccessInfo{
+		IsPrivate:   bool(repository.Field(0).Interface().(githubv4.Boolean)),
+		ViewerLogin: string(result.Field(0).Field(0).Interface().(githubv4.String)),
+	}
+	hasPush := make([]bool, len(usernames))
+	for i, username := range usernames {
+		hasPush[i] = repository.Field(i + 1).Interface().(collaboratorEdges).hasPushAccess(username)
+	}
+
+	c.logDebug(ctx, fmt.Sprintf("queried repo access info for %d users to %s/%s: isPrivate=%t, viewerLogin=%s",
+		len(usernames), owner, repo, info.IsPrivate, info.ViewerLogin))
+
+	return info, hasPush, nil
+}
+
+// collaboratorEdges is the result of a repository collaborators search for a user.
+type collaboratorEdges struct {
+	Edges []struct {
+		Permission githubv4.String
+		Node       struct {
+			Login githubv4.String
+		}
+	}
+}
+
+// hasPushAccess reports whether username is among the collaborators with push access.
+func (e collaboratorEdges) hasPushAccess(username string) bool {
+	for _, edge := range e.Edges {
+		if strings.EqualFold(string(edge.Node.Login), username) {
+			permission := string(edge.Permission)
+			return permission == "WRITE" || permission == "ADMIN" || permission == "MAINTAIN"
+		}
+	}
+	return false
+}
+
 func (c *RepoAccessCache) log(ctx context.Context, level slog.Level, msg string, attrs ...slog.Attr) {
-	if c == nil || c.logger == nil {
+	if c == nil {
 		return
 	}
-	if !c.logger.Enabled(ctx, level) {
+	c.mu.Lock()
+	logger := c.logger
+	c.mu.Unlock()
+	if logger == nil || !logger.Enabled(ctx, level) {
 		return
 	}
-	c.logger.LogAttrs(ctx, level, msg, attrs...)
+	logger.LogAttrs(ctx, level, msg, attrs...)
 }
 
 func (c *RepoAccessCache) logDebug(ctx context.Context, msg string, attrs ...slog.Attr) {
@@ -265,7 +630,7 @@ func (c *RepoAccessCache) logDebug(ctx context.Context, msg string, attrs ...slo
 }
 
 func (c *RepoAccessCache) isTrustedBot(username string) bool {
-	_, ok := c.trustedBotLogins[strings.ToLower(username)]
+	_, ok := c.trustedBotLogins[botLogin(username)]
 	return ok
 }
 
@@ -89,7 +89,7 @@ func newMockRepoAccessCache(t *testing.T, ttl time.Duration) (*RepoAccessCache,
 
 	gqlClient := githubv4.NewClient(httpClient)
 
-	return GetInstance(gqlClient, WithTTL(ttl)), counting
+	return NewRepoAccessCache(gqlClient, WithTTL(ttl)), counting
 }
 
 func TestRepoAccessCacheEvictsAfterTTL(t *testing.T) {
@@ -102,6 +102,11 @@ func TestRepoAccessCacheEvictsAfterTTL(t *testing.T) {
 	require.True(t, info.HasPushAccess)
 	require.EqualValues(t, 1, transport.CallCount())
 
+	_, err = cache.getRepoAccessInfo(ctx, testUser, testOwner, testRepo)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, transport.CallCount())
+	require.Equal(t, CacheStats{Hits: 1, Misses: 1}, cache.Stats())
+
 	time.Sleep(20 * time.Millisecond)
 
 	info, err = cache.getRepoAccessInfo(ctx, testUser, testOwner, testRepo)
@@ -109,4 +114,185 @@ func TestRepoAccessCacheEvictsAfterTTL(t *testing.T) {
 	require.Equal(t, testUser, info.ViewerLogin)
 	require.True(t, info.HasPushAccess)
 	require.EqualValues(t, 2, transport.CallCount())
+	require.Equal(t, CacheStats{Hits: 1, Misses: 2, Evictions: 1}, cache.Stats())
+}
+
+// newBatchMockClient answers a prefetch of the access of "maintainer", who can push, and
+// "Drive-By", who can't.
+func newBatchMockClient() (*githubv4.Client, *countingTransport) {
+	collaborator := func(login, permission string) map[string]any {
+		return map[string]any{"edges": []any{map[string]any{"permission": permission, "node": map[string]any{"login": login}}}}
+	}
+	query := "query($name:String!$owner:String!$u0:String!$u1:String!){viewer{login},repository(owner: $owner, name: $name){isPrivate,u0: collaborators(query: $u0, first: 1){edges{permission,node{login}}},u1: collaborators(query: $u1, first: 1){edges{permission,node{login}}}}}"
+	variables := map[string]any{
+		"owner": githubv4.String(testOwner),
+		"name":  githubv4.String(testRepo),
+		"u0":    githubv4.String("maintainer"),
+		"u1":    githubv4.String("Drive-By"),
+	}
+	response := githubv4mock.DataResponse(map[string]any{
+		"viewer": map[string]any{"login": testUser},
+		"repository": map[string]any{
+			"isPrivate": false,
+			"u0":        collaborator("maintainer", "MAINTAIN"),
+			"u1":        map[string]any{"edges": []any{}},
+		},
+	})
+	httpClient := githubv4mock.NewMockedHTTPClient(githubv4mock.NewQueryMatcher(query, variables, response))
+	counting := &countingTransport{next: httpClient.Transport}
+	httpClient.Transport = counting
+	return githubv4.NewClient(httpClient), counting
+}
+
+func TestRepoAccessCachePrefetchBatchesUsers(t *testing.T) {
+	ctx := t.Context()
+
+	client, counting := newBatchMockClient()
+	cache := NewRepoAccessCache(client, WithTTL(time.Minute))
+
+	require.NoError(t, cache.Prefetch(ctx, testOwner, testRepo, []string{"maintainer", "", "Drive-By", "MAINTAINER"}))
+	require.EqualValues(t, 1, counting.CallCount(), "all unknown users are resolved by one query")
+
+	safe, err := cache.IsSafeContent(ctx, "maintainer", "", testOwner, testRepo)
+	require.NoError(t, err)
+	require.True(t, safe)
+	safe, err = cache.IsSafeContent(ctx, "drive-by", "", testOwner, testRepo)
+	require.NoError(t, err)
+	require.False(t, safe)
+
+	// Users known from the batch are not queried again
+	require.NoError(t, cache.Prefetch(ctx, testOwner, testRepo, []string{"maintainer", "drive-by"}))
+	require.EqualValues(t, 1, counting.CallCount())
+	require.Equal(t, CacheStats{Hits: 2, Misses: 2}, cache.Stats())
+}
+
+// barrierTransport holds each request until wg is done, so that concurrent lookups
+// are in flight at the same time.
+type barrierTransport struct {
+	next http.RoundTripper
+	wg   *sync.WaitGroup
+}
+
+func (b *barrierTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	b.wg.Done()
+	b.wg.Wait()
+	return b.next.RoundTrip(req)
+}
+
+func TestRepoAccessCacheWithClientMergesConcurrentLookups(t *testing.T) {
+	ctx := t.Context()
+
+	single, singleCounting := newMockRepoAccessCache(t, time.Minute)
+	batch, batchCounting := newBatchMockClient()
+	var inFlight sync.WaitGroup
+	inFlight.Add(2)
+	for _, counting := range []*countingTransport{singleCounting, batchCounting} {
+		counting.next = &barrierTransport{next: counting.next, wg: &inFlight}
+	}
+
+	shared := NewRepoAccessCache(nil, WithTTL(time.Minute))
+	first, second := shared.WithClient(single.client), shared.WithClient(batch)
+
+	// Both lookups miss, and neither waits for the other's request
+	errs := make(chan error, 2)
+	go func() {
+		_, err := first.getRepoAccessInfo(ctx, testUser, testOwner, testRepo)
+		errs <- err
+	}()
+	go func() {
+		errs <- second.Prefetch(ctx, testOwner, testRepo, []string{"maintainer", "Drive-By"})
+	}()
+	require.NoError(t, <-errs)
+	require.NoError(t, <-errs)
+
+	entry, found := shared.store.Get(cacheKey(testOwner, testRepo))
+	require.True(t, found)
+	require.Equal(t, map[string]bool{testUser: true, "maintainer": true, "drive-by": false}, entry.PushAccess, "both lookups are kept")
+}
+
+func TestRepoAccessCacheWithClientSharesStore(t *testing.T) {
+	ctx := t.Context()
+
+	shared := NewRepoAccessCache(nil, WithTTL(time.Minute))
+	client, counting := newBatchMockClient()
+	first := shared.WithClient(client)
+	require.NoError(t, first.Prefetch(ctx, testOwner, testRepo, []string{"maintainer", "Drive-By"}))
+	require.EqualValues(t, 1, counting.CallCount())
+
+	// Another client reuses the access resolved by the first, but not its viewer
+	viewerClient := githubv4mock.NewMockedHTTPClient(githubv4mock.NewQueryMatcher(
+		"{viewer{login}}", nil,
+		githubv4mock.DataResponse(map[string]any{"viewer": map[string]any{"login": "Drive-By"}}),
+	))
+	viewerCounting := &countingTransport{next: viewerClient.Transport}
+	viewerClient.Transport = viewerCounting
+	second := shared.WithClient(githubv4.NewClient(viewerClient))
+
+	safe, err := second.IsSafeContent(ctx, "maintainer", "", testOwner, testRepo)
+	require.NoError(t, err)
+	require.True(t, safe)
+	require.EqualValues(t, 0, viewerCounting.CallCount(), "the viewer is only needed for authors without push access")
+
+	safe, err = second.IsSafeContent(ctx, "drive-by", "", testOwner, testRepo)
+	require.NoError(t, err)
+	require.True(t, safe, "the viewer's own content is safe")
+	safe, err = second.IsSafeContent(ctx, "drive-by", "", testOwner, testRepo)
+	require.NoError(t, err)
+	require.True(t, safe)
+	require.EqualValues(t, 1, viewerCounting.CallCount(), "the viewer is queried once per client")
+
+	require.EqualValues(t, 1, counting.CallCount())
+	require.Equal(t, CacheStats{Hits: 3, Misses: 2}, shared.Stats(), "statistics are shared")
+}
+
+func TestRepoAccessCacheAppliesPolicy(t *testing.T) {
+	ctx := t.Context()
+
+	policy, err := ParsePolicy([]byte(`
+trusted_bots: [renovate]
+trusted_orgs: [octo-org]
+trusted_teams: [octo-org/maintainers]
+min_author_association: MEMBER
+`))
+	require.NoError(t, err)
+
+	client, counting := newBatchMockClient()
+	membershipQuery := "query($login:String!$o0:String!$t0org:String!$t0slug:String!){user(login: $login){o0: organization(login: $o0){login}},t0: organization(login: $t0org){team(slug: $t0slug){members(query: $login, first: 10){nodes{login}}}}}"
+	membershipVariables := map[string]any{
+		"login":  githubv4.String("Drive-By"),
+		"o0":     githubv4.String("octo-org"),
+		"t0org":  githubv4.String("octo-org"),
+		"t0slug": githubv4.String("maintainers"),
+	}
+	membershipClient := githubv4mock.NewMockedHTTPClient(
+		githubv4mock.NewQueryMatcher("{viewer{login}}", nil, githubv4mock.DataResponse(map[string]any{"viewer": map[string]any{"login": testUser}})),
+		githubv4mock.NewQueryMatcher(membershipQuery, membershipVariables, githubv4mock.DataResponse(map[string]any{
+			"user": map[string]any{"o0": nil},
+			"t0":   map[string]any{"team": map[string]any{"members": map[string]any{"nodes": []any{map[string]any{"login": "drive-by"}}}}},
+		})),
+	)
+	membershipCounting := &countingTransport{next: membershipClient.Transport}
+	membershipClient.Transport = membershipCounting
+
+	cache := NewRepoAccessCache(client, WithTTL(time.Minute), WithPolicy(policy))
+	require.NoError(t, cache.Prefetch(ctx, testOwner, testRepo, []string{"maintainer", "Drive-By", "renovate[bot]"}))
+	require.EqualValues(t, 1, counting.CallCount(), "trusted bots are not looked up")
+
+	safe, err := cache.IsSafeContent(ctx, "renovate[bot]", "NONE", testOwner, testRepo)
+	require.NoError(t, err)
+	require.True(t, safe, "trusted bots are safe")
+	safe, err = cache.IsSafeContent(ctx, "outsider", "MEMBER", testOwner, testRepo)
+	require.NoError(t, err)
+	require.True(t, safe, "authors with a trusted association are safe")
+	require.EqualValues(t, 1, counting.CallCount(), "neither needs a query")
+
+	// Team membership is looked up once and cached with the repository
+	teamCache := cache.WithClient(githubv4.NewClient(membershipClient))
+	for range 2 {
+		safe, err = teamCache.IsSafeContent(ctx, "Drive-By", "CONTRIBUTOR", testOwner, testRepo)
+		require.NoError(t, err)
+		require.True(t, safe, "members of trusted teams are safe")
+	}
+	require.EqualValues(t, 2, membershipCounting.CallCount(), "the viewer and the membership are queried once")
+	require.EqualValues(t, 1, counting.CallCount())
 }
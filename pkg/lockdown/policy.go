@@ -0,0 +1,175 @@
+package lockdown
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// authorAssociationRanks orders the author associations GitHub reports for content, from
+// the closest relationship with the repository down.
+var authorAssociationRanks = map[string]int{
+	"OWNER":                  6,
+	"MEMBER":                 5,
+	"COLLABORATOR":           4,
+	"CONTRIBUTOR":            3,
+	"FIRST_TIME_CONTRIBUTOR": 2,
+	"FIRST_TIMER":            1,
+	"NONE":                   0,
+	"MANNEQUIN":              0,
+}
+
+// Policy extends the content that lockdown mode trusts, beyond content by authors with push
+// access, content in private repositories, the viewer's own content and Copilot's.
+//
+// A policy is loaded from a YAML file such as:
+//
+//	trusted_bots: [dependabot, renovate]
+//	trusted_orgs: [octo-org]
+//	trusted_teams: [octo-org/maintainers]
+//	min_author_association: MEMBER
+//	repos:
+//	  octo-org/website:
+//	    min_author_association: COLLABORATOR
+type Policy struct {
+	RepoPolicy `yaml:",inline"`
+
+	// Repos overrides the policy of repositories, keyed by "owner/repo". The fields set in
+	// an override replace those of the policy; an empty list clears them.
+	Repos map[string]RepoPolicy `yaml:"repos,omitempty"`
+}
+
+// RepoPolicy is the trust policy applied to a repository.
+type RepoPolicy struct {
+	// TrustedBots are logins of bots whose content is trusted, with or without the "[bot]"
+	// suffix of their REST API login.
+	TrustedBots []string `yaml:"trusted_bots,omitempty"`
+
+	// TrustedOrgs trusts the content of the members of these organizations.
+	TrustedOrgs []string `yaml:"trusted_orgs,omitempty"`
+
+	// TrustedTeams trusts the content of the members of these teams, named "org/team-slug".
+	TrustedTeams []string `yaml:"trusted_teams,omitempty"`
+
+	// MinAuthorAssociation trusts content whose author association with the repository is
+	// this one or closer, for example MEMBER for OWNER and MEMBER. One of OWNER, MEMBER,
+	// COLLABORATOR, CONTRIBUTOR, FIRST_TIME_CONTRIBUTOR or FIRST_TIMER.
+	MinAuthorAssociation string `yaml:"min_author_association,omitempty"`
+}
+
+// LoadPolicy reads a trust policy from a YAML file.
+func LoadPolicy(path string) (*Policy, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // the policy path comes from operator configuration
+	if err != nil {
+		return nil, fmt.Errorf("failed to read lockdown policy %s: %w", path, err)
+	}
+	return ParsePolicy(data)
+}
+
+// ParsePolicy parses and validates a YAML trust policy.
+func ParsePolicy(data []byte) (*Policy, error) {
+	var policy Policy
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&policy); err != nil {
+		return nil, fmt.Errorf("failed to parse lockdown policy: %w", err)
+	}
+
+	if err := policy.RepoPolicy.normalize(); err != nil {
+		return nil, err
+	}
+	repos := make(map[string]RepoPolicy, len(policy.Repos))
+	for name, override := range policy.Repos {
+		owner, repo, ok := strings.Cut(name, "/")
+		if !ok || owner == "" || repo == "" || strings.Contains(repo, "/") {
+			return nil, fmt.Errorf("invalid lockdown policy repository %q: expected owner/repo", name)
+		}
+		if err := override.normalize(); err != nil {
+			return nil, fmt.Errorf("lockdown policy of %s: %w", name, err)
+		}
+		repos[cacheKey(owner, repo)] = override
+	}
+	policy.Repos = repos
+	return &policy, nil
+}
+
+// normalize validates the policy and lowercases its logins, so that they can be compared
+// directly.
+func (p *RepoPolicy) normalize() error {
+	for i, bot := range p.TrustedBots {
+		p.TrustedBots[i] = botLogin(bot)
+	}
+	for i, org := range p.TrustedOrgs {
+		if org == "" || strings.Contains(org, "/") {
+			return fmt.Errorf("invalid trusted organization %q", org)
+		}
+		p.TrustedOrgs[i] = strings.ToLower(org)
+	}
+	for i, team := range p.TrustedTeams {
+		org, slug, ok := strings.Cut(team, "/")
+		if !ok || org == "" || slug == "" || strings.Contains(slug, "/") {
+			return fmt.Errorf("invalid trusted team %q: expected org/team-slug", team)
+		}
+		p.TrustedTeams[i] = strings.ToLower(team)
+	}
+	if p.MinAuthorAssociation != "" {
+		p.MinAuthorAssociation = strings.ToUpper(p.MinAuthorAssociation)
+		if authorAssociationRanks[p.MinAuthorAssociation] == 0 {
+			return fmt.Errorf("invalid minimum author association %q", p.MinAuthorAssociation)
+		}
+	}
+	return nil
+}
+
+// forRepo returns the policy applied to a repository.
+func (p *Policy) forRepo(owner, repo string) RepoPolicy {
+	if p == nil {
+		return RepoPolicy{}
+	}
+	policy := p.RepoPolicy
+	override, ok := p.Repos[cacheKey(owner, repo)]
+	if !ok {
+		return policy
+	}
+	if override.TrustedBots != nil {
+		policy.TrustedBots = override.TrustedBots
+	}
+	if override.TrustedOrgs != nil {
+		policy.TrustedOrgs = override.TrustedOrgs
+	}
+	if override.TrustedTeams != nil {
+		policy.TrustedTeams = override.TrustedTeams
+	}
+	if override.MinAuthorAssociation != "" {
+		policy.MinAuthorAssociation = override.MinAuthorAssociation
+	}
+	return policy
+}
+
+// trustsBot reports whether username is one of the trusted bots.
+func (p RepoPolicy) trustsBot(username string) bool {
+	return slices.Contains(p.TrustedBots, botLogin(username))
+}
+
+// trustsAssociation reports whether content with the given author association is trusted.
+func (p RepoPolicy) trustsAssociation(association string) bool {
+	if p.MinAuthorAssociation == "" {
+		return false
+	}
+	return authorAssociationRanks[strings.ToUpper(association)] >= authorAssociationRanks[p.MinAuthorAssociation]
+}
+
+// trustsMembers reports whether the policy trusts the members of organizations or teams.
+func (p RepoPolicy) trustsMembers() bool {
+	return len(p.TrustedOrgs) > 0 || len(p.TrustedTeams) > 0
+}
+
+// botLogin normalizes a bot login: REST API logins of apps end with "[bot]", while GraphQL
+// ones don't.
+func botLogin(login string) string {
+	return strings.TrimSuffix(strings.ToLower(login), "[bot]")
+}
@@ -0,0 +1,79 @@
+package lockdown
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePolicy(t *testing.T) {
+	t.Parallel()
+
+	policy, err := ParsePolicy([]byte(`
+trusted_bots: ["Dependabot[bot]", renovate]
+trusted_orgs: [Octo-Org]
+trusted_teams: [octo-org/Maintainers]
+min_author_association: member
+repos:
+  Octo-Org/Website:
+    trusted_bots: []
+    min_author_association: COLLABORATOR
+`))
+	require.NoError(t, err)
+
+	defaults := policy.forRepo("octo-org", "octo-repo")
+	assert.Equal(t, []string{"dependabot", "renovate"}, defaults.TrustedBots)
+	assert.Equal(t, []string{"octo-org"}, defaults.TrustedOrgs)
+	assert.Equal(t, []string{"octo-org/maintainers"}, defaults.TrustedTeams)
+	assert.True(t, defaults.trustsBot("dependabot[bot]"))
+	assert.True(t, defaults.trustsBot("Renovate"))
+	assert.True(t, defaults.trustsAssociation("OWNER"))
+	assert.True(t, defaults.trustsAssociation("MEMBER"))
+	assert.False(t, defaults.trustsAssociation("COLLABORATOR"))
+	assert.False(t, defaults.trustsAssociation(""))
+
+	website := policy.forRepo("OCTO-ORG", "website")
+	assert.False(t, website.trustsBot("dependabot"), "an empty list clears the default")
+	assert.True(t, website.trustsAssociation("COLLABORATOR"))
+	assert.Equal(t, []string{"octo-org"}, website.TrustedOrgs, "fields not overridden are kept")
+
+	var unset *Policy
+	assert.Equal(t, RepoPolicy{}, unset.forRepo("octo-org", "octo-repo"))
+}
+
+func TestParsePolicyRejectsInvalidPolicies(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"unknown field":           "trusted_users: [octocat]",
+		"team without org":        "trusted_teams: [maintainers]",
+		"org with slash":          "trusted_orgs: [octo-org/team]",
+		"unknown association":     "min_author_association: MAINTAINER",
+		"association of everyone": "min_author_association: NONE",
+		"repository without name": "repos: {octo-org: {trusted_bots: [renovate]}}",
+		"invalid override":        "repos: {octo-org/website: {trusted_teams: [maintainers]}}",
+	}
+	for name, policy := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			_, err := ParsePolicy([]byte(policy))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestLoadPolicy(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("trusted_bots: [renovate]\n"), 0o600))
+
+	policy, err := LoadPolicy(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"renovate"}, policy.TrustedBots)
+
+	_, err = LoadPolicy(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,279 @@
+package lockdown
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultRepoAccessCacheSize is the number of repositories whose access information the
+// in-memory store keeps by default.
+const DefaultRepoAccessCacheSize = 1000
+
+// RepoAccessEntry is the access information cached for a repository.
+type RepoAccessEntry struct {
+	IsPrivate bool `json:"is_private"`
+
+	// PushAccess records, by lowercase login, whether users have push access.
+	PushAccess map[string]bool `json:"push_access"`
+
+	// TrustedMembers records, by lowercase login, whether users belong to an organization
+	// or team trusted by the policy of the repository.
+	TrustedMembers map[string]bool `json:"trusted_members,omitempty"`
+}
+
+// clone returns a copy of the entry that can be changed without affecting readers of the
+// stored one.
+func (e *RepoAccessEntry) clone() *RepoAccessEntry {
+	c := &RepoAccessEntry{
+		IsPrivate:      e.IsPrivate,
+		PushAccess:     make(map[string]bool, len(e.PushAccess)+1),
+		TrustedMembers: maps.Clone(e.TrustedMembers),
+	}
+	maps.Copy(c.PushAccess, e.PushAccess)
+	return c
+}
+
+// RepoAccessStore keeps repository access information for RepoAccessCache, keyed by
+// lowercase "owner/repo". Entries are never changed once stored: the cache stores a new
+// entry instead. Implementations must be safe for concurrent use.
+//
+// Stores that implement Evictions() int64 have it reported in the cache statistics.
+type RepoAccessStore interface {
+	// Get returns the entry of a repository, unless it is missing or has expired.
+	Get(key string) (*RepoAccessEntry, bool)
+
+	// Set stores the entry of a repository, expiring it after ttl. A non-positive ttl
+	// disables expiration.
+	Set(key string, entry *RepoAccessEntry, ttl time.Duration)
+}
+
+// NewRepoAccessStore returns a store in files under dir if it is set, and otherwise in
+// memory holding up to maxEntries repositories (DefaultRepoAccessCacheSize if it is not
+// positive).
+func NewRepoAccessStore(dir string, maxEntries int) (RepoAccessStore, error) {
+	if dir != "" {
+		return NewFileRepoAccessStore(dir)
+	}
+	return NewMemoryRepoAccessStore(maxEntries), nil
+}
+
+// MemoryRepoAccessStore keeps repository access information in memory, evicting the least
+// recently used repository once it holds more than its size.
+type MemoryRepoAccessStore struct {
+	maxEntries int
+	evictions  atomic.Int64
+
+	mu      sync.Mutex
+	lru     *list.List // of *memoryRepoAccessEntry, most recently used first
+	entries map[string]*list.Element
+}
+
+type memoryRepoAccessEntry struct {
+	key       string
+	entry     *RepoAccessEntry
+	expiresAt time.Time
+}
+
+// NewMemoryRepoAccessStore creates a store holding up to maxEntries repositories, or
+// DefaultRepoAccessCacheSize if it is not positive.
+func NewMemoryRepoAccessStore(maxEntries int) *MemoryRepoAccessStore {
+	if maxEntries <= 0 {
+		maxEntries = DefaultRepoAccessCacheSize
+	}
+	return &MemoryRepoAccessStore{
+		maxEntries: maxEntries,
+		lru:        list.New(),
+		entries:    make(map[string]*list.Element),
+	}
+}
+
+// Get implements RepoAccessStore.
+func (s *MemoryRepoAccessStore) Get(key string) (*RepoAccessEntry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	elem, ok := s.entries[key]
+	if !ok {
+		return nil, false
+	}
+	e := elem.Value.(*memoryRepoAccessEntry)
+	if expired(e.expiresAt) {
+		s.remove(elem)
+		s.evictions.Add(1)
+		return nil, false
+	}
+	s.lru.MoveToFront(elem)
+	return e.entry, true
+}
+
+// Set implements RepoAccessStore.
+func (s *MemoryRepoAccessStore) Set(key string, entry *RepoAccessEntry, ttl time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if elem, ok := s.entries[key]; ok {
+		s.remove(elem)
+	}
+	s.entries[key] = s.lru.PushFront(&memoryRepoAccessEntry{key: key, entry: entry, expiresAt: expiry(ttl)})
+	for s.lru.Len() > s.maxEntries {
+		s.remove(s.lru.Back())
+		s.evictions.Add(1)
+	}
+}
+
+// Evictions returns the number of entries dropped after their TTL or to bound the store.
+func (s *MemoryRepoAccessStore) Evictions() int64 {
+	return s.evictions.Load()
+}
+
+// remove must be called with s.mu held.
+func (s *MemoryRepoAccessStore) remove(elem *list.Element) {
+	s.lru.Remove(elem)
+	delete(s.entries, elem.Value.(*memoryRepoAccessEntry).key)
+}
+
+// repoAccessTempPrefix names the files entries are written to before they are complete.
+const repoAccessTempPrefix = ".tmp-"
+
+// FileRepoAccessStore keeps repository access information in files under a directory.
+// Several servers can share the directory, for example on a volume mounted by every replica
+// of the HTTP server, so that access resolved by one of them is reused by the others. As
+// entries are replaced whole, concurrent updates of a repository may drop users added by
+// another server, who are then queried again.
+//
+// The directory and files are only accessible to the owner, as they hold the visibility of
+// private repositories.
+type FileRepoAccessStore struct {
+	dir       string
+	evictions atomic.Int64
+}
+
+type fileRepoAccessEntry struct {
+	Entry     *RepoAccessEntry `json:"entry"`
+	ExpiresAt time.Time        `json:"expires_at,omitzero"`
+}
+
+// NewFileRepoAccessStore creates a store under dir, which is created if it doesn't exist.
+// Expired entries are removed.
+func NewFileRepoAccessStore(dir string) (*FileRepoAccessStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create repo access cache directory: %w", err)
+	}
+	s := &FileRepoAccessStore{dir: dir}
+	if err := s.prune(); err != nil {
+		return nil, fmt.Errorf("failed to read repo access cache directory: %w", err)
+	}
+	return s, nil
+}
+
+// Get implements RepoAccessStore.
+func (s *FileRepoAccessStore) Get(key string) (*RepoAccessEntry, bool) {
+	path := s.path(key)
+	e, err := readRepoAccessFile(path)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			_ = os.Remove(path)
+		}
+		return nil, false
+	}
+	if expired(e.ExpiresAt) {
+		if os.Remove(path) == nil {
+			s.evictions.Add(1)
+		}
+		return nil, false
+	}
+	return e.Entry, true
+}
+
+// Set implements RepoAccessStore.
+func (s *FileRepoAccessStore) Set(key string, entry *RepoAccessEntry, ttl time.Duration) {
+	data, err := json.Marshal(fileRepoAccessEntry{Entry: entry, ExpiresAt: expiry(ttl)})
+	if err != nil {
+		return
+	}
+
+	// Write to a temporary file and rename, so readers never see a partial entry
+	tmp, err := os.CreateTemp(s.dir, repoAccessTempPrefix+"*")
+	if err != nil {
+		return
+	}
+	_, writeErr := tmp.Write(data)
+	closeErr := tmp.Close()
+	if writeErr != nil || closeErr != nil || os.Rename(tmp.Name(), s.path(key)) != nil {
+		_ = os.Remove(tmp.Name())
+	}
+}
+
+// Evictions returns the number of expired entries this store removed.
+func (s *FileRepoAccessStore) Evictions() int64 {
+	return s.evictions.Load()
+}
+
+// path names entry files after a hash of the key, which contains a slash.
+func (s *FileRepoAccessStore) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(s.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// prune removes expired entries, along with temporary files left behind by a crash.
+func (s *FileRepoAccessStore) prune() error {
+	dirEntries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return err
+	}
+	for _, d := range dirEntries {
+		if d.IsDir() {
+			continue
+		}
+		path := filepath.Join(s.dir, d.Name())
+		if strings.HasPrefix(d.Name(), repoAccessTempPrefix) {
+			if info, err := d.Info(); err == nil && time.Since(info.ModTime()) > time.Hour {
+				_ = os.Remove(path)
+			}
+			continue
+		}
+		if filepath.Ext(path) != ".json" {
+			continue
+		}
+		if e, err := readRepoAccessFile(path); err == nil && expired(e.ExpiresAt) && os.Remove(path) == nil {
+			s.evictions.Add(1)
+		}
+	}
+	return nil
+}
+
+func readRepoAccessFile(path string) (*fileRepoAccessEntry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var e fileRepoAccessEntry
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, err
+	}
+	if e.Entry == nil {
+		return nil, fmt.Errorf("repo access cache file %s has no entry", path)
+	}
+	return &e, nil
+}
+
+func expiry(ttl time.Duration) time.Time {
+	if ttl <= 0 {
+		return time.Time{}
+	}
+	return time.Now().Add(ttl)
+}
+
+func expired(expiresAt time.Time) bool {
+	return !expiresAt.IsZero() && time.Now().After(expiresAt)
+}
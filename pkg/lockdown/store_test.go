@@ -0,0 +1,86 @@
+package lockdown
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func pushAccess(login string) *RepoAccessEntry {
+	return &RepoAccessEntry{PushAccess: map[string]bool{login: true}}
+}
+
+func TestMemoryRepoAccessStoreEvictsLeastRecentlyUsed(t *testing.T) {
+	t.Parallel()
+	store := NewMemoryRepoAccessStore(2)
+	store.Set("o/a", pushAccess("a"), time.Minute)
+	store.Set("o/b", pushAccess("b"), time.Minute)
+	_, _ = store.Get("o/a")
+	store.Set("o/c", pushAccess("c"), time.Minute)
+
+	_, ok := store.Get("o/b")
+	assert.False(t, ok, "the least recently used entry is evicted")
+	got, ok := store.Get("o/a")
+	require.True(t, ok)
+	assert.True(t, got.PushAccess["a"])
+	_, ok = store.Get("o/c")
+	assert.True(t, ok)
+	assert.EqualValues(t, 1, store.Evictions())
+
+	store.Set("o/expiring", pushAccess("d"), time.Nanosecond)
+	time.Sleep(time.Millisecond)
+	_, ok = store.Get("o/expiring")
+	assert.False(t, ok, "expired entries are not returned")
+	assert.EqualValues(t, 3, store.Evictions())
+}
+
+func TestFileRepoAccessStore(t *testing.T) {
+	t.Parallel()
+	dir := filepath.Join(t.TempDir(), "lockdown")
+
+	store, err := NewFileRepoAccessStore(dir)
+	require.NoError(t, err)
+	store.Set("o/r", &RepoAccessEntry{IsPrivate: true, PushAccess: map[string]bool{"octocat": false}}, time.Minute)
+	store.Set("o/expiring", pushAccess("a"), time.Nanosecond)
+	store.Set("o/forever", pushAccess("b"), 0)
+
+	info, err := os.Stat(dir)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o700), info.Mode().Perm())
+	info, err = os.Stat(store.path("o/r"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	// Another store over the same directory, such as another replica, sees the entries
+	time.Sleep(time.Millisecond)
+	other, err := NewFileRepoAccessStore(dir)
+	require.NoError(t, err)
+	got, ok := other.Get("o/r")
+	require.True(t, ok)
+	assert.True(t, got.IsPrivate)
+	assert.Equal(t, map[string]bool{"octocat": false}, got.PushAccess)
+	_, ok = other.Get("o/forever")
+	assert.True(t, ok)
+
+	assert.NoFileExists(t, store.path("o/expiring"), "expired entries are removed when the store is opened")
+	assert.EqualValues(t, 1, other.Evictions())
+	_, ok = store.Get("o/expiring")
+	assert.False(t, ok)
+}
+
+func TestNewRepoAccessStore(t *testing.T) {
+	t.Parallel()
+
+	store, err := NewRepoAccessStore("", 0)
+	require.NoError(t, err)
+	require.IsType(t, &MemoryRepoAccessStore{}, store)
+	assert.Equal(t, DefaultRepoAccessCacheSize, store.(*MemoryRepoAccessStore).maxEntries)
+
+	store, err = NewRepoAccessStore(t.TempDir(), 10)
+	require.NoError(t, err)
+	assert.IsType(t, &FileRepoAccessStore{}, store)
+}
@@ -0,0 +1,156 @@
+// Package metrics exposes server metrics for Prometheus.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/github/github-mcp-server/pkg/lockdown"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Label values for the api label of GitHub request metrics.
+const (
+	APIREST    = "rest"
+	APIGraphQL = "graphql"
+	APIRaw     = "raw"
+)
+
+// ErrorClassNone is the error class recorded for tool calls that succeeded.
+const ErrorClassNone = "none"
+
+// latencyBuckets are histogram bucket upper bounds, in seconds, suited to request latencies.
+var latencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+// Metrics is the set of metrics recorded by the server.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	mcpRequests        *prometheus.CounterVec
+	toolCalls          *prometheus.CounterVec
+	toolCallDuration   *prometheus.HistogramVec
+	githubRequests     *prometheus.CounterVec
+	rateLimitRemaining *prometheus.GaugeVec
+	rateLimitLimit     *prometheus.GaugeVec
+	activeSessions     prometheus.Gauge
+}
+
+// New creates the server metrics in a fresh registry.
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		mcpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "github_mcp_requests_total",
+			Help: "MCP requests received, by method.",
+		}, []string{"method"}),
+		toolCalls: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "github_mcp_tool_calls_total",
+			Help: "Tool calls, by tool and error class. Successful calls have error class \"none\".",
+		}, []string{"tool", "error_class"}),
+		toolCallDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "github_mcp_tool_call_duration_seconds",
+			Help:    "Tool call latency in seconds.",
+			Buckets: latencyBuckets,
+		}, []string{"tool"}),
+		githubRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "github_mcp_github_requests_total",
+			Help: "Requests made to the GitHub API, by API and HTTP status. Transport failures have status \"error\".",
+		}, []string{"api", "status"}),
+		rateLimitRemaining: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "github_mcp_github_rate_limit_remaining",
+			Help: "Requests remaining in the current GitHub rate limit window, as last reported by the API.",
+		}, []string{"resource"}),
+		rateLimitLimit: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "github_mcp_github_rate_limit_limit",
+			Help: "Requests allowed per GitHub rate limit window, as last reported by the API.",
+		}, []string{"resource"}),
+		activeSessions: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "github_mcp_active_sessions",
+			Help: "Active MCP sessions. In stateless HTTP mode, each in-flight request is a session.",
+		}),
+	}
+	m.registry.MustRegister(
+		m.mcpRequests,
+		m.toolCalls,
+		m.toolCallDuration,
+		m.githubRequests,
+		m.rateLimitRemaining,
+		m.rateLimitLimit,
+		m.activeSessions,
+	)
+	return m
+}
+
+// Registry returns the registry holding the metrics, for registering additional metrics.
+func (m *Metrics) Registry() *prometheus.Registry {
+	return m.registry
+}
+
+// Handler serves the metrics for scraping.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// RegisterLockdownCache exports the lockdown repository access cache counters, read from
+// stats at scrape time.
+func (m *Metrics) RegisterLockdownCache(stats func() lockdown.CacheStats) {
+	m.registry.MustRegister(
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name: "github_mcp_lockdown_cache_hits_total",
+			Help: "Lockdown repository access lookups served from the cache.",
+		}, func() float64 { return float64(stats().Hits) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name: "github_mcp_lockdown_cache_misses_total",
+			Help: "Lockdown repository access lookups that queried the GitHub API.",
+		}, func() float64 { return float64(stats().Misses) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name: "github_mcp_lockdown_cache_evictions_total",
+			Help: "Lockdown repository access cache entries evicted after their TTL or to bound the cache size.",
+		}, func() float64 { return float64(stats().Evictions) }),
+	)
+}
+
+// ObserveMCPRequest records an MCP request.
+func (m *Metrics) ObserveMCPRequest(method string) {
+	m.mcpRequests.WithLabelValues(method).Inc()
+}
+
+// ObserveToolCall records a completed tool call.
+func (m *Metrics) ObserveToolCall(tool, errorClass string, duration time.Duration) {
+	m.toolCalls.WithLabelValues(tool, errorClass).Inc()
+	m.toolCallDuration.WithLabelValues(tool).Observe(duration.Seconds())
+}
+
+// ObserveGitHubResponse records a request to the GitHub API. resp is nil when the request
+// failed before a response was received. Rate limit headers, when present, update the
+// rate limit gauges.
+func (m *Metrics) ObserveGitHubResponse(api string, resp *http.Response) {
+	if resp == nil {
+		m.githubRequests.WithLabelValues(api, "error").Inc()
+		return
+	}
+	m.githubRequests.WithLabelValues(api, strconv.Itoa(resp.StatusCode)).Inc()
+
+	resource := resp.Header.Get("X-RateLimit-Resource")
+	if resource == "" {
+		return
+	}
+	if remaining, err := strconv.Atoi(resp.Header.Get("X-RateLimit-Remaining")); err == nil {
+		m.rateLimitRemaining.WithLabelValues(resource).Set(float64(remaining))
+	}
+	if limit, err := strconv.Atoi(resp.Header.Get("X-RateLimit-Limit")); err == nil {
+		m.rateLimitLimit.WithLabelValues(resource).Set(float64(limit))
+	}
+}
+
+// SessionStarted increments the active session gauge.
+func (m *Metrics) SessionStarted() {
+	m.activeSessions.Inc()
+}
+
+// SessionEnded decrements the active session gauge.
+func (m *Metrics) SessionEnded() {
+	m.activeSessions.Dec()
+}
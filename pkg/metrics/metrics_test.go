@@ -0,0 +1,49 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/github/github-mcp-server/pkg/lockdown"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetrics(t *testing.T) {
+	m := New()
+	m.RegisterLockdownCache(func() lockdown.CacheStats {
+		return lockdown.CacheStats{Hits: 7, Misses: 3, Evictions: 1}
+	})
+
+	m.ObserveMCPRequest("tools/call")
+	m.ObserveToolCall("get_me", ErrorClassNone, 30*time.Millisecond)
+	m.ObserveGitHubResponse(APIREST, &http.Response{StatusCode: http.StatusOK, Header: http.Header{
+		"X-Ratelimit-Resource":  []string{"core"},
+		"X-Ratelimit-Remaining": []string{"4990"},
+		"X-Ratelimit-Limit":     []string{"5000"},
+	}})
+	m.ObserveGitHubResponse(APIGraphQL, nil)
+	m.SessionStarted()
+
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Contains(t, rec.Header().Get("Content-Type"), "text/plain; version=0.0.4")
+
+	for _, line := range []string{
+		`github_mcp_requests_total{method="tools/call"} 1`,
+		`github_mcp_tool_calls_total{error_class="none",tool="get_me"} 1`,
+		`github_mcp_tool_call_duration_seconds_bucket{tool="get_me",le="0.05"} 1`,
+		`github_mcp_tool_call_duration_seconds_bucket{tool="get_me",le="0.025"} 0`,
+		`github_mcp_github_requests_total{api="rest",status="200"} 1`,
+		`github_mcp_github_requests_total{api="graphql",status="error"} 1`,
+		`github_mcp_github_rate_limit_remaining{resource="core"} 4990`,
+		`github_mcp_github_rate_limit_limit{resource="core"} 5000`,
+		`github_mcp_active_sessions 1`,
+		`github_mcp_lockdown_cache_hits_total 7`,
+		`github_mcp_lockdown_cache_misses_total 3`,
+		`github_mcp_lockdown_cache_evictions_total 1`,
+	} {
+		assert.Contains(t, rec.Body.String(), line+"\n")
+	}
+}
@@ -0,0 +1,295 @@
+// Package prompts loads user-defined prompt templates from YAML and Markdown files.
+package prompts
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.yaml.in/yaml/v3"
+)
+
+var namePattern = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// Argument describes a single prompt argument.
+type Argument struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+	Required    bool   `yaml:"required"`
+	// Values restricts the argument to a fixed set of values. The values are also
+	// offered as completions.
+	Values []string `yaml:"values,omitempty"`
+	// Complete names a dynamic completion source for the argument, such as "owner" or "repo".
+	Complete string `yaml:"complete,omitempty"`
+}
+
+// Message is a templated prompt message. Content is a Go text/template that
+// receives the prompt arguments as a map, e.g. {{.owner}}.
+type Message struct {
+	Role    string `yaml:"role"`
+	Content string `yaml:"content"`
+}
+
+// Definition is a prompt loaded from a file.
+type Definition struct {
+	Name        string     `yaml:"name"`
+	Title       string     `yaml:"title,omitempty"`
+	Description string     `yaml:"description"`
+	Toolset     string     `yaml:"toolset,omitempty"`
+	Arguments   []Argument `yaml:"arguments,omitempty"`
+	Messages    []Message  `yaml:"messages,omitempty"`
+
+	// Source is the file the definition was loaded from.
+	Source string `yaml:"-"`
+
+	templates []*template.Template
+}
+
+// LoadDir loads every .yaml, .yml and .md prompt definition in dir, sorted by prompt name.
+// Subdirectories are not traversed. Duplicate prompt names are an error.
+func LoadDir(dir string) ([]Definition, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read prompts directory %s: %w", dir, err)
+	}
+
+	var definitions []Definition
+	seen := make(map[string]string)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		switch strings.ToLower(filepath.Ext(entry.Name())) {
+		case ".yaml", ".yml", ".md":
+		default:
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		def, err := LoadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if other, ok := seen[def.Name]; ok {
+			return nil, fmt.Errorf("prompt %q in %s is already defined in %s", def.Name, path, other)
+		}
+		seen[def.Name] = path
+		definitions = append(definitions, def)
+	}
+
+	sort.Slice(definitions, func(i, j int) bool {
+		return definitions[i].Name < definitions[j].Name
+	})
+	return definitions, nil
+}
+
+// LoadFile loads a single prompt definition. Markdown files carry the definition in
+// YAML front matter and use the body as a final user message.
+func LoadFile(path string) (Definition, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // prompt paths come from operator configuration
+	if err != nil {
+		return Definition{}, fmt.Errorf("failed to read prompt file %s: %w", path, err)
+	}
+
+	var def Definition
+	if strings.EqualFold(filepath.Ext(path), ".md") {
+		def, err = ParseMarkdown(data)
+	} else {
+		def, err = ParseYAML(data)
+	}
+	if err != nil {
+		return Definition{}, fmt.Errorf("invalid prompt file %s: %w", path, err)
+	}
+	def.Source = path
+	return def, nil
+}
+
+// ParseYAML parses and validates a YAML prompt definition.
+func ParseYAML(data []byte) (Definition, error) {
+	var def Definition
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&def); err != nil {
+		return Definition{}, fmt.Errorf("failed to parse YAML: %w", err)
+	}
+	if err := def.compile(); err != nil {
+		return Definition{}, err
+	}
+	return def, nil
+}
+
+// ParseMarkdown parses a Markdown prompt definition. The file must start with YAML
+// front matter delimited by "---" lines; the remaining body becomes a user message.
+func ParseMarkdown(data []byte) (Definition, error) {
+	text := strings.ReplaceAll(string(data), "\r\n", "\n")
+	if !strings.HasPrefix(text, "---\n") {
+		return Definition{}, errors.New("markdown prompt must start with YAML front matter")
+	}
+	frontMatter, body, found := strings.Cut(text[len("---\n"):], "\n---\n")
+	if !found {
+		frontMatter, found = strings.CutSuffix(text[len("---\n"):], "\n---")
+		if !found {
+			return Definition{}, errors.New("unterminated YAML front matter")
+		}
+		body = ""
+	}
+
+	var def Definition
+	decoder := yaml.NewDecoder(strings.NewReader(frontMatter))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&def); err != nil {
+		return Definition{}, fmt.Errorf("failed to parse front matter: %w", err)
+	}
+	if body = strings.TrimSpace(body); body != "" {
+		def.Messages = append(def.Messages, Message{Role: "user", Content: body})
+	}
+	if err := def.compile(); err != nil {
+		return Definition{}, err
+	}
+	return def, nil
+}
+
+// compile validates the definition and parses its message templates.
+func (d *Definition) compile() error {
+	if !namePattern.MatchString(d.Name) {
+		return fmt.Errorf("prompt name %q must be non-empty and contain only letters, digits, '_' or '-'", d.Name)
+	}
+	if d.Description == "" {
+		return fmt.Errorf("prompt %q has no description", d.Name)
+	}
+	if len(d.Messages) == 0 {
+		return fmt.Errorf("prompt %q has no messages", d.Name)
+	}
+
+	seen := make(map[string]bool, len(d.Arguments))
+	for _, arg := range d.Arguments {
+		if !namePattern.MatchString(arg.Name) {
+			return fmt.Errorf("prompt %q has an invalid argument name %q", d.Name, arg.Name)
+		}
+		if seen[arg.Name] {
+			return fmt.Errorf("prompt %q declares argument %q more than once", d.Name, arg.Name)
+		}
+		seen[arg.Name] = true
+	}
+
+	d.templates = make([]*template.Template, 0, len(d.Messages))
+	for i, msg := range d.Messages {
+		if msg.Role != "user" && msg.Role != "assistant" {
+			return fmt.Errorf("prompt %q message %d has invalid role %q (must be user or assistant)", d.Name, i, msg.Role)
+		}
+		tmpl, err := template.New(fmt.Sprintf("%s[%d]", d.Name, i)).Option("missingkey=zero").Parse(msg.Content)
+		if err != nil {
+			return fmt.Errorf("prompt %q message %d has an invalid template: %w", d.Name, i, err)
+		}
+		d.templates = append(d.templates, tmpl)
+	}
+	return nil
+}
+
+// Argument returns the named argument definition.
+func (d Definition) Argument(name string) (Argument, bool) {
+	for _, arg := range d.Arguments {
+		if arg.Name == name {
+			return arg, true
+		}
+	}
+	return Argument{}, false
+}
+
+// Prompt returns the MCP prompt description for the definition.
+func (d Definition) Prompt() mcp.Prompt {
+	args := make([]*mcp.PromptArgument, 0, len(d.Arguments))
+	for _, arg := range d.Arguments {
+		description := arg.Description
+		if len(arg.Values) > 0 {
+			description = strings.TrimSpace(fmt.Sprintf("%s (one of: %s)", description, strings.Join(arg.Values, ", ")))
+		}
+		args = append(args, &mcp.PromptArgument{
+			Name:        arg.Name,
+			Description: description,
+			Required:    arg.Required,
+		})
+	}
+	return mcp.Prompt{
+		Name:        d.Name,
+		Title:       d.Title,
+		Description: d.Description,
+		Arguments:   args,
+	}
+}
+
+// ValidateArguments checks supplied arguments against the definition: required
+// arguments must be non-empty, constrained arguments must use an allowed value, and
+// undeclared arguments are rejected.
+func (d Definition) ValidateArguments(args map[string]string) error {
+	var problems []string
+	for name := range args {
+		if _, ok := d.Argument(name); !ok {
+			problems = append(problems, fmt.Sprintf("unknown argument %q", name))
+		}
+	}
+	for _, arg := range d.Arguments {
+		value, ok := args[arg.Name]
+		if arg.Required && (!ok || strings.TrimSpace(value) == "") {
+			problems = append(problems, fmt.Sprintf("missing required argument %q", arg.Name))
+			continue
+		}
+		if ok && value != "" && len(arg.Values) > 0 && !slices.Contains(arg.Values, value) {
+			problems = append(problems, fmt.Sprintf("argument %q must be one of: %s", arg.Name, strings.Join(arg.Values, ", ")))
+		}
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return fmt.Errorf("invalid arguments for prompt %q: %s", d.Name, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// Render validates the arguments and executes the message templates.
+func (d Definition) Render(args map[string]string) ([]*mcp.PromptMessage, error) {
+	if err := d.ValidateArguments(args); err != nil {
+		return nil, err
+	}
+
+	// Every declared argument is present so optional ones can be tested with {{if .name}}.
+	data := make(map[string]string, len(d.Arguments))
+	for _, arg := range d.Arguments {
+		data[arg.Name] = args[arg.Name]
+	}
+
+	messages := make([]*mcp.PromptMessage, 0, len(d.templates))
+	for i, tmpl := range d.templates {
+		var buf strings.Builder
+		if err := tmpl.Execute(&buf, data); err != nil {
+			return nil, fmt.Errorf("failed to render prompt %q message %d: %w", d.Name, i, err)
+		}
+		messages = append(messages, &mcp.PromptMessage{
+			Role:    mcp.Role(d.Messages[i].Role),
+			Content: &mcp.TextContent{Text: buf.String()},
+		})
+	}
+	return messages, nil
+}
+
+// CompleteValues returns the allowed values of the named argument that start with prefix.
+func (d Definition) CompleteValues(argName, prefix string) []string {
+	arg, ok := d.Argument(argName)
+	if !ok {
+		return nil
+	}
+	var values []string
+	for _, v := range arg.Values {
+		if strings.HasPrefix(v, prefix) {
+			values = append(values, v)
+		}
+	}
+	return values
+}
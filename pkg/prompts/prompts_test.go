@@ -0,0 +1,247 @@
+package prompts
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const releaseChecklistYAML = `
+name: release_checklist
+title: Release checklist
+description: Walk through the release checklist for a repository
+toolset: repos
+arguments:
+  - name: owner
+    description: Repository owner
+    required: true
+    complete: owner
+  - name: repo
+    description: Repository name
+    required: true
+    complete: repo
+  - name: kind
+    description: Release kind
+    values: [major, minor, patch]
+messages:
+  - role: user
+    content: "Prepare a {{if .kind}}{{.kind}}{{else}}patch{{end}} release of {{.owner}}/{{.repo}}."
+  - role: assistant
+    content: I'll start by listing the merged pull requests since the last tag.
+`
+
+const triageMarkdown = `---
+name: triage
+description: Triage new issues
+toolset: issues
+arguments:
+  - name: owner
+    required: true
+  - name: repo
+    required: true
+---
+Triage the open issues in {{.owner}}/{{.repo}} that have no labels.
+`
+
+func TestParseYAML(t *testing.T) {
+	def, err := ParseYAML([]byte(releaseChecklistYAML))
+	require.NoError(t, err)
+
+	assert.Equal(t, "release_checklist", def.Name)
+	assert.Equal(t, "repos", def.Toolset)
+	require.Len(t, def.Arguments, 3)
+	assert.Equal(t, "owner", def.Arguments[0].Complete)
+
+	prompt := def.Prompt()
+	assert.Equal(t, "Release checklist", prompt.Title)
+	require.Len(t, prompt.Arguments, 3)
+	assert.True(t, prompt.Arguments[0].Required)
+	assert.Equal(t, "Release kind (one of: major, minor, patch)", prompt.Arguments[2].Description)
+}
+
+func TestParseMarkdown(t *testing.T) {
+	def, err := ParseMarkdown([]byte(triageMarkdown))
+	require.NoError(t, err)
+
+	assert.Equal(t, "triage", def.Name)
+	assert.Equal(t, "issues", def.Toolset)
+	require.Len(t, def.Messages, 1)
+	assert.Equal(t, "user", def.Messages[0].Role)
+
+	messages, err := def.Render(map[string]string{"owner": "octo", "repo": "hello"})
+	require.NoError(t, err)
+	require.Len(t, messages, 1)
+	assert.Equal(t, "Triage the open issues in octo/hello that have no labels.", messages[0].Content.(*mcp.TextContent).Text)
+}
+
+func TestParseInvalidDefinitions(t *testing.T) {
+	tests := []struct {
+		name        string
+		markdown    bool
+		input       string
+		expectedErr string
+	}{
+		{
+			name:        "missing name",
+			input:       "description: d\nmessages: [{role: user, content: hi}]",
+			expectedErr: "prompt name",
+		},
+		{
+			name:        "invalid name",
+			input:       "name: bad name\ndescription: d\nmessages: [{role: user, content: hi}]",
+			expectedErr: "prompt name",
+		},
+		{
+			name:        "missing description",
+			input:       "name: p\nmessages: [{role: user, content: hi}]",
+			expectedErr: "has no description",
+		},
+		{
+			name:        "no messages",
+			input:       "name: p\ndescription: d",
+			expectedErr: "has no messages",
+		},
+		{
+			name:        "invalid role",
+			input:       "name: p\ndescription: d\nmessages: [{role: system, content: hi}]",
+			expectedErr: "invalid role",
+		},
+		{
+			name:        "invalid template",
+			input:       "name: p\ndescription: d\nmessages: [{role: user, content: '{{.owner'}]",
+			expectedErr: "invalid template",
+		},
+		{
+			name:        "duplicate argument",
+			input:       "name: p\ndescription: d\narguments: [{name: a}, {name: a}]\nmessages: [{role: user, content: hi}]",
+			expectedErr: "more than once",
+		},
+		{
+			name:        "unknown field",
+			input:       "name: p\ndescription: d\nmesages: []",
+			expectedErr: "failed to parse YAML",
+		},
+		{
+			name:        "markdown without front matter",
+			markdown:    true,
+			input:       "Just a body",
+			expectedErr: "must start with YAML front matter",
+		},
+		{
+			name:        "markdown with unterminated front matter",
+			markdown:    true,
+			input:       "---\nname: p\ndescription: d\n",
+			expectedErr: "unterminated YAML front matter",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var err error
+			if tc.markdown {
+				_, err = ParseMarkdown([]byte(tc.input))
+			} else {
+				_, err = ParseYAML([]byte(tc.input))
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.expectedErr)
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	def, err := ParseYAML([]byte(releaseChecklistYAML))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name          string
+		args          map[string]string
+		expectedFirst string
+		expectedErr   string
+	}{
+		{
+			name:          "optional argument omitted",
+			args:          map[string]string{"owner": "octo", "repo": "hello"},
+			expectedFirst: "Prepare a patch release of octo/hello.",
+		},
+		{
+			name:          "optional argument provided",
+			args:          map[string]string{"owner": "octo", "repo": "hello", "kind": "major"},
+			expectedFirst: "Prepare a major release of octo/hello.",
+		},
+		{
+			name:        "missing required argument",
+			args:        map[string]string{"owner": "octo"},
+			expectedErr: `missing required argument "repo"`,
+		},
+		{
+			name:        "blank required argument",
+			args:        map[string]string{"owner": "octo", "repo": "  "},
+			expectedErr: `missing required argument "repo"`,
+		},
+		{
+			name:        "value outside allowed set",
+			args:        map[string]string{"owner": "octo", "repo": "hello", "kind": "huge"},
+			expectedErr: `argument "kind" must be one of: major, minor, patch`,
+		},
+		{
+			name:        "unknown argument",
+			args:        map[string]string{"owner": "octo", "repo": "hello", "branch": "main"},
+			expectedErr: `unknown argument "branch"`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			messages, err := def.Render(tc.args)
+			if tc.expectedErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, messages, 2)
+			assert.Equal(t, mcp.Role("user"), messages[0].Role)
+			assert.Equal(t, tc.expectedFirst, messages[0].Content.(*mcp.TextContent).Text)
+			assert.Equal(t, mcp.Role("assistant"), messages[1].Role)
+		})
+	}
+}
+
+func TestCompleteValues(t *testing.T) {
+	def, err := ParseYAML([]byte(releaseChecklistYAML))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"major", "minor"}, def.CompleteValues("kind", "m"))
+	assert.Equal(t, []string{"patch"}, def.CompleteValues("kind", "p"))
+	assert.Empty(t, def.CompleteValues("owner", ""))
+	assert.Empty(t, def.CompleteValues("missing", ""))
+}
+
+func TestLoadDir(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "release.yaml"), []byte(releaseChecklistYAML), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "triage.md"), []byte(triageMarkdown), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("ignored"), 0600))
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "nested"), 0700))
+
+	defs, err := LoadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, defs, 2)
+	assert.Equal(t, "release_checklist", defs[0].Name)
+	assert.Equal(t, filepath.Join(dir, "release.yaml"), defs[0].Source)
+	assert.Equal(t, "triage", defs[1].Name)
+
+	// A second file defining the same prompt name is rejected
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "triage-copy.md"), []byte(triageMarkdown), 0600))
+	_, err = LoadDir(dir)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `prompt "triage"`)
+
+	_, err = LoadDir(filepath.Join(dir, "does-not-exist"))
+	require.Error(t, err)
+}
@@ -0,0 +1,218 @@
+package scopes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/github/github-mcp-server/pkg/http/headers"
+	"github.com/github/github-mcp-server/pkg/utils"
+)
+
+// permissionProbe is a request whose outcome shows whether a token has a permission.
+// Write probes send requests that GitHub rejects during validation, after checking
+// permissions, so they never modify anything.
+type permissionProbe struct {
+	method string
+	// path is relative to the REST API. {repo} is replaced with the full name of a
+	// repository the token can access.
+	path string
+	body string
+}
+
+// permissionProbes lists the permissions that can be probed. Read probes are cheap list
+// requests for a single item.
+var permissionProbes = map[Permission]permissionProbe{
+	ContentsRead:             {method: http.MethodGet, path: "repos/{repo}/commits?per_page=1"},
+	ContentsWrite:            {method: http.MethodPut, path: "repos/{repo}/contents/.github-mcp-server-permission-probe", body: "{}"},
+	IssuesRead:               {method: http.MethodGet, path: "repos/{repo}/issues?per_page=1"},
+	IssuesWrite:              {method: http.MethodPost, path: "repos/{repo}/issues", body: "{}"},
+	PullRequestsRead:         {method: http.MethodGet, path: "repos/{repo}/pulls?per_page=1"},
+	PullRequestsWrite:        {method: http.MethodPost, path: "repos/{repo}/pulls", body: "{}"},
+	ActionsRead:              {method: http.MethodGet, path: "repos/{repo}/actions/runs?per_page=1"},
+	SecurityEventsRead:       {method: http.MethodGet, path: "repos/{repo}/code-scanning/alerts?per_page=1"},
+	SecretScanningAlertsRead: {method: http.MethodGet, path: "repos/{repo}/secret-scanning/alerts?per_page=1"},
+	VulnerabilityAlertsRead:  {method: http.MethodGet, path: "repos/{repo}/dependabot/alerts?per_page=1"},
+	RepositoryAdvisoriesRead: {method: http.MethodGet, path: "repos/{repo}/security-advisories?per_page=1"},
+	StarringRead:             {method: http.MethodGet, path: "user/starred?per_page=1"},
+	NotificationsRead:        {method: http.MethodGet, path: "notifications?per_page=1"},
+}
+
+// PermissionProber determines the fine-grained permissions of a token. GitHub has no API
+// that lists them, so the prober makes requests that need each permission and checks
+// which are refused.
+type PermissionProber struct {
+	client  *http.Client
+	apiHost utils.APIHostResolver
+}
+
+// NewPermissionProber creates a permission prober with the given options.
+func NewPermissionProber(apiHost utils.APIHostResolver, opts FetcherOptions) *PermissionProber {
+	client := opts.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: DefaultFetchTimeout}
+	}
+
+	return &PermissionProber{
+		client:  client,
+		apiHost: apiHost,
+	}
+}
+
+// ProbePermissions determines which of the wanted permissions the token has. Repository
+// permissions are probed on a repository the token can access; they are the same for all
+// repositories a fine-grained token is granted. Permissions that can't be probed, or
+// whose probe is inconclusive, are left out of the result. Write permissions are known
+// to be missing when their read permission is.
+func (p *PermissionProber) ProbePermissions(ctx context.Context, token string, wanted []Permission) (PermissionSet, error) {
+	baseURL, err := p.apiHost.BaseRESTURL(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get API host URL: %w", err)
+	}
+
+	repo, err := p.probeRepository(ctx, baseURL, token)
+	if err != nil {
+		return nil, err
+	}
+
+	// Probe read permissions first, as write access is only possible with read access
+	var reads, writes []Permission
+	for _, permission := range wanted {
+		if permission.IsWrite() {
+			writes = append(writes, permission)
+			if !slices.Contains(wanted, permission.Read()) {
+				reads = append(reads, permission.Read())
+			}
+		} else {
+			reads = append(reads, permission)
+		}
+	}
+
+	result := make(PermissionSet)
+	p.probeAll(ctx, baseURL, token, repo, reads, result)
+
+	var writesToProbe []Permission
+	for _, permission := range writes {
+		if granted, known := result[permission.Read()]; known && !granted {
+			result[permission] = false
+			continue
+		}
+		writesToProbe = append(writesToProbe, permission)
+	}
+	p.probeAll(ctx, baseURL, token, repo, writesToProbe, result)
+
+	// Only report what was asked for
+	for permission := range result {
+		if !slices.Contains(wanted, permission) {
+			delete(result, permission)
+		}
+	}
+	return result, nil
+}
+
+// probeRepository returns the full name of a repository the token can access, or an
+// empty string when there is none. It also checks that the token is valid.
+func (p *PermissionProber) probeRepository(ctx context.Context, baseURL *url.URL, token string) (string, error) {
+	resp, err := p.do(ctx, baseURL, token, permissionProbe{method: http.MethodGet, path: "user/repos?per_page=1&sort=pushed"})
+	if err != nil {
+		return "", fmt.Errorf("failed to list repositories: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return "", fmt.Errorf("invalid or expired token")
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", nil
+	}
+
+	var repos []struct {
+		FullName string `json:"full_name"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&repos); err != nil || len(repos) == 0 {
+		return "", nil
+	}
+	return repos[0].FullName, nil
+}
+
+// probeAll probes permissions concurrently, recording conclusive outcomes in result.
+func (p *PermissionProber) probeAll(ctx context.Context, baseURL *url.URL, token, repo string, permissions []Permission, result PermissionSet) {
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, permission := range permissions {
+		probe, ok := permissionProbes[permission]
+		if !ok || (strings.Contains(probe.path, "{repo}") && repo == "") {
+			continue
+		}
+		probe.path = strings.ReplaceAll(probe.path, "{repo}", repo)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if granted, known := p.probe(ctx, baseURL, token, probe); known {
+				mu.Lock()
+				result[permission] = granted
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// probe reports whether the probe shows the permission to be granted, and whether the
+// outcome is conclusive. Only refusals for lack of permission count as missing; other
+// client errors, such as a feature being disabled or a validation failure, mean the
+// request got past the permission check.
+func (p *PermissionProber) probe(ctx context.Context, baseURL *url.URL, token string, probe permissionProbe) (granted bool, known bool) {
+	resp, err := p.do(ctx, baseURL, token, probe)
+	if err != nil {
+		return false, false
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusForbidden:
+		var body struct {
+			Message string `json:"message"`
+		}
+		_ = json.NewDecoder(io.LimitReader(resp.Body, 64*1024)).Decode(&body)
+		if strings.HasPrefix(body.Message, "Resource not accessible") {
+			return false, true
+		}
+		// Other refusals, such as secondary rate limits, say nothing about permissions
+		return false, false
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode >= http.StatusInternalServerError:
+		return false, false
+	default:
+		return true, true
+	}
+}
+
+func (p *PermissionProber) do(ctx context.Context, baseURL *url.URL, token string, probe permissionProbe) (*http.Response, error) {
+	endpoint, err := baseURL.Parse(probe.path)
+	if err != nil {
+		return nil, err
+	}
+
+	var body io.Reader
+	if probe.body != "" {
+		body = strings.NewReader(probe.body)
+	}
+	req, err := http.NewRequestWithContext(ctx, probe.method, endpoint.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set(headers.AuthorizationHeader, "Bearer "+token)
+	req.Header.Set(headers.AcceptHeader, "application/vnd.github+json")
+	req.Header.Set(headers.GitHubAPIVersionHeader, "2022-11-28")
+	if body != nil {
+		req.Header.Set(headers.ContentTypeHeader, "application/json")
+	}
+	return p.client.Do(req)
+}
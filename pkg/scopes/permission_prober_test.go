@@ -0,0 +1,104 @@
+package scopes
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const notAccessible = `{"message":"Resource not accessible by personal access token"}`
+
+// newProbeServer serves octo/repo to a token that can read and write issues, read
+// contents, and has no access to pull requests or notifications. It records the requests
+// it receives.
+func newProbeServer(t *testing.T, repos string) (*httptest.Server, *[]string) {
+	t.Helper()
+	var mu sync.Mutex
+	var requests []string
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /user/repos", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(repos))
+	})
+	mux.HandleFunc("GET /repos/octo/repo/issues", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`[]`))
+	})
+	mux.HandleFunc("POST /repos/octo/repo/issues", func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, `{"message":"Invalid request.\n\n\"title\" wasn't supplied."}`, http.StatusUnprocessableEntity)
+	})
+	mux.HandleFunc("GET /repos/octo/repo/commits", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`[]`))
+	})
+	mux.HandleFunc("PUT /repos/octo/repo/contents/", func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, notAccessible, http.StatusForbidden)
+	})
+	mux.HandleFunc("GET /repos/octo/repo/pulls", func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, notAccessible, http.StatusForbidden)
+	})
+	mux.HandleFunc("GET /repos/octo/repo/actions/runs", func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, `{"message":"You have exceeded a secondary rate limit."}`, http.StatusForbidden)
+	})
+	mux.HandleFunc("GET /notifications", func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, notAccessible, http.StatusForbidden)
+	})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer github_pat_test" {
+			http.Error(w, `{"message":"Bad credentials"}`, http.StatusUnauthorized)
+			return
+		}
+		mu.Lock()
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		mu.Unlock()
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func TestProbePermissions(t *testing.T) {
+	server, requests := newProbeServer(t, `[{"full_name":"octo/repo"}]`)
+	prober := NewPermissionProber(testAPIHostResolver{baseURL: server.URL + "/"}, FetcherOptions{})
+
+	permissions, err := prober.ProbePermissions(context.Background(), "github_pat_test", []Permission{
+		IssuesRead, IssuesWrite,
+		ContentsWrite,
+		PullRequestsWrite,
+		ActionsRead,
+		NotificationsRead,
+		DiscussionsRead,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, PermissionSet{
+		IssuesRead:        true,
+		IssuesWrite:       true,
+		ContentsWrite:     false,
+		PullRequestsWrite: false,
+		NotificationsRead: false,
+	}, permissions, "inconclusive and unprobeable permissions are left out, as are reads that weren't asked for")
+
+	assert.NotContains(t, *requests, "POST /repos/octo/repo/pulls", "writes are not probed when reads are refused")
+}
+
+func TestProbePermissionsWithoutRepository(t *testing.T) {
+	server, _ := newProbeServer(t, `[]`)
+	prober := NewPermissionProber(testAPIHostResolver{baseURL: server.URL + "/"}, FetcherOptions{})
+
+	permissions, err := prober.ProbePermissions(context.Background(), "github_pat_test", []Permission{IssuesRead, NotificationsRead})
+	require.NoError(t, err)
+	assert.Equal(t, PermissionSet{NotificationsRead: false}, permissions, "repository permissions need a repository to probe")
+}
+
+func TestProbePermissionsInvalidToken(t *testing.T) {
+	server, _ := newProbeServer(t, `[]`)
+	prober := NewPermissionProber(testAPIHostResolver{baseURL: server.URL + "/"}, FetcherOptions{})
+
+	_, err := prober.ProbePermissions(context.Background(), "github_pat_invalid", []Permission{IssuesRead})
+	assert.ErrorContains(t, err, "invalid or expired token")
+}
@@ -0,0 +1,168 @@
+package scopes
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/github/github-mcp-server/pkg/inventory"
+)
+
+// Permission represents a fine-grained permission of a token, as a resource and an access
+// level such as "issues:write". Fine-grained personal access tokens and GitHub App tokens
+// have permissions instead of OAuth scopes.
+// See https://docs.github.com/en/rest/authentication/permissions-required-for-fine-grained-personal-access-tokens
+type Permission string
+
+const (
+	// ContentsRead grants read access to repository contents, commits, branches and releases
+	ContentsRead Permission = "contents:read"
+	// ContentsWrite grants write access to repository contents, including merging pull requests
+	ContentsWrite Permission = "contents:write"
+
+	// IssuesRead grants read access to issues and their comments and labels
+	IssuesRead Permission = "issues:read"
+	// IssuesWrite grants write access to issues and their comments and labels
+	IssuesWrite Permission = "issues:write"
+
+	// PullRequestsRead grants read access to pull requests and their reviews
+	PullRequestsRead Permission = "pull_requests:read"
+	// PullRequestsWrite grants write access to pull requests and their reviews
+	PullRequestsWrite Permission = "pull_requests:write"
+
+	// ActionsRead grants read access to workflows, runs and job logs
+	ActionsRead Permission = "actions:read"
+	// ActionsWrite grants access to run, re-run and cancel workflows
+	ActionsWrite Permission = "actions:write"
+
+	// SecurityEventsRead grants read access to code scanning alerts
+	SecurityEventsRead Permission = "security_events:read"
+
+	// SecretScanningAlertsRead grants read access to secret scanning alerts
+	SecretScanningAlertsRead Permission = "secret_scanning_alerts:read"
+
+	// VulnerabilityAlertsRead grants read access to Dependabot alerts
+	VulnerabilityAlertsRead Permission = "vulnerability_alerts:read"
+
+	// DiscussionsRead grants read access to discussions
+	DiscussionsRead Permission = "discussions:read"
+
+	// RepositoryAdvisoriesRead grants read access to repository security advisories
+	RepositoryAdvisoriesRead Permission = "repository_advisories:read"
+
+	// AdministrationWrite grants access to create and fork repositories
+	AdministrationWrite Permission = "administration:write"
+
+	// MembersRead grants read access to organization teams and members
+	MembersRead Permission = "members:read"
+
+	// GistsWrite grants access to create and update gists
+	GistsWrite Permission = "gists:write"
+
+	// StarringRead grants read access to starred repositories
+	StarringRead Permission = "starring:read"
+	// StarringWrite grants access to star and unstar repositories
+	StarringWrite Permission = "starring:write"
+
+	// NotificationsRead stands for access to the notifications API. It is not a permission
+	// that can be granted: fine-grained personal access tokens can't use notifications.
+	NotificationsRead Permission = "notifications:read"
+	// NotificationsWrite stands for managing notifications, which is likewise unavailable.
+	NotificationsWrite Permission = "notifications:write"
+)
+
+// Resource returns the resource of the permission, such as "issues".
+func (p Permission) Resource() string {
+	resource, _, _ := strings.Cut(string(p), ":")
+	return resource
+}
+
+// IsWrite reports whether the permission grants write access.
+func (p Permission) IsWrite() bool {
+	return strings.HasSuffix(string(p), ":write")
+}
+
+// Read returns the read permission of the same resource. Write access implies read access.
+func (p Permission) Read() Permission {
+	return Permission(p.Resource() + ":read")
+}
+
+// toolsetPermissionResources maps toolsets to the resource their tools need. Tools need
+// read access when they are read-only and write access otherwise.
+var toolsetPermissionResources = map[inventory.ToolsetID]string{
+	"repos":               "contents",
+	"git":                 "contents",
+	"issues":              "issues",
+	"labels":              "issues",
+	"pull_requests":       "pull_requests",
+	"actions":             "actions",
+	"code_security":       "security_events",
+	"secret_protection":   "secret_scanning_alerts",
+	"dependabot":          "vulnerability_alerts",
+	"discussions":         "discussions",
+	"security_advisories": "repository_advisories",
+	"gists":               "gists",
+	"stargazers":          "starring",
+	"notifications":       "notifications",
+}
+
+// toolPermissionOverrides lists the permissions of tools that differ from their toolset.
+// An empty list means the tool needs no permission beyond the metadata access every token has.
+var toolPermissionOverrides = map[string][]Permission{
+	"search_code":                     {},
+	"search_repositories":             {},
+	"create_repository":               {AdministrationWrite},
+	"fork_repository":                 {AdministrationWrite},
+	"merge_pull_request":              {ContentsWrite},
+	"get_gist":                        {},
+	"list_gists":                      {},
+	"get_teams":                       {MembersRead},
+	"get_team_members":                {MembersRead},
+	"get_global_security_advisory":    {},
+	"list_global_security_advisories": {},
+}
+
+// ToolPermissions returns the fine-grained permissions a tool needs, or nil when it needs
+// none or its needs are not known.
+func ToolPermissions(tool *inventory.ServerTool) []Permission {
+	if permissions, ok := toolPermissionOverrides[tool.Tool.Name]; ok {
+		return permissions
+	}
+	resource, ok := toolsetPermissionResources[tool.Toolset.ID]
+	if !ok {
+		return nil
+	}
+	if tool.IsReadOnly() {
+		return []Permission{Permission(resource + ":read")}
+	}
+	return []Permission{Permission(resource + ":write")}
+}
+
+// PermissionsForTools returns the permissions needed by any of the tools, sorted.
+func PermissionsForTools(tools []inventory.ServerTool) []Permission {
+	var permissions []Permission
+	for i := range tools {
+		for _, permission := range ToolPermissions(&tools[i]) {
+			if !slices.Contains(permissions, permission) {
+				permissions = append(permissions, permission)
+			}
+		}
+	}
+	slices.Sort(permissions)
+	return permissions
+}
+
+// PermissionSet records which permissions a token was found to have (true) or to lack
+// (false). Permissions that could not be determined are absent.
+type PermissionSet map[Permission]bool
+
+// Allows reports whether none of the required permissions is known to be missing.
+// Permissions that could not be determined are assumed to be granted, so that tools are
+// only hidden when they would certainly fail.
+func (s PermissionSet) Allows(required ...Permission) bool {
+	for _, permission := range required {
+		if granted, known := s[permission]; known && !granted {
+			return false
+		}
+	}
+	return true
+}
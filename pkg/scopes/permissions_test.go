@@ -0,0 +1,81 @@
+package scopes
+
+import (
+	"testing"
+
+	"github.com/github/github-mcp-server/pkg/inventory"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPermission(t *testing.T) {
+	assert.Equal(t, "pull_requests", PullRequestsWrite.Resource())
+	assert.True(t, PullRequestsWrite.IsWrite())
+	assert.False(t, PullRequestsRead.IsWrite())
+	assert.Equal(t, PullRequestsRead, PullRequestsWrite.Read())
+	assert.Equal(t, PullRequestsRead, PullRequestsRead.Read())
+}
+
+func TestToolPermissions(t *testing.T) {
+	readOnly := &mcp.ToolAnnotations{ReadOnlyHint: true}
+
+	tests := []struct {
+		name     string
+		tool     inventory.ServerTool
+		expected []Permission
+	}{
+		{
+			name:     "read-only tool needs read access to its toolset's resource",
+			tool:     inventory.ServerTool{Tool: mcp.Tool{Name: "list_issues", Annotations: readOnly}, Toolset: inventory.ToolsetMetadata{ID: "issues"}},
+			expected: []Permission{IssuesRead},
+		},
+		{
+			name:     "other tools need write access",
+			tool:     inventory.ServerTool{Tool: mcp.Tool{Name: "issue_write"}, Toolset: inventory.ToolsetMetadata{ID: "issues"}},
+			expected: []Permission{IssuesWrite},
+		},
+		{
+			name:     "override",
+			tool:     inventory.ServerTool{Tool: mcp.Tool{Name: "merge_pull_request"}, Toolset: inventory.ToolsetMetadata{ID: "pull_requests"}},
+			expected: []Permission{ContentsWrite},
+		},
+		{
+			name:     "override without permissions",
+			tool:     inventory.ServerTool{Tool: mcp.Tool{Name: "search_code", Annotations: readOnly}, Toolset: inventory.ToolsetMetadata{ID: "repos"}},
+			expected: []Permission{},
+		},
+		{
+			name:     "toolset without a resource",
+			tool:     inventory.ServerTool{Tool: mcp.Tool{Name: "get_me", Annotations: readOnly}, Toolset: inventory.ToolsetMetadata{ID: "context"}},
+			expected: nil,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, ToolPermissions(&tc.tool))
+		})
+	}
+}
+
+func TestPermissionsForTools(t *testing.T) {
+	tools := []inventory.ServerTool{
+		{Tool: mcp.Tool{Name: "issue_write"}, Toolset: inventory.ToolsetMetadata{ID: "issues"}},
+		{Tool: mcp.Tool{Name: "add_issue_comment"}, Toolset: inventory.ToolsetMetadata{ID: "issues"}},
+		{Tool: mcp.Tool{Name: "actions_list", Annotations: &mcp.ToolAnnotations{ReadOnlyHint: true}}, Toolset: inventory.ToolsetMetadata{ID: "actions"}},
+		{Tool: mcp.Tool{Name: "get_me"}, Toolset: inventory.ToolsetMetadata{ID: "context"}},
+	}
+
+	assert.Equal(t, []Permission{ActionsRead, IssuesWrite}, PermissionsForTools(tools))
+}
+
+func TestPermissionSetAllows(t *testing.T) {
+	set := PermissionSet{IssuesRead: true, IssuesWrite: false}
+
+	assert.True(t, set.Allows(), "no permissions are required")
+	assert.True(t, set.Allows(IssuesRead))
+	assert.False(t, set.Allows(IssuesWrite))
+	assert.False(t, set.Allows(IssuesRead, IssuesWrite), "all required permissions must be allowed")
+	assert.True(t, set.Allows(ActionsRead), "unknown permissions are assumed to be granted")
+	assert.True(t, PermissionSet(nil).Allows(IssuesWrite))
+}
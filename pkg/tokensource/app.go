@@ -0,0 +1,220 @@
+package tokensource
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	gogithub "github.com/google/go-github/v82/github"
+)
+
+const (
+	// appJWTLifetime is how long app JWTs are valid. GitHub accepts at most ten minutes.
+	appJWTLifetime = 9 * time.Minute
+	// appJWTClockSkew backdates app JWTs to allow for clock drift with GitHub.
+	appJWTClockSkew = time.Minute
+	// installationTokenRefreshMargin is how long before expiry installation tokens are
+	// replaced, so that requests in flight don't use an expiring token.
+	installationTokenRefreshMargin = 5 * time.Minute
+)
+
+// AppConfig configures authentication as a GitHub App installation.
+type AppConfig struct {
+	// AppID is the ID, or client ID, of the GitHub App.
+	AppID string
+	// PrivateKey is the PEM-encoded private key of the app.
+	PrivateKey []byte
+	// InstallationID is the installation to authenticate as.
+	InstallationID int64
+	// Owner is the organization, user or "owner/repo" repository the app is installed on.
+	// It is used to look up the installation when InstallationID is not set.
+	Owner string
+	// BaseURL is the base URL of the GitHub REST API.
+	BaseURL *url.URL
+	// HTTPClient is used for requests to GitHub. Defaults to a client with a timeout.
+	HTTPClient *http.Client
+}
+
+// AppInstallation provides installation access tokens of a GitHub App, minting a new
+// token shortly before the current one expires.
+type AppInstallation struct {
+	appID  string
+	key    *rsa.PrivateKey
+	owner  string
+	client *gogithub.Client
+	now    func() time.Time
+
+	mu             sync.Mutex
+	installationID int64
+	token          string
+	expiresAt      time.Time
+}
+
+var _ TokenSource = (*AppInstallation)(nil)
+
+// NewAppInstallation creates a token source for a GitHub App installation.
+func NewAppInstallation(cfg AppConfig) (*AppInstallation, error) {
+	if cfg.AppID == "" {
+		return nil, errors.New("GitHub App ID is required")
+	}
+	if cfg.InstallationID == 0 && cfg.Owner == "" {
+		return nil, errors.New("GitHub App installation ID or owner is required")
+	}
+	if cfg.BaseURL == nil {
+		return nil, errors.New("GitHub API base URL is required")
+	}
+	key, err := parsePrivateKey(cfg.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	httpClient := cfg.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 30 * time.Second}
+	}
+	s := &AppInstallation{
+		appID:          cfg.AppID,
+		key:            key,
+		owner:          cfg.Owner,
+		installationID: cfg.InstallationID,
+		now:            time.Now,
+	}
+
+	// Requests for installation tokens are authenticated as the app itself
+	client := gogithub.NewClient(&http.Client{
+		Transport: &appJWTTransport{source: s, transport: transportOf(httpClient)},
+		Timeout:   httpClient.Timeout,
+	})
+	baseURL := *cfg.BaseURL
+	if !strings.HasSuffix(baseURL.Path, "/") {
+		baseURL.Path += "/"
+	}
+	client.BaseURL = &baseURL
+	s.client = client
+	return s, nil
+}
+
+// Token returns the current installation token, minting a new one when it is about to
+// expire.
+func (s *AppInstallation) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != "" && s.now().Before(s.expiresAt.Add(-installationTokenRefreshMargin)) {
+		return s.token, nil
+	}
+
+	if s.installationID == 0 {
+		id, err := s.findInstallation(ctx)
+		if err != nil {
+			return "", err
+		}
+		s.installationID = id
+	}
+
+	token, _, err := s.client.Apps.CreateInstallationToken(ctx, s.installationID, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create installation token: %w", err)
+	}
+	s.token = token.GetToken()
+	s.expiresAt = token.GetExpiresAt().Time
+	return s.token, nil
+}
+
+// findInstallation looks up the installation on the configured owner.
+func (s *AppInstallation) findInstallation(ctx context.Context) (int64, error) {
+	var installation *gogithub.Installation
+	var err error
+	if owner, repo, ok := strings.Cut(s.owner, "/"); ok {
+		installation, _, err = s.client.Apps.FindRepositoryInstallation(ctx, owner, repo)
+	} else {
+		var resp *gogithub.Response
+		installation, resp, err = s.client.Apps.FindOrganizationInstallation(ctx, s.owner)
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			installation, _, err = s.client.Apps.FindUserInstallation(ctx, s.owner)
+		}
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to find GitHub App installation for %s: %w", s.owner, err)
+	}
+	return installation.GetID(), nil
+}
+
+// jwt returns a JSON Web Token that authenticates as the app.
+func (s *AppInstallation) jwt() (string, error) {
+	now := s.now()
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"RS256","typ":"JWT"}`))
+	claims, err := json.Marshal(map[string]any{
+		"iat": now.Add(-appJWTClockSkew).Unix(),
+		"exp": now.Add(appJWTLifetime).Unix(),
+		"iss": s.appID,
+	})
+	if err != nil {
+		return "", err
+	}
+	signingInput := header + "." + base64.RawURLEncoding.EncodeToString(claims)
+
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, s.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign GitHub App JWT: %w", err)
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// appJWTTransport authenticates requests as the app.
+type appJWTTransport struct {
+	source    *AppInstallation
+	transport http.RoundTripper
+}
+
+func (t *appJWTTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	jwt, err := t.source.jwt()
+	if err != nil {
+		return nil, err
+	}
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+jwt)
+	return t.transport.RoundTrip(req)
+}
+
+func transportOf(client *http.Client) http.RoundTripper {
+	if client.Transport != nil {
+		return client.Transport
+	}
+	return http.DefaultTransport
+}
+
+// parsePrivateKey parses a PKCS#1 or PKCS#8 PEM-encoded RSA key, as downloaded from the
+// app settings.
+func parsePrivateKey(data []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("GitHub App private key is not PEM encoded")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse GitHub App private key: %w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("GitHub App private key is not an RSA key")
+	}
+	return key, nil
+}
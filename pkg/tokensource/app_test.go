@@ -0,0 +1,236 @@
+package tokensource
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// tokenEndpoint stubs the GitHub App endpoints, checking that requests are signed by the
+// app's key.
+type tokenEndpoint struct {
+	t      *testing.T
+	key    *rsa.PublicKey
+	expiry time.Duration
+
+	mu     sync.Mutex
+	minted []int64
+}
+
+func (e *tokenEndpoint) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /app/installations/{id}/access_tokens", func(w http.ResponseWriter, r *http.Request) {
+		e.verifyJWT(r)
+		var id int64
+		_, err := fmt.Sscan(r.PathValue("id"), &id)
+		require.NoError(e.t, err)
+
+		e.mu.Lock()
+		e.minted = append(e.minted, id)
+		token := fmt.Sprintf("ghs_%d_%d", id, len(e.minted))
+		e.mu.Unlock()
+
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"token":      token,
+			"expires_at": time.Now().Add(e.expiry).UTC().Format(time.RFC3339),
+		})
+	})
+	mux.HandleFunc("GET /orgs/{org}/installation", func(w http.ResponseWriter, r *http.Request) {
+		e.verifyJWT(r)
+		if r.PathValue("org") != "github" {
+			http.Error(w, `{"message":"Not Found"}`, http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":1}`))
+	})
+	mux.HandleFunc("GET /users/{user}/installation", func(w http.ResponseWriter, r *http.Request) {
+		e.verifyJWT(r)
+		_, _ = w.Write([]byte(`{"id":2}`))
+	})
+	mux.HandleFunc("GET /repos/{owner}/{repo}/installation", func(w http.ResponseWriter, r *http.Request) {
+		e.verifyJWT(r)
+		_, _ = w.Write([]byte(`{"id":3}`))
+	})
+	return mux
+}
+
+func (e *tokenEndpoint) verifyJWT(r *http.Request) {
+	jwt, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	require.True(e.t, ok, "requests are authenticated with a bearer token")
+
+	parts := strings.Split(jwt, ".")
+	require.Len(e.t, parts, 3)
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	require.NoError(e.t, err)
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	require.NoError(e.t, rsa.VerifyPKCS1v15(e.key, crypto.SHA256, digest[:], signature), "JWT is signed by the app key")
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	require.NoError(e.t, err)
+	var claims struct {
+		Iss string `json:"iss"`
+		Iat int64  `json:"iat"`
+		Exp int64  `json:"exp"`
+	}
+	require.NoError(e.t, json.Unmarshal(payload, &claims))
+	assert.Equal(e.t, "12345", claims.Iss)
+	assert.LessOrEqual(e.t, claims.Exp-claims.Iat, int64(10*time.Minute/time.Second), "GitHub rejects JWTs valid for over ten minutes")
+}
+
+func (e *tokenEndpoint) mintedIDs() []int64 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]int64(nil), e.minted...)
+}
+
+func newTestApp(t *testing.T, cfg AppConfig) (*AppInstallation, *tokenEndpoint) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	endpoint := &tokenEndpoint{t: t, key: &key.PublicKey, expiry: time.Hour}
+	server := httptest.NewServer(endpoint.handler())
+	t.Cleanup(server.Close)
+
+	baseURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	cfg.AppID = "12345"
+	cfg.BaseURL = baseURL
+	cfg.PrivateKey = pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+
+	app, err := NewAppInstallation(cfg)
+	require.NoError(t, err)
+	return app, endpoint
+}
+
+func TestAppInstallationToken(t *testing.T) {
+	app, endpoint := newTestApp(t, AppConfig{InstallationID: 99})
+
+	token, err := app.Token(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, "ghs_99_1", token)
+
+	token, err = app.Token(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, "ghs_99_1", token, "tokens are reused until they near expiry")
+	assert.Equal(t, []int64{99}, endpoint.mintedIDs())
+}
+
+func TestAppInstallationTokenRefresh(t *testing.T) {
+	app, endpoint := newTestApp(t, AppConfig{InstallationID: 99})
+	now := time.Now()
+	app.now = func() time.Time { return now }
+
+	token, err := app.Token(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, "ghs_99_1", token)
+
+	now = now.Add(time.Hour - installationTokenRefreshMargin - time.Minute)
+	token, err = app.Token(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, "ghs_99_1", token)
+
+	now = now.Add(2 * time.Minute)
+	token, err = app.Token(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, "ghs_99_2", token, "a new token is minted shortly before the current one expires")
+	assert.Len(t, endpoint.mintedIDs(), 2)
+}
+
+func TestAppInstallationLookup(t *testing.T) {
+	tests := []struct {
+		name       string
+		owner      string
+		expectedID int64
+	}{
+		{name: "organization", owner: "github", expectedID: 1},
+		{name: "user", owner: "octocat", expectedID: 2},
+		{name: "repository", owner: "octocat/hello-world", expectedID: 3},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			app, endpoint := newTestApp(t, AppConfig{Owner: tc.owner})
+
+			_, err := app.Token(t.Context())
+			require.NoError(t, err)
+			assert.Equal(t, []int64{tc.expectedID}, endpoint.mintedIDs())
+		})
+	}
+}
+
+func TestAppInstallationTokenError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, `{"message":"A JSON web token could not be decoded"}`, http.StatusUnauthorized)
+	}))
+	t.Cleanup(server.Close)
+	baseURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	app, err := NewAppInstallation(AppConfig{
+		AppID:          "12345",
+		PrivateKey:     pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8}),
+		InstallationID: 99,
+		BaseURL:        baseURL,
+	})
+	require.NoError(t, err, "PKCS#8 keys are accepted")
+
+	_, err = app.Token(t.Context())
+	require.ErrorContains(t, err, "failed to create installation token")
+}
+
+func TestNewAppInstallationValidation(t *testing.T) {
+	baseURL, err := url.Parse("https://api.github.com/")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		cfg         AppConfig
+		expectedErr string
+	}{
+		{
+			name:        "missing app ID",
+			cfg:         AppConfig{InstallationID: 1, BaseURL: baseURL},
+			expectedErr: "GitHub App ID is required",
+		},
+		{
+			name:        "missing installation",
+			cfg:         AppConfig{AppID: "1", BaseURL: baseURL},
+			expectedErr: "installation ID or owner is required",
+		},
+		{
+			name:        "invalid key",
+			cfg:         AppConfig{AppID: "1", InstallationID: 1, BaseURL: baseURL, PrivateKey: []byte("not a key")},
+			expectedErr: "not PEM encoded",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewAppInstallation(tc.cfg)
+			require.ErrorContains(t, err, tc.expectedErr)
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package tokensource
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// commandTimeout bounds how long a credential command may run.
+const commandTimeout = 30 * time.Second
+
+// Command returns a source that runs a command, such as "gh auth token" or the CLI of a
+// secrets manager, and uses its trimmed output as the token. The token is cached until
+// it is invalidated, when the command is run again.
+func Command(name string, args ...string) TokenSource {
+	return &cachedSource{fetch: func(ctx context.Context) (string, error) {
+		out, err := runCommand(ctx, nil, name, args...)
+		if err != nil {
+			return "", fmt.Errorf("credential command failed: %w", err)
+		}
+		token := strings.TrimSpace(out)
+		if token == "" {
+			return "", errors.New("credential command printed no token")
+		}
+		return token, nil
+	}}
+}
+
+// ShellCommand returns a Command source that runs command with the shell, sh -c or cmd /C
+// on Windows, so that its arguments can be quoted and it can use pipes or variables.
+func ShellCommand(command string) TokenSource {
+	if runtime.GOOS == "windows" {
+		return Command("cmd", "/C", command)
+	}
+	return Command("sh", "-c", command)
+}
+
+// GitCredential returns a source that asks the git credential helpers configured for
+// host, such as the GitHub CLI or Git Credential Manager, for a password to use as the
+// token. The host is a hostname or URL; it defaults to github.com. The token is cached
+// until it is invalidated.
+func GitCredential(host string) TokenSource {
+	protocol, hostname := "https", host
+	if u, err := url.Parse(host); err == nil && u.Scheme != "" {
+		protocol, hostname = u.Scheme, u.Host
+	}
+	if hostname == "" {
+		hostname = "github.com"
+	}
+	request := fmt.Sprintf("protocol=%s\nhost=%s\n\n", protocol, hostname)
+
+	return &cachedSource{fetch: func(ctx context.Context) (string, error) {
+		out, err := runCommand(ctx, strings.NewReader(request), "git", "credential", "fill")
+		if err != nil {
+			return "", fmt.Errorf("git credential helper failed: %w", err)
+		}
+		scanner := bufio.NewScanner(strings.NewReader(out))
+		for scanner.Scan() {
+			if password, ok := strings.CutPrefix(scanner.Text(), "password="); ok && password != "" {
+				return password, nil
+			}
+		}
+		return "", fmt.Errorf("git credential helper returned no password for %s", hostname)
+	}}
+}
+
+// runCommand runs a command and returns its output. The command can't prompt for input:
+// stdin is the given reader or empty, and git is told not to use the terminal.
+func runCommand(ctx context.Context, stdin *strings.Reader, name string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, commandTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, name, args...)
+	if stdin != nil {
+		cmd.Stdin = stdin
+	}
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%w: %s", err, msg)
+		}
+		return "", err
+	}
+	return stdout.String(), nil
+}
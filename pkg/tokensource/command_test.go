@@ -0,0 +1,116 @@
+package tokensource
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeScript writes an executable shell script with the given body.
+func writeScript(t *testing.T, dir, body string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("credential command tests use shell scripts")
+	}
+	path := filepath.Join(dir, "credential.sh")
+	require.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\n"+body), 0700))
+	return path
+}
+
+func TestCommand(t *testing.T) {
+	dir := t.TempDir()
+	tokenFile := filepath.Join(dir, "token")
+	runsFile := filepath.Join(dir, "runs")
+	script := writeScript(t, dir, `echo run >> "$2"; cat "$1"`)
+	require.NoError(t, os.WriteFile(tokenFile, []byte("gho_first\n"), 0600))
+
+	source := Command(script, tokenFile, runsFile)
+	runs := func() int {
+		data, err := os.ReadFile(runsFile)
+		require.NoError(t, err)
+		return len(data) / len("run\n")
+	}
+
+	token, err := source.Token(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, "gho_first", token)
+
+	require.NoError(t, os.WriteFile(tokenFile, []byte("gho_second\n"), 0600))
+	token, err = source.Token(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, "gho_first", token, "the token is cached")
+	assert.Equal(t, 1, runs())
+
+	source.(Invalidator).Invalidate("gho_stale")
+	token, err = source.Token(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, "gho_first", token, "only the current token is invalidated")
+
+	source.(Invalidator).Invalidate("gho_first")
+	token, err = source.Token(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, "gho_second", token, "the command is run again once the token is invalidated")
+	assert.Equal(t, 2, runs())
+}
+
+func TestShellCommand(t *testing.T) {
+	script := writeScript(t, t.TempDir(), `echo "$1"`)
+
+	// Quoted arguments reach the command whole, as with a secrets manager reference
+	token, err := ShellCommand(script + ` "op://vault/GitHub Token/credential"`).Token(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, "op://vault/GitHub Token/credential", token)
+}
+
+func TestCommandErrors(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := Command(writeScript(t, dir, "echo 'not logged in' >&2; exit 1")).Token(t.Context())
+	assert.ErrorContains(t, err, "not logged in", "the command's error output is reported")
+
+	_, err = Command(writeScript(t, dir, "echo")).Token(t.Context())
+	assert.ErrorContains(t, err, "printed no token")
+
+	_, err = Command(filepath.Join(dir, "missing")).Token(t.Context())
+	assert.ErrorContains(t, err, "credential command failed")
+}
+
+func TestGitCredential(t *testing.T) {
+	dir := t.TempDir()
+	requestFile := filepath.Join(dir, "request")
+	// The helper records the request it is given and answers with a password
+	helper := writeScript(t, dir, `cat > "`+requestFile+`"; echo username=x-access-token; echo password=gho_helper`)
+
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	t.Setenv("GIT_CONFIG_COUNT", "1")
+	t.Setenv("GIT_CONFIG_KEY_0", "credential.helper")
+	t.Setenv("GIT_CONFIG_VALUE_0", "!"+helper)
+
+	tests := []struct {
+		name         string
+		host         string
+		expectedHost string
+	}{
+		{name: "default host", host: "", expectedHost: "github.com"},
+		{name: "hostname", host: "ghe.example.com", expectedHost: "ghe.example.com"},
+		{name: "URL", host: "https://octo.ghe.com", expectedHost: "octo.ghe.com"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			token, err := GitCredential(tc.host).Token(t.Context())
+			require.NoError(t, err)
+			assert.Equal(t, "gho_helper", token)
+
+			request, err := os.ReadFile(requestFile)
+			require.NoError(t, err)
+			assert.Contains(t, string(request), "protocol=https\n")
+			assert.Contains(t, string(request), "host="+tc.expectedHost+"\n")
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package tokensource
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// File returns a source that reads the token from a file, such as a mounted secret. The
+// file is read again whenever it changes, so the token can be rotated without restarting
+// the server.
+func File(path string) TokenSource {
+	return &fileSource{path: path}
+}
+
+type fileSource struct {
+	path string
+
+	mu      sync.Mutex
+	token   string
+	modTime time.Time
+	size    int64
+}
+
+var _ Invalidator = (*fileSource)(nil)
+
+func (s *fileSource) Token(_ context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read token file: %w", err)
+	}
+	if s.token != "" && info.ModTime().Equal(s.modTime) && info.Size() == s.size {
+		return s.token, nil
+	}
+
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read token file: %w", err)
+	}
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return "", fmt.Errorf("token file %s is empty", s.path)
+	}
+	s.token = token
+	s.modTime = info.ModTime()
+	s.size = info.Size()
+	return token, nil
+}
+
+// Invalidate makes the next call read the file, in case it was replaced within the
+// resolution of its modification time.
+func (s *fileSource) Invalidate(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token == token {
+		s.token = ""
+	}
+}
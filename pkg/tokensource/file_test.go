@@ -0,0 +1,60 @@
+package tokensource
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(path, []byte("ghp_first\n"), 0600))
+	source := File(path)
+
+	token, err := source.Token(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, "ghp_first", token, "surrounding whitespace is trimmed")
+
+	require.NoError(t, os.WriteFile(path, []byte("ghp_second\n"), 0600))
+	require.NoError(t, os.Chtimes(path, time.Now(), time.Now().Add(time.Minute)))
+	token, err = source.Token(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, "ghp_second", token, "the file is re-read when it changes")
+
+	require.NoError(t, os.WriteFile(path, []byte("  \n"), 0600))
+	require.NoError(t, os.Chtimes(path, time.Now(), time.Now().Add(2*time.Minute)))
+	_, err = source.Token(t.Context())
+	assert.ErrorContains(t, err, "is empty")
+
+	require.NoError(t, os.Remove(path))
+	_, err = source.Token(t.Context())
+	assert.ErrorContains(t, err, "failed to read token file")
+}
+
+func TestFileInvalidate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(path, []byte("ghp_first"), 0600))
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	source := File(path)
+
+	token, err := source.Token(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, "ghp_first", token)
+
+	// Replace the file without changing its size or modification time
+	require.NoError(t, os.WriteFile(path, []byte("ghp_other"), 0600))
+	require.NoError(t, os.Chtimes(path, info.ModTime(), info.ModTime()))
+	token, err = source.Token(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, "ghp_first", token)
+
+	source.(Invalidator).Invalidate("ghp_first")
+	token, err = source.Token(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, "ghp_other", token, "invalidated tokens are read again")
+}
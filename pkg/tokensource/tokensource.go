@@ -0,0 +1,66 @@
+// Package tokensource provides the tokens the server authenticates to GitHub with, for
+// credentials that are not a fixed personal access token.
+package tokensource
+
+import (
+	"context"
+	"sync"
+)
+
+// TokenSource provides a token for GitHub API requests. Implementations cache tokens and
+// refresh them as needed, and must be safe for concurrent use.
+type TokenSource interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// Invalidator is implemented by sources whose tokens can be revoked or rotated outside the
+// server. Invalidate discards token, if it is still the current one, so that the next call
+// to Token fetches a fresh token. Callers invalidate tokens that GitHub rejects.
+type Invalidator interface {
+	Invalidate(token string)
+}
+
+// Static returns a source that always provides the same token.
+func Static(token string) TokenSource {
+	return staticSource(token)
+}
+
+type staticSource string
+
+func (s staticSource) Token(context.Context) (string, error) {
+	return string(s), nil
+}
+
+// cachedSource caches the token of a fetch function until it is invalidated.
+type cachedSource struct {
+	fetch func(ctx context.Context) (string, error)
+
+	mu    sync.Mutex
+	token string
+}
+
+var _ Invalidator = (*cachedSource)(nil)
+
+func (s *cachedSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != "" {
+		return s.token, nil
+	}
+	token, err := s.fetch(ctx)
+	if err != nil {
+		return "", err
+	}
+	s.token = token
+	return token, nil
+}
+
+func (s *cachedSource) Invalidate(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token == token {
+		s.token = ""
+	}
+}
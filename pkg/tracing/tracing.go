@@ -0,0 +1,85 @@
+// Package tracing configures the OpenTelemetry SDK to export the spans of MCP requests,
+// and the GitHub API calls they make, to a collector over OTLP/HTTP.
+//
+// Spans are parented through the context: StartSpan begins a child span only when the
+// context already holds one, so code deep in the call stack can be instrumented without
+// access to the tracer provider.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ServiceName is the service.name resource attribute of exported spans.
+const ServiceName = "github-mcp-server"
+
+// ScopeName is the instrumentation scope of the spans recorded by the server.
+const ScopeName = "github.com/github/github-mcp-server"
+
+// Propagator reads and writes the W3C traceparent header.
+var Propagator propagation.TextMapPropagator = propagation.TraceContext{}
+
+// ProviderOptions configures NewProvider.
+type ProviderOptions struct {
+	// Endpoint is the base URL of the collector, e.g. "http://localhost:4318". Spans are
+	// posted to its /v1/traces path, as with OTEL_EXPORTER_OTLP_ENDPOINT. Headers, e.g. for
+	// collector authentication, are read from OTEL_EXPORTER_OTLP_HEADERS.
+	Endpoint string
+	// ServiceVersion is reported as the service.version resource attribute.
+	ServiceVersion string
+	// Logger reports export failures. It replaces the global OpenTelemetry error handler.
+	Logger *slog.Logger
+}
+
+// NewProvider creates a tracer provider that exports spans in batches to the collector at
+// opts.Endpoint. Shut it down to export the spans still queued.
+func NewProvider(ctx context.Context, opts ProviderOptions) (*sdktrace.TracerProvider, error) {
+	if opts.Endpoint == "" {
+		return nil, fmt.Errorf("no OTLP endpoint configured")
+	}
+	if !strings.HasPrefix(opts.Endpoint, "http://") && !strings.HasPrefix(opts.Endpoint, "https://") {
+		return nil, fmt.Errorf("OTLP endpoint %q must be an http or https URL", opts.Endpoint)
+	}
+
+	exporter, err := otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(strings.TrimSuffix(opts.Endpoint, "/")+"/v1/traces"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
+	}
+
+	attrs := []attribute.KeyValue{attribute.String("service.name", ServiceName)}
+	if opts.ServiceVersion != "" {
+		attrs = append(attrs, attribute.String("service.version", opts.ServiceVersion))
+	}
+	if opts.Logger != nil {
+		otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+			opts.Logger.Warn("failed to export spans", "error", err)
+		}))
+	}
+
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(attrs...)),
+	), nil
+}
+
+// StartSpan begins a child of the span in ctx, with the tracer provider that recorded it.
+// If ctx holds no span, tracing is not active for this operation and the returned span
+// does nothing.
+func StartSpan(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	parent := trace.SpanFromContext(ctx)
+	if !parent.SpanContext().IsValid() {
+		return ctx, parent
+	}
+	return parent.TracerProvider().Tracer(ScopeName).Start(ctx, name, opts...)
+}
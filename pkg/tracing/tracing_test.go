@@ -0,0 +1,97 @@
+package tracing
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	collectortrace "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestStartSpan(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	ctx, root := provider.Tracer(ScopeName).Start(context.Background(), "tools/call get_me", trace.WithSpanKind(trace.SpanKindServer))
+	_, child := StartSpan(ctx, "lockdown.is_safe_content", trace.WithSpanKind(trace.SpanKindInternal))
+	child.SetStatus(codes.Error, "boom")
+	child.End()
+	root.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "lockdown.is_safe_content", spans[0].Name())
+	assert.Equal(t, root.SpanContext().TraceID(), spans[0].SpanContext().TraceID())
+	assert.Equal(t, root.SpanContext().SpanID(), spans[0].Parent().SpanID())
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+
+	// Without an active span no span is recorded
+	ctx, span := StartSpan(context.Background(), "lockdown.is_safe_content")
+	assert.False(t, span.IsRecording())
+	assert.False(t, trace.SpanContextFromContext(ctx).IsValid())
+}
+
+func TestNewProvider(t *testing.T) {
+	var mu sync.Mutex
+	var received []*collectortrace.ExportTraceServiceRequest
+	var authorization string
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/traces" {
+			http.NotFound(w, r)
+			return
+		}
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		var req collectortrace.ExportTraceServiceRequest
+		require.NoError(t, proto.Unmarshal(body, &req))
+
+		mu.Lock()
+		defer mu.Unlock()
+		received = append(received, &req)
+		authorization = r.Header.Get("Authorization")
+		w.Header().Set("Content-Type", "application/x-protobuf")
+	}))
+	t.Cleanup(collector.Close)
+	t.Setenv("OTEL_EXPORTER_OTLP_HEADERS", "Authorization=Bearer%20collector-token")
+
+	provider, err := NewProvider(context.Background(), ProviderOptions{Endpoint: collector.URL + "/", ServiceVersion: "1.2.3"})
+	require.NoError(t, err)
+	_, span := provider.Tracer(ScopeName).Start(context.Background(), "tools/call get_me")
+	span.End()
+	require.NoError(t, provider.Shutdown(context.Background()))
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, received, 1)
+	assert.Equal(t, "Bearer collector-token", authorization)
+
+	resourceSpans := received[0].GetResourceSpans()
+	require.Len(t, resourceSpans, 1)
+	resource := map[string]string{}
+	for _, attr := range resourceSpans[0].GetResource().GetAttributes() {
+		resource[attr.GetKey()] = attr.GetValue().GetStringValue()
+	}
+	assert.Equal(t, ServiceName, resource["service.name"])
+	assert.Equal(t, "1.2.3", resource["service.version"])
+	require.Len(t, resourceSpans[0].GetScopeSpans(), 1)
+	assert.Equal(t, ScopeName, resourceSpans[0].GetScopeSpans()[0].GetScope().GetName())
+	assert.Equal(t, "tools/call get_me", resourceSpans[0].GetScopeSpans()[0].GetSpans()[0].GetName())
+}
+
+func TestNewProviderValidatesEndpoint(t *testing.T) {
+	_, err := NewProvider(context.Background(), ProviderOptions{})
+	require.ErrorContains(t, err, "no OTLP endpoint")
+
+	_, err = NewProvider(context.Background(), ProviderOptions{Endpoint: "localhost:4318"})
+	require.ErrorContains(t, err, "must be an http or https URL")
+}